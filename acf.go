@@ -0,0 +1,175 @@
+package dynamics
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// acfThreshold is the least normalised autocorrelation a peak must reach for
+// EstimateFrequencyACF to take it for the period. A sine in noise of equal
+// power peaks at about one half.
+const acfThreshold = 0.2
+
+// acfDirectLimit is the most multiply-adds, values times lags, for which
+// autocorrelation sums the products directly rather than going through the
+// FFT.
+const acfDirectLimit = 1 << 16
+
+// Autocorrelation returns the autocorrelation of the data about its mean at
+// lags of 0 to maxLag samples, normalised to 1 at lag zero, for periodicity
+// checks or estimating a noise floor. Each coefficient is Σ xₙ·xₙ₊ₖ over the
+// record divided by Σ xₙ², so coefficients taper towards zero at long lags as
+// fewer products overlap. Short records are summed directly and long ones go
+// through the FFT.
+//
+// Lags count samples, so the samples should be evenly spaced; the spacing is
+// not checked.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - maxLag: The largest lag, from 0 to one less than the number of samples
+//
+// Returns:
+//   - []float64: The coefficients at lags 0 to maxLag, nil when maxLag is out
+//     of range or the data is constant
+func Autocorrelation(data []SingleChannelSample, maxLag int) []float64 {
+	if maxLag < 0 || maxLag >= len(data) {
+		return nil
+	}
+	values := scratchValues(data)
+	defer floatScratch.put(values)
+	acf := autocorrelation(*values, maxLag)
+	if !(acf[0] > 0) {
+		return nil
+	}
+	scale := acf[0]
+	for k := range acf {
+		acf[k] /= scale
+	}
+	return acf
+}
+
+// EstimateFrequencyACF estimates the frequency of the data from its
+// autocorrelation, which averages the noise over the whole record rather than
+// letting it add crossings, so it holds up at signal-to-noise ratios where the
+// zero crossing rate fails. The mean is removed and the autocorrelation,
+// normalised to 1 at lag zero, is computed through the FFT. The period is the
+// lag of the highest point of the first positive lobe after the correlation
+// has first gone negative whose height reaches 0.2, placed between samples by
+// a parabola through it and its neighbours. Lobes below that height, from noise
+// about a crossing of the correlation, are passed over.
+//
+// The samples must be evenly spaced, as for Spectrum.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The estimated frequency in Hz, or 0 on error
+//   - error: As for Spectrum, or an error if no lobe within the first half of
+//     the record reaches the threshold, as for a constant signal, noise alone
+//     or a record holding less than two cycles
+func EstimateFrequencyACF(data []SingleChannelSample) (float64, error) {
+	step, err := evenStep(data)
+	if err != nil {
+		return 0, err
+	}
+	values := scratchValues(data)
+	defer floatScratch.put(values)
+	scratch := floatScratch.get(len(data)/2 + 1)
+	defer floatScratch.put(scratch)
+	acf := autocorrelationInto(*scratch, *values)
+	if len(acf) == 0 || !(acf[0] > 0) {
+		return 0, errors.New("dynamics: signal has no autocorrelation peak")
+	}
+
+	lag := 1
+	for lag < len(acf) && acf[lag] >= 0 {
+		lag++
+	}
+	for lag < len(acf) {
+		// skip the negative stretch, then find the top of the lobe after it
+		for lag < len(acf) && acf[lag] < 0 {
+			lag++
+		}
+		peak := lag
+		for ; lag < len(acf) && acf[lag] >= 0; lag++ {
+			if acf[lag] > acf[peak] {
+				peak = lag
+			}
+		}
+		if lag == len(acf) || acf[peak] < acfThreshold*acf[0] {
+			continue
+		}
+		a, b, c := acf[peak-1], acf[peak], acf[peak+1]
+		offset := 0.0
+		if curvature := a - 2*b + c; curvature < 0 {
+			offset = (a - c) / (2 * curvature)
+		}
+		return 1 / ((float64(peak) + offset) * step), nil
+	}
+	return 0, errors.New("dynamics: signal has no autocorrelation peak")
+}
+
+// autocorrelation returns the autocorrelation of the values about their mean
+// at lags 0 to maxLag, Σ xₙ·xₙ₊ₖ without normalisation. Up to acfDirectLimit
+// products it is summed directly; beyond, it is computed through the FFT with
+// the values padded to at least twice their length so that the correlation
+// does not wrap around.
+//
+// Parameters:
+//   - values: Evenly spaced values
+//   - maxLag: The largest lag, less than the number of values
+//
+// Returns:
+//   - []float64: The autocorrelation at each lag, nil for no values
+func autocorrelation(values []float64, maxLag int) []float64 {
+	if len(values) == 0 || maxLag < 0 {
+		return nil
+	}
+	return autocorrelationInto(make([]float64, min(maxLag, len(values)-1)+1), values)
+}
+
+// autocorrelationInto is autocorrelation writing the lags into acf, whose
+// length sets maxLag and is cut to the number of values.
+func autocorrelationInto(acf, values []float64) []float64 {
+	n := len(values)
+	if n == 0 {
+		return acf[:0]
+	}
+	acf = acf[:min(len(acf), n)]
+	maxLag := len(acf) - 1
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+
+	if n*(maxLag+1) <= acfDirectLimit {
+		for k := range acf {
+			var sum float64
+			for i := k; i < n; i++ {
+				sum += (values[i] - mean) * (values[i-k] - mean)
+			}
+			acf[k] = sum
+		}
+		return acf
+	}
+
+	scratch := complexScratch.get(1 << bits.Len(uint(2*n-1)))
+	defer complexScratch.put(scratch)
+	x := *scratch
+	for i, v := range values {
+		x[i] = complex(v-mean, 0)
+	}
+	fft(x)
+	for i, v := range x {
+		x[i] = complex(real(v)*real(v)+imag(v)*imag(v), 0)
+	}
+	ifft(x)
+
+	for k := range acf {
+		acf[k] = real(x[k])
+	}
+	return acf
+}
@@ -0,0 +1,171 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEstimateFrequencyACF(t *testing.T) {
+	// Generate sample data: a 50 Hz sine sampled at 10 kHz for 1 s, clean and
+	// with white noise of equal power, 0 dB SNR
+	clean := GenerateSineWave(50, 1, 1, 10000)
+	noisy := AddNoise(clean, 0, 1)
+
+	// Run the test: the ACF estimate holds within a few percent in the noise,
+	// while the noise adds crossings far beyond one a cycle
+	for _, tt := range []struct {
+		name string
+		data []SingleChannelSample
+	}{
+		{"clean", clean},
+		{"noisy", noisy},
+	} {
+		frequency, err := EstimateFrequencyACF(tt.data)
+		if err != nil {
+			t.Fatalf("%s: EstimateFrequencyACF returned error: %v", tt.name, err)
+		}
+		if math.Abs(frequency-50) > 1 {
+			t.Errorf("%s: EstimateFrequencyACF returned %v Hz, expected within 2%% of 50 Hz", tt.name, frequency)
+		}
+	}
+	if nzcr := NegativeZeroCrossingRate(clean); math.Abs(nzcr-50) > 1 {
+		t.Errorf("clean: NegativeZeroCrossingRate returned %v Hz, expected 50 Hz", nzcr)
+	}
+	if nzcr := NegativeZeroCrossingRate(noisy); nzcr < 500 {
+		t.Errorf("noisy: NegativeZeroCrossingRate returned %v Hz, expected the noise to multiply it", nzcr)
+	}
+
+	// the lag is converted through the timestamps: 1 ms steps give 50 Hz from 20 samples a cycle
+	slow := make([]SingleChannelSample, 500)
+	for i := range slow {
+		slow[i] = SingleChannelSample{Time: 3 + float64(i)/1000, Value: math.Sin(2*math.Pi*float64(i)/20) + 0.2}
+	}
+	if frequency, err := EstimateFrequencyACF(slow); err != nil || math.Abs(frequency-50) > 0.5 {
+		t.Errorf("EstimateFrequencyACF returned %v Hz, %v; expected 50 Hz", frequency, err)
+	}
+}
+
+func TestEstimateFrequencyACFInvalid(t *testing.T) {
+	// Generate sample data
+	dc := make([]SingleChannelSample, 1000)
+	for i := range dc {
+		dc[i] = SingleChannelSample{Time: float64(i) / 1000, Value: 1.5}
+	}
+	uneven := GenerateSineWave(50, 1, 0.1, 1000)
+	uneven[40].Time += 0.0002
+
+	// Run the test
+	if _, err := EstimateFrequencyACF(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("EstimateFrequencyACF of no data returned %v, expected ErrEmptyData", err)
+	}
+	if _, err := EstimateFrequencyACF(uneven); !errors.Is(err, ErrUnevenSpacing) {
+		t.Errorf("EstimateFrequencyACF of unevenly spaced data returned %v, expected ErrUnevenSpacing", err)
+	}
+	for name, data := range map[string][]SingleChannelSample{
+		"DC":        dc,
+		"noise":     GenerateWhiteNoise(1, 1, 1000, 1),
+		"one cycle": GenerateSineWave(5, 1, 0.2, 1000),
+	} {
+		if frequency, err := EstimateFrequencyACF(data); err == nil {
+			t.Errorf("EstimateFrequencyACF of %s returned %v Hz, expected an error", name, frequency)
+		}
+	}
+}
+
+func TestAnalyzeACFFrequency(t *testing.T) {
+	// Generate sample data: a 50 Hz sine of amplitude 1 at 0 dB SNR
+	data := AddNoise(GenerateSineWave(50, 1, 1, 10000), 0, 2)
+
+	// Run the test: the crossing rate misplaces the window, the ACF estimate does not
+	_, zcr := Analyze(data)
+	if zcr < 500 {
+		t.Errorf("Analyze returned NZCR %v, expected the noise to multiply it", zcr)
+	}
+	result, err := AnalyzeDetailed(data, WithACFFrequency())
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed with WithACFFrequency returned error: %v", err)
+	}
+	if math.Abs(result.NZCR-50) > 1 {
+		t.Errorf("AnalyzeDetailed with WithACFFrequency returned frequency %v, expected about 50 Hz", result.NZCR)
+	}
+	if !result.CycleAligned || math.Abs(result.RMSSpan*result.NZCR-math.Round(result.RMSSpan*result.NZCR)) > 1e-6 {
+		t.Errorf("AnalyzeDetailed with WithACFFrequency spanned %v s, expected whole cycles", result.RMSSpan)
+	}
+	// the signal and noise each have an RMS of 1/√2
+	if math.Abs(result.RMS-1) > 0.05 {
+		t.Errorf("AnalyzeDetailed with WithACFFrequency returned RMS %v, expected about 1", result.RMS)
+	}
+	if rms, zcr := Analyze(data, WithACFFrequency()); rms != result.RMS || zcr != result.NZCR {
+		t.Errorf("Analyze with WithACFFrequency returned %v, %v; expected %v, %v", rms, zcr, result.RMS, result.NZCR)
+	}
+	if got := NewAnalyzer(WithACFFrequency()).Analyze(data); got.RMS != result.RMS || got.NZCR != result.NZCR {
+		t.Errorf("Analyzer with WithACFFrequency returned %v, %v; expected %v, %v", got.RMS, got.NZCR, result.RMS, result.NZCR)
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	// Generate sample data: a 100 Hz sine sampled at 10 kHz, long enough to
+	// go through the FFT and short enough to be summed directly
+	for _, duration := range []float64{1, 0.05} {
+		data := GenerateSineWave(100, 1, duration, 10000)
+
+		// Run the test
+		acf := Autocorrelation(data, 120)
+		if len(acf) != 121 || acf[0] != 1 {
+			t.Fatalf("%v s: Autocorrelation returned %d lags starting %v, expected 121 starting 1", duration, len(acf), acf)
+		}
+		lag := 1
+		for lag < len(acf)-1 && !(acf[lag] < 0 && acf[lag+1] > acf[lag]) {
+			lag++
+		}
+		for lag < len(acf)-1 && acf[lag+1] > acf[lag] {
+			lag++
+		}
+		if lag != 100 {
+			t.Errorf("%v s: first autocorrelation peak at lag %d, expected 100", duration, lag)
+		}
+	}
+
+	// white noise decorrelates immediately
+	noise := GenerateWhiteNoise(1, 1, 10000, 1)
+	acf := Autocorrelation(noise, 100)
+	for k := 1; k < len(acf); k++ {
+		if math.Abs(acf[k]) > 0.05 {
+			t.Errorf("white noise: coefficient %v at lag %d, expected near 0", acf[k], k)
+		}
+	}
+
+	// the direct sum and the FFT agree
+	short := noise[:1000]
+	direct, viaFFT := Autocorrelation(short, 64), Autocorrelation(short, 65)
+	for k := range direct {
+		if math.Abs(direct[k]-viaFFT[k]) > 1e-12 {
+			t.Errorf("lag %d: direct %v, FFT %v", k, direct[k], viaFFT[k])
+		}
+	}
+}
+
+func TestAutocorrelationInvalid(t *testing.T) {
+	data := GenerateSineWave(100, 1, 0.01, 10000)
+	cases := []struct {
+		name   string
+		data   []SingleChannelSample
+		maxLag int
+	}{
+		{"negative lag", data, -1},
+		{"lag beyond the record", data, len(data)},
+		{"empty", nil, 0},
+		{"constant", valuesToSamples([]float64{2, 2, 2, 2}), 2},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		if acf := Autocorrelation(c.data, c.maxLag); acf != nil {
+			t.Errorf("%s: Autocorrelation returned %v, expected nil", c.name, acf)
+		}
+	}
+	if acf := Autocorrelation(data, len(data)-1); len(acf) != len(data) {
+		t.Errorf("Autocorrelation returned %d lags, expected %d", len(acf), len(data))
+	}
+}
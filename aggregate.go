@@ -0,0 +1,187 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sync"
+)
+
+// Bucket summarises the samples that fell into one aggregation interval.
+type Bucket struct {
+	Start   float64 `json:"start"`   // inclusive start time of the interval
+	End     float64 `json:"end"`     // exclusive end time of the interval
+	RMS     float64 `json:"rms"`     // root mean square of the samples
+	Min     float64 `json:"min"`     // smallest sample value
+	Max     float64 `json:"max"`     // largest sample value
+	Peak    float64 `json:"peak"`    // largest absolute sample value
+	Samples int     `json:"samples"` // number of samples in the interval
+	Partial bool    `json:"partial"` // the bucket holds the newest sample and was flushed by Close before it was complete
+}
+
+// Aggregator reduces a stream of samples to one Bucket per fixed interval of
+// sample time, for storing a compact trend instead of raw samples. Intervals
+// are aligned to multiples of the bucket duration.
+//
+// Samples may arrive out of order by up to the tolerance: a bucket is only
+// emitted once a sample at least tolerance seconds past its end has been seen.
+// Samples belonging to a bucket that has already been emitted are dropped and
+// counted. Intervals without samples produce no bucket. Close emits the
+// buckets still open, flagging as Partial only the one holding the newest
+// sample; those before it, held back for late samples, are complete.
+//
+// An Aggregator is safe for concurrent use. The callback is invoked while the
+// aggregator's lock is held and must not call back into the aggregator.
+type Aggregator struct {
+	mu        sync.Mutex
+	duration  float64
+	tolerance float64
+	fn        func(Bucket)
+	open      map[int64]*bucketSums
+	latest    float64 // largest sample time seen
+	emitted   int64   // buckets before this index have been emitted
+	started   bool
+	closed    bool
+	dropped   int
+}
+
+// bucketSums accumulates the running sums of one bucket.
+type bucketSums struct {
+	sumSq   compensatedSum
+	min     float64
+	max     float64
+	samples int
+}
+
+// NewAggregator creates an Aggregator.
+//
+// Parameters:
+//   - bucketDuration: The length of each bucket in seconds
+//   - tolerance: How far in seconds a sample may lag the newest sample and still be accepted
+//   - fn: Callback receiving each completed bucket
+//
+// Returns:
+//   - *Aggregator: The new aggregator
+//   - error: An error if bucketDuration is not positive, tolerance is negative or fn is nil
+func NewAggregator(bucketDuration, tolerance float64, fn func(Bucket)) (*Aggregator, error) {
+	if !(bucketDuration > 0) || math.IsInf(bucketDuration, 1) {
+		return nil, errors.New("dynamics: bucket duration must be positive")
+	}
+	if !(tolerance >= 0) || math.IsInf(tolerance, 1) {
+		return nil, errors.New("dynamics: aggregator tolerance must not be negative")
+	}
+	if fn == nil {
+		return nil, errors.New("dynamics: aggregator callback is nil")
+	}
+
+	return &Aggregator{
+		duration:  bucketDuration,
+		tolerance: tolerance,
+		fn:        fn,
+		open:      make(map[int64]*bucketSums),
+	}, nil
+}
+
+// Push adds a sample to its bucket and emits every bucket that can no longer
+// receive samples. Pushing after Close returns ErrClosed.
+func (a *Aggregator) Push(sample SingleChannelSample) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	if math.IsNaN(sample.Time) || math.IsInf(sample.Time, 0) {
+		return errors.New("dynamics: sample time is not finite")
+	}
+
+	index := a.index(sample.Time)
+	if !a.started {
+		// nothing before the first sample less the tolerance can still arrive in time
+		a.emitted = a.index(sample.Time - a.tolerance)
+	}
+	if index < a.emitted {
+		a.dropped++
+		return nil
+	}
+
+	sums, ok := a.open[index]
+	if !ok {
+		sums = &bucketSums{min: sample.Value, max: sample.Value}
+		a.open[index] = sums
+	}
+	sums.sumSq.add(sample.Value * sample.Value)
+	sums.min = math.Min(sums.min, sample.Value)
+	sums.max = math.Max(sums.max, sample.Value)
+	sums.samples++
+
+	if !a.started || sample.Time > a.latest {
+		a.started = true
+		a.latest = sample.Time
+	}
+	a.flush(a.index(a.latest-a.tolerance), false)
+	return nil
+}
+
+// Close emits the buckets that are still open, flagging as Partial the one
+// holding the newest sample. Closing an aggregator more than once is harmless.
+func (a *Aggregator) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	if a.started {
+		latest := a.index(a.latest)
+		a.flush(latest, false)
+		a.flush(latest+1, true)
+	}
+	return nil
+}
+
+// Dropped returns the number of samples that arrived too late to be aggregated.
+func (a *Aggregator) Dropped() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.dropped
+}
+
+// index returns the index of the bucket containing time t.
+func (a *Aggregator) index(t float64) int64 {
+	return int64(math.Floor((t + timeTolerance(t, a.duration)) / a.duration))
+}
+
+// flush emits, in time order, the open buckets before the given index. The
+// caller must hold a.mu.
+func (a *Aggregator) flush(before int64, partial bool) {
+	if before <= a.emitted {
+		return
+	}
+	a.emitted = before
+
+	var ready []int64
+	for index := range a.open {
+		if index < before {
+			ready = append(ready, index)
+		}
+	}
+	slices.Sort(ready)
+
+	for _, index := range ready {
+		sums := a.open[index]
+		delete(a.open, index)
+		a.fn(Bucket{
+			Start:   float64(index) * a.duration,
+			End:     float64(index+1) * a.duration,
+			RMS:     math.Sqrt(sums.sumSq.value() / float64(sums.samples)),
+			Min:     sums.min,
+			Max:     sums.max,
+			Peak:    math.Max(math.Abs(sums.min), math.Abs(sums.max)),
+			Samples: sums.samples,
+			Partial: partial,
+		})
+	}
+}
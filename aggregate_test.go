@@ -0,0 +1,173 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAggregator(t *testing.T) {
+	// Generate sample data whose amplitude steps up every second
+	data := GenerateSineWave(50, 1, 5, 1000)
+	for i := range data {
+		data[i].Value *= 1 + float64(i/1000)
+	}
+
+	// Run the test
+	var buckets []Bucket
+	aggregator, err := NewAggregator(1, 0, func(bucket Bucket) {
+		buckets = append(buckets, bucket)
+	})
+	if err != nil {
+		t.Fatalf("NewAggregator returned error: %v", err)
+	}
+	for _, sample := range data {
+		if err := aggregator.Push(sample); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+	}
+
+	// The final second stays open because nothing has passed its end yet
+	if len(buckets) != 4 {
+		t.Fatalf("Aggregator emitted %d buckets, expected 4", len(buckets))
+	}
+	for i, bucket := range buckets {
+		window := data[i*1000 : (i+1)*1000]
+		if bucket.Start != float64(i) || bucket.End != float64(i+1) || bucket.Samples != 1000 {
+			t.Errorf("Bucket %d covers [%f, %f) with %d samples, expected [%d, %d) with 1000", i, bucket.Start, bucket.End, bucket.Samples, i, i+1)
+		}
+
+		minimum, maximum := math.Inf(1), math.Inf(-1)
+		for _, sample := range window {
+			minimum = math.Min(minimum, sample.Value)
+			maximum = math.Max(maximum, sample.Value)
+		}
+		if diff := math.Abs(bucket.RMS - calculateRMS(window)); diff > 1e-12 {
+			t.Errorf("Bucket %d RMS %f, expected %f (difference: %g)", i, bucket.RMS, calculateRMS(window), diff)
+		}
+		if bucket.Min != minimum || bucket.Max != maximum {
+			t.Errorf("Bucket %d min/max %f/%f, expected %f/%f", i, bucket.Min, bucket.Max, minimum, maximum)
+		}
+		if bucket.Peak != math.Max(-minimum, maximum) {
+			t.Errorf("Bucket %d peak %f, expected %f", i, bucket.Peak, math.Max(-minimum, maximum))
+		}
+	}
+}
+
+func TestAggregatorLateSamples(t *testing.T) {
+	var buckets []Bucket
+	aggregator, _ := NewAggregator(1, 0.5, func(bucket Bucket) {
+		buckets = append(buckets, bucket)
+	})
+
+	// Run the test
+	samples := []SingleChannelSample{
+		{Time: 0.2, Value: 1},
+		{Time: 1.1, Value: 2},
+		{Time: 0.9, Value: 3}, // late, but within the tolerance
+		{Time: 1.6, Value: 4}, // closes the first bucket
+		{Time: 0.8, Value: 5}, // too late
+		{Time: 2.7, Value: 6}, // closes the second bucket
+	}
+	for _, sample := range samples {
+		_ = aggregator.Push(sample)
+	}
+
+	if len(buckets) != 2 {
+		t.Fatalf("Aggregator emitted %d buckets, expected 2", len(buckets))
+	}
+	if buckets[0].Samples != 2 || buckets[0].Max != 3 {
+		t.Errorf("First bucket has %d samples with max %f, expected 2 with max 3", buckets[0].Samples, buckets[0].Max)
+	}
+	if buckets[1].Samples != 2 || buckets[1].Min != 2 {
+		t.Errorf("Second bucket has %d samples with min %f, expected 2 with min 2", buckets[1].Samples, buckets[1].Min)
+	}
+	if dropped := aggregator.Dropped(); dropped != 1 {
+		t.Errorf("Aggregator dropped %d samples, expected 1", dropped)
+	}
+}
+
+func TestAggregatorNegativeTime(t *testing.T) {
+	var buckets []Bucket
+	aggregator, _ := NewAggregator(1, 0, func(bucket Bucket) {
+		buckets = append(buckets, bucket)
+	})
+
+	// Generate sample data: 3 s starting 2 s before time zero
+	data := GenerateSineWave(50, 1, 3, 1000)
+	for i := range data {
+		data[i].Time -= 2
+	}
+
+	// Run the test
+	for _, sample := range data {
+		_ = aggregator.Push(sample)
+	}
+	_ = aggregator.Close()
+
+	if dropped := aggregator.Dropped(); dropped != 0 {
+		t.Errorf("Aggregator dropped %d samples, expected none", dropped)
+	}
+	if len(buckets) != 3 {
+		t.Fatalf("Aggregator emitted %d buckets, expected 3", len(buckets))
+	}
+	for i, bucket := range buckets {
+		if bucket.Start != float64(i-2) || bucket.Samples != 1000 {
+			t.Errorf("Bucket %d starts at %f with %d samples, expected %d with 1000", i, bucket.Start, bucket.Samples, i-2)
+		}
+	}
+}
+
+func TestAggregatorClose(t *testing.T) {
+	var buckets []Bucket
+	aggregator, _ := NewAggregator(1, 0, func(bucket Bucket) {
+		buckets = append(buckets, bucket)
+	})
+
+	// Run the test
+	for _, sample := range GenerateSineWave(50, 1, 2.5, 1000) {
+		_ = aggregator.Push(sample)
+	}
+	if err := aggregator.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := aggregator.Close(); err != nil {
+		t.Errorf("Second Close returned error: %v", err)
+	}
+
+	if len(buckets) != 3 {
+		t.Fatalf("Aggregator emitted %d buckets, expected 3", len(buckets))
+	}
+	if buckets[1].Partial || !buckets[2].Partial || buckets[2].Samples != 500 {
+		t.Errorf("Buckets %+v, expected only the last to be partial with 500 samples", buckets)
+	}
+	if err := aggregator.Push(SingleChannelSample{Time: 3}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push after Close returned %v, expected ErrClosed", err)
+	}
+}
+
+func TestAggregatorCloseWithTolerance(t *testing.T) {
+	var buckets []Bucket
+	aggregator, _ := NewAggregator(1, 0.75, func(bucket Bucket) {
+		buckets = append(buckets, bucket)
+	})
+
+	// Run the test: the tolerance holds the second bucket open until Close
+	for _, sample := range GenerateSineWave(50, 1, 2.5, 1000) {
+		_ = aggregator.Push(sample)
+	}
+	if len(buckets) != 1 {
+		t.Fatalf("Aggregator emitted %d buckets before Close, expected 1", len(buckets))
+	}
+	_ = aggregator.Close()
+
+	if len(buckets) != 3 {
+		t.Fatalf("Aggregator emitted %d buckets, expected 3", len(buckets))
+	}
+	if buckets[0].Partial || buckets[1].Partial || buckets[1].Samples != 1000 {
+		t.Errorf("Buckets %+v, expected the second to be complete with 1000 samples", buckets[:2])
+	}
+	if !buckets[2].Partial || buckets[2].Samples != 500 {
+		t.Errorf("Bucket %+v, expected it to be partial with 500 samples", buckets[2])
+	}
+}
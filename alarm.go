@@ -0,0 +1,185 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// Metric selects which value of an AnalysisResult an alarm watches.
+type Metric int
+
+const (
+	MetricRMS         Metric = iota // AnalysisResult.RMS
+	MetricPeak                      // AnalysisResult.Peak
+	MetricNZCR                      // AnalysisResult.NZCR
+	MetricCrestFactor               // AnalysisResult.CrestFactor
+)
+
+// AlarmDirection selects whether an alarm triggers above or below its threshold.
+type AlarmDirection int
+
+const (
+	AlarmAbove AlarmDirection = iota // raise when the metric exceeds the threshold
+	AlarmBelow                       // raise when the metric falls below the threshold
+)
+
+// AlarmState is the transition reported by an AlarmEvent.
+type AlarmState int
+
+const (
+	AlarmRaised AlarmState = iota + 1
+	AlarmCleared
+)
+
+// AlarmEvent reports that an alarm was raised or cleared.
+type AlarmEvent struct {
+	Name  string     `json:"name"`
+	State AlarmState `json:"state"`
+	Time  float64    `json:"time"`  // sample time of the result that caused the transition
+	Value float64    `json:"value"` // metric value at that time
+}
+
+// AlarmConfig configures an Alarm.
+//
+// Separate raise and clear thresholds give the alarm hysteresis: an alarm
+// watching for values above Threshold stays raised until the value falls to
+// ClearThreshold or below, and the reverse for AlarmBelow. A nil
+// ClearThreshold clears the alarm at Threshold.
+type AlarmConfig struct {
+	Name           string         // reported in every AlarmEvent
+	Metric         Metric         // the metric to watch
+	Direction      AlarmDirection // which side of the threshold is the alarm condition
+	Threshold      float64        // the limit at which the alarm is raised
+	ClearThreshold *float64       // the limit at which the alarm clears, nil for Threshold
+	HoldOff        float64        // seconds the condition must persist before the alarm is raised
+	ClearHoldOff   float64        // seconds the recovery must persist before the alarm clears
+}
+
+// Alarm watches a metric of a stream of analysis results and reports when it
+// crosses a threshold. Only transitions are reported: one AlarmRaised when the
+// condition has held for the hold-off time and one AlarmCleared when the
+// recovery has held for the clear hold-off time. All timing is taken from the
+// result timestamps, not the wall clock, so replayed data behaves exactly like
+// live data.
+type Alarm struct {
+	mu      sync.Mutex
+	config  AlarmConfig
+	fn      func(AlarmEvent)
+	clear   float64 // the limit at which the alarm clears, copied from the config
+	active  bool
+	pending bool    // the next transition's condition holds but its hold-off has not yet elapsed
+	since   float64 // time at which the pending condition started
+}
+
+// NewAlarm creates an Alarm.
+//
+// Parameters:
+//   - config: The alarm configuration
+//   - fn: Callback receiving each raise and clear event
+//
+// Returns:
+//   - *Alarm: The new alarm, to be attached with StreamAnalyzer.AddAlarm
+//   - error: An error if the configuration is invalid
+func NewAlarm(config AlarmConfig, fn func(AlarmEvent)) (*Alarm, error) {
+	if config.Metric < MetricRMS || config.Metric > MetricCrestFactor {
+		return nil, errors.New("dynamics: unknown alarm metric")
+	}
+	if config.Direction != AlarmAbove && config.Direction != AlarmBelow {
+		return nil, errors.New("dynamics: unknown alarm direction")
+	}
+	if math.IsNaN(config.Threshold) {
+		return nil, errors.New("dynamics: alarm threshold is NaN")
+	}
+	clear := config.Threshold
+	if config.ClearThreshold != nil {
+		clear = *config.ClearThreshold
+	}
+	if math.IsNaN(clear) {
+		return nil, errors.New("dynamics: alarm clear threshold is NaN")
+	}
+	if config.Direction == AlarmAbove && clear > config.Threshold {
+		return nil, errors.New("dynamics: alarm clear threshold must not be above the threshold")
+	}
+	if config.Direction == AlarmBelow && clear < config.Threshold {
+		return nil, errors.New("dynamics: alarm clear threshold must not be below the threshold")
+	}
+	if !(config.HoldOff >= 0) || !(config.ClearHoldOff >= 0) {
+		return nil, errors.New("dynamics: alarm hold-off must not be negative")
+	}
+	if fn == nil {
+		return nil, errors.New("dynamics: alarm callback is nil")
+	}
+
+	// the config keeps the caller's pointer, so the alarm reads its own copy
+	config.ClearThreshold = nil
+	return &Alarm{config: config, fn: fn, clear: clear}, nil
+}
+
+// Active reports whether the alarm is currently raised.
+func (a *Alarm) Active() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.active
+}
+
+// update evaluates the alarm against a new result.
+func (a *Alarm) update(result AnalysisResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	value := a.config.Metric.value(result)
+
+	// the condition that would move the alarm to its other state
+	var changing bool
+	switch {
+	case !a.active && a.config.Direction == AlarmAbove:
+		changing = value > a.config.Threshold
+	case !a.active:
+		changing = value < a.config.Threshold
+	case a.config.Direction == AlarmAbove:
+		changing = value <= a.clear
+	default:
+		changing = value >= a.clear
+	}
+
+	if !changing {
+		a.pending = false
+		return
+	}
+	if !a.pending {
+		a.pending = true
+		a.since = result.Time
+	}
+
+	holdOff := a.config.HoldOff
+	if a.active {
+		holdOff = a.config.ClearHoldOff
+	}
+	if result.Time-a.since < holdOff {
+		return
+	}
+
+	a.pending = false
+	a.active = !a.active
+	state := AlarmRaised
+	if !a.active {
+		state = AlarmCleared
+	}
+	a.fn(AlarmEvent{Name: a.config.Name, State: state, Time: result.Time, Value: value})
+}
+
+// value returns the value of the metric in the result.
+func (m Metric) value(result AnalysisResult) float64 {
+	switch m {
+	case MetricPeak:
+		return result.Peak
+	case MetricNZCR:
+		return result.NZCR
+	case MetricCrestFactor:
+		return result.CrestFactor
+	default:
+		return result.RMS
+	}
+}
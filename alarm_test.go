@@ -0,0 +1,216 @@
+package dynamics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// burstSignal returns a 50 Hz sine of unit amplitude, raised to the given
+// amplitude between start and end seconds.
+func burstSignal(duration, start, end, amplitude float64) []SingleChannelSample {
+	data := GenerateSineWave(50, 1, duration, 1000)
+	for i := range data {
+		if data[i].Time >= start && data[i].Time < end {
+			data[i].Value *= amplitude
+		}
+	}
+	return data
+}
+
+func TestAlarm(t *testing.T) {
+	// Generate sample data: RMS 0.71 outside the burst, 4.24 inside
+	data := burstSignal(10, 3, 6, 6)
+
+	// Run the test
+	var events []AlarmEvent
+	sa, _ := NewStreamAnalyzer(0.1, 0.01, nil)
+	alarm, err := NewAlarm(AlarmConfig{Name: "rms", Metric: MetricRMS, Direction: AlarmAbove, Threshold: 3.5, HoldOff: 2}, func(event AlarmEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("NewAlarm returned error: %v", err)
+	}
+	sa.AddAlarm(alarm)
+	for _, sample := range data {
+		_ = sa.Push(sample)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Alarm produced %d events, expected 2: %v", len(events), events)
+	}
+
+	// The window RMS exceeds 3.5 once 67% of it lies inside the burst, and the
+	// hold-off delays the raise by a further two seconds
+	expected := []AlarmEvent{
+		{Name: "rms", State: AlarmRaised, Time: 5.067},
+		{Name: "rms", State: AlarmCleared, Time: 6.033},
+	}
+	for i, event := range events {
+		if event.Name != expected[i].Name || event.State != expected[i].State {
+			t.Errorf("Event %d is %v, expected %v", i, event, expected[i])
+		}
+		if diff := math.Abs(event.Time - expected[i].Time); diff > 0.015 {
+			t.Errorf("Event %d at %f, expected %f (difference: %f)", i, event.Time, expected[i].Time, diff)
+		}
+	}
+	if events[0].Value <= 3.5 || events[1].Value > 3.5 {
+		t.Errorf("Event values %f and %f are on the wrong side of the threshold", events[0].Value, events[1].Value)
+	}
+	if alarm.Active() {
+		t.Errorf("Alarm still active after the burst")
+	}
+}
+
+func TestAlarmHoldOffSuppressesShortBreach(t *testing.T) {
+	// Generate sample data: the burst is shorter than the hold-off
+	data := burstSignal(5, 2, 3, 6)
+
+	// Run the test
+	var events []AlarmEvent
+	sa, _ := NewStreamAnalyzer(0.1, 0.01, nil)
+	alarm, _ := NewAlarm(AlarmConfig{Metric: MetricRMS, Threshold: 3.5, HoldOff: 2}, func(event AlarmEvent) {
+		events = append(events, event)
+	})
+	sa.AddAlarm(alarm)
+	for _, sample := range data {
+		_ = sa.Push(sample)
+	}
+
+	if len(events) != 0 {
+		t.Errorf("Alarm produced %d events for a breach shorter than the hold-off, expected 0", len(events))
+	}
+}
+
+func TestMultipleAlarms(t *testing.T) {
+	// Generate sample data
+	data := burstSignal(6, 2, 4, 6)
+
+	// Run the test
+	counts := map[string]int{}
+	record := func(event AlarmEvent) {
+		counts[event.Name]++
+	}
+	sa, _ := NewStreamAnalyzer(0.1, 0.01, nil)
+	peak, _ := NewAlarm(AlarmConfig{Name: "peak", Metric: MetricPeak, Threshold: 5}, record)
+	quiet, _ := NewAlarm(AlarmConfig{Name: "quiet", Metric: MetricRMS, Direction: AlarmBelow, Threshold: 1}, record)
+	sa.AddAlarm(peak)
+	sa.AddAlarm(quiet)
+	for _, sample := range data {
+		_ = sa.Push(sample)
+	}
+
+	// The peak alarm raises and clears around the burst; the quiet alarm
+	// raises at the start, clears during the burst and raises again after it
+	if counts["peak"] != 2 {
+		t.Errorf("Peak alarm produced %d events, expected 2", counts["peak"])
+	}
+	if counts["quiet"] != 3 {
+		t.Errorf("Quiet alarm produced %d events, expected 3", counts["quiet"])
+	}
+}
+
+func TestNewAlarmValidation(t *testing.T) {
+	noop := func(AlarmEvent) {}
+	if _, err := NewAlarm(AlarmConfig{Metric: Metric(42)}, noop); err == nil {
+		t.Errorf("NewAlarm accepted an unknown metric")
+	}
+	if _, err := NewAlarm(AlarmConfig{HoldOff: -1}, noop); err == nil {
+		t.Errorf("NewAlarm accepted a negative hold-off")
+	}
+	if _, err := NewAlarm(AlarmConfig{}, nil); err == nil {
+		t.Errorf("NewAlarm accepted a nil callback")
+	}
+}
+
+func TestAlarmHysteresis(t *testing.T) {
+	// Generate results whose RMS hovers noisily around 3.5 between 2s and 6s
+	rng := rand.New(rand.NewSource(1))
+	var results []AnalysisResult
+	for i := 0; i < 800; i++ {
+		time := float64(i) * 0.01
+		rms := 1.0
+		if time >= 2 && time < 6 {
+			rms = 3.5 + 0.3*math.Sin(2*math.Pi*3*time) + 0.2*rng.NormFloat64()
+		}
+		results = append(results, AnalysisResult{Time: time, RMS: rms})
+	}
+
+	// Run the test
+	count := func(config AlarmConfig) []AlarmEvent {
+		var events []AlarmEvent
+		alarm, err := NewAlarm(config, func(event AlarmEvent) {
+			events = append(events, event)
+		})
+		if err != nil {
+			t.Fatalf("NewAlarm returned error: %v", err)
+		}
+		for _, result := range results {
+			alarm.update(result)
+		}
+		return events
+	}
+
+	plain := count(AlarmConfig{Metric: MetricRMS, Threshold: 3.5})
+	if len(plain) < 20 {
+		t.Errorf("Plain alarm produced %d events, expected it to chatter", len(plain))
+	}
+
+	clear := 2.5
+	events := count(AlarmConfig{Metric: MetricRMS, Threshold: 3.5, ClearThreshold: &clear, HoldOff: 0.1, ClearHoldOff: 0.5})
+	if len(events) != 2 || events[0].State != AlarmRaised || events[1].State != AlarmCleared {
+		t.Fatalf("Alarm with hysteresis produced %v, expected one raise and one clear", events)
+	}
+	if events[0].Time < 2 || events[0].Time > 2.5 {
+		t.Errorf("Alarm raised at %f, expected shortly after 2", events[0].Time)
+	}
+	if diff := math.Abs(events[1].Time - 6.5); diff > 1e-9 {
+		t.Errorf("Alarm cleared at %f, expected 6.5", events[1].Time)
+	}
+}
+
+func TestAlarmClearThresholdValidation(t *testing.T) {
+	noop := func(AlarmEvent) {}
+	two, four, nan := 2.0, 4.0, math.NaN()
+	if _, err := NewAlarm(AlarmConfig{Direction: AlarmAbove, Threshold: 3, ClearThreshold: &four}, noop); err == nil {
+		t.Errorf("NewAlarm accepted a clear threshold above the raise threshold")
+	}
+	if _, err := NewAlarm(AlarmConfig{Direction: AlarmBelow, Threshold: 3, ClearThreshold: &two}, noop); err == nil {
+		t.Errorf("NewAlarm accepted a clear threshold below the raise threshold")
+	}
+	if _, err := NewAlarm(AlarmConfig{Direction: AlarmBelow, Threshold: 3, ClearThreshold: &four}, noop); err != nil {
+		t.Errorf("NewAlarm rejected a valid clear threshold: %v", err)
+	}
+	if _, err := NewAlarm(AlarmConfig{Threshold: 3, ClearThreshold: &nan}, noop); err == nil {
+		t.Errorf("NewAlarm accepted a NaN clear threshold")
+	}
+	if _, err := NewAlarm(AlarmConfig{ClearHoldOff: -1}, noop); err == nil {
+		t.Errorf("NewAlarm accepted a negative clear hold-off")
+	}
+}
+
+func TestAlarmClearThresholdZero(t *testing.T) {
+	// Generate sample data: a level that rises, falls back part way, then to zero
+	results := []AnalysisResult{{Time: 0, RMS: 0}, {Time: 1, RMS: 2}, {Time: 2, RMS: 0.5}, {Time: 3, RMS: 0}}
+
+	// Run the test: an explicit clear threshold of 0 holds the alarm until the level reaches it
+	var events []AlarmEvent
+	zero := 0.0
+	alarm, err := NewAlarm(AlarmConfig{Metric: MetricRMS, Threshold: 1, ClearThreshold: &zero}, func(event AlarmEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("NewAlarm returned error: %v", err)
+	}
+	zero = 1 // the alarm keeps the clear threshold it was created with
+	for _, result := range results {
+		alarm.update(result)
+	}
+	if len(events) != 2 || events[1].State != AlarmCleared || events[1].Time != 3 {
+		t.Errorf("got %v, expected a raise at 1 and a clear at 3", events)
+	}
+	zero = 0
+	if _, err := NewAlarm(AlarmConfig{Direction: AlarmBelow, Threshold: 1, ClearThreshold: &zero}, func(AlarmEvent) {}); err == nil {
+		t.Errorf("NewAlarm accepted a clear threshold of 0 below the raise threshold")
+	}
+}
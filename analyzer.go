@@ -0,0 +1,83 @@
+package dynamics
+
+import "math"
+
+// Analyzer analyses windows of samples repeatedly without allocating. It owns
+// the scratch storage that the free functions would otherwise allocate on each
+// call: the copy of a CircularBuffer's contents and, under NonFiniteSkip or
+// WithRemoveDC, the prepared samples. Once that storage has grown to the
+// largest window seen, the analysis makes no further allocations, which suits
+// calling it at a fixed high rate.
+//
+// An Analyzer is not safe for concurrent use; give each goroutine its own.
+type Analyzer struct {
+	config analyzeConfig
+	buffer []SingleChannelSample // copy of a CircularBuffer's contents
+	finite []SingleChannelSample // samples kept by NonFiniteSkip or changed by WithRemoveDC
+}
+
+// NewAnalyzer creates an Analyzer.
+//
+// Parameters:
+//   - opts: Options such as WithNonFinite and WithMaxCycles, applied to every analysis
+//
+// Returns:
+//   - *Analyzer: The new analyzer
+func NewAnalyzer(opts ...AnalyzeOption) *Analyzer {
+	return &Analyzer{config: newAnalyzeConfig(opts)}
+}
+
+// Analyze analyses the data. RMS and NZCR are those Analyze returns with the
+// same options, and the result also carries the time of the last sample, the
+// peak, the number of samples analysed and the span the RMS covers. Data that
+// Analyze would give zeros for yields a zero result.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - AnalysisResult: The analysis
+func (a *Analyzer) Analyze(data []SingleChannelSample) AnalysisResult {
+	prepared, err := a.config.prepareInto(a.finite, data)
+	if err != nil {
+		return AnalysisResult{}
+	}
+	if copied(prepared, data) {
+		// samples were copied into a.finite, which may have grown; keep it for the next call
+		a.finite = prepared[:0]
+	}
+	data = prepared
+	if len(data) == 0 {
+		return AnalysisResult{}
+	}
+
+	rms, zcr, span := analyze(data, a.config)
+	result := AnalysisResult{
+		Time:    data[len(data)-1].Time,
+		RMS:     rms,
+		NZCR:    zcr,
+		Samples: len(data),
+	}
+	for _, sample := range data {
+		result.Peak = math.Max(result.Peak, math.Abs(sample.Value))
+	}
+	if len(data) > 1 {
+		result.RMSSpan = math.Min(span, result.Time-data[0].Time)
+		result.CycleAligned = !math.IsInf(span, 1)
+	}
+	result.CrestFactor = crestFactorOf(result.Peak, result.RMS)
+	return result
+}
+
+// AnalyzeBuffer analyses the contents of a CircularBuffer as Analyze does,
+// copying them into the analyzer's own storage rather than a new slice.
+//
+// Parameters:
+//   - cb: The circular buffer to analyse
+//
+// Returns:
+//   - AnalysisResult: The analysis
+func (a *Analyzer) AnalyzeBuffer(cb *CircularBuffer) AnalysisResult {
+	a.buffer = cb.AppendData(a.buffer[:0])
+	return a.Analyze(a.buffer)
+}
@@ -0,0 +1,119 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzerMatchesAnalyze(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 0.5, 1000)
+	withNaN := append([]SingleChannelSample{}, data...)
+	withNaN[123].Value = math.NaN()
+
+	cases := []struct {
+		name string
+		data []SingleChannelSample
+		opts []AnalyzeOption
+	}{
+		{"default", data, nil},
+		{"uncapped", data, []AnalyzeOption{WithMaxCycles(0)}},
+		{"skip", withNaN, []AnalyzeOption{WithNonFinite(NonFiniteSkip)}},
+		{"strict", withNaN, []AnalyzeOption{WithNonFinite(NonFiniteStrict)}},
+		{"half cycle", data[:10], nil},
+		{"one sample", data[:1], nil},
+		{"empty", nil, nil},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		analyzer := NewAnalyzer(c.opts...)
+		result := analyzer.Analyze(c.data)
+		rms, zcr := Analyze(c.data, c.opts...)
+		if result.RMS != rms || result.NZCR != zcr {
+			t.Errorf("Analyzer on %s returned %f, %f; expected %f, %f", c.name, result.RMS, result.NZCR, rms, zcr)
+		}
+
+		if detailed, err := AnalyzeDetailed(c.data, c.opts...); err == nil && result != detailed {
+			t.Errorf("Analyzer on %s returned %+v, expected %+v", c.name, result, detailed)
+		}
+	}
+}
+
+func TestAnalyzerSkipLeavesInputAlone(t *testing.T) {
+	// Generate sample data: a clean window followed by one with a NaN
+	clean := GenerateSineWave(50, 1, 0.2, 1000)
+	original := append([]SingleChannelSample{}, clean...)
+	dirty := GenerateSineWave(50, 2, 0.2, 1000)
+	dirty[5].Value = math.NaN()
+
+	// Run the test
+	analyzer := NewAnalyzer(WithNonFinite(NonFiniteSkip))
+	analyzer.Analyze(clean)
+	analyzer.Analyze(dirty)
+
+	for i := range clean {
+		if clean[i] != original[i] {
+			t.Fatalf("Analyzer wrote into the caller's data at sample %d", i)
+		}
+	}
+}
+
+func TestAnalyzerAllocations(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+	withNaN := append([]SingleChannelSample{}, data...)
+	withNaN[500].Value = math.NaN()
+	cb := NewCircularBuffer(1000)
+	for _, sample := range data {
+		cb.Update(sample)
+	}
+
+	// Run the test
+	analyzer := NewAnalyzer()
+	if allocs := testing.AllocsPerRun(100, func() { analyzer.Analyze(data) }); allocs != 0 {
+		t.Errorf("Analyzer.Analyze made %f allocations per call, expected 0", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { analyzer.AnalyzeBuffer(cb) }); allocs != 0 {
+		t.Errorf("Analyzer.AnalyzeBuffer made %f allocations per call, expected 0", allocs)
+	}
+
+	skipping := NewAnalyzer(WithNonFinite(NonFiniteSkip))
+	if allocs := testing.AllocsPerRun(100, func() { skipping.Analyze(withNaN) }); allocs != 0 {
+		t.Errorf("Analyzer.Analyze skipping made %f allocations per call, expected 0", allocs)
+	}
+
+	// the buffer result matches analysing a copy
+	if result, expected := analyzer.AnalyzeBuffer(cb), analyzer.Analyze(cb.GetData()); result != expected {
+		t.Errorf("Analyzer.AnalyzeBuffer returned %+v, expected %+v", result, expected)
+	}
+}
+
+func BenchmarkAnalyzer(b *testing.B) {
+	// Generate sample data
+	data := GenerateSineWave(440, 1, 1, 1000)
+	analyzer := NewAnalyzer()
+
+	// Run the benchmark
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.Analyze(data)
+	}
+}
+
+func BenchmarkAnalyzerBuffer(b *testing.B) {
+	// Generate sample data
+	cb := NewCircularBuffer(1000)
+	for _, sample := range GenerateSineWave(440, 1, 1, 1000) {
+		cb.Update(sample)
+	}
+	analyzer := NewAnalyzer()
+
+	// Run the benchmark
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.AnalyzeBuffer(cb)
+	}
+}
@@ -0,0 +1,135 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// BearingGeometry describes a rolling-element bearing.
+type BearingGeometry struct {
+	Balls         int     `json:"balls"`         // number of rolling elements
+	BallDiameter  float64 `json:"ballDiameter"`  // in any unit of length
+	PitchDiameter float64 `json:"pitchDiameter"` // in the unit of BallDiameter
+	ContactAngle  float64 `json:"contactAngle"`  // in degrees
+}
+
+// BearingFreqs are the defect frequencies of a bearing, in Hz.
+type BearingFreqs struct {
+	BPFO float64 `json:"bpfo"` // ball pass frequency, outer race
+	BPFI float64 `json:"bpfi"` // ball pass frequency, inner race
+	BSF  float64 `json:"bsf"`  // ball spin frequency
+	FTF  float64 `json:"ftf"`  // fundamental train (cage) frequency
+}
+
+// BearingFrequencies returns the defect frequencies of a bearing on a shaft
+// turning at rpm, with a stationary outer race and no slip. An impossible
+// geometry or speed yields zero frequencies; see BearingFrequenciesE.
+//
+// Parameters:
+//   - geom: The geometry of the bearing
+//   - rpm: The shaft speed in revolutions per minute
+//
+// Returns:
+//   - BearingFreqs: The defect frequencies in Hz
+func BearingFrequencies(geom BearingGeometry, rpm float64) BearingFreqs {
+	freqs, _ := BearingFrequenciesE(geom, rpm)
+	return freqs
+}
+
+// BearingFrequenciesE returns the defect frequencies as BearingFrequencies
+// does, but reports an impossible geometry or speed instead of returning zeros.
+//
+// Parameters:
+//   - geom: The geometry of the bearing
+//   - rpm: The shaft speed in revolutions per minute
+//
+// Returns:
+//   - BearingFreqs: The defect frequencies in Hz, zero on error
+//   - error: An error if the bearing has no balls, the ball diameter is not
+//     positive or not below the pitch diameter, the contact angle is outside
+//     0° to 90°, or rpm is negative or not finite
+func BearingFrequenciesE(geom BearingGeometry, rpm float64) (BearingFreqs, error) {
+	if geom.Balls <= 0 {
+		return BearingFreqs{}, errors.New("dynamics: bearing must have at least one ball")
+	}
+	if !(geom.BallDiameter > 0) || !(geom.BallDiameter < geom.PitchDiameter) || math.IsInf(geom.PitchDiameter, 1) {
+		return BearingFreqs{}, errors.New("dynamics: bearing ball diameter must be positive and below the pitch diameter")
+	}
+	if !(geom.ContactAngle >= 0 && geom.ContactAngle < 90) {
+		return BearingFreqs{}, errors.New("dynamics: bearing contact angle must be from 0 to below 90 degrees")
+	}
+	if !(rpm >= 0) || math.IsInf(rpm, 1) {
+		return BearingFreqs{}, errors.New("dynamics: shaft speed must not be negative")
+	}
+
+	shaft := rpm / 60
+	ratio := geom.BallDiameter / geom.PitchDiameter * math.Cos(geom.ContactAngle*math.Pi/180)
+	balls := float64(geom.Balls)
+	return BearingFreqs{
+		BPFO: balls / 2 * shaft * (1 - ratio),
+		BPFI: balls / 2 * shaft * (1 + ratio),
+		BSF:  geom.PitchDiameter / (2 * geom.BallDiameter) * shaft * (1 - ratio*ratio),
+		FTF:  shaft / 2 * (1 - ratio),
+	}, nil
+}
+
+// BearingBandEnergies measures the RMS of the vibration in narrow bands
+// around each defect frequency and its harmonics, from the amplitude spectrum
+// of the whole record. The bands of a defect are combined into one RMS, a
+// spectral line within two bands counting once, and the lines are summed in
+// frequency order so that every call gives the same result. The samples are taken to be
+// evenly spaced, and bands should be a few lines wide, a line being the
+// reciprocal of the record length, so that a tone between lines is caught.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - freqs: The defect frequencies, from BearingFrequencies
+//   - harmonics: The number of harmonics of each frequency, counting the
+//     fundamental, to measure
+//   - bandwidth: The width in Hz of each band, centred on the harmonic
+//
+// Returns:
+//   - map[string]float64: The RMS of each defect, keyed "BPFO", "BPFI", "BSF"
+//     and "FTF"; nil when harmonics or bandwidth is not positive or the data
+//     spans no time
+func BearingBandEnergies(data []SingleChannelSample, freqs BearingFreqs, harmonics int, bandwidth float64) map[string]float64 {
+	if harmonics <= 0 || !(bandwidth > 0) || len(data) < 2 {
+		return nil
+	}
+	duration := data[len(data)-1].Time - data[0].Time
+	if !(duration > 0) {
+		return nil
+	}
+	amplitudes := amplitudeSpectrum(sampleValues(data))
+	// the spectrum of N evenly spaced samples has lines 1/(N·step) apart
+	resolution := float64(len(data)-1) / duration / float64(len(data))
+
+	defects := [...]struct {
+		name      string
+		frequency float64
+	}{{"BPFO", freqs.BPFO}, {"BPFI", freqs.BPFI}, {"BSF", freqs.BSF}, {"FTF", freqs.FTF}}
+	energies := make(map[string]float64, len(defects))
+	var lines []int
+	for _, defect := range defects {
+		lines = lines[:0]
+		for h := 1; h <= harmonics; h++ {
+			centre := float64(h) * defect.frequency
+			lo := max(int(math.Ceil((centre-bandwidth/2)/resolution)), 1)
+			hi := min(int(math.Floor((centre+bandwidth/2)/resolution)), len(amplitudes)-1)
+			for k := lo; k <= hi; k++ {
+				lines = append(lines, k)
+			}
+		}
+		sort.Ints(lines)
+		var sumSq float64
+		for i, k := range lines {
+			if i > 0 && k == lines[i-1] {
+				continue
+			}
+			sumSq += amplitudes[k] * amplitudes[k] / 2
+		}
+		energies[defect.name] = math.Sqrt(sumSq)
+	}
+	return energies
+}
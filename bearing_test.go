@@ -0,0 +1,104 @@
+package dynamics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testBearing is a 6205 deep-groove ball bearing.
+var testBearing = BearingGeometry{Balls: 9, BallDiameter: 7.94, PitchDiameter: 39.04, ContactAngle: 0}
+
+func TestBearingFrequencies(t *testing.T) {
+	// Run the test: the published 6205 multiples of shaft speed
+	freqs := BearingFrequencies(testBearing, 60)
+	expected := BearingFreqs{BPFO: 3.585, BPFI: 5.415, BSF: 2.357, FTF: 0.398}
+	for _, tt := range []struct {
+		name          string
+		got, expected float64
+	}{
+		{"BPFO", freqs.BPFO, expected.BPFO},
+		{"BPFI", freqs.BPFI, expected.BPFI},
+		{"BSF", freqs.BSF, expected.BSF},
+		{"FTF", freqs.FTF, expected.FTF},
+	} {
+		if math.Abs(tt.got-tt.expected) > 1e-3 {
+			t.Errorf("%s: got %v, expected %v", tt.name, tt.got, tt.expected)
+		}
+	}
+}
+
+func TestBearingBandEnergies(t *testing.T) {
+	// Generate sample data: 2 s at 10 kHz of a tone of amplitude 1 at BPFO
+	// and its second harmonic at amplitude 0.5, with a 1X shaft tone
+	freqs := BearingFrequencies(testBearing, 1800)
+	data := make([]SingleChannelSample, 20000)
+	for i := range data {
+		tm := float64(i) / 10000
+		data[i] = SingleChannelSample{Time: tm, Value: math.Sin(2*math.Pi*freqs.BPFO*tm) + 0.5*math.Sin(2*math.Pi*2*freqs.BPFO*tm) + 2*math.Sin(2*math.Pi*30*tm)}
+	}
+
+	// Run the test: the BPFO bands hold both tones, the others none
+	energies := BearingBandEnergies(data, freqs, 2, 4)
+	if expected := math.Sqrt(1.0/2 + 0.25/2); math.Abs(energies["BPFO"]-expected) > 0.02*expected {
+		t.Errorf("BPFO RMS %v, expected %v", energies["BPFO"], expected)
+	}
+	for _, name := range []string{"BPFI", "BSF", "FTF"} {
+		if energies[name] > 0.05 {
+			t.Errorf("%s RMS %v, expected near 0", name, energies[name])
+		}
+	}
+	if expected := math.Sqrt(0.5); math.Abs(BearingBandEnergies(data, freqs, 1, 4)["BPFO"]-expected) > 0.02*expected {
+		t.Errorf("fundamental band only: expected RMS %v", expected)
+	}
+	if BearingBandEnergies(data, freqs, 0, 4) != nil || BearingBandEnergies(data, freqs, 1, 0) != nil {
+		t.Error("invalid harmonics or bandwidth: expected nil")
+	}
+}
+
+func TestBearingFrequenciesInvalid(t *testing.T) {
+	// Run the test
+	for _, tt := range []struct {
+		name string
+		geom BearingGeometry
+		rpm  float64
+	}{
+		{"no balls", BearingGeometry{Balls: 0, BallDiameter: 7.94, PitchDiameter: 39.04}, 60},
+		{"negative balls", BearingGeometry{Balls: -9, BallDiameter: 7.94, PitchDiameter: 39.04}, 60},
+		{"ball as wide as the pitch", BearingGeometry{Balls: 9, BallDiameter: 39.04, PitchDiameter: 39.04}, 60},
+		{"zero ball diameter", BearingGeometry{Balls: 9, PitchDiameter: 39.04}, 60},
+		{"contact angle of 90", BearingGeometry{Balls: 9, BallDiameter: 7.94, PitchDiameter: 39.04, ContactAngle: 90}, 60},
+		{"negative speed", testBearing, -60},
+		{"NaN speed", testBearing, math.NaN()},
+	} {
+		if freqs, err := BearingFrequenciesE(tt.geom, tt.rpm); err == nil || freqs != (BearingFreqs{}) {
+			t.Errorf("%s: got %+v, %v, expected zeros and an error", tt.name, freqs, err)
+		}
+		if freqs := BearingFrequencies(tt.geom, tt.rpm); freqs != (BearingFreqs{}) {
+			t.Errorf("%s: BearingFrequencies returned %+v, expected zeros", tt.name, freqs)
+		}
+	}
+	if _, err := BearingFrequenciesE(testBearing, 60); err != nil {
+		t.Errorf("valid bearing: got error %v", err)
+	}
+}
+
+func TestBearingBandEnergiesRepeatable(t *testing.T) {
+	// Generate sample data: broadband noise, with overlapping harmonic bands
+	rng := rand.New(rand.NewSource(1))
+	data := make([]SingleChannelSample, 20000)
+	for i := range data {
+		data[i] = SingleChannelSample{Time: float64(i) / 10000, Value: rng.NormFloat64()}
+	}
+	freqs := BearingFrequencies(testBearing, 1800)
+
+	// Run the test: every call gives the same bits
+	first := BearingBandEnergies(data, freqs, 10, 40)
+	for range 20 {
+		for name, energy := range BearingBandEnergies(data, freqs, 10, 40) {
+			if math.Float64bits(energy) != math.Float64bits(first[name]) {
+				t.Fatalf("%s: got %v, then %v", name, first[name], energy)
+			}
+		}
+	}
+}
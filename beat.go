@@ -0,0 +1,99 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// beatMinDepth is the least modulation depth DetectBeat reports a beat for.
+const beatMinDepth = 0.05
+
+// beatTrim is the fraction of the envelope at each end that DetectBeat leaves
+// out, where the Hilbert transform of a record that does not hold whole cycles
+// rings.
+const beatTrim = 0.05
+
+// DetectBeat measures the beat of two nearly equal tones, as two machines
+// running at slightly different speeds make. The envelope of the record is
+// the magnitude of its analytic signal, found with the Hilbert transform, and
+// its strongest frequency is the beat frequency, the difference between the
+// tones. The carrier is the power-weighted mean frequency, from the phase of
+// the sum over the record of each analytic sample times the conjugate of the
+// one before, which for tones of equal amplitude is their mean and otherwise
+// lies nearer the stronger tone. The modulation depth is
+// (max − min)/(max + min) of the envelope, 1 for tones of equal amplitude.
+// The envelope is measured away from the ends of the record, where the
+// transform rings. The samples are taken to be evenly spaced, and the record
+// should hold several beats.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - carrierFreq: The carrier frequency in Hz
+//   - beatFreq: The beat frequency in Hz
+//   - modulationDepth: The depth of the envelope's modulation, from 0 to 1
+//   - err: ErrEmptyData if data is empty, ErrZeroDuration if it spans no time,
+//     ErrUnsortedData if it is not in time order, or ErrNoModulation if the
+//     depth is under 5%
+func DetectBeat(data []SingleChannelSample) (carrierFreq, beatFreq, modulationDepth float64, err error) {
+	if len(data) == 0 {
+		return 0, 0, 0, ErrEmptyData
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, 0, 0, err
+	}
+	n := len(data)
+	duration := data[n-1].Time - data[0].Time
+	if !(duration > 0) {
+		return 0, 0, 0, ErrZeroDuration
+	}
+	step := duration / float64(n-1)
+
+	x := make([]complex128, n)
+	for i, sample := range data {
+		x[i] = complex(sample.Value, 0)
+	}
+	analytic(x)
+
+	trim := int(beatTrim * float64(n))
+	kept := x[trim : n-trim]
+	envelope := make([]float64, len(kept))
+	low, high := math.Inf(1), math.Inf(-1)
+	var lag complex128 // sum of each sample times the conjugate of the one before
+	for i, v := range kept {
+		envelope[i] = cmplx.Abs(v)
+		low = math.Min(low, envelope[i])
+		high = math.Max(high, envelope[i])
+		if i > 0 {
+			lag += v * cmplx.Conj(kept[i-1])
+		}
+	}
+	if high > 0 {
+		modulationDepth = (high - low) / (high + low)
+	}
+	if len(kept) < 3 || modulationDepth < beatMinDepth {
+		return 0, 0, modulationDepth, fmt.Errorf("%w: depth %.3g", ErrNoModulation, modulationDepth)
+	}
+
+	carrierFreq = cmplx.Phase(lag) / (2 * math.Pi * step)
+	beatFreq = peakFrequency(envelope, step, 0)
+	return carrierFreq, beatFreq, modulationDepth, nil
+}
+
+// analytic replaces x, a real signal, with its analytic signal, whose real
+// part is the signal and imaginary part its Hilbert transform.
+func analytic(x []complex128) {
+	n := len(x)
+	fft(x)
+	for k := 1; k < n; k++ {
+		switch {
+		case 2*k < n:
+			x[k] *= 2
+		case 2*k > n:
+			x[k] = 0
+		}
+	}
+	ifft(x)
+}
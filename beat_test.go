@@ -0,0 +1,58 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDetectBeat(t *testing.T) {
+	// Generate sample data: 10 s at 2 kHz of 100 Hz and 101.5 Hz tones
+	data := GenerateSineWave(100, 1, 10, 2000)
+	for i := range data {
+		data[i].Value += math.Sin(2 * math.Pi * 101.5 * data[i].Time)
+	}
+
+	// Run the test
+	carrier, beat, depth, err := DetectBeat(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(beat-1.5) > 0.05 {
+		t.Errorf("beat %v Hz, expected 1.5", beat)
+	}
+	if math.Abs(carrier-100.75) > 0.05 {
+		t.Errorf("carrier %v Hz, expected 100.75", carrier)
+	}
+	if math.Abs(depth-1) > 0.02 {
+		t.Errorf("depth %v, expected 1", depth)
+	}
+}
+
+func TestDetectBeatUnequal(t *testing.T) {
+	// Generate sample data: the second tone at a fifth of the amplitude
+	// modulates the envelope to depth 0.2
+	data := GenerateSineWave(100, 1, 10, 2000)
+	for i := range data {
+		data[i].Value += 0.2 * math.Sin(2*math.Pi*102*data[i].Time)
+	}
+
+	// Run the test: the carrier lies nearer the stronger tone, at
+	// (100 + 0.04·102)/1.04 Hz
+	carrier, beat, depth, err := DetectBeat(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(beat-2) > 0.05 || math.Abs(carrier-100.077) > 0.005 || math.Abs(depth-0.2) > 0.01 {
+		t.Errorf("got carrier %v Hz, beat %v Hz, depth %v, expected 100.077, 2 and 0.2", carrier, beat, depth)
+	}
+}
+
+func TestDetectBeatNoModulation(t *testing.T) {
+	if _, _, _, err := DetectBeat(GenerateSineWave(100, 1, 10, 2000)); !errors.Is(err, ErrNoModulation) {
+		t.Errorf("single tone: got %v", err)
+	}
+	if _, _, _, err := DetectBeat(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got %v", err)
+	}
+}
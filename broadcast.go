@@ -0,0 +1,190 @@
+package dynamics
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SampleConsumer is a streaming stage that accepts single-channel samples,
+// such as a StreamAnalyzer, Aggregator or FrequencyTracker.
+type SampleConsumer interface {
+	Push(sample SingleChannelSample) error
+}
+
+// Broadcaster delivers every pushed sample to each registered consumer, so one
+// acquisition stream can feed several analyses.
+//
+// Each consumer is served by its own goroutine from a bounded queue. What
+// happens when a consumer's queue is full is chosen per consumer when it is
+// registered: with OverflowDropNewest or OverflowDropOldest a slow consumer
+// never holds up the producer or the other consumers, and the samples it misses
+// are counted in its Subscription; with OverflowBlock Push waits for the
+// consumer, delaying every other consumer too. A Broadcaster is safe for
+// concurrent use, including registering and unregistering consumers while
+// samples flow.
+type Broadcaster struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// Subscription is a consumer's registration with a Broadcaster.
+type Subscription struct {
+	consumer  SampleConsumer
+	queue     *sampleQueue
+	drain     atomic.Bool // deliver the queued samples before exiting
+	exited    chan struct{}
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// NewBroadcaster creates a Broadcaster with no consumers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscriptions: make(map[*Subscription]struct{})}
+}
+
+// Register starts delivering samples to the consumer.
+//
+// Parameters:
+//   - consumer: The stage to deliver samples to
+//   - queueSize: The number of samples that may wait for the consumer before
+//     the policy applies; values below 1 are treated as 1
+//   - policy: What to do when the consumer's queue is full
+//
+// Returns:
+//   - *Subscription: The registration, used to read counters and to unregister
+func (b *Broadcaster) Register(consumer SampleConsumer, queueSize int, policy OverflowPolicy) *Subscription {
+	sub := &Subscription{
+		consumer: consumer,
+		queue:    newSampleQueue(queueSize, policy),
+		exited:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// a consumer registered after Close never receives anything
+	if b.closed {
+		close(sub.exited)
+		return sub
+	}
+	go sub.run()
+	b.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+// Unregister stops delivering samples to a subscription's consumer. It waits
+// for a Push already in progress on the consumer to return; samples still
+// queued for the consumer are discarded. Unregistering twice is harmless.
+func (b *Broadcaster) Unregister(sub *Subscription) {
+	b.mu.Lock()
+	_, ok := b.subscriptions[sub]
+	delete(b.subscriptions, sub)
+	b.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	close(sub.queue.stop)
+	<-sub.exited
+}
+
+// Close delivers the samples already queued for each consumer, then stops
+// the delivery goroutines and unregisters every consumer. The consumers
+// themselves are not closed. Pushing after Close returns ErrClosed; closing a
+// broadcaster more than once is harmless.
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	subscriptions := b.subscriptions
+	b.subscriptions = make(map[*Subscription]struct{})
+	b.closed = true
+	b.mu.Unlock()
+
+	for sub := range subscriptions {
+		sub.drain.Store(true)
+		close(sub.queue.stop)
+	}
+	for sub := range subscriptions {
+		<-sub.exited
+	}
+	return nil
+}
+
+// Push queues the sample for every registered consumer, blocking only for
+// consumers registered with OverflowBlock. Pushing after Close returns ErrClosed.
+func (b *Broadcaster) Push(sample SingleChannelSample) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	for sub := range b.subscriptions {
+		dropped, _ := sub.queue.put(sample)
+		sub.dropped.Add(uint64(dropped))
+	}
+	return nil
+}
+
+// Delivered returns the number of samples handed to the consumer.
+func (s *Subscription) Delivered() uint64 {
+	return s.delivered.Load()
+}
+
+// Dropped returns the number of samples dropped because the consumer's queue was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Failed returns the number of samples for which the consumer's Push returned an error.
+func (s *Subscription) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// run feeds queued samples to the consumer until the subscription is stopped.
+func (s *Subscription) run() {
+	defer close(s.exited)
+
+	for {
+		select {
+		case <-s.queue.stop:
+			if s.drain.Load() {
+				s.flush()
+			}
+			return
+		case sample := <-s.queue.ch:
+			// re-check so that an unregistered consumer sees no further samples
+			select {
+			case <-s.queue.stop:
+				if s.drain.Load() {
+					s.deliver(sample)
+					s.flush()
+				}
+				return
+			default:
+			}
+			s.deliver(sample)
+		}
+	}
+}
+
+// flush delivers the samples remaining in the queue.
+func (s *Subscription) flush() {
+	for {
+		select {
+		case sample := <-s.queue.ch:
+			s.deliver(sample)
+		default:
+			return
+		}
+	}
+}
+
+// deliver hands one sample to the consumer.
+func (s *Subscription) deliver(sample SingleChannelSample) {
+	if err := s.consumer.Push(sample); err != nil {
+		s.failed.Add(1)
+	}
+	s.delivered.Add(1)
+}
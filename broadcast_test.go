@@ -0,0 +1,129 @@
+package dynamics
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingConsumer counts the samples it receives, optionally slowly.
+type countingConsumer struct {
+	delay time.Duration
+	count atomic.Int64
+}
+
+func (c *countingConsumer) Push(SingleChannelSample) error {
+	time.Sleep(c.delay)
+	c.count.Add(1)
+	return nil
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
+
+func TestBroadcaster(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+
+	// Register two fast consumers with room for the whole stream and a slow one
+	// with a small queue
+	b := NewBroadcaster()
+	fast := []*countingConsumer{{}, {}}
+	fastSubs := []*Subscription{b.Register(fast[0], len(data), OverflowDropNewest), b.Register(fast[1], len(data), OverflowDropNewest)}
+	slow := &countingConsumer{delay: time.Millisecond}
+	slowSub := b.Register(slow, 10, OverflowDropNewest)
+
+	// Run the test
+	for _, sample := range data {
+		if err := b.Push(sample); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+	}
+
+	for i, consumer := range fast {
+		if !waitFor(func() bool { return consumer.count.Load() == int64(len(data)) }) {
+			t.Errorf("Fast consumer %d received %d samples, expected %d", i, consumer.count.Load(), len(data))
+		}
+		if dropped := fastSubs[i].Dropped(); dropped != 0 {
+			t.Errorf("Fast consumer %d dropped %d samples, expected 0", i, dropped)
+		}
+	}
+
+	b.Unregister(slowSub)
+	if slowSub.Dropped() == 0 {
+		t.Errorf("Slow consumer dropped no samples")
+	}
+	if got := slowSub.Delivered() + slowSub.Dropped(); got > uint64(len(data)) {
+		t.Errorf("Slow consumer accounted for %d samples, more than the %d pushed", got, len(data))
+	}
+}
+
+func TestBroadcasterUnregister(t *testing.T) {
+	b := NewBroadcaster()
+	consumer := &countingConsumer{}
+	sub := b.Register(consumer, 100, OverflowBlock)
+
+	// Run the test
+	_ = b.Push(SingleChannelSample{Time: 0})
+	waitFor(func() bool { return consumer.count.Load() == 1 })
+	b.Unregister(sub)
+	b.Unregister(sub)
+	_ = b.Push(SingleChannelSample{Time: 1})
+
+	time.Sleep(5 * time.Millisecond)
+	if count := consumer.count.Load(); count != 1 {
+		t.Errorf("Consumer received %d samples, expected 1 before unregistering", count)
+	}
+}
+
+func TestBroadcasterConcurrentRegistration(t *testing.T) {
+	b := NewBroadcaster()
+	done := make(chan struct{})
+
+	// Push continuously while consumers come and go
+	go func() {
+		defer close(done)
+		for i := 0; i < 2000; i++ {
+			_ = b.Push(SingleChannelSample{Time: float64(i)})
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		sub := b.Register(&countingConsumer{}, 4, OverflowDropOldest)
+		b.Unregister(sub)
+	}
+	<-done
+}
+
+func TestBroadcasterClose(t *testing.T) {
+	b := NewBroadcaster()
+	slow := &countingConsumer{delay: 100 * time.Microsecond}
+	sub := b.Register(slow, 100, OverflowBlock)
+
+	// Run the test: Close delivers everything still queued before returning
+	for i := 0; i < 100; i++ {
+		_ = b.Push(SingleChannelSample{Time: float64(i)})
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if count := slow.count.Load(); count != 100 || sub.Delivered() != 100 {
+		t.Errorf("Consumer received %d samples before Close returned, expected 100", count)
+	}
+	if err := b.Push(SingleChannelSample{Time: 100}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push after Close returned %v, expected ErrClosed", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Errorf("Second Close returned error: %v", err)
+	}
+	b.Unregister(sub)
+}
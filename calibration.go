@@ -0,0 +1,171 @@
+package dynamics
+
+import "fmt"
+
+// Signal is a single-channel record with the metadata that travels with it.
+type Signal struct {
+	Name string                `json:"name"`
+	Unit string                `json:"unit"` // engineering unit of the values, such as "m/s²"
+	Data []SingleChannelSample `json:"data"`
+}
+
+// AnalyzeSignal analyses the signal's data as AnalyzeDetailed does and labels
+// the result with the signal's unit.
+//
+// Parameters:
+//   - signal: The signal to analyse
+//   - opts: Options such as WithNonFinite and WithMaxCycles
+//
+// Returns:
+//   - AnalysisResult: The analysis in the signal's unit, zero on error
+//   - error: As for AnalyzeDetailed
+func AnalyzeSignal(signal Signal, opts ...AnalyzeOption) (AnalysisResult, error) {
+	result, err := AnalyzeDetailed(signal.Data, opts...)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	result.Unit = signal.Unit
+	return result, nil
+}
+
+// RollingAnalyzeSignal analyses the signal's data as RollingAnalyze does and
+// labels every result with the signal's unit.
+//
+// Parameters:
+//   - signal: The signal to analyse
+//   - window: The length of the analysis window in seconds
+//   - hop: The sample time between successive results in seconds
+//
+// Returns:
+//   - []AnalysisResult: The results in time order, in the signal's unit
+//   - error: As for RollingAnalyze
+func RollingAnalyzeSignal(signal Signal, window, hop float64) ([]AnalysisResult, error) {
+	results, err := RollingAnalyze(signal.Data, window, hop)
+	for i := range results {
+		results[i].Unit = signal.Unit
+	}
+	return results, err
+}
+
+// Calibration converts the raw readings of a measurement chain to
+// engineering units: value = (raw − Offset)/(Gain·Sensitivity). For an
+// accelerometer of 100 mV/g read by an ADC at 3276.8 counts/V, Gain is 3276.8,
+// Sensitivity 0.1 and the values come out in g. A zero Gain or Sensitivity is
+// taken as 1, so the zero Calibration leaves values unchanged.
+type Calibration struct {
+	Sensitivity float64 `json:"sensitivity"` // sensor output per engineering unit, such as V/g
+	Gain        float64 `json:"gain"`        // raw units per sensor output unit, such as counts/V
+	Offset      float64 `json:"offset"`      // raw reading at zero input
+	RawUnit     string  `json:"rawUnit"`     // unit of the raw readings, such as "counts"
+	Unit        string  `json:"unit"`        // engineering unit, such as "g"
+}
+
+// scale returns the raw units per engineering unit.
+func (c Calibration) scale() float64 {
+	gain, sensitivity := c.Gain, c.Sensitivity
+	if gain == 0 {
+		gain = 1
+	}
+	if sensitivity == 0 {
+		sensitivity = 1
+	}
+	return gain * sensitivity
+}
+
+// Apply converts raw readings to engineering units.
+//
+// Parameters:
+//   - data: A slice of Sample structs holding raw readings
+//
+// Returns:
+//   - []SingleChannelSample: A new slice holding the values in engineering units
+func (c Calibration) Apply(data []SingleChannelSample) []SingleChannelSample {
+	scale := c.scale()
+	converted := make([]SingleChannelSample, len(data))
+	for i, sample := range data {
+		converted[i] = SingleChannelSample{Time: sample.Time, Value: (sample.Value - c.Offset) / scale}
+	}
+	return converted
+}
+
+// Invert converts values in engineering units back to raw readings, undoing Apply.
+//
+// Parameters:
+//   - data: A slice of Sample structs holding values in engineering units
+//
+// Returns:
+//   - []SingleChannelSample: A new slice holding the raw readings
+func (c Calibration) Invert(data []SingleChannelSample) []SingleChannelSample {
+	scale := c.scale()
+	converted := make([]SingleChannelSample, len(data))
+	for i, sample := range data {
+		converted[i] = SingleChannelSample{Time: sample.Time, Value: sample.Value*scale + c.Offset}
+	}
+	return converted
+}
+
+// ApplySignal converts a signal of raw readings to engineering units,
+// labelling it with the calibration's Unit.
+func (c Calibration) ApplySignal(signal Signal) Signal {
+	return Signal{Name: signal.Name, Unit: c.Unit, Data: c.Apply(signal.Data)}
+}
+
+// InvertSignal converts a signal back to raw readings, labelling it with the
+// calibration's RawUnit.
+func (c Calibration) InvertSignal(signal Signal) Signal {
+	return Signal{Name: signal.Name, Unit: c.RawUnit, Data: c.Invert(signal.Data)}
+}
+
+// ApplyChannels converts multi-channel raw readings to engineering units,
+// each channel with its own calibration.
+//
+// Parameters:
+//   - data: A slice of MultiChannelSample structs holding raw readings
+//   - calibrations: The calibration of each channel
+//
+// Returns:
+//   - []MultiChannelSample: A new slice holding the values in engineering units
+//   - error: ErrChannelMismatch, wrapped with the sample's details, if a
+//     sample does not have one value per calibration
+func ApplyChannels(data []MultiChannelSample, calibrations []Calibration) ([]MultiChannelSample, error) {
+	return convertChannels(data, calibrations, func(c Calibration, scale, v float64) float64 {
+		return (v - c.Offset) / scale
+	})
+}
+
+// InvertChannels converts multi-channel values in engineering units back to
+// raw readings, undoing ApplyChannels.
+//
+// Parameters:
+//   - data: A slice of MultiChannelSample structs holding values in engineering units
+//   - calibrations: The calibration of each channel
+//
+// Returns:
+//   - []MultiChannelSample: A new slice holding the raw readings
+//   - error: As for ApplyChannels
+func InvertChannels(data []MultiChannelSample, calibrations []Calibration) ([]MultiChannelSample, error) {
+	return convertChannels(data, calibrations, func(c Calibration, scale, v float64) float64 {
+		return v*scale + c.Offset
+	})
+}
+
+// convertChannels applies convert to every value with its channel's calibration.
+func convertChannels(data []MultiChannelSample, calibrations []Calibration, convert func(c Calibration, scale, v float64) float64) ([]MultiChannelSample, error) {
+	scales := make([]float64, len(calibrations))
+	for ch, c := range calibrations {
+		scales[ch] = c.scale()
+	}
+	values := make([]float64, len(data)*len(calibrations))
+	converted := make([]MultiChannelSample, len(data))
+	for i, sample := range data {
+		if len(sample.Value) != len(calibrations) {
+			return nil, fmt.Errorf("%w: sample %d has %d channels but there are %d calibrations", ErrChannelMismatch, i, len(sample.Value), len(calibrations))
+		}
+		row := values[i*len(calibrations) : (i+1)*len(calibrations) : (i+1)*len(calibrations)]
+		for ch, v := range sample.Value {
+			row[ch] = convert(calibrations[ch], scales[ch], v)
+		}
+		converted[i] = MultiChannelSample{Time: sample.Time, Value: row}
+	}
+	return converted, nil
+}
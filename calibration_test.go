@@ -0,0 +1,115 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// testCalibration is a 100 mV/g accelerometer on a 16-bit ADC of ±10 V with a small offset.
+var testCalibration = Calibration{Sensitivity: 0.1, Gain: 3276.8, Offset: 12, RawUnit: "counts", Unit: "g"}
+
+func TestCalibrationRoundTrip(t *testing.T) {
+	// Generate sample data
+	raw := GenerateSineWave(50, 3000, 0.1, 10000)
+
+	// Run the test
+	back := testCalibration.Invert(testCalibration.Apply(raw))
+	for i := range raw {
+		if back[i].Time != raw[i].Time || math.Abs(back[i].Value-raw[i].Value) > 1e-9 {
+			t.Fatalf("sample %d: got %+v, expected %+v", i, back[i], raw[i])
+		}
+	}
+}
+
+func TestCalibrationScalesRMS(t *testing.T) {
+	// Generate sample data
+	raw := GenerateSineWave(50, 3000, 1, 10000)
+	calibration := Calibration{Sensitivity: 0.1, Gain: 3276.8}
+
+	// Run the test: without an offset the RMS divides by the gain and sensitivity
+	expected := RMS(raw, 50) / (3276.8 * 0.1)
+	if got := RMS(calibration.Apply(raw), 50); math.Abs(got-expected) > 1e-12*expected {
+		t.Errorf("calibrated RMS %v, expected %v", got, expected)
+	}
+	if got := RMS(Calibration{}.Apply(raw), 50); got != RMS(raw, 50) {
+		t.Errorf("zero calibration changed the RMS to %v", got)
+	}
+}
+
+func TestCalibrationSignal(t *testing.T) {
+	signal := Signal{Name: "bearing DE", Unit: "counts", Data: GenerateSineWave(50, 3000, 0.1, 1000)}
+
+	calibrated := testCalibration.ApplySignal(signal)
+	if calibrated.Name != "bearing DE" || calibrated.Unit != "g" {
+		t.Errorf("got %q in %q, expected \"bearing DE\" in \"g\"", calibrated.Name, calibrated.Unit)
+	}
+	if raw := testCalibration.InvertSignal(calibrated); raw.Unit != "counts" {
+		t.Errorf("inverted unit %q, expected \"counts\"", raw.Unit)
+	}
+}
+
+func TestApplyChannels(t *testing.T) {
+	// Generate sample data: two channels with different calibrations
+	calibrations := []Calibration{testCalibration, {Gain: 2, Offset: 1}}
+	data := []MultiChannelSample{
+		{Time: 0, Value: []float64{12 + 327.68, 5}},
+		{Time: 1, Value: []float64{12 - 655.36, -3}},
+	}
+
+	// Run the test
+	converted, err := ApplyChannels(data, calibrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]float64{{1, 2}, {-2, -2}}
+	for i := range converted {
+		for ch := range converted[i].Value {
+			if math.Abs(converted[i].Value[ch]-expected[i][ch]) > 1e-12 {
+				t.Errorf("sample %d channel %d: got %v, expected %v", i, ch, converted[i].Value[ch], expected[i][ch])
+			}
+		}
+	}
+
+	back, err := InvertChannels(converted, calibrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range back {
+		for ch := range back[i].Value {
+			if math.Abs(back[i].Value[ch]-data[i].Value[ch]) > 1e-9 {
+				t.Errorf("round trip sample %d channel %d: got %v, expected %v", i, ch, back[i].Value[ch], data[i].Value[ch])
+			}
+		}
+	}
+
+	if _, err := ApplyChannels(data, calibrations[:1]); !errors.Is(err, ErrChannelMismatch) {
+		t.Errorf("too few calibrations: got %v", err)
+	}
+}
+
+func TestAnalyzeSignal(t *testing.T) {
+	// Generate sample data: a calibrated signal
+	signal := testCalibration.ApplySignal(Signal{Name: "bearing DE", Unit: "counts", Data: GenerateSineWave(50, 3000, 0.1, 10000)})
+
+	// Run the test: the result matches AnalyzeDetailed and carries the unit
+	result, err := AnalyzeSignal(signal)
+	expected, _ := AnalyzeDetailed(signal.Data)
+	expected.Unit = "g"
+	if err != nil || result != expected {
+		t.Errorf("got %+v, %v, expected %+v", result, err, expected)
+	}
+	if _, err := AnalyzeSignal(Signal{Unit: "g"}); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty signal: got error %v, expected ErrEmptyData", err)
+	}
+
+	results, err := RollingAnalyzeSignal(signal, 0.02, 0.01)
+	if err != nil || len(results) == 0 {
+		t.Fatalf("rolling: got %d results and error %v", len(results), err)
+	}
+	for i, r := range results {
+		if r.Unit != "g" {
+			t.Fatalf("rolling result %d has unit %q, expected \"g\"", i, r.Unit)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ChunkedAnalyze analyses a record read chunk by chunk, for records too large
+// to hold in memory, and gives the same result as AnalyzeDetailed on the whole
+// record. next is called until it returns io.EOF; samples returned along with
+// io.EOF are analysed, and next may reuse the slice it returns from call to call.
+//
+// Crossing counts, the peak and the time span are carried from chunk to
+// chunk, including the sign of the last sample, so a crossing that spans a
+// chunk boundary is counted. The RMS is taken over the last whole cycles of
+// the record, which are only known at its end, so the most recent samples are
+// kept: twice the span the RMS would cover if the record ended now. Once the
+// record holds more than the cycle limit of crossings this is a bounded
+// tail, but under WithMaxCycles(0), or until the limit is reached, it is the
+// whole record.
+//
+// Parameters:
+//   - next: Returns the next chunk of samples, io.EOF after the last
+//   - opts: Options such as WithNonFinite and WithMaxCycles
+//
+// Returns:
+//   - AnalysisResult: The analysis, zero on error
+//   - error: An error from next, an error wrapping ErrUnsupportedOption for
+//     WithRemoveDC or WithACFFrequency, or as for AnalyzeDetailed with indices counted from the
+//     start of the record
+func ChunkedAnalyze(next func() ([]SingleChannelSample, error), opts ...AnalyzeOption) (AnalysisResult, error) {
+	ca := chunkedAnalysis{config: newAnalyzeConfig(opts)}
+	if err := ca.config.checkSinglePass(); err != nil {
+		return AnalysisResult{}, err
+	}
+	for chunk := 0; ; chunk++ {
+		data, err := next()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return AnalysisResult{}, fmt.Errorf("dynamics: reading chunk %d: %w", chunk, err)
+		}
+		if addErr := ca.add(data); addErr != nil {
+			return AnalysisResult{}, addErr
+		}
+		if err != nil {
+			return ca.result()
+		}
+	}
+}
+
+// chunkedAnalysis holds the state ChunkedAnalyze carries between chunks.
+type chunkedAnalysis struct {
+	config    analyzeConfig
+	index     int // index in the record of the next sample, counting dropped ones
+	samples   int // samples analysed
+	first     float64
+	last      float64
+	peak      float64
+	detector  crossingDetector
+	crossings int
+	tail      []SingleChannelSample // the most recent samples, from tail[start] on
+	start     int
+}
+
+// add analyses a chunk and appends it to the tail.
+func (ca *chunkedAnalysis) add(data []SingleChannelSample) error {
+	for _, sample := range data {
+		index := ca.index
+		ca.index++
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			switch ca.config.nonFinite {
+			case NonFiniteStrict:
+				return nonFiniteError(index, sample.Time, sample.Value)
+			case NonFiniteSkip:
+				continue
+			}
+		}
+
+		if ca.samples == 0 {
+			ca.first = sample.Time
+		} else if sample.Time < ca.last {
+			return fmt.Errorf("%w: sample %d at time %g is earlier than the sample before it at time %g", ErrUnsortedData, index, sample.Time, ca.last)
+		}
+		ca.last = sample.Time
+		ca.samples++
+		ca.peak = math.Max(ca.peak, math.Abs(sample.Value))
+		if negative, _ := ca.detector.step(sample.Value); negative {
+			ca.crossings++
+		}
+		ca.tail = append(ca.tail, sample)
+	}
+	ca.trim()
+	return nil
+}
+
+// trim drops the samples that the RMS cannot need, whatever follows.
+//
+// If the record ended now the RMS would cover span seconds, and with more than
+// maxCycles crossings that is maxCycles·duration/crossings. Data still to come
+// lengthens the duration by some Δ and cannot remove crossings, so the final
+// span is at most span + Δ·maxCycles/crossings, less than span + Δ: the final
+// RMS starts no earlier than span before the present last sample. Keeping
+// twice the span leaves room for rounding.
+func (ca *chunkedAnalysis) trim() {
+	duration := ca.last - ca.first
+	if ca.crossings == 0 || !(duration > 0) {
+		return
+	}
+	span := rmsSpan(duration, float64(ca.crossings)/duration, ca.config.maxCycles)
+	if math.IsInf(span, 1) || 2*span >= duration {
+		return
+	}
+
+	cutoff := ca.last - 2*span
+	for ca.start < len(ca.tail) && ca.tail[ca.start].Time < cutoff {
+		ca.start++
+	}
+	// compact once the dropped samples outnumber the kept ones, so that each
+	// sample is moved a bounded number of times
+	if ca.start > len(ca.tail)-ca.start {
+		ca.tail = append(ca.tail[:0], ca.tail[ca.start:]...)
+		ca.start = 0
+	}
+}
+
+// result completes the analysis once the last chunk has been added.
+func (ca *chunkedAnalysis) result() (AnalysisResult, error) {
+	if ca.samples == 0 {
+		return AnalysisResult{}, ErrEmptyData
+	}
+	duration := ca.last - ca.first
+	if !(duration > 0) {
+		return AnalysisResult{}, ErrZeroDuration
+	}
+
+	result := AnalysisResult{
+		Time:    ca.last,
+		Peak:    ca.peak,
+		Samples: ca.samples,
+	}
+	zcr := float64(ca.crossings) / duration
+	span := math.Inf(1)
+	if zcr > 0 && !math.IsInf(zcr, 1) {
+		result.NZCR = zcr
+		span = rmsSpan(duration, zcr, ca.config.maxCycles)
+	}
+	kept := KeepXSecondsOfData(ca.tail[ca.start:], span)
+	result.RMS = ca.config.rms(kept)
+	result.RMSSpan = math.Min(span, duration)
+	result.CycleAligned = !math.IsInf(span, 1)
+	result.CrestFactor = crestFactorOf(result.Peak, result.RMS)
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package dynamics
+
+import (
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+// chunker returns a next function for ChunkedAnalyze that hands out data in
+// chunks of the given sizes, repeating the last size, through one reused buffer.
+func chunker(data []SingleChannelSample, sizes ...int) func() ([]SingleChannelSample, error) {
+	buffer := make([]SingleChannelSample, 0, len(data))
+	n := 0
+	return func() ([]SingleChannelSample, error) {
+		if len(data) == 0 {
+			return nil, io.EOF
+		}
+		size := sizes[min(n, len(sizes)-1)]
+		n++
+		size = min(size, len(data))
+		buffer = append(buffer[:0], data[:size]...)
+		data = data[size:]
+		return buffer, nil
+	}
+}
+
+func TestChunkedAnalyzeMatchesAnalyzeDetailed(t *testing.T) {
+	// Generate sample data: a long record, one that ends in silence, and one
+	// whose amplitude and frequency change
+	long := GenerateSineWave(50, 1, 60, 1000)
+	silent := append(GenerateSineWave(60, 2, 30, 1000), make([]SingleChannelSample, 20000)...)
+	for i := range silent {
+		silent[i].Time = float64(i) / 1000
+	}
+	varying := GenerateSineWave(50, 1, 30, 997)
+	for i := range varying {
+		varying[i].Value *= 1 + varying[i].Time/10
+		if varying[i].Time > 20 {
+			varying[i].Value = math.Sin(2 * math.Pi * 173 * varying[i].Time)
+		}
+	}
+
+	records := []struct {
+		name string
+		data []SingleChannelSample
+		opts []AnalyzeOption
+	}{
+		{"long", long, nil},
+		{"ending in silence", silent, nil},
+		{"varying", varying, nil},
+		{"no cycle limit", varying, []AnalyzeOption{WithMaxCycles(0)}},
+		{"small cycle limit", varying, []AnalyzeOption{WithMaxCycles(3)}},
+		{"short", GenerateSineWave(50, 1, 0.015, 1000), nil},
+	}
+	splits := [][]int{{1}, {7}, {333}, {1 << 30}, {19, 1, 2000, 3}}
+
+	for _, record := range records {
+		expected, err := AnalyzeDetailed(record.data, record.opts...)
+		if err != nil {
+			t.Fatalf("%s: AnalyzeDetailed returned error: %v", record.name, err)
+		}
+		for _, sizes := range splits {
+			// Run the test
+			result, err := ChunkedAnalyze(chunker(record.data, sizes...), record.opts...)
+			if err != nil {
+				t.Errorf("%s in chunks of %v: ChunkedAnalyze returned error: %v", record.name, sizes, err)
+				continue
+			}
+			if result != expected {
+				t.Errorf("%s in chunks of %v: got %+v, expected %+v", record.name, sizes, result, expected)
+			}
+		}
+	}
+}
+
+func TestChunkedAnalyzeBoundedTail(t *testing.T) {
+	// Generate sample data: 10000 cycles, ten times the default cycle limit
+	data := GenerateSineWave(50, 1, 200, 1000)
+
+	// Run the test: only about twice the 20 s RMS span is kept
+	ca := chunkedAnalysis{config: newAnalyzeConfig(nil)}
+	for start := 0; start < len(data); start += 500 {
+		if err := ca.add(data[start : start+500]); err != nil {
+			t.Fatalf("add returned error: %v", err)
+		}
+	}
+	if kept := len(ca.tail) - ca.start; kept > 40500 {
+		t.Errorf("%d samples kept, expected about 40000", kept)
+	}
+	if cap(ca.tail) >= len(data)/2 {
+		t.Errorf("tail capacity %d, expected less than half the %d samples in the record", cap(ca.tail), len(data))
+	}
+}
+
+func TestChunkedAnalyzeNonFinite(t *testing.T) {
+	// Generate sample data with gaps, one at a chunk boundary
+	data := GenerateSineWave(50, 1, 2, 1000)
+	data[100].Value = math.NaN()
+	data[1234].Value = math.Inf(-1)
+
+	// Run the test
+	expected, _ := AnalyzeDetailed(data, WithNonFinite(NonFiniteSkip))
+	result, err := ChunkedAnalyze(chunker(data, 100, 1000), WithNonFinite(NonFiniteSkip))
+	if err != nil || result != expected {
+		t.Errorf("NonFiniteSkip: got %+v, %v, expected %+v", result, err, expected)
+	}
+
+	_, err = ChunkedAnalyze(chunker(data, 1000), WithNonFinite(NonFiniteStrict))
+	if !errors.Is(err, ErrNonFinite) {
+		t.Fatalf("NonFiniteStrict: got error %v, expected ErrNonFinite", err)
+	}
+	_, expectedErr := AnalyzeDetailed(data, WithNonFinite(NonFiniteStrict))
+	if err.Error() != expectedErr.Error() {
+		t.Errorf("NonFiniteStrict: got error %q, expected %q", err, expectedErr)
+	}
+}
+
+func TestChunkedAnalyzeErrors(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+	swapped := append([]SingleChannelSample(nil), data...)
+	swapped[500], swapped[501] = swapped[501], swapped[500]
+	failure := errors.New("disk on fire")
+
+	// Run the test
+	if _, err := ChunkedAnalyze(chunker(nil, 1)); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty record: got error %v, expected ErrEmptyData", err)
+	}
+	if _, err := ChunkedAnalyze(chunker(data[:1], 1)); !errors.Is(err, ErrZeroDuration) {
+		t.Errorf("single sample: got error %v, expected ErrZeroDuration", err)
+	}
+	if _, err := ChunkedAnalyze(chunker(swapped, 501)); !errors.Is(err, ErrUnsortedData) {
+		t.Errorf("unsorted across a chunk boundary: got error %v, expected ErrUnsortedData", err)
+	}
+	if _, err := ChunkedAnalyze(chunker(data, 100), WithRemoveDC()); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("WithRemoveDC: got error %v, expected ErrUnsupportedOption", err)
+	}
+	if _, err := ChunkedAnalyze(chunker(data, 100), WithACFFrequency()); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("WithACFFrequency: got error %v, expected ErrUnsupportedOption", err)
+	}
+
+	calls := 0
+	_, err := ChunkedAnalyze(func() ([]SingleChannelSample, error) {
+		calls++
+		if calls == 3 {
+			return nil, failure
+		}
+		return data[calls*10 : calls*10+10], nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("failing reader: got error %v, expected it to wrap %v", err, failure)
+	}
+
+	// samples returned along with io.EOF are analysed
+	expected, _ := AnalyzeDetailed(data)
+	result, err := ChunkedAnalyze(func() ([]SingleChannelSample, error) {
+		return data, io.EOF
+	})
+	if err != nil || result != expected {
+		t.Errorf("data with io.EOF: got %+v, %v, expected %+v", result, err, expected)
+	}
+}
@@ -0,0 +1,83 @@
+package dynamics
+
+import "math"
+
+// ClipEvent is a run of samples at the rails found by DetectClipping.
+type ClipEvent struct {
+	Start    float64 `json:"start"`    // time of the first sample at the rail
+	End      float64 `json:"end"`      // time of the last sample at the rail
+	Positive bool    `json:"positive"` // true at the positive rail, false at the negative
+}
+
+// DetectClipping finds where the sensor or ADC saturated, which makes RMS and
+// peak values under-report. A sample is at a rail when its magnitude is at
+// least the limit, the positive rail for positive values and the negative for
+// negative ones, and an event is a run of at least minConsecutive consecutive
+// samples at the same rail. NaN values are never at a rail.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - limit: The magnitude of the rails
+//   - minConsecutive: The least samples in a run, taken as 1 when smaller
+//
+// Returns:
+//   - []ClipEvent: The events in time order, nil when limit is not positive and finite
+func DetectClipping(data []SingleChannelSample, limit float64, minConsecutive int) []ClipEvent {
+	if !(limit > 0) || math.IsInf(limit, 1) {
+		return nil
+	}
+	minConsecutive = max(minConsecutive, 1)
+
+	var events []ClipEvent
+	start := -1 // index of the first sample of the current run
+	for i := 0; i <= len(data); i++ {
+		rail := 0
+		if i < len(data) {
+			rail = clipRail(data[i].Value, limit)
+		}
+		if start >= 0 && rail != clipRail(data[start].Value, limit) {
+			if i-start >= minConsecutive {
+				events = append(events, ClipEvent{Start: data[start].Time, End: data[i-1].Time, Positive: data[start].Value > 0})
+			}
+			start = -1
+		}
+		if start < 0 && rail != 0 {
+			start = i
+		}
+	}
+	return events
+}
+
+// ClippedFraction returns the proportion of samples at the rails, those with
+// a magnitude of at least the limit, as DetectClipping counts them.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - limit: The magnitude of the rails
+//
+// Returns:
+//   - float64: The fraction of samples at the rails, 0 for empty data or a limit not positive and finite
+func ClippedFraction(data []SingleChannelSample, limit float64) float64 {
+	if len(data) == 0 || !(limit > 0) || math.IsInf(limit, 1) {
+		return 0
+	}
+	var clipped int
+	for _, sample := range data {
+		if clipRail(sample.Value, limit) != 0 {
+			clipped++
+		}
+	}
+	return float64(clipped) / float64(len(data))
+}
+
+// clipRail returns 1 when the value is at the positive rail, -1 when at the
+// negative and 0 otherwise.
+func clipRail(value, limit float64) int {
+	switch {
+	case value >= limit:
+		return 1
+	case value <= -limit:
+		return -1
+	}
+	return 0
+}
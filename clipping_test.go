@@ -0,0 +1,78 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDetectClipping(t *testing.T) {
+	// Generate sample data: a sine of amplitude 1.2 hard-limited at ±1
+	data := GenerateSineWave(50, 1.2, 1, 10000)
+	for i := range data {
+		data[i].Value = math.Max(-1, math.Min(1, data[i].Value))
+	}
+
+	// Run the test
+	events := DetectClipping(data, 1, 2)
+
+	// each half cycle clips while |sin| ≥ 1/1.2
+	if len(events) != 100 {
+		t.Fatalf("%d events, expected 100, two per cycle", len(events))
+	}
+	want := (math.Pi - 2*math.Asin(1/1.2)) / (2 * math.Pi * 50)
+	for i, event := range events {
+		if event.Positive != (i%2 == 0) {
+			t.Errorf("event %d at the positive rail %v, expected the rails to alternate from positive", i, event.Positive)
+		}
+		if d := event.End - event.Start; math.Abs(d-want) > 2e-4 {
+			t.Errorf("event %d lasts %v, expected %v", i, d, want)
+		}
+	}
+	if f := ClippedFraction(data, 1); math.Abs(f-want*100) > 0.01 {
+		t.Errorf("ClippedFraction %v, expected %v", f, want*100)
+	}
+}
+
+func TestDetectClippingRuns(t *testing.T) {
+	// Generate sample data: a single sample, then three, at the rails
+	data := valuesToSamples([]float64{0, 1, 0, -1, -1, -1, 0, 1, -1, math.NaN()})
+
+	cases := []struct {
+		minConsecutive int
+		expected       []ClipEvent
+	}{
+		{0, []ClipEvent{{1, 1, true}, {3, 5, false}, {7, 7, true}, {8, 8, false}}},
+		{2, []ClipEvent{{3, 5, false}}},
+		{4, nil},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		events := DetectClipping(data, 1, c.minConsecutive)
+		if len(events) != len(c.expected) {
+			t.Errorf("minConsecutive %d: %v, expected %v", c.minConsecutive, events, c.expected)
+			continue
+		}
+		for i := range events {
+			if events[i] != c.expected[i] {
+				t.Errorf("minConsecutive %d: %v, expected %v", c.minConsecutive, events, c.expected)
+				break
+			}
+		}
+	}
+
+	if f := ClippedFraction(data, 1); f != 0.6 {
+		t.Errorf("ClippedFraction %v, expected 0.6", f)
+	}
+	for _, limit := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if events := DetectClipping(data, limit, 1); events != nil {
+			t.Errorf("limit %v: %v, expected nil", limit, events)
+		}
+		if f := ClippedFraction(data, limit); f != 0 {
+			t.Errorf("limit %v: ClippedFraction %v, expected 0", limit, f)
+		}
+	}
+	if f := ClippedFraction(nil, 1); f != 0 {
+		t.Errorf("ClippedFraction of empty data %v, expected 0", f)
+	}
+}
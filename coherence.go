@@ -0,0 +1,105 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+)
+
+// Coherence returns the magnitude-squared coherence of two channels against
+// frequency, |Pab|²/(Paa·Pbb), which is near 1 at frequencies where one
+// channel follows the other linearly, as when both measure the same vibration
+// source, and near 0 where they are unrelated. The spectra are Welch averages:
+// the record is cut into segments of segmentLength samples, each overlapping
+// the one before by the given fraction, and each segment has its mean removed
+// and a Hann window applied before the transform. The lines are
+// 1/(segmentLength·step) apart, the step being the mean interval between the
+// timestamps, from 0 to half the sample rate.
+//
+// A single segment has a coherence of exactly 1 at every frequency, and
+// heavily overlapping segments are nearly the same one, so the record must
+// span at least two segments at least half a segment apart, whatever the
+// overlap; the estimate is biased upwards by about one over the number of
+// independent segments where the channels are unrelated. Lines where either
+// channel has no power have a coherence of 0.
+//
+// The samples must be evenly spaced, as for Spectrum.
+//
+// Parameters:
+//   - data: A slice of MultiChannelSample structs containing time and value data
+//   - chA: The index of the first channel
+//   - chB: The index of the second channel
+//   - segmentLength: The number of samples in each segment, at least 2
+//   - overlap: The fraction of each segment shared with the next, from 0 up to but not including 1
+//
+// Returns:
+//   - []SpectrumBin: The coherence from 0 to 1 at each line, lowest frequency first
+//   - error: As for Spectrum, an error wrapping ErrChannelMismatch naming the
+//     first sample without either channel, or an error if segmentLength or
+//     overlap is out of range or the record holds fewer than two segments
+//     half a segment apart
+func Coherence(data []MultiChannelSample, chA, chB int, segmentLength int, overlap float64) ([]SpectrumBin, error) {
+	if segmentLength < 2 {
+		return nil, fmt.Errorf("dynamics: segment length %d is less than 2", segmentLength)
+	}
+	if !(overlap >= 0 && overlap < 1) {
+		return nil, fmt.Errorf("dynamics: overlap %g is outside [0, 1)", overlap)
+	}
+	a := make([]SingleChannelSample, len(data))
+	b := make([]SingleChannelSample, len(data))
+	for i, sample := range data {
+		if chA < 0 || chB < 0 || chA >= len(sample.Value) || chB >= len(sample.Value) {
+			return nil, fmt.Errorf("%w: sample %d at time %g has %d channels, expected channels %d and %d", ErrChannelMismatch, i, sample.Time, len(sample.Value), chA, chB)
+		}
+		a[i] = SingleChannelSample{Time: sample.Time, Value: sample.Value[chA]}
+		b[i] = SingleChannelSample{Time: sample.Time, Value: sample.Value[chB]}
+	}
+	step, err := evenStep(a)
+	if err != nil {
+		return nil, err
+	}
+
+	hop := max(1, int(math.Round(float64(segmentLength)*(1-overlap))))
+	// segments closer than this share most of their samples and add little
+	spacing := max(hop, (segmentLength+1)/2)
+	if len(data) < segmentLength+spacing {
+		return nil, fmt.Errorf("dynamics: %d samples hold fewer than two segments of %d samples %d apart", len(data), segmentLength, spacing)
+	}
+
+	// periodic Hann window
+	window := make([]float64, segmentLength)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(segmentLength))
+	}
+	lines := segmentLength/2 + 1
+	paa := make([]float64, lines)
+	pbb := make([]float64, lines)
+	pab := make([]complex128, lines)
+	x := make([]complex128, segmentLength)
+	y := make([]complex128, segmentLength)
+	for start := 0; start+segmentLength <= len(data); start += hop {
+		segmentA := RemoveDC(a[start : start+segmentLength])
+		segmentB := RemoveDC(b[start : start+segmentLength])
+		for i, w := range window {
+			x[i] = complex(segmentA[i].Value*w, 0)
+			y[i] = complex(segmentB[i].Value*w, 0)
+		}
+		fft(x)
+		fft(y)
+		for k := range lines {
+			paa[k] += real(x[k])*real(x[k]) + imag(x[k])*imag(x[k])
+			pbb[k] += real(y[k])*real(y[k]) + imag(y[k])*imag(y[k])
+			pab[k] += complex(real(x[k]), -imag(x[k])) * y[k]
+		}
+	}
+
+	resolution := 1 / (step * float64(segmentLength))
+	bins := make([]SpectrumBin, lines)
+	for k := range bins {
+		bins[k].Frequency = float64(k) * resolution
+		if power := paa[k] * pbb[k]; power > 0 {
+			cross := real(pab[k])*real(pab[k]) + imag(pab[k])*imag(pab[k])
+			bins[k].Magnitude = math.Min(cross/power, 1)
+		}
+	}
+	return bins, nil
+}
@@ -0,0 +1,123 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// twoChannels returns the values of a and b as two channels at the times of a.
+func twoChannels(a, b []SingleChannelSample) []MultiChannelSample {
+	data := make([]MultiChannelSample, len(a))
+	for i := range a {
+		data[i] = MultiChannelSample{Time: a[i].Time, Value: []float64{a[i].Value, b[i].Value}}
+	}
+	return data
+}
+
+func TestCoherence(t *testing.T) {
+	// Generate sample data: the same 100 Hz sine in two channels with
+	// independent noise of equal power, and two channels of independent noise,
+	// 10 s at 1 kHz
+	sine := GenerateSineWave(100, 1, 10, 1000)
+	shared := twoChannels(AddNoise(sine, 0, 1), AddNoise(sine, 0, 2))
+	independent := twoChannels(GenerateWhiteNoise(1, 10, 1000, 3), GenerateWhiteNoise(1, 10, 1000, 4))
+
+	// Run the test
+	bins, err := Coherence(shared, 0, 1, 256, 0.5)
+	if err != nil {
+		t.Fatalf("Coherence returned error: %v", err)
+	}
+	if len(bins) != 129 || bins[128].Frequency != 500 {
+		t.Fatalf("Coherence returned %d lines up to %v Hz, expected 129 up to 500 Hz", len(bins), bins[len(bins)-1].Frequency)
+	}
+	var tone float64
+	for _, bin := range bins {
+		if bin.Magnitude < 0 || bin.Magnitude > 1 {
+			t.Errorf("coherence %v at %v Hz outside [0, 1]", bin.Magnitude, bin.Frequency)
+		}
+		if math.Abs(bin.Frequency-100) > 10 {
+			if bin.Magnitude > 0.2 {
+				t.Errorf("shared tone: coherence %v at %v Hz, expected low away from the tone", bin.Magnitude, bin.Frequency)
+			}
+			continue
+		}
+		tone = math.Max(tone, bin.Magnitude)
+	}
+	if tone < 0.95 {
+		t.Errorf("shared tone: coherence %v at 100 Hz, expected near 1", tone)
+	}
+
+	bins, err = Coherence(independent, 1, 0, 256, 0.5)
+	if err != nil {
+		t.Fatalf("Coherence returned error: %v", err)
+	}
+	var mean float64
+	for _, bin := range bins {
+		if bin.Magnitude > 0.2 {
+			t.Errorf("independent noise: coherence %v at %v Hz, expected low everywhere", bin.Magnitude, bin.Frequency)
+		}
+		mean += bin.Magnitude / float64(len(bins))
+	}
+	if mean > 0.05 {
+		t.Errorf("independent noise: mean coherence %v, expected near 0", mean)
+	}
+
+	// a channel is fully coherent with itself
+	bins, err = Coherence(shared, 0, 0, 100, 0)
+	if err != nil {
+		t.Fatalf("Coherence returned error: %v", err)
+	}
+	for _, bin := range bins[1:] {
+		if math.Abs(bin.Magnitude-1) > 1e-9 {
+			t.Errorf("self: coherence %v at %v Hz, expected 1", bin.Magnitude, bin.Frequency)
+		}
+	}
+}
+
+func TestCoherenceInvalid(t *testing.T) {
+	data := twoChannels(GenerateWhiteNoise(1, 1, 1000, 1), GenerateWhiteNoise(1, 1, 1000, 2))
+	uneven := twoChannels(GenerateWhiteNoise(1, 1, 1000, 1), GenerateWhiteNoise(1, 1, 1000, 2))
+	uneven[500].Time += 0.0005
+
+	cases := []struct {
+		name          string
+		data          []MultiChannelSample
+		chA, chB      int
+		segmentLength int
+		overlap       float64
+		err           error
+	}{
+		{"empty", nil, 0, 1, 256, 0.5, ErrEmptyData},
+		{"missing channel", data, 0, 2, 256, 0.5, ErrChannelMismatch},
+		{"negative channel", data, -1, 1, 256, 0.5, ErrChannelMismatch},
+		{"uneven", uneven, 0, 1, 256, 0.5, ErrUnevenSpacing},
+		{"one segment", data, 0, 1, 1000, 0.5, nil},
+		{"one segment without overlap", data, 0, 1, 501, 0, nil},
+		// two segments of 700 samples 7 apart fit, but are nearly the same one
+		{"segments overlapping almost entirely", data, 0, 1, 700, 0.99, nil},
+		{"short segment", data, 0, 1, 1, 0.5, nil},
+		{"overlap of 1", data, 0, 1, 256, 1, nil},
+		{"negative overlap", data, 0, 1, 256, -0.1, nil},
+		{"NaN overlap", data, 0, 1, 256, math.NaN(), nil},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		bins, err := Coherence(c.data, c.chA, c.chB, c.segmentLength, c.overlap)
+		if err == nil || bins != nil {
+			t.Errorf("%s: Coherence returned %d lines, %v; expected an error", c.name, len(bins), err)
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: Coherence returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+
+	// two segments are enough, half a segment apart or more
+	if _, err := Coherence(data, 0, 1, 500, 0); err != nil {
+		t.Errorf("Coherence of two segments returned error: %v", err)
+	}
+	if _, err := Coherence(data, 0, 1, 600, 0.99); err != nil {
+		t.Errorf("Coherence of overlapping segments spanning 900 samples returned error: %v", err)
+	}
+}
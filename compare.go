@@ -0,0 +1,172 @@
+package dynamics
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultAlarmDelta is the change in dB of a band that CompareSpectra flags
+// without WithAlarmDelta.
+const DefaultAlarmDelta = 6
+
+// SpectrumBin is one line of a spectrum.
+type SpectrumBin struct {
+	Frequency float64 `json:"frequency"` // in Hz
+	Magnitude float64 `json:"magnitude"`
+}
+
+// BandChange is the change in level of one band between two spectra.
+type BandChange struct {
+	Low      float64 `json:"low"`      // lower edge in Hz
+	High     float64 `json:"high"`     // upper edge in Hz
+	Baseline float64 `json:"baseline"` // level of the baseline in dB
+	Current  float64 `json:"current"`  // level of the current spectrum in dB
+	Change   float64 `json:"change"`   // Current − Baseline in dB
+	Alarm    bool    `json:"alarm"`    // the change is at least the alarm delta either way
+}
+
+// ComparisonResult is the outcome of CompareSpectra.
+type ComparisonResult struct {
+	Bands  []BandChange `json:"bands"`
+	Alarms []int        `json:"alarms"` // indices in Bands of the bands in alarm
+	Score  float64      `json:"score"`  // weighted RMS of the band changes in dB, 0 for identical spectra
+}
+
+// CompareOption configures a call to CompareSpectra.
+type CompareOption func(*compareConfig)
+
+// compareConfig holds the settings made by CompareOptions.
+type compareConfig struct {
+	edges   []float64
+	weights []float64
+	alarm   float64
+}
+
+// WithBands sets the band edges in Hz, in increasing order, in place of the
+// default octave bands; n+1 edges make n bands.
+func WithBands(edges ...float64) CompareOption {
+	return func(c *compareConfig) {
+		c.edges = edges
+	}
+}
+
+// WithBandWeights sets the weight of each band in the anomaly score, in the
+// order of the bands, in place of equal weights. Missing weights count as 1.
+func WithBandWeights(weights ...float64) CompareOption {
+	return func(c *compareConfig) {
+		c.weights = weights
+	}
+}
+
+// WithAlarmDelta sets the change in dB, up or down, at which a band is in
+// alarm, in place of DefaultAlarmDelta.
+func WithAlarmDelta(dB float64) CompareOption {
+	return func(c *compareConfig) {
+		c.alarm = dB
+	}
+}
+
+// CompareSpectra scores a spectrum against a baseline for condition
+// monitoring. The level of each band is the summed squared magnitudes of the
+// lines within it and within the overlap of the two spectra's frequency
+// ranges, taken on each spectrum's own lines, and the levels are compared in
+// dB. For amplitude spectra, as Spectrum gives, the level is the power in the
+// band whatever the resolution, so spectra of records of different lengths
+// can be compared and a narrow line is counted wherever it falls. A
+// level is floored at 10⁻¹² of the total baseline power, 120 dB down, so an
+// empty band does not give an infinite change. The anomaly score is the
+// weighted RMS of the band changes.
+//
+// Without WithBands the bands are the octaves of IEC 61260, centred on 1 kHz
+// and its doublings and halvings down to 16 Hz, that fall wholly within the
+// overlap.
+//
+// Parameters:
+//   - baseline: The reference spectrum, in increasing order of frequency
+//   - current: The spectrum to score, in increasing order of frequency
+//   - opts: Options such as WithBands and WithAlarmDelta
+//
+// Returns:
+//   - ComparisonResult: The band changes, the bands in alarm and the score;
+//     zero when the spectra do not overlap
+func CompareSpectra(baseline, current []SpectrumBin, opts ...CompareOption) ComparisonResult {
+	config := compareConfig{alarm: DefaultAlarmDelta}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if len(baseline) == 0 || len(current) == 0 {
+		return ComparisonResult{}
+	}
+	low := math.Max(baseline[0].Frequency, current[0].Frequency)
+	high := math.Min(baseline[len(baseline)-1].Frequency, current[len(current)-1].Frequency)
+	if !(high > low) {
+		return ComparisonResult{}
+	}
+	edges := config.edges
+	if edges == nil {
+		edges = octaveEdges(low, high)
+	}
+
+	var total float64
+	for _, bin := range baseline {
+		total += bin.Magnitude * bin.Magnitude
+	}
+	floor := 1e-12 * total
+
+	var result ComparisonResult
+	var sumSq, sumWeights float64
+	for b := 0; b+1 < len(edges); b++ {
+		lo := math.Max(edges[b], low)
+		base, cur := bandPower(baseline, lo, edges[b+1], high), bandPower(current, lo, edges[b+1], high)
+		band := BandChange{
+			Low:      edges[b],
+			High:     edges[b+1],
+			Baseline: 10 * math.Log10(math.Max(base, floor)),
+			Current:  10 * math.Log10(math.Max(cur, floor)),
+		}
+		band.Change = band.Current - band.Baseline
+		band.Alarm = math.Abs(band.Change) >= config.alarm
+		if band.Alarm {
+			result.Alarms = append(result.Alarms, len(result.Bands))
+		}
+		weight := 1.0
+		if b < len(config.weights) {
+			weight = config.weights[b]
+		}
+		sumSq += weight * band.Change * band.Change
+		sumWeights += weight
+		result.Bands = append(result.Bands, band)
+	}
+	if sumWeights > 0 {
+		result.Score = math.Sqrt(sumSq / sumWeights)
+	}
+	return result
+}
+
+// octaveEdges returns the edges of the octave bands centred on 1000·2ᵏ Hz,
+// from the 16 Hz band up, that lie wholly between low and high.
+func octaveEdges(low, high float64) []float64 {
+	var edges []float64
+	first := math.Max(math.Ceil(math.Log2(low/1000)+0.5), -6)
+	for k := first; ; k++ {
+		edge := 1000 * math.Pow(2, k-0.5)
+		if edge > high {
+			break
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// bandPower returns the summed squared magnitudes of the lines of the
+// spectrum from lo up to, but not including, hi, and no higher than top.
+func bandPower(spectrum []SpectrumBin, lo, hi, top float64) float64 {
+	var power float64
+	for _, bin := range spectrum[sort.Search(len(spectrum), func(i int) bool { return spectrum[i].Frequency >= lo }):] {
+		if bin.Frequency >= hi || bin.Frequency > top {
+			break
+		}
+		power += bin.Magnitude * bin.Magnitude
+	}
+	return power
+}
@@ -0,0 +1,91 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// flatSpectrum returns lines step Hz apart from 0 to 5 kHz of the given magnitude.
+func flatSpectrum(step, magnitude float64) []SpectrumBin {
+	var spectrum []SpectrumBin
+	for f := 0.0; f <= 5000; f += step {
+		spectrum = append(spectrum, SpectrumBin{Frequency: f, Magnitude: magnitude})
+	}
+	return spectrum
+}
+
+func TestCompareSpectraIdentical(t *testing.T) {
+	baseline := flatSpectrum(1, 0.01)
+
+	// Run the test
+	result := CompareSpectra(baseline, baseline)
+	if result.Score != 0 || len(result.Alarms) != 0 {
+		t.Errorf("got score %v and alarms %v, expected 0 and none", result.Score, result.Alarms)
+	}
+	// the octaves from 16 Hz to 2 kHz lie within 0 to 5 kHz
+	if len(result.Bands) != 8 {
+		t.Errorf("got %d bands, expected 8", len(result.Bands))
+	}
+}
+
+func TestCompareSpectraLine(t *testing.T) {
+	// Generate sample data: a current spectrum of the same power density on a
+	// grid twice as fine, with a line at 2 kHz carrying ten times the power of
+	// its octave band
+	baseline := flatSpectrum(1, 0.01)
+	current := flatSpectrum(0.5, 0.01/math.Sqrt2)
+	var power float64
+	for _, bin := range baseline {
+		if bin.Frequency >= 1000*math.Sqrt2 && bin.Frequency < 2000*math.Sqrt2 {
+			power += bin.Magnitude * bin.Magnitude
+		}
+	}
+	current[4000].Magnitude = math.Sqrt(9*power + 0.01*0.01/2)
+
+	// Run the test: the 2 kHz octave rises 10 dB and nothing else moves
+	result := CompareSpectra(baseline, current)
+	if len(result.Alarms) != 1 {
+		t.Fatalf("got alarms %v, expected one", result.Alarms)
+	}
+	band := result.Bands[result.Alarms[0]]
+	if band.Low > 2000 || band.High < 2000 {
+		t.Errorf("alarm in %v to %v Hz, expected the 2 kHz octave", band.Low, band.High)
+	}
+	if math.Abs(band.Change-10) > 0.01 {
+		t.Errorf("change %v dB, expected 10", band.Change)
+	}
+	if expected := 10 / math.Sqrt(8); math.Abs(result.Score-expected) > 0.01 {
+		t.Errorf("score %v, expected %v", result.Score, expected)
+	}
+
+	// a larger alarm delta clears the alarm, and weights shift the score
+	if result := CompareSpectra(baseline, current, WithAlarmDelta(12)); len(result.Alarms) != 0 {
+		t.Errorf("12 dB delta: got alarms %v, expected none", result.Alarms)
+	}
+	if result := CompareSpectra(baseline, current, WithBands(1000, 3000), WithBandWeights(2)); len(result.Bands) != 1 || math.Abs(result.Score-result.Bands[0].Change) > 1e-9 {
+		t.Errorf("one band: got %+v", result)
+	}
+}
+
+func TestCompareSpectraLineBetweenLines(t *testing.T) {
+	// Generate sample data: a line at 2000.5 Hz in a current spectrum on a
+	// grid twice as fine, halfway between two lines of the baseline
+	baseline := flatSpectrum(1, 0.01)
+	current := flatSpectrum(0.5, 0.01/math.Sqrt2)
+	power := bandPower(baseline, 1000*math.Sqrt2, 2000*math.Sqrt2, 5000)
+	current[4001].Magnitude = math.Sqrt(9*power + 0.01*0.01/2)
+
+	// Run the test: the line is counted in full on the current spectrum's grid
+	result := CompareSpectra(baseline, current)
+	if len(result.Alarms) != 1 {
+		t.Fatalf("got alarms %v, expected one", result.Alarms)
+	}
+	if band := result.Bands[result.Alarms[0]]; math.Abs(band.Change-10) > 0.01 {
+		t.Errorf("change %v dB in %v to %v Hz, expected 10 in the 2 kHz octave", band.Change, band.Low, band.High)
+	}
+
+	// a coarser current spectrum compares the same way
+	if result := CompareSpectra(current, baseline); math.Abs(result.Bands[result.Alarms[0]].Change+10) > 0.01 {
+		t.Errorf("reversed: change %v dB, expected -10", result.Bands[result.Alarms[0]].Change)
+	}
+}
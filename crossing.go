@@ -0,0 +1,170 @@
+package dynamics
+
+import "fmt"
+
+// CrossingDirection selects the zero crossings ZeroCrossingTimes returns.
+type CrossingDirection int
+
+const (
+	CrossingRising  CrossingDirection = iota // from negative to positive
+	CrossingFalling                          // from positive to negative
+	CrossingBoth                             // either way
+)
+
+// ZeroCrossingTimes returns the times of the zero crossings of the data in
+// the given direction, each located between its bracketing samples by linear
+// interpolation, so their resolution is far finer than the sample interval.
+// Crossings are found as ZeroCrossingRate counts them; after a run of zeros
+// the crossing is placed at the last of them.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - direction: CrossingRising, CrossingFalling or CrossingBoth
+//
+// Returns:
+//   - []float64: The crossing times in order, none for an unknown direction
+func ZeroCrossingTimes(data []SingleChannelSample, direction CrossingDirection) []float64 {
+	if direction < CrossingRising || direction > CrossingBoth {
+		return nil
+	}
+	var times []float64
+	var detector crossingDetector
+	for i, sample := range data {
+		negative, positive := detector.step(sample.Value)
+		if !(negative && direction != CrossingRising) && !(positive && direction != CrossingFalling) {
+			continue
+		}
+		prev := data[i-1]
+		times = append(times, prev.Time+(sample.Time-prev.Time)*prev.Value/(prev.Value-sample.Value))
+	}
+	return times
+}
+
+// EstimateFrequencyFromCrossings estimates the frequency of the data from the
+// interpolated times of its zero crossings, as ZeroCrossingTimes gives them.
+// A straight line is fitted by least squares to the times of the rising
+// crossings against their count, and another with the same slope to the
+// falling crossings, and the slope is the period. Every crossing contributes,
+// so the estimate is far finer than the crossing count over the record that
+// NegativeZeroCrossingRate gives, and a DC offset, which shifts the rising and
+// falling crossings apart, does not bias it. Noise that adds crossings spoils
+// the fit; EstimateFrequencyACF suits noisy signals.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The estimated frequency in Hz, or 0 on error
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span
+//     no time, ErrUnsortedData if the data is not in time order, or an error
+//     wrapping ErrTooShort if neither direction has two crossings
+func EstimateFrequencyFromCrossings(data []SingleChannelSample) (float64, error) {
+	if _, err := recordDuration(data); err != nil {
+		return 0, err
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, err
+	}
+
+	var sxy, sxx float64
+	for _, direction := range []CrossingDirection{CrossingRising, CrossingFalling} {
+		times := ZeroCrossingTimes(data, direction)
+		if len(times) < 2 {
+			continue
+		}
+		meanK := float64(len(times)-1) / 2
+		var meanT float64
+		for _, t := range times {
+			meanT += t
+		}
+		meanT /= float64(len(times))
+		for k, t := range times {
+			dk := float64(k) - meanK
+			sxy += dk * (t - meanT)
+			sxx += dk * dk
+		}
+	}
+	if sxx == 0 {
+		return 0, fmt.Errorf("%w: fewer than two crossings either way", ErrTooShort)
+	}
+	return sxx / sxy, nil
+}
+
+// crossingDetector finds zero crossings in a sequence of values.
+//
+// A crossing is counted once per change of sign. A value of exactly zero takes
+// the sign of the last nonzero value before it, so +1, 0, -1 and +1, 0, 0, -1
+// each hold one negative-going crossing, found at the -1, while +1, 0, +1
+// holds none. Zeros before the first nonzero value have no sign, so the first
+// nonzero value never completes a crossing. NaN values are treated like zeros.
+type crossingDetector struct {
+	sign int // sign of the last nonzero value, 0 until one has been seen
+}
+
+// step feeds the next value and reports whether it completes a negative-going
+// or a positive-going crossing.
+func (d *crossingDetector) step(value float64) (negative, positive bool) {
+	var sign int
+	switch {
+	case value > 0:
+		sign = 1
+	case value < 0:
+		sign = -1
+	default:
+		return false, false
+	}
+
+	negative = d.sign > 0 && sign < 0
+	positive = d.sign < 0 && sign > 0
+	d.sign = sign
+	return negative, positive
+}
+
+// count feeds a run of samples, as step would one at a time, and returns the
+// number of negative-going and positive-going crossings among them.
+//
+// The sign is kept in a local for the length of the loop. Unrolling the loop
+// or making it branch-free was measured to be slower: every sample depends on
+// the sign left by the one before, and the branches are well predicted
+// because real signals keep their sign for many samples at a time.
+func (d *crossingDetector) count(data []SingleChannelSample) (negative, positive int) {
+	sign := d.sign
+	for _, sample := range data {
+		if sample.Value > 0 {
+			if sign < 0 {
+				positive++
+			}
+			sign = 1
+		} else if sample.Value < 0 {
+			if sign > 0 {
+				negative++
+			}
+			sign = -1
+		}
+	}
+	d.sign = sign
+	return negative, positive
+}
+
+// countHysteresis is count with hysteresis: a value takes a sign only when it
+// lies beyond threshold, which must not be negative, on that side of zero, and
+// values between −threshold and +threshold are treated like zeros. A threshold
+// of 0 counts exactly as count does.
+func (d *crossingDetector) countHysteresis(data []SingleChannelSample, threshold float64) (negative, positive int) {
+	sign := d.sign
+	for _, sample := range data {
+		if sample.Value > threshold {
+			if sign < 0 {
+				positive++
+			}
+			sign = 1
+		} else if sample.Value < -threshold {
+			if sign > 0 {
+				negative++
+			}
+			sign = -1
+		}
+	}
+	d.sign = sign
+	return negative, positive
+}
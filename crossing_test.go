@@ -0,0 +1,156 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCrossingZeroPatterns(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		negative int
+		positive int
+	}{
+		{"no zeros", []float64{1, -1, 1, -1}, 2, 1},
+		{"single zero between signs", []float64{1, 0, -1}, 1, 0},
+		{"zero run between signs", []float64{1, 0, 0, 0, -1}, 1, 0},
+		{"single zero rising", []float64{-1, 0, 1}, 0, 1},
+		{"zero run rising", []float64{-1, 0, 0, 1}, 0, 1},
+		{"zero touch from above", []float64{1, 0, 1}, 0, 0},
+		{"zero touch from below", []float64{-1, 0, -1}, 0, 0},
+		{"zero run touch", []float64{1, 0, 0, 0, 1}, 0, 0},
+		{"leading zero then negative", []float64{0, -1, 1}, 0, 1},
+		{"leading zero then positive", []float64{0, 1, -1}, 1, 0},
+		{"leading zero run", []float64{0, 0, 0, -1}, 0, 0},
+		{"trailing zeros", []float64{1, -1, 0, 0}, 1, 0},
+		{"all zeros", []float64{0, 0, 0, 0}, 0, 0},
+		{"zero after every sample", []float64{1, 0, -1, 0, 1, 0, -1, 0}, 2, 1},
+	}
+
+	for _, c := range cases {
+		// Generate sample data, one sample per second
+		data := make([]SingleChannelSample, len(c.values))
+		for i, value := range c.values {
+			data[i] = SingleChannelSample{Time: float64(i), Value: value}
+		}
+		duration := float64(len(data) - 1)
+
+		// Run the test
+		if result, expected := ZeroCrossingRate(data), float64(c.negative+c.positive)/duration; result != expected {
+			t.Errorf("ZeroCrossingRate on %s returned %f, expected %f", c.name, result, expected)
+		}
+		if result, expected := NegativeZeroCrossingRate(data), float64(c.negative)/duration; result != expected {
+			t.Errorf("NegativeZeroCrossingRate on %s returned %f, expected %f", c.name, result, expected)
+		}
+		if result, expected := PositiveZeroCrossingRate(data), float64(c.positive)/duration; result != expected {
+			t.Errorf("PositiveZeroCrossingRate on %s returned %f, expected %f", c.name, result, expected)
+		}
+		// each crossing is counted once, in one direction
+		if sum, total := NegativeZeroCrossingRate(data)+PositiveZeroCrossingRate(data), ZeroCrossingRate(data); sum != total {
+			t.Errorf("on %s the negative and positive rates sum to %f, expected ZeroCrossingRate %f", c.name, sum, total)
+		}
+
+		cb := NewCircularBuffer(len(data))
+		for _, sample := range data {
+			cb.Update(sample)
+		}
+		if result, expected := cb.GetBufferNZCR(), float64(c.negative)/duration; result != expected {
+			t.Errorf("GetBufferNZCR on %s returned %f, expected %f", c.name, result, expected)
+		}
+
+		w := newSlidingWindow(duration+1, 1)
+		for _, sample := range data {
+			w.push(sample.Time, []float64{sample.Value})
+		}
+		if result := w.crossings[0]; result != c.negative {
+			t.Errorf("slidingWindow on %s counted %d crossings, expected %d", c.name, result, c.negative)
+		}
+	}
+}
+
+func TestSlidingWindowZeroEviction(t *testing.T) {
+	// Generate sample data: the sign before the zeros is evicted first
+	values := []float64{1, 0, 0, -1, 0, 1, 0, -1, -1, 0, 0, 0, 1, 0, -1}
+	length := 4.0
+
+	// Run the test: after every push the running count must match a recount of the window
+	w := newSlidingWindow(length, 1)
+	for i, value := range values {
+		w.push(float64(i), []float64{value})
+
+		expected := 0
+		var detector crossingDetector
+		for _, sample := range w.samples(0) {
+			if negative, _ := detector.step(sample.Value); negative {
+				expected++
+			}
+		}
+		if result := w.crossings[0]; result != expected {
+			t.Errorf("slidingWindow after sample %d counted %d crossings, expected %d", i, result, expected)
+		}
+	}
+}
+
+func TestZeroCrossingTimes(t *testing.T) {
+	// Generate sample data: crossings a quarter and three quarters of the way
+	// between samples, and one through a run of zeros
+	data := []SingleChannelSample{{0, 3}, {1, -1}, {2, -3}, {3, 1}, {4, 0}, {5, 0}, {6, -2}}
+
+	// Run the test
+	cases := []struct {
+		direction CrossingDirection
+		expected  []float64
+	}{
+		{CrossingRising, []float64{2.75}},
+		{CrossingFalling, []float64{0.75, 5}},
+		{CrossingBoth, []float64{0.75, 2.75, 5}},
+		{CrossingDirection(5), nil},
+	}
+	for _, c := range cases {
+		times := ZeroCrossingTimes(data, c.direction)
+		if len(times) != len(c.expected) {
+			t.Errorf("direction %d: ZeroCrossingTimes returned %v, expected %v", c.direction, times, c.expected)
+			continue
+		}
+		for i := range times {
+			if math.Abs(times[i]-c.expected[i]) > 1e-12 {
+				t.Errorf("direction %d: ZeroCrossingTimes returned %v, expected %v", c.direction, times, c.expected)
+				break
+			}
+		}
+	}
+
+	// the counts agree with the crossing rates
+	sine := GenerateSineWave(50.3, 1, 1, 500, WithPhase(0.4))
+	if n := len(ZeroCrossingTimes(sine, CrossingFalling)); float64(n) != NegativeZeroCrossingRate(sine)*(sine[len(sine)-1].Time-sine[0].Time) {
+		t.Errorf("ZeroCrossingTimes found %d falling crossings, expected the count of NegativeZeroCrossingRate", n)
+	}
+}
+
+func TestEstimateFrequencyFromCrossings(t *testing.T) {
+	// Generate sample data: 50.3 Hz at only 500 Hz, about ten samples a
+	// cycle, on a DC offset that moves the rising and falling crossings apart
+	data := GenerateSineWave(50.3, 1, 2, 500, WithPhase(0.4), WithDCOffset(0.3))
+
+	// Run the test: the crossing count is out by a fraction of a cycle over
+	// the record, the fit by far less
+	frequency, err := EstimateFrequencyFromCrossings(data)
+	if err != nil {
+		t.Fatalf("EstimateFrequencyFromCrossings returned error: %v", err)
+	}
+	if math.Abs(frequency-50.3) > 0.05 {
+		t.Errorf("EstimateFrequencyFromCrossings returned %v Hz, expected within 0.05 Hz of 50.3 Hz", frequency)
+	}
+	if nzcr := NegativeZeroCrossingRate(data); math.Abs(nzcr-50.3) < 0.05 {
+		t.Errorf("NegativeZeroCrossingRate returned %v Hz, expected it to be coarser than 0.05 Hz", nzcr)
+	}
+
+	if _, err := EstimateFrequencyFromCrossings(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("EstimateFrequencyFromCrossings of no data returned %v, expected ErrEmptyData", err)
+	}
+	if _, err := EstimateFrequencyFromCrossings(data[:8]); !errors.Is(err, ErrTooShort) {
+		t.Errorf("EstimateFrequencyFromCrossings of one cycle returned %v, expected ErrTooShort", err)
+	}
+}
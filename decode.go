@@ -0,0 +1,143 @@
+package dynamics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// UnmarshalJSON decodes a sample strictly: both "time" and "value" must be
+// present and not null, and a multi-channel value must be an array whose
+// elements are not null. Each number may also be given as a string holding a
+// number in the form strconv.ParseFloat accepts, as some producers quote them.
+// Errors wrap ErrMalformedSample.
+func (s *Sample[T]) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Time  json.RawMessage `json:"time"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedSample, err)
+	}
+
+	t, err := parseJSONNumber("time", raw.Time)
+	if err != nil {
+		return err
+	}
+
+	var value T
+	switch v := any(&value).(type) {
+	case *float64:
+		if *v, err = parseJSONNumber("value", raw.Value); err != nil {
+			return err
+		}
+	case *[]float64:
+		if *v, err = parseJSONNumbers("value", raw.Value); err != nil {
+			return err
+		}
+	}
+
+	s.Time = t
+	s.Value = value
+	return nil
+}
+
+// DecodeSamples reads a JSON array of samples from r, decoding each record as
+// Sample.UnmarshalJSON does. An error names the index of the offending record.
+//
+// Parameters:
+//   - r: The reader holding the JSON array
+//
+// Returns:
+//   - []Sample[T]: The decoded samples
+//   - error: An error if the input is not a JSON array or a record is malformed
+func DecodeSamples[T float64 | []float64](r io.Reader) ([]Sample[T], error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("%w: input is not a JSON array", ErrMalformedSample)
+	}
+
+	var samples []Sample[T]
+	for i := 0; decoder.More(); i++ {
+		var sample Sample[T]
+		if err := decoder.Decode(&sample); err != nil {
+			if !errors.Is(err, ErrMalformedSample) {
+				err = fmt.Errorf("%w: %v", ErrMalformedSample, err)
+			}
+			return nil, fmt.Errorf("%w in record %d", err, i)
+		}
+		samples = append(samples, sample)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%w: unterminated JSON array: %v", ErrMalformedSample, err)
+	}
+	return samples, nil
+}
+
+// parseJSONNumber decodes a required number, which may be quoted.
+//
+// Parameters:
+//   - field: The name of the field, for error messages
+//   - raw: The raw JSON of the field, nil if it was missing
+//
+// Returns:
+//   - float64: The number
+//   - error: An error wrapping ErrMalformedSample if the field is missing, null or not a number
+func parseJSONNumber(field string, raw json.RawMessage) (float64, error) {
+	if raw == nil {
+		return 0, fmt.Errorf("%w: missing %s", ErrMalformedSample, field)
+	}
+	if string(raw) == "null" {
+		return 0, fmt.Errorf("%w: %s is null", ErrMalformedSample, field)
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s %q is not a number", ErrMalformedSample, field, text)
+		}
+		return number, nil
+	}
+
+	var number float64
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return 0, fmt.Errorf("%w: %s %s is not a number", ErrMalformedSample, field, raw)
+	}
+	return number, nil
+}
+
+// parseJSONNumbers decodes a required array of numbers, each of which may be quoted.
+//
+// Parameters:
+//   - field: The name of the field, for error messages
+//   - raw: The raw JSON of the field, nil if it was missing
+//
+// Returns:
+//   - []float64: The numbers
+//   - error: An error wrapping ErrMalformedSample if the field is missing, null or
+//     not an array, or an element is null or not a number
+func parseJSONNumbers(field string, raw json.RawMessage) ([]float64, error) {
+	if raw == nil {
+		return nil, fmt.Errorf("%w: missing %s", ErrMalformedSample, field)
+	}
+	if string(raw) == "null" {
+		return nil, fmt.Errorf("%w: %s is null", ErrMalformedSample, field)
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, fmt.Errorf("%w: %s %s is not an array", ErrMalformedSample, field, raw)
+	}
+	numbers := make([]float64, len(elements))
+	for i, element := range elements {
+		number, err := parseJSONNumber(fmt.Sprintf("%s[%d]", field, i), element)
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = number
+	}
+	return numbers, nil
+}
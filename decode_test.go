@@ -0,0 +1,100 @@
+package dynamics
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSampleUnmarshalJSON(t *testing.T) {
+	// Run the test: well-formed documents, including quoted numbers
+	var single SingleChannelSample
+	if err := json.Unmarshal([]byte(`{"time": 1.5, "value": "-2.25"}`), &single); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if single.Time != 1.5 || single.Value != -2.25 {
+		t.Errorf("Unmarshal returned %+v, expected time 1.5 and value -2.25", single)
+	}
+
+	var multi MultiChannelSample
+	if err := json.Unmarshal([]byte(`{"time": "2", "value": [1, "2", 3e-1]}`), &multi); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if multi.Time != 2 || len(multi.Value) != 3 || multi.Value[1] != 2 || multi.Value[2] != 0.3 {
+		t.Errorf("Unmarshal returned %+v, expected time 2 and values [1 2 0.3]", multi)
+	}
+
+	// samples still round-trip through the default encoding
+	encoded, _ := json.Marshal(multi)
+	var decoded MultiChannelSample
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.Time != multi.Time || len(decoded.Value) != 3 {
+		t.Errorf("round trip of %s returned %+v, %v", encoded, decoded, err)
+	}
+}
+
+func TestDecodeSamplesMalformed(t *testing.T) {
+	singleCases := []struct {
+		document string
+		message  string
+	}{
+		{`[{"time": 1.0}]`, "dynamics: malformed sample: missing value in record 0"},
+		{`[{"value": 1.0}]`, "dynamics: malformed sample: missing time in record 0"},
+		{`[{"time": 0, "value": 1}, {"time": 1.0, "value": null}]`, "dynamics: malformed sample: value is null in record 1"},
+		{`[{"time": null, "value": 1}]`, "dynamics: malformed sample: time is null in record 0"},
+		{`[{"time": 0, "value": "abc"}]`, `dynamics: malformed sample: value "abc" is not a number in record 0`},
+		{`[{"time": 0, "value": true}]`, "dynamics: malformed sample: value true is not a number in record 0"},
+		{`[{"time": 0, "value": 1}, 7]`, "in record 1"},
+		{`{"time": 0, "value": 1}`, "dynamics: malformed sample: input is not a JSON array"},
+		{`[{"time": 0, "value": 1}`, "unexpected end of JSON input"},
+	}
+	for _, c := range singleCases {
+		// Run the test
+		samples, err := DecodeSamples[float64](strings.NewReader(c.document))
+		if !errors.Is(err, ErrMalformedSample) || samples != nil {
+			t.Errorf("DecodeSamples on %s returned %v, %v; expected ErrMalformedSample", c.document, samples, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("DecodeSamples on %s returned %q, expected it to contain %q", c.document, err, c.message)
+		}
+	}
+
+	multiCases := []struct {
+		document string
+		message  string
+	}{
+		{`[{"time": 1.0}]`, "dynamics: malformed sample: missing value in record 0"},
+		{`[{"time": 1.0, "value": null}]`, "dynamics: malformed sample: value is null in record 0"},
+		{`[{"time": 0, "value": [1]}, {"time": 1, "value": [1, null]}]`, "dynamics: malformed sample: value[1] is null in record 1"},
+		{`[{"time": 0, "value": 1}]`, "dynamics: malformed sample: value 1 is not an array in record 0"},
+		{`[{"time": 0, "value": ["x"]}]`, `dynamics: malformed sample: value[0] "x" is not a number in record 0`},
+	}
+	for _, c := range multiCases {
+		// Run the test
+		samples, err := DecodeSamples[[]float64](strings.NewReader(c.document))
+		if !errors.Is(err, ErrMalformedSample) || samples != nil {
+			t.Errorf("DecodeSamples on %s returned %v, %v; expected ErrMalformedSample", c.document, samples, err)
+			continue
+		}
+		if err.Error() != c.message {
+			t.Errorf("DecodeSamples on %s returned %q, expected %q", c.document, err, c.message)
+		}
+	}
+}
+
+func TestDecodeSamples(t *testing.T) {
+	// Run the test
+	samples, err := DecodeSamples[[]float64](strings.NewReader(`[{"time": 0, "value": [1, 2]}, {"time": "0.001", "value": ["-1", -2]}]`))
+	if err != nil {
+		t.Fatalf("DecodeSamples returned error: %v", err)
+	}
+	if len(samples) != 2 || samples[1].Time != 0.001 || samples[1].Value[0] != -1 || samples[1].Value[1] != -2 {
+		t.Errorf("DecodeSamples returned %+v", samples)
+	}
+
+	// an empty array decodes to no samples
+	if samples, err := DecodeSamples[float64](strings.NewReader(`[]`)); err != nil || len(samples) != 0 {
+		t.Errorf("DecodeSamples on an empty array returned %v, %v", samples, err)
+	}
+}
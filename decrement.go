@@ -0,0 +1,122 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+)
+
+// minDecrementPeaks is the fewest peaks LogDecrement fits a decay to.
+const minDecrementPeaks = 4
+
+// decrementFloor is the fraction of the largest peak below which LogDecrement
+// takes peaks to be lost in noise.
+const decrementFloor = 0.01
+
+// LogDecrement estimates the natural frequency and damping ratio of a free
+// decay, such as the ring-down after an impact. Each positive lobe of the
+// signal gives a peak, located between samples by fitting a parabola through
+// the largest sample and its neighbours. The peaks from the largest on, down
+// to a hundredth of it, are taken as clean; a straight line fitted to the
+// logarithm of their amplitudes against their index gives the logarithmic
+// decrement δ, the fall over one cycle, and one fitted to their times gives
+// the damped period. Then ζ = δ/√(4π² + δ²) and fn = fd/√(1 − ζ²).
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - naturalFreq: The undamped natural frequency in Hz
+//   - dampingRatio: The damping ratio ζ
+//   - err: ErrEmptyData if data is empty, ErrUnsortedData if it is not in
+//     time order, ErrTooShort if there are fewer than four clean peaks, or
+//     ErrNoDecay if they do not decay
+func LogDecrement(data []SingleChannelSample) (naturalFreq, dampingRatio float64, err error) {
+	if len(data) == 0 {
+		return 0, 0, ErrEmptyData
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, 0, err
+	}
+
+	peaks := lobePeaks(data)
+	largest := 0
+	for i, peak := range peaks {
+		if peak.Value > peaks[largest].Value {
+			largest = i
+		}
+	}
+	if len(peaks) > 0 {
+		peaks = peaks[largest:]
+		for i, peak := range peaks {
+			if peak.Value < decrementFloor*peaks[0].Value {
+				peaks = peaks[:i]
+				break
+			}
+		}
+	}
+	if len(peaks) < minDecrementPeaks {
+		return 0, 0, fmt.Errorf("%w: %d clean peaks, need at least %d", ErrTooShort, len(peaks), minDecrementPeaks)
+	}
+
+	// least-squares slopes of log amplitude and time against peak index
+	n := float64(len(peaks))
+	centre := (n - 1) / 2
+	var logMean, timeMean, logSlope, timeSlope, spread float64
+	for _, peak := range peaks {
+		logMean += math.Log(peak.Value)
+		timeMean += peak.Time
+	}
+	logMean /= n
+	timeMean /= n
+	for i, peak := range peaks {
+		d := float64(i) - centre
+		logSlope += d * (math.Log(peak.Value) - logMean)
+		timeSlope += d * (peak.Time - timeMean)
+		spread += d * d
+	}
+	delta := -logSlope / spread
+	period := timeSlope / spread
+	if !(delta > 0) || !(period > 0) {
+		return 0, 0, ErrNoDecay
+	}
+
+	dampingRatio = delta / math.Sqrt(4*math.Pi*math.Pi+delta*delta)
+	naturalFreq = 1 / period / math.Sqrt(1-dampingRatio*dampingRatio)
+	return naturalFreq, dampingRatio, nil
+}
+
+// lobePeaks returns the peak of each positive lobe of the data, a run of
+// positive values that ends in a fall to zero or below, interpolated by a
+// parabola through the largest sample and its neighbours.
+func lobePeaks(data []SingleChannelSample) []SingleChannelSample {
+	var peaks []SingleChannelSample
+	top := -1 // index of the largest sample in the current lobe
+	for i, sample := range data {
+		if sample.Value > 0 {
+			if top < 0 || sample.Value > data[top].Value {
+				top = i
+			}
+			continue
+		}
+		if top > 0 {
+			peaks = append(peaks, parabolicPeak(data[top-1], data[top], data[top+1]))
+		}
+		top = -1
+	}
+	return peaks
+}
+
+// parabolicPeak returns the vertex of the parabola through three samples
+// around a maximum, or the middle sample if they are collinear or unevenly spaced.
+func parabolicPeak(a, b, c SingleChannelSample) SingleChannelSample {
+	step := b.Time - a.Time
+	curvature := a.Value - 2*b.Value + c.Value
+	if curvature >= 0 || math.Abs((c.Time-b.Time)-step) > 1e-6*step {
+		return b
+	}
+	offset := (a.Value - c.Value) / (2 * curvature) // in steps
+	return SingleChannelSample{
+		Time:  b.Time + offset*step,
+		Value: b.Value - (a.Value-c.Value)*offset/4,
+	}
+}
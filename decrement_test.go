@@ -0,0 +1,55 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// dampedSine returns a free decay of natural frequency fn and damping ratio
+// zeta, sampled at rate for the given duration.
+func dampedSine(fn, zeta, duration float64, rate int) []SingleChannelSample {
+	wn := 2 * math.Pi * fn
+	wd := wn * math.Sqrt(1-zeta*zeta)
+	data := make([]SingleChannelSample, int(duration*float64(rate)))
+	for i := range data {
+		tm := float64(i) / float64(rate)
+		data[i] = SingleChannelSample{Time: tm, Value: 3 * math.Exp(-zeta*wn*tm) * math.Sin(wd*tm)}
+	}
+	return data
+}
+
+func TestLogDecrement(t *testing.T) {
+	// Generate sample data: 1 s of a 50 Hz mode with ζ = 0.02 at 5 kHz
+	data := dampedSine(50, 0.02, 1, 5000)
+
+	// Run the test
+	fn, zeta, err := LogDecrement(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(zeta-0.02) > 0.05*0.02 {
+		t.Errorf("damping ratio %v, expected 0.02", zeta)
+	}
+	if math.Abs(fn-50) > 0.05*50 {
+		t.Errorf("natural frequency %v Hz, expected 50", fn)
+	}
+}
+
+func TestLogDecrementTooFewPeaks(t *testing.T) {
+	// a heavily damped decay falls below a hundredth within three peaks
+	if _, _, err := LogDecrement(dampedSine(50, 0.3, 1, 5000)); !errors.Is(err, ErrTooShort) {
+		t.Errorf("heavy damping: got %v", err)
+	}
+	// a growing oscillation has its largest peak last
+	if _, _, err := LogDecrement(dampedSine(50, -0.01, 1, 5000)); !errors.Is(err, ErrTooShort) {
+		t.Errorf("growth: got %v", err)
+	}
+	// a steady oscillation has peaks to fit, but they do not fall
+	if _, _, err := LogDecrement(dampedSine(50, 0, 1, 5000)); !errors.Is(err, ErrNoDecay) {
+		t.Errorf("no damping: got %v", err)
+	}
+	if _, _, err := LogDecrement(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got %v", err)
+	}
+}
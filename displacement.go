@@ -0,0 +1,92 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// displacementPeriods is the fewest periods of the high-pass cutoff that
+// AccelerationToDisplacement accepts; the filters take about this long to
+// settle.
+const displacementPeriods = 3
+
+// AccelerationToDisplacement integrates an acceleration record twice to
+// displacement. Invalid input yields nil; see AccelerationToDisplacementE.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the acceleration
+//   - highpassCutoff: The cutoff in Hz of the high-pass filters that control drift
+//
+// Returns:
+//   - []SingleChannelSample: The displacement, in the units of the acceleration times s²
+func AccelerationToDisplacement(data []SingleChannelSample, highpassCutoff float64) []SingleChannelSample {
+	displacement, err := AccelerationToDisplacementE(data, highpassCutoff)
+	if err != nil {
+		return nil
+	}
+	return displacement
+}
+
+// AccelerationToDisplacementE integrates an acceleration record twice to
+// displacement, reporting invalid input. Integration turns any offset in the
+// acceleration into a ramp in velocity and a parabola in displacement, and
+// amplifies low-frequency noise, so the drift is controlled at each stage:
+// the linear trend is removed, a second-order Butterworth high-pass filter is
+// applied, the result is integrated to velocity, filtered again and integrated
+// to displacement, and the mean of the displacement, which depends only on
+// where integration started, is removed.
+//
+// Content below the cutoff is lost and content near it is attenuated, so the
+// cutoff should sit well below the frequencies of interest; a tenth of the
+// lowest of them leaves amplitudes within 0.01%. The filters settle over the
+// first few periods of the cutoff, so the record must hold at least three of
+// them. The samples are taken to be evenly spaced.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the acceleration
+//   - highpassCutoff: The cutoff in Hz of the high-pass filters that control drift
+//
+// Returns:
+//   - []SingleChannelSample: The displacement, in the units of the acceleration times s²
+//   - error: ErrEmptyData if there is no data, ErrUnsortedData if it is not in
+//     time order, ErrTooShort if it spans less than three periods of the cutoff,
+//     or an error if the cutoff is not positive and below the Nyquist frequency
+func AccelerationToDisplacementE(data []SingleChannelSample, highpassCutoff float64) ([]SingleChannelSample, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+	if !(highpassCutoff > 0) || math.IsInf(highpassCutoff, 1) {
+		return nil, errors.New("dynamics: high-pass cutoff must be positive")
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return nil, err
+	}
+	duration := data[len(data)-1].Time - data[0].Time
+	if duration < displacementPeriods/highpassCutoff {
+		return nil, fmt.Errorf("%w: %g s of data is less than %d periods of the %g Hz cutoff", ErrTooShort, duration, displacementPeriods, highpassCutoff)
+	}
+	step := duration / float64(len(data)-1)
+	if highpassCutoff*step >= 0.5 {
+		return nil, fmt.Errorf("dynamics: high-pass cutoff %g Hz is not below the Nyquist frequency", highpassCutoff)
+	}
+
+	values := sampleValues(data)
+	highPass := highPassBiquad(highpassCutoff*step, 1/math.Sqrt2)
+	detrend(values)
+	highPass.filter(values)
+	integrate(values, step)
+	highPass.filter(values)
+	integrate(values, step)
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	displacement := make([]SingleChannelSample, len(data))
+	for i, sample := range data {
+		displacement[i] = SingleChannelSample{Time: sample.Time, Value: values[i] - mean}
+	}
+	return displacement, nil
+}
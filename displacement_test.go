@@ -0,0 +1,53 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAccelerationToDisplacement(t *testing.T) {
+	// Generate sample data: 10 s at 1 kHz of a 10 Hz acceleration of
+	// amplitude 5 with an offset, as an accelerometer's bias gives
+	data := GenerateSineWave(10, 5, 10, 1000)
+	for i := range data {
+		data[i].Value += 0.2
+	}
+	expected := 5 / math.Pow(2*math.Pi*10, 2)
+
+	// Run the test: over the last half, once the filters have settled
+	displacement := AccelerationToDisplacement(data, 1)
+	if len(displacement) != len(data) {
+		t.Fatalf("got %d samples, expected %d", len(displacement), len(data))
+	}
+	var low, high float64
+	for _, sample := range displacement[len(displacement)/2:] {
+		low = math.Min(low, sample.Value)
+		high = math.Max(high, sample.Value)
+	}
+	if amplitude := (high - low) / 2; math.Abs(amplitude-expected) > 0.02*expected {
+		t.Errorf("displacement amplitude %v, expected %v", amplitude, expected)
+	}
+	if centre := (high + low) / 2; math.Abs(centre) > 0.02*expected {
+		t.Errorf("displacement centred on %v, expected 0", centre)
+	}
+}
+
+func TestAccelerationToDisplacementE(t *testing.T) {
+	data := GenerateSineWave(10, 1, 1, 1000)
+	if _, err := AccelerationToDisplacementE(nil, 1); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got %v", err)
+	}
+	if _, err := AccelerationToDisplacementE(data, 0); err == nil {
+		t.Error("zero cutoff: expected an error")
+	}
+	if _, err := AccelerationToDisplacementE(data, 1); !errors.Is(err, ErrTooShort) {
+		t.Errorf("one period of the cutoff: got %v", err)
+	}
+	if _, err := AccelerationToDisplacementE(data, 600); err == nil {
+		t.Error("cutoff above Nyquist: expected an error")
+	}
+	if AccelerationToDisplacement(data, 1) != nil {
+		t.Error("lenient form: expected nil on error")
+	}
+}
@@ -1,8 +1,13 @@
 package dynamics
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
+	"math/rand"
+	"slices"
+	"sync"
 )
 
 // Sample represents a single sample of data with a time and a generic value.
@@ -15,8 +20,31 @@ type Sample[T float64 | []float64] struct {
 type SingleChannelSample = Sample[float64]
 type MultiChannelSample = Sample[[]float64]
 
+// AnalysisResult holds the outcome of analysing a window of samples.
+type AnalysisResult struct {
+	Time    float64 `json:"time"`    // time of the newest sample analysed
+	RMS     float64 `json:"rms"`     // root mean square of the window
+	Peak    float64 `json:"peak"`    // largest absolute value in the window
+	NZCR    float64 `json:"nzcr"`    // negative zero crossing rate of the window
+	Samples int     `json:"samples"` // number of samples analysed
+	Partial bool    `json:"partial"` // the result was flushed by Close before it was due
+	RMSSpan float64 `json:"rmsSpan"` // seconds of data the RMS was taken over
+	// CrestFactor is Peak/RMS, 0 when the RMS is 0. The peak is taken over
+	// every sample analysed and the RMS over RMSSpan.
+	CrestFactor float64 `json:"crestFactor"`
+	// CycleAligned reports that the RMS covers a whole number of cycles, which
+	// only the batch analyses do; it is false when the data held less than one cycle.
+	CycleAligned bool `json:"cycleAligned"`
+	// Unit is the unit of RMS and Peak, set by the analyses that take a
+	// Signal and empty otherwise. NZCR is always in Hz.
+	Unit string `json:"unit,omitempty"`
+}
+
 // CircularBuffer represents a circular buffer for storing SingleChannelSample data.
+// It is safe for concurrent use: Update may be called from a producer goroutine
+// while other goroutines read or analyse the buffer.
 type CircularBuffer struct {
+	mu    sync.RWMutex
 	data  []SingleChannelSample
 	size  int
 	head  int
@@ -35,6 +63,9 @@ func NewCircularBuffer(size int) *CircularBuffer {
 
 // Update adds a new sample to the circular buffer.
 func (cb *CircularBuffer) Update(sample SingleChannelSample) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
 	cb.data[cb.head] = sample
 	cb.head = (cb.head + 1) % cb.size
 	if cb.count < cb.size {
@@ -44,6 +75,9 @@ func (cb *CircularBuffer) Update(sample SingleChannelSample) {
 
 // GetData returns a slice of the data in the buffer, from oldest to newest.
 func (cb *CircularBuffer) GetData() []SingleChannelSample {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
 	result := make([]SingleChannelSample, cb.count)
 	for i := 0; i < cb.count; i++ {
 		index := (cb.head - cb.count + i + cb.size) % cb.size
@@ -52,273 +86,2951 @@ func (cb *CircularBuffer) GetData() []SingleChannelSample {
 	return result
 }
 
+// AppendData appends the data in the buffer, from oldest to newest, to dst and
+// returns the extended slice. Passing a slice with enough capacity, such as
+// the result of a previous call truncated to zero length, avoids the
+// allocation GetData makes.
+func (cb *CircularBuffer) AppendData(dst []SingleChannelSample) []SingleChannelSample {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	for i := 0; i < cb.count; i++ {
+		index := (cb.head - cb.count + i + cb.size) % cb.size
+		dst = append(dst, cb.data[index])
+	}
+	return dst
+}
+
 // AnalyzeBuffer calculates the RMS and NZCR of the data stored in the circular buffer.
 func (cb *CircularBuffer) AnalyzeBuffer() (rms float64, zcr float64) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
 	if cb.count == 0 {
 		return 0, 0
 	}
-	zcr = cb.GetBufferNZCR()
-	rms = cb.GetBufferRMS()
+	zcr = cb.nzcr()
+	rms = cb.rms()
 	return
 }
 
-// GetBufferRMS returns the RMS of the data stored in the circular buffer.
-func (cb *CircularBuffer) GetBufferRMS() float64 {
+// Analysis returns the RMS, peak and NZCR of the data stored in the circular
+// buffer. All values are computed under a single read lock, so they describe
+// the same set of samples even while a producer is calling Update.
+func (cb *CircularBuffer) Analysis() AnalysisResult {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
 	if cb.count == 0 {
-		return 0
+		return AnalysisResult{}
 	}
 
-	sum := 0.0
+	peak := 0.0
 	for i := 0; i < cb.count; i++ {
 		index := (cb.head - cb.count + i + cb.size) % cb.size
-		value := cb.data[index].Value
-		sum += value * value
+		peak = math.Max(peak, math.Abs(cb.data[index].Value))
 	}
-	mean := sum / float64(cb.count)
-	return math.Sqrt(mean)
+
+	first, last := cb.data[(cb.head-cb.count+cb.size)%cb.size].Time, cb.data[(cb.head-1+cb.size)%cb.size].Time
+	rms := cb.rms()
+	return AnalysisResult{
+		Time:        last,
+		RMS:         rms,
+		Peak:        peak,
+		NZCR:        cb.nzcr(),
+		Samples:     cb.count,
+		RMSSpan:     last - first,
+		CrestFactor: crestFactorOf(peak, rms),
+	}
+}
+
+// GetBufferRMS returns the RMS of the data stored in the circular buffer.
+func (cb *CircularBuffer) GetBufferRMS() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.rms()
 }
 
 // GetBufferNZCR returns the NZCR of the data stored in the circular buffer.
 func (cb *CircularBuffer) GetBufferNZCR() float64 {
-	if cb.count < 2 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.nzcr()
+}
+
+// GetBufferPeakToPeak returns the peak-to-peak value of the data stored in
+// the circular buffer, as PeakToPeak gives it, reading the buffer in place.
+func (cb *CircularBuffer) GetBufferPeakToPeak() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if cb.count == 0 {
 		return 0
 	}
+	older, newer := cb.segments()
+	low, high := valueRange(older, older[0].Value, older[0].Value)
+	low, high = valueRange(newer, low, high)
+	return high - low
+}
 
-	crossings := 0
-	prevIndex := (cb.head - cb.count + cb.size) % cb.size
-	for i := 1; i < cb.count; i++ {
-		currIndex := (prevIndex + 1) % cb.size
-		if cb.data[prevIndex].Value >= 0 && cb.data[currIndex].Value < 0 {
-			crossings++
-		}
-		prevIndex = currIndex
+// GetBufferVariance returns the sample variance of the data stored in the
+// circular buffer, as Variance gives it, reading the buffer in place.
+func (cb *CircularBuffer) GetBufferVariance() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.variance()
+}
+
+// GetBufferStdDev returns the sample standard deviation of the data stored in
+// the circular buffer, as StdDev gives it, reading the buffer in place.
+func (cb *CircularBuffer) GetBufferStdDev() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return math.Sqrt(cb.variance())
+}
+
+// variance returns the sample variance of the buffered data. The caller must hold cb.mu.
+func (cb *CircularBuffer) variance() float64 {
+	var w welford
+	older, newer := cb.segments()
+	w.add(older)
+	w.add(newer)
+	return w.variance()
+}
+
+// GetBufferSkewness returns the skewness of the data stored in the circular
+// buffer, as Skewness gives it, reading the buffer in place.
+func (cb *CircularBuffer) GetBufferSkewness() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	older, newer := cb.segments()
+	var m moments
+	m.add(older)
+	m.add(newer)
+	return m.skewness()
+}
+
+// GetBufferKurtosis returns the excess kurtosis of the data stored in the
+// circular buffer, as Kurtosis gives it, reading the buffer in place.
+func (cb *CircularBuffer) GetBufferKurtosis() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	older, newer := cb.segments()
+	var m moments
+	m.add(older)
+	m.add(newer)
+	return m.excessKurtosis()
+}
+
+// MinMax returns the samples holding the smallest and largest values stored
+// in the circular buffer, as MinMax gives them, reading the buffer in place.
+func (cb *CircularBuffer) MinMax() (min SingleChannelSample, max SingleChannelSample, err error) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if cb.count == 0 {
+		return SingleChannelSample{}, SingleChannelSample{}, ErrEmptyData
+	}
+	older, newer := cb.segments()
+	min, max = extremes(older, older[0], older[0])
+	min, max = extremes(newer, min, max)
+	return min, max, nil
+}
+
+// rms returns the RMS of the buffered data. The caller must hold cb.mu.
+func (cb *CircularBuffer) rms() float64 {
+	if cb.count == 0 {
+		return 0
+	}
+
+	older, newer := cb.segments()
+	if cb.count >= compensatedSumThreshold {
+		var sum compensatedSum
+		sum.addSquares(older)
+		sum.addSquares(newer)
+		return math.Sqrt(sum.value() / float64(cb.count))
+	}
+	return math.Sqrt((sumSquares(older) + sumSquares(newer)) / float64(cb.count))
+}
+
+// Segments returns the buffered data, oldest first, as the two contiguous
+// regions of the buffer's storage it occupies, so that code able to take two
+// slices can read the buffer without the copy GetData makes. newer is empty
+// unless the data wraps around the end of the storage, and both are empty
+// when the buffer is.
+//
+// The slices are views of the buffer, not copies: the next Update overwrites
+// the oldest sample in place, so they must not be used once Update may have
+// been called, and must never be written to.
+func (cb *CircularBuffer) Segments() (older, newer []SingleChannelSample) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.segments()
+}
+
+// segments returns the buffered data, oldest first, as the two contiguous
+// runs of the underlying array it occupies; newer is empty unless the data
+// wraps around the end of the array. The caller must hold cb.mu.
+func (cb *CircularBuffer) segments() (older, newer []SingleChannelSample) {
+	first := (cb.head - cb.count + cb.size) % cb.size
+	if first+cb.count <= cb.size {
+		return cb.data[first : first+cb.count], nil
+	}
+	return cb.data[first:], cb.data[:cb.head]
+}
+
+// nzcr returns the NZCR of the buffered data. The caller must hold cb.mu.
+func (cb *CircularBuffer) nzcr() float64 {
+	if cb.count < 2 {
+		return 0
 	}
 
+	var detector crossingDetector
+	older, newer := cb.segments()
+	crossings, _ := detector.count(older)
+	more, _ := detector.count(newer)
+	crossings += more
+
 	duration := cb.data[(cb.head-1+cb.size)%cb.size].Time - cb.data[(cb.head-cb.count+cb.size)%cb.size].Time
+	if !(duration > 0) {
+		return 0
+	}
 	return float64(crossings) / duration
 }
 
 // Analyze calculates the Root Mean Square (RMS) and Negative Zero Crossing Rate (NZCR) of the given data.
+// Data rejected by a NonFiniteStrict option, and data of fewer than two samples, yields zeros.
+// The RMS is taken over the last whole cycles at the measured frequency; data
+// holding less than one cycle has no frequency to align to, and its RMS is taken
+// over all the samples, as AnalyzeDetailed reports with CycleAligned.
 //
 // Parameters:
 //   - data: A slice of Sample structs containing time and value data
+//   - opts: Options such as WithNonFinite
 //
 // Returns:
 //   - rms: The calculated Root Mean Square value
 //   - zcr: The calculated Negative Zero Crossing Rate
-func Analyze(data []SingleChannelSample) (rms float64, zcr float64) {
-	zcr = NegativeZeroCrossingRate(data)
-	rms = RMS(data, zcr)
+func Analyze(data []SingleChannelSample, opts ...AnalyzeOption) (rms float64, zcr float64) {
+	config := newAnalyzeConfig(opts)
+	data, scratch, err := config.prepareScratch(data)
+	defer sampleScratch.put(scratch)
+	if err != nil {
+		return 0, 0
+	}
+	rms, zcr, _ = analyze(data, config)
 	return
 }
 
-// AnalyzeMultiChannel analyzes the given multi-channel data and returns the RMS and NZCR for each channel.
+// analyze calculates the RMS and NZCR of data that has been prepared for analysis.
 //
 // Parameters:
-//   - data: A slice of MultiChannelSample structs containing time and value data
+//   - data: A slice of Sample structs containing time and value data
+//   - config: The analysis configuration
 //
 // Returns:
-//   - rms: A slice of float64 values representing the RMS for each channel
-//   - zcr: A slice of float64 values representing the NZCR for each channel
-func AnalyzeMultiChannel(data []MultiChannelSample) (rms []float64, zcr []float64) {
-	// channel count is the length of the value array
-	channelCount := len(data[0].Value)
+//   - rms: The calculated Root Mean Square value
+//   - zcr: The calculated Negative Zero Crossing Rate
+//   - span: The seconds of data the RMS was taken over, +Inf if it covers all the data
+func analyze(data []SingleChannelSample, config analyzeConfig) (rms float64, zcr float64, span float64) {
+	zcr = config.frequency(data)
+	if len(data) < 2 {
+		return 0, 0, math.Inf(1)
+	}
+	// non-finite timestamps can make the rate non-finite, and it is no use as a frequency then
+	if zcr == 0 || math.IsNaN(zcr) || math.IsInf(zcr, 0) {
+		return config.rms(data), 0, math.Inf(1)
+	}
+	span = rmsSpan(data[len(data)-1].Time-data[0].Time, zcr, config.maxCycles)
+	rms = config.rms(KeepXSecondsOfData(data, span))
+	return
+}
 
-	zcr = make([]float64, channelCount)
-	rms = make([]float64, channelCount)
+// AnalyzeE calculates the Root Mean Square (RMS) and Negative Zero Crossing Rate (NZCR) of the given data,
+// reporting input for which they are undefined instead of returning zeros.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - opts: Options such as WithNonFinite
+//
+// Returns:
+//   - rms: The calculated Root Mean Square value, or 0 on error
+//   - zcr: The calculated Negative Zero Crossing Rate, or 0 on error
+//   - err: ErrEmptyData if data is empty, ErrZeroDuration if the samples span no time,
+//     ErrUnsortedData if the data is not in time order, or ErrNonFinite under NonFiniteStrict
+func AnalyzeE(data []SingleChannelSample, opts ...AnalyzeOption) (rms float64, zcr float64, err error) {
+	result, err := AnalyzeDetailed(data, opts...)
+	return result.RMS, result.NZCR, err
+}
+
+// AnalyzeDetailed analyses the given data as AnalyzeE does and returns the
+// values in an AnalysisResult, together with the peak, the number of samples
+// analysed, the span of data the RMS was taken over and whether that span is
+// a whole number of cycles.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - opts: Options such as WithNonFinite and WithMaxCycles
+//
+// Returns:
+//   - AnalysisResult: The analysis, zero on error
+//   - error: As for AnalyzeE
+func AnalyzeDetailed(data []SingleChannelSample, opts ...AnalyzeOption) (AnalysisResult, error) {
+	config := newAnalyzeConfig(opts)
+	data, scratch, err := config.prepareScratch(data)
+	defer sampleScratch.put(scratch)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	zcr, err := NegativeZeroCrossingRateE(data)
+	if err != nil {
+		return AnalysisResult{}, err
+	}
+	if config.acf {
+		zcr = config.frequency(data)
+	}
 
-	fmt.Printf("channelCount: %d\n", channelCount)
+	result := AnalysisResult{
+		Time:    data[len(data)-1].Time,
+		Samples: len(data),
+	}
+	for _, sample := range data {
+		result.Peak = math.Max(result.Peak, math.Abs(sample.Value))
+	}
 
-	for i := range channelCount {
-		singleChannelData := make([]SingleChannelSample, len(data))
-		for j := range data {
-			singleChannelData[j] = SingleChannelSample{Time: data[j].Time, Value: data[j].Value[i]}
-		}
-		zcr[i] = NegativeZeroCrossingRate(singleChannelData)
-		rms[i] = RMS(singleChannelData, zcr[i])
+	span := math.Inf(1)
+	if zcr > 0 && !math.IsInf(zcr, 1) {
+		result.NZCR = zcr
+		span = rmsSpan(data[len(data)-1].Time-data[0].Time, zcr, config.maxCycles)
 	}
+	kept := KeepXSecondsOfData(data, span)
+	result.RMS = config.rms(kept)
+	result.RMSSpan = math.Min(span, result.Time-data[0].Time)
+	result.CycleAligned = !math.IsInf(span, 1)
+	result.CrestFactor = crestFactorOf(result.Peak, result.RMS)
+	return result, nil
+}
 
-	fmt.Printf("Length of zcr: %d, length of rms: %d\n", len(zcr), len(rms))
+// AnalyzeMultiChannel analyzes the given multi-channel data and returns the RMS and NZCR for each channel.
+// Empty data, samples with no channels, or samples whose channel counts differ yield nil slices;
+// use AnalyzeMultiChannelE to tell these apart.
+//
+// Parameters:
+//   - data: A slice of MultiChannelSample structs containing time and value data
+//   - opts: Options such as WithNonFinite or WithRemoveDC, applied to each channel separately
+//
+// Returns:
+//   - rms: A slice of float64 values representing the RMS for each channel
+//   - zcr: A slice of float64 values representing the NZCR for each channel
+func AnalyzeMultiChannel(data []MultiChannelSample, opts ...AnalyzeOption) (rms []float64, zcr []float64) {
+	rms, zcr, _ = AnalyzeMultiChannelE(data, opts...)
 	return
 }
 
-// RMS calculates the Root Mean Square value of the given data.
+// AnalyzeMultiChannelE is like AnalyzeMultiChannel but reports unusable input as an error.
 //
 // Parameters:
-//   - data: A slice of Sample structs containing time and value data
-//   - frequency: The frequency of the signal
+//   - data: A slice of MultiChannelSample structs containing time and value data
+//   - opts: Options such as WithNonFinite or WithRemoveDC, applied to each channel separately
 //
 // Returns:
-//   - float64: The calculated Root Mean Square value
-func RMS(data []SingleChannelSample, frequency float64) float64 {
+//   - rms: A slice of float64 values representing the RMS for each channel
+//   - zcr: A slice of float64 values representing the NZCR for each channel
+//   - err: ErrEmptyData if data is empty, ErrNoChannels if the first sample has no values, an
+//     error wrapping ErrChannelMismatch that names the first sample whose channel count differs,
+//     or under NonFiniteStrict an error wrapping ErrNonFinite that names the sample and channel
+func AnalyzeMultiChannelE(data []MultiChannelSample, opts ...AnalyzeOption) (rms []float64, zcr []float64, err error) {
 	if len(data) == 0 {
-		return 0
+		return nil, nil, ErrEmptyData
 	}
-	if frequency == 0 {
-		return 0
+
+	// channel count is the length of the value array
+	channelCount := len(data[0].Value)
+	if channelCount == 0 {
+		return nil, nil, ErrNoChannels
 	}
 
-	period := 1 / frequency
+	// check every sample up front so a ragged sample is reported, not indexed out of range
+	for i, sample := range data {
+		if len(sample.Value) != channelCount {
+			return nil, nil, fmt.Errorf("%w: sample %d at time %g has %d channels, expected %d", ErrChannelMismatch, i, sample.Time, len(sample.Value), channelCount)
+		}
+	}
 
-	duration := data[len(data)-1].Time - data[0].Time
-	wholeCycles := math.Floor(duration / period)
+	if l := loggerFor(slog.LevelDebug); l != nil {
+		l.Debug("analyzing multi-channel data", "channels", channelCount, "samples", len(data))
+	}
+
+	return analyzeColumns(data, channelCount, newAnalyzeConfig(opts))
+}
+
+// column holds the running state of one channel in analyzeColumns.
+type column struct {
+	detector  crossingDetector
+	crossings int
+	samples   int
+	first     float64    // time of the first sample analysed
+	last      float64    // time of the last sample analysed
+	sumSq     [4]float64 // partial sums of squares, laid out as in sumSquares
+	sumSqComp compensatedSum
+	// compensated reports that squares go to sumSqComp rather than sumSq,
+	// from the start under WithCompensatedSum and otherwise from the sample
+	// that brings the count to compensatedSumThreshold
+	compensated bool
+	from        int            // row from which the current pass admits samples
+	mean        float64        // subtracted from every value, set under WithRemoveDC
+	sum         compensatedSum // sum of the values while the mean is taken
+	bad         int            // index of the first non-finite value, -1 if none
+	windowed    bool           // the RMS is taken over the samples from cutoff on
+	cutoff      float64        // time from which the windowed RMS starts
+	started     bool
+}
 
-	if wholeCycles < 1 {
-		return calculateRMS(data)
+// sumSquares returns the column's sum of squares.
+func (col *column) sumSquares() float64 {
+	if col.compensated {
+		return col.sumSqComp.value()
 	}
+	return combineLanes(col.sumSq)
+}
 
-	// get last 1000 whole cycles, or x whole cycles if less than 1000
-	cyclesToUse := math.Min(wholeCycles, 1000)
+// addSquare adds the square of a value of channel c at the given row. On
+// reaching compensatedSumThreshold samples the column switches to the
+// compensated sum, summing again the samples the pass has admitted so far,
+// so that the sum is the one analyzeConfig.rms takes over the same samples.
+func (col *column) addSquare(data []MultiChannelSample, c, row int, value float64, config analyzeConfig) {
+	if col.compensated {
+		col.sumSqComp.add(value * value)
+	} else {
+		col.sumSq[col.samples%4] += value * value
+	}
+	col.samples++
+	if col.samples == compensatedSumThreshold && !col.compensated {
+		col.compensated = true
+		for _, sample := range data[col.from : row+1] {
+			if v := sample.Value[c]; config.admits(v) {
+				col.sumSqComp.add((v - col.mean) * (v - col.mean))
+			}
+		}
+	}
+}
 
-	// get the data from the start time to the end
-	data = KeepXSecondsOfData(data, cyclesToUse*period)
+// columnMeans sets the mean of each column's admitted values for
+// WithRemoveDC, summed as Mean sums them: plainly below
+// compensatedSumThreshold values and with compensation from then on, the
+// values so far being summed again on reaching it.
+func columnMeans(data []MultiChannelSample, columns []column, config analyzeConfig) {
+	for i, sample := range data {
+		for c, value := range sample.Value {
+			if !config.admits(value) {
+				continue
+			}
+			col := &columns[c]
+			if col.samples >= compensatedSumThreshold {
+				col.sum.add(value)
+			} else {
+				col.sum.sum += value
+			}
+			col.samples++
+			if col.samples == compensatedSumThreshold {
+				col.sum = compensatedSum{}
+				for _, earlier := range data[:i+1] {
+					if v := earlier.Value[c]; config.admits(v) {
+						col.sum.add(v)
+					}
+				}
+			}
+		}
+	}
+	for c := range columns {
+		col := &columns[c]
+		if col.samples > 0 {
+			col.mean = col.sum.value() / float64(col.samples)
+		}
+		col.sum, col.samples = compensatedSum{}, 0
+	}
+}
 
-	// calculate RMS
-	return calculateRMS(data)
+// columnSamples appends the admitted values of channel c, less the mean, to
+// dst, for WithACFFrequency, which estimates the frequency of each channel
+// from a copy of its samples.
+func columnSamples(dst []SingleChannelSample, data []MultiChannelSample, c int, mean float64, config analyzeConfig) []SingleChannelSample {
+	for _, sample := range data {
+		if value := sample.Value[c]; config.admits(value) {
+			dst = append(dst, SingleChannelSample{Time: sample.Time, Value: value - mean})
+		}
+	}
+	return dst
 }
 
-// calculateRMS calculates the Root Mean Square value of the given data.
+// analyzeColumns analyses every channel of the data as analyze does for a
+// single channel, giving the same values bit for bit, but reads the samples
+// row by row into per-channel accumulators rather than copying each channel
+// out. Under WithRemoveDC a first pass takes the mean of each channel. The
+// next counts crossings and sums squares over the whole record, and the last
+// sums squares over the whole cycles at the end of each channel, which are
+// only known once its crossing rate is. Under WithACFFrequency the frequency
+// of each channel is estimated from a copy of its samples in between.
 //
 // Parameters:
-//   - data: A slice of Sample structs containing time and value data
+//   - data: A slice of MultiChannelSample structs, each with channelCount values
+//   - channelCount: The number of channels
+//   - config: The analysis configuration
 //
 // Returns:
-//   - float64: The calculated Root Mean Square value
-func calculateRMS(data []SingleChannelSample) float64 {
-	if len(data) == 0 {
-		return 0
+//   - rms: A slice of float64 values representing the RMS for each channel
+//   - zcr: A slice of float64 values representing the NZCR for each channel
+//   - err: Under NonFiniteStrict, an error wrapping ErrNonFinite for the lowest channel holding a non-finite value
+func analyzeColumns(data []MultiChannelSample, channelCount int, config analyzeConfig) (rms []float64, zcr []float64, err error) {
+	scratch := columnScratch.get(channelCount)
+	defer columnScratch.put(scratch)
+	columns := *scratch
+	for c := range columns {
+		columns[c].bad = -1
+		columns[c].compensated = config.compensated
+	}
+	if config.removeDC {
+		columnMeans(data, columns, config)
 	}
 
-	return calculateRMSAverage(data)
+	for i, sample := range data {
+		for c, value := range sample.Value {
+			col := &columns[c]
+			if !config.admits(value) {
+				if col.bad < 0 {
+					col.bad = i
+				}
+				continue
+			}
+			value -= col.mean
+			if negative, _ := col.detector.step(value); negative {
+				col.crossings++
+			}
+			if col.samples == 0 {
+				col.first = sample.Time
+			}
+			col.last = sample.Time
+			col.addSquare(data, c, i, value, config)
+		}
+	}
+
+	if config.nonFinite == NonFiniteStrict {
+		for c, col := range columns {
+			if col.bad >= 0 {
+				sample := data[col.bad]
+				return nil, nil, fmt.Errorf("%w in channel %d", nonFiniteError(col.bad, sample.Time, sample.Value[c]), c)
+			}
+		}
+	}
+
+	var single *[]SingleChannelSample
+	if config.acf {
+		single = sampleScratch.get(0)
+		defer sampleScratch.put(single)
+	}
+	rms = make([]float64, channelCount)
+	zcr = make([]float64, channelCount)
+	windowed := false
+	for c := range columns {
+		col := &columns[c]
+		if col.samples < 2 {
+			continue
+		}
+		duration := col.last - col.first
+		if config.acf {
+			*single = columnSamples((*single)[:0], data, c, col.mean, config)
+			zcr[c] = config.frequency(*single)
+		} else if duration > 0 {
+			zcr[c] = float64(col.crossings) / duration
+		}
+		if zcr[c] == 0 || math.IsNaN(zcr[c]) || math.IsInf(zcr[c], 0) {
+			zcr[c] = 0
+			rms[c] = math.Sqrt(col.sumSquares() / float64(col.samples))
+			continue
+		}
+		col.windowed = true
+		col.cutoff = col.last - rmsSpan(duration, zcr[c], config.maxCycles)
+		col.sumSq = [4]float64{}
+		col.sumSqComp = compensatedSum{}
+		col.compensated = config.compensated
+		col.samples = 0
+		windowed = true
+	}
+	if !windowed {
+		return rms, zcr, nil
+	}
+
+	for i, sample := range data {
+		for c, value := range sample.Value {
+			col := &columns[c]
+			// under NonFiniteStrict a non-finite value has already been reported
+			if !col.windowed || !config.admits(value) {
+				continue
+			}
+			value -= col.mean
+			// as in KeepXSecondsOfData, the window starts at the first sample at or after the cutoff
+			if !col.started {
+				if !(sample.Time >= col.cutoff) {
+					continue
+				}
+				col.started = true
+				col.from = i
+			}
+			col.addSquare(data, c, i, value, config)
+		}
+	}
+	for c := range columns {
+		if col := &columns[c]; col.windowed && col.samples > 0 {
+			rms[c] = math.Sqrt(col.sumSquares() / float64(col.samples))
+		}
+	}
+	return rms, zcr, nil
 }
 
-// calculateRMSAverage calculates the Root Mean Square value using the average method.
+// RMSMethod selects how RMSWithMethod computes the RMS.
+type RMSMethod int
+
+const (
+	// RMSAverage takes the square root of the mean of the squared values, the
+	// true RMS of any waveform. It is what RMS uses.
+	RMSAverage RMSMethod = iota
+	// RMSPeak divides the largest absolute value by √2, as instruments that
+	// assume a sinusoid report it. It matches RMSAverage on a clean sine, but
+	// reads low on a flattened or clipped waveform and high on a peaky one.
+	RMSPeak
+)
+
+// RMS calculates the Root Mean Square value of the given data.
+// The RMS is taken over the last whole cycles of the signal, up to
+// DefaultMaxCycles of them, so a long record of a high-frequency signal is
+// measured over only its final fraction; AnalyzeDetailed with WithMaxCycles
+// lifts the limit.
+// A zero frequency yields 0. A negative or non-finite frequency is treated as
+// unknown and the RMS of all the data is returned; use RMSE to have it rejected.
 //
 // Parameters:
 //   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency of the signal
 //
 // Returns:
 //   - float64: The calculated Root Mean Square value
-func calculateRMSAverage(data []SingleChannelSample) float64 {
-	sum := 0.0
-	for _, value := range data {
-		sum += value.Value * value.Value
-	}
-	mean := sum / float64(len(data))
-	return math.Sqrt(mean)
+func RMS(data []SingleChannelSample, frequency float64) float64 {
+	return RMSWithMethod(data, frequency, RMSAverage)
 }
 
-// calculateRMSPeak calculates the Root Mean Square value using the peak method.
+// RMSWithMethod calculates the Root Mean Square value of the given data as
+// RMS does, over the same whole cycles, by the given method.
 //
 // Parameters:
 //   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency of the signal
+//   - method: RMSAverage, as RMS uses, or RMSPeak
 //
 // Returns:
-//   - float64: The calculated Root Mean Square value
-// func calculateRMSPeak(data []SingleChannelSample) float64 {
-// 	peak := 0.0
-// 	for _, value := range data {
-// 		absValue := math.Abs(value.Value)
-// 		if absValue > peak {
-// 			peak = absValue
-// 		}
-// 	}
-// 	return peak / math.Sqrt(2)
-// }
+//   - float64: The calculated Root Mean Square value, 0 for an unknown method
+func RMSWithMethod(data []SingleChannelSample, frequency float64, method RMSMethod) float64 {
+	if method != RMSAverage && method != RMSPeak {
+		return 0
+	}
+	data = rmsWindow(data, frequency)
+	if len(data) == 0 {
+		return 0
+	}
 
-// ZeroCrossingRate calculates the Zero Crossing Rate of the given data.
+	// calculate RMS
+	return method.rms(data)
+}
+
+// rmsWindow returns the data RMS takes the RMS over: the last whole cycles at
+// the frequency, up to DefaultMaxCycles of them, or all the data when the
+// frequency is negative or not finite.
 //
 // Parameters:
 //   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency of the signal
 //
 // Returns:
-//   - float64: The calculated Zero Crossing Rate
-func ZeroCrossingRate(data []SingleChannelSample) float64 {
-	if len(data) == 0 {
-		return 0
+//   - []SingleChannelSample: The window, nil when data is empty or frequency is 0
+func rmsWindow(data []SingleChannelSample, frequency float64) []SingleChannelSample {
+	if len(data) == 0 || frequency == 0 {
+		return nil
 	}
-
-	crossings := 0
-	for i := 1; i < len(data); i++ {
-		if (data[i-1].Value >= 0 && data[i].Value < 0) || (data[i-1].Value <= 0 && data[i].Value > 0) {
-			crossings++
-		}
+	// without a usable frequency there are no cycles to align to
+	if frequency < 0 || math.IsNaN(frequency) || math.IsInf(frequency, 0) {
+		return data
 	}
 
-	duration := data[len(data)-1].Time - data[0].Time
-	return float64(crossings) / duration
+	// get the data from the start time to the end
+	return KeepXSecondsOfData(data, rmsSpan(data[len(data)-1].Time-data[0].Time, frequency, DefaultMaxCycles))
 }
 
-// NegativeZeroCrossingRate calculates the Negative Zero Crossing Rate of the given data.
+// rms returns the RMS of the data by the method.
+func (m RMSMethod) rms(data []SingleChannelSample) float64 {
+	if m == RMSPeak {
+		return calculateRMSPeak(data)
+	}
+	return calculateRMS(data)
+}
+
+// RMSE calculates the Root Mean Square value of the given data as RMS does,
+// but reports invalid input instead of falling back.
 //
 // Parameters:
 //   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency of the signal
 //
 // Returns:
-//   - float64: The calculated Negative Zero Crossing Rate
-func NegativeZeroCrossingRate(data []SingleChannelSample) float64 {
+//   - float64: The calculated Root Mean Square value, or 0 on error
+//   - error: ErrEmptyData if data is empty, ErrInvalidFrequency if frequency is
+//     not positive and finite, or ErrUnsortedData if the data is not in time order
+func RMSE(data []SingleChannelSample, frequency float64) (float64, error) {
 	if len(data) == 0 {
-		return 0
+		return 0, ErrEmptyData
 	}
-
-	crossings := 0
-	for i := 1; i < len(data); i++ {
-		// Only count crossings from positive to negative
-		if data[i-1].Value >= 0 && data[i].Value < 0 {
-			crossings++
-		}
+	if !(frequency > 0) || math.IsInf(frequency, 1) {
+		return 0, fmt.Errorf("%w: %g Hz", ErrInvalidFrequency, frequency)
 	}
 
-	duration := data[len(data)-1].Time - data[0].Time
-	return float64(crossings) / duration
+	data, err := KeepXSecondsOfDataE(data, rmsSpan(data[len(data)-1].Time-data[0].Time, frequency, DefaultMaxCycles))
+	if err != nil {
+		return 0, err
+	}
+	return calculateRMS(data), nil
 }
 
-// GenerateSineWave generates a sine wave with the specified parameters.
+// rmsSpan returns the number of seconds of data RMS uses: the last whole
+// cycles, up to maxCycles of them, or +Inf for all the data if it is shorter than a cycle.
 //
 // Parameters:
-//   - frequency: The frequency of the sine wave
-//   - amplitude: The amplitude of the sine wave
-//   - duration: The duration of the generated wave in seconds
-//   - sampleRate: The number of samples per second
+//   - duration: The time from the first to the last sample
+//   - frequency: The frequency of the signal, positive and finite
+//   - maxCycles: The most cycles to use, or 0 for no limit
 //
 // Returns:
-//   - []Sample: A slice of Sample structs representing the generated sine wave
-func GenerateSineWave(frequency, amplitude, duration float64, sampleRate int) []SingleChannelSample {
-	samples := int(duration * float64(sampleRate))
-	data := make([]SingleChannelSample, samples)
+//   - float64: The span in seconds to keep
+func rmsSpan(duration, frequency float64, maxCycles int) float64 {
+	period := 1 / frequency
 
-	// Constants
-	angularFrequency := 2 * math.Pi * frequency
-	timeStep := 1.0 / float64(sampleRate)
+	wholeCycles := math.Floor(duration / period)
 
-	// Initialize first two samples
-	data[0] = SingleChannelSample{Time: 0, Value: 0}
-	if samples > 1 {
-		data[1] = SingleChannelSample{Time: timeStep, Value: amplitude * math.Sin(angularFrequency*timeStep)}
+	if !(wholeCycles >= 1) {
+		return math.Inf(1)
 	}
 
-	// Recurrence coefficients
-	c := 2 * math.Cos(angularFrequency*timeStep)
-
-	// Generate sine wave using recurrence relation
-	for i := 2; i < samples; i++ {
-		t := float64(i) * timeStep
-		// Recurrence relation: y[n] = c * y[n-1] - y[n-2]
-		value := c*data[i-1].Value - data[i-2].Value
-		data[i] = SingleChannelSample{Time: t, Value: value}
+	// get last maxCycles whole cycles, or x whole cycles if less than maxCycles
+	cyclesToUse := wholeCycles
+	if maxCycles > 0 {
+		cyclesToUse = math.Min(wholeCycles, float64(maxCycles))
 	}
-
-	return data
+	return cyclesToUse * period
 }
 
-// KeepXSecondsOfData keeps the last X seconds of data from the given slice.
+// Mean returns the mean of the sample values, their DC level. From
+// compensatedSumThreshold samples on the sum is compensated, so a long record
+// keeps its precision.
 //
 // Parameters:
-//   - fastDataArray: A slice of Sample structs containing time and value data
-//   - seconds: The number of seconds of data to keep
+//   - data: A slice of Sample structs containing time and value data
 //
 // Returns:
-//   - []Sample: A slice of Sample structs containing the last X seconds of data
+//   - float64: The mean value, 0 for empty data
+func Mean(data []SingleChannelSample) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	return sumValues(data, false) / float64(len(data))
+}
+
+// RemoveDC returns a copy of the data with its mean subtracted from every
+// value, as AC coupling would remove it. The data itself is left unchanged.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - []SingleChannelSample: The data less its mean, nil for empty data
+func RemoveDC(data []SingleChannelSample) []SingleChannelSample {
+	if len(data) == 0 {
+		return nil
+	}
+	return subtractMean(append([]SingleChannelSample(nil), data...))
+}
+
+// subtractMean subtracts the mean of the data from every value in place.
+func subtractMean(data []SingleChannelSample) []SingleChannelSample {
+	mean := Mean(data)
+	for i := range data {
+		data[i].Value -= mean
+	}
+	return data
+}
+
+// Variance returns the sample variance of the values, the sum of their
+// squared deviations from the mean divided by one less than their number. It
+// is accumulated in a single pass with Welford's algorithm, which updates the
+// mean and the sum of squared deviations sample by sample, so values riding on
+// a large offset keep their precision where the textbook Σx² − (Σx)²/n would
+// cancel it away.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The sample variance, 0 for fewer than two samples
+func Variance(data []SingleChannelSample) float64 {
+	var w welford
+	w.add(data)
+	return w.variance()
+}
+
+// StdDev returns the sample standard deviation of the values, the square root
+// of their Variance.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The sample standard deviation, 0 for fewer than two samples
+func StdDev(data []SingleChannelSample) float64 {
+	return math.Sqrt(Variance(data))
+}
+
+// welford accumulates the mean and the sum of squared deviations from it of
+// the values added, by Welford's algorithm.
+type welford struct {
+	n    int
+	mean float64
+	m2   float64 // sum of squared deviations from the mean
+}
+
+// add adds the values of the data.
+func (w *welford) add(data []SingleChannelSample) {
+	for _, sample := range data {
+		w.n++
+		delta := sample.Value - w.mean
+		w.mean += delta / float64(w.n)
+		w.m2 += delta * (sample.Value - w.mean)
+	}
+}
+
+// variance returns the sample variance of the values added, 0 for fewer than two.
+func (w *welford) variance() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.n-1)
+}
+
+// Skewness returns the skewness of the values, their third moment about the
+// mean over the cube of their standard deviation: 0 for a symmetric signal,
+// positive when the larger excursions are above the mean. The moments are
+// accumulated in a single pass, as for Kurtosis.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The skewness, 0 for fewer than four samples or constant values
+func Skewness(data []SingleChannelSample) float64 {
+	var m moments
+	m.add(data)
+	return m.skewness()
+}
+
+// Kurtosis returns the excess kurtosis of the values, their fourth moment
+// about the mean over the square of their variance, less 3: 0 for Gaussian
+// noise, -1.5 for a sine and strongly positive for a signal with occasional
+// large impacts, as a damaged bearing gives. The moments are accumulated in a
+// single pass by the updates of Pébay, the extension of Welford's algorithm,
+// so a value costs the same whatever the length of the data and an offset does
+// not cost precision.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The excess kurtosis, 0 for fewer than four samples or constant values
+func Kurtosis(data []SingleChannelSample) float64 {
+	var m moments
+	m.add(data)
+	return m.excessKurtosis()
+}
+
+// moments accumulates the mean and the sums of the second, third and fourth
+// powers of the deviations from it of the values added.
+type moments struct {
+	n          int
+	mean       float64
+	m2, m3, m4 float64
+}
+
+// add adds the values of the data.
+func (m *moments) add(data []SingleChannelSample) {
+	for _, sample := range data {
+		n1 := float64(m.n)
+		m.n++
+		n := float64(m.n)
+		delta := sample.Value - m.mean
+		deltaN := delta / n
+		deltaN2 := deltaN * deltaN
+		term := delta * deltaN * n1
+		m.mean += deltaN
+		m.m4 += term*deltaN2*(n*n-3*n+3) + 6*deltaN2*m.m2 - 4*deltaN*m.m3
+		m.m3 += term*deltaN*(n-2) - 3*deltaN*m.m2
+		m.m2 += term
+	}
+}
+
+// skewness returns the skewness of the values added, 0 for fewer than four
+// or constant values.
+func (m *moments) skewness() float64 {
+	if m.n < 4 || m.m2 == 0 {
+		return 0
+	}
+	return math.Sqrt(float64(m.n)) * m.m3 / math.Pow(m.m2, 1.5)
+}
+
+// excessKurtosis returns the excess kurtosis of the values added, 0 for fewer
+// than four or constant values.
+func (m *moments) excessKurtosis() float64 {
+	if m.n < 4 || m.m2 == 0 {
+		return 0
+	}
+	return m.kurtosis() - 3
+}
+
+// kurtosis returns the kurtosis of the values added, not less 3, NaN when
+// they are constant or there are none.
+func (m *moments) kurtosis() float64 {
+	if m.m2 == 0 {
+		return math.NaN()
+	}
+	return float64(m.n) * m.m4 / (m.m2 * m.m2)
+}
+
+// Percentile returns the p-th percentile of the values, interpolating
+// linearly between the two values whose ranks straddle p/100·(n−1) in sorted
+// order, so p of 0 gives the smallest value, 100 the largest and 50 the
+// median. The values are sorted in a copy, leaving the data unchanged. A NaN
+// value sorts below every other value.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - p: The percentile, from 0 to 100
+//
+// Returns:
+//   - float64: The percentile, 0 for empty data, or NaN when p is outside 0 to 100
+func Percentile(data []SingleChannelSample, p float64) float64 {
+	if !(p >= 0 && p <= 100) {
+		return math.NaN()
+	}
+	if len(data) == 0 {
+		return 0
+	}
+	values := sampleValues(data)
+	slices.Sort(values)
+	rank := p / 100 * float64(len(values)-1)
+	low := int(rank)
+	frac := rank - float64(low)
+	if frac == 0 {
+		return values[low]
+	}
+	return values[low] + frac*(values[low+1]-values[low])
+}
+
+// Median returns the median of the values, the middle value in sorted order,
+// or the mean of the two middle values for an even number of samples. It is
+// Percentile at 50, and like it leaves the data unchanged.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The median, 0 for empty data
+func Median(data []SingleChannelSample) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	return median(sampleValues(data))
+}
+
+// MinMax returns the samples holding the smallest and largest values of the
+// data, with their times, so that the extremes can be matched to events. Where
+// several samples share an extreme value the earliest is returned. NaN values
+// are passed over, and when every value is NaN both extremes are the first
+// sample.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - min: The sample with the smallest value
+//   - max: The sample with the largest value
+//   - err: ErrEmptyData if data is empty
+func MinMax(data []SingleChannelSample) (min SingleChannelSample, max SingleChannelSample, err error) {
+	if len(data) == 0 {
+		return SingleChannelSample{}, SingleChannelSample{}, ErrEmptyData
+	}
+	min, max = extremes(data, data[0], data[0])
+	return min, max, nil
+}
+
+// extremes returns the earliest samples of the data with values below low
+// and above high, or low and high themselves when there are none. A NaN low
+// or high gives way to the first value that is not NaN.
+func extremes(data []SingleChannelSample, low, high SingleChannelSample) (SingleChannelSample, SingleChannelSample) {
+	for _, sample := range data {
+		if sample.Value < low.Value || math.IsNaN(low.Value) && !math.IsNaN(sample.Value) {
+			low = sample
+		}
+		if sample.Value > high.Value || math.IsNaN(high.Value) && !math.IsNaN(sample.Value) {
+			high = sample
+		}
+	}
+	return low, high
+}
+
+// PeakToPeak returns the difference between the largest and smallest values
+// of the data, taken in a single pass without allocating.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The peak-to-peak value, 0 for empty data, or NaN when a value is NaN
+func PeakToPeak(data []SingleChannelSample) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	low, high := valueRange(data, data[0].Value, data[0].Value)
+	return high - low
+}
+
+// CrestFactor returns the ratio of the largest absolute value of the data to
+// its RMS, √2 for a sine and 1 for a square wave. It rises as the signal
+// grows more impulsive, as when a bearing defect adds sharp impacts.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The crest factor, 0 for empty or all-zero data
+func CrestFactor(data []SingleChannelSample) float64 {
+	var peak float64
+	for _, sample := range data {
+		peak = math.Max(peak, math.Abs(sample.Value))
+	}
+	return crestFactorOf(peak, calculateRMS(data))
+}
+
+// AverageRectified returns the average rectified value (ARV) of the data, the
+// mean of the absolute values, 2/π of the amplitude for a sine. It is what
+// average-responding meters measure before scaling to RMS. Long records are
+// summed with compensation, as for Mean.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The average rectified value, 0 for empty data
+func AverageRectified(data []SingleChannelSample) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	return sumValues(data, true) / float64(len(data))
+}
+
+// FormFactor returns the ratio of the RMS of the data to its average
+// rectified value, π/(2√2) ≈ 1.111 for a sine, 1 for a square wave and 2/√3
+// for a triangle wave. Both are taken over the window RMS uses at the
+// frequency, the last whole cycles, or all the data when the frequency is
+// negative or not finite.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency of the signal
+//
+// Returns:
+//   - float64: The form factor, 0 for empty or all-zero data or a zero frequency
+func FormFactor(data []SingleChannelSample, frequency float64) float64 {
+	data = rmsWindow(data, frequency)
+	arv := AverageRectified(data)
+	if arv == 0 {
+		return 0
+	}
+	return calculateRMS(data) / arv
+}
+
+// crestFactorOf returns peak/rms, or 0 when rms is 0.
+func crestFactorOf(peak, rms float64) float64 {
+	if rms == 0 {
+		return 0
+	}
+	return peak / rms
+}
+
+// valueRange returns the smallest and largest of low, high and the values of the data.
+func valueRange(data []SingleChannelSample, low, high float64) (float64, float64) {
+	for _, sample := range data {
+		low = math.Min(low, sample.Value)
+		high = math.Max(high, sample.Value)
+	}
+	return low, high
+}
+
+// calculateRMS calculates the Root Mean Square value of the given data.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Root Mean Square value
+func calculateRMS(data []SingleChannelSample) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	return calculateRMSAverage(data)
+}
+
+// calculateRMSAverage calculates the Root Mean Square value using the average method.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Root Mean Square value
+func calculateRMSAverage(data []SingleChannelSample) float64 {
+	sum := sumSquares(data)
+	mean := sum / float64(len(data))
+	return math.Sqrt(mean)
+}
+
+// calculateRMSPeak calculates the Root Mean Square value using the peak method.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Root Mean Square value
+func calculateRMSPeak(data []SingleChannelSample) float64 {
+	return peakValue(data) / math.Sqrt2
+}
+
+// ZeroCrossingRate calculates the Zero Crossing Rate of the given data.
+// It returns 0 when the rate is undefined; see ZeroCrossingRateE.
+//
+// A crossing is counted once per change of sign. A sample of exactly zero
+// takes the sign of the last nonzero sample before it, so any run of zeros
+// between a positive and a negative sample is a single crossing, a run of
+// zeros between samples of the same sign is none, and zeros at the start of
+// the data carry no sign. Every crossing count in the package follows this
+// convention, so the rate is always the sum of NegativeZeroCrossingRate and
+// PositiveZeroCrossingRate.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Zero Crossing Rate
+func ZeroCrossingRate(data []SingleChannelSample) float64 {
+	rate, _ := crossingRate(data, true, true)
+	return rate
+}
+
+// ZeroCrossingRateE calculates the Zero Crossing Rate of the given data, reporting an error when it is undefined.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Zero Crossing Rate, or 0 on error
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span no time,
+//     or ErrUnsortedData if the data is not in time order
+func ZeroCrossingRateE(data []SingleChannelSample) (float64, error) {
+	rate, err := crossingRate(data, true, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, err
+	}
+	return rate, nil
+}
+
+// NegativeZeroCrossingRate calculates the Negative Zero Crossing Rate of the given data.
+// It returns 0 when the rate is undefined; see NegativeZeroCrossingRateE. Zero
+// samples are handled as described for ZeroCrossingRate.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Negative Zero Crossing Rate
+func NegativeZeroCrossingRate(data []SingleChannelSample) float64 {
+	rate, _ := crossingRate(data, true, false)
+	return rate
+}
+
+// NegativeZeroCrossingRateE calculates the Negative Zero Crossing Rate of the given data, reporting an error when it is undefined.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Negative Zero Crossing Rate, or 0 on error
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span no time,
+//     or ErrUnsortedData if the data is not in time order
+func NegativeZeroCrossingRateE(data []SingleChannelSample) (float64, error) {
+	rate, err := crossingRate(data, true, false)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, err
+	}
+	return rate, nil
+}
+
+// PositiveZeroCrossingRate calculates the Positive Zero Crossing Rate of the
+// given data, the rate of crossings from negative to positive, mirroring
+// NegativeZeroCrossingRate. It returns 0 when the rate is undefined; see
+// PositiveZeroCrossingRateE. Zero samples are handled as described for
+// ZeroCrossingRate, so -1, 0, +1 holds one positive-going crossing, found at
+// the +1.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Positive Zero Crossing Rate
+func PositiveZeroCrossingRate(data []SingleChannelSample) float64 {
+	rate, _ := crossingRate(data, false, true)
+	return rate
+}
+
+// PositiveZeroCrossingRateE calculates the Positive Zero Crossing Rate of the given data, reporting an error when it is undefined.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The calculated Positive Zero Crossing Rate, or 0 on error
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span no time,
+//     or ErrUnsortedData if the data is not in time order
+func PositiveZeroCrossingRateE(data []SingleChannelSample) (float64, error) {
+	rate, err := crossingRate(data, false, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, err
+	}
+	return rate, nil
+}
+
+// ZeroCrossingRateHysteresis calculates the Zero Crossing Rate of the given
+// data with hysteresis, as a Schmitt trigger counts: the signal is taken to be
+// positive once it rises above +threshold and negative once it falls below
+// −threshold, and keeps its sign in between, so noise riding on the signal
+// near zero adds no crossings as long as it is smaller than the threshold. A
+// threshold of 0 gives ZeroCrossingRate exactly, and a negative threshold
+// counts as its magnitude. It returns 0 when the rate is undefined.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - threshold: The level either side of zero the signal must pass for its sign to change
+//
+// Returns:
+//   - float64: The calculated Zero Crossing Rate
+func ZeroCrossingRateHysteresis(data []SingleChannelSample, threshold float64) float64 {
+	duration, err := recordDuration(data)
+	if err != nil {
+		return 0
+	}
+	var detector crossingDetector
+	negative, positive := detector.countHysteresis(data, math.Abs(threshold))
+	return float64(negative+positive) / duration
+}
+
+// NegativeZeroCrossingRateHysteresis calculates the Negative Zero Crossing
+// Rate of the given data with hysteresis, counting only the crossings from
+// positive to negative of ZeroCrossingRateHysteresis. A threshold of 0 gives
+// NegativeZeroCrossingRate exactly.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - threshold: The level either side of zero the signal must pass for its sign to change
+//
+// Returns:
+//   - float64: The calculated Negative Zero Crossing Rate
+func NegativeZeroCrossingRateHysteresis(data []SingleChannelSample, threshold float64) float64 {
+	duration, err := recordDuration(data)
+	if err != nil {
+		return 0
+	}
+	var detector crossingDetector
+	negative, _ := detector.countHysteresis(data, math.Abs(threshold))
+	return float64(negative) / duration
+}
+
+// crossingRate counts the zero crossings in the data and divides by its duration.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - negative: Whether to count crossings from positive to negative
+//   - positive: Whether to count crossings from negative to positive
+//
+// Returns:
+//   - float64: The crossing rate, or 0 on error
+//   - error: ErrEmptyData if data is empty, or ErrZeroDuration if the samples span no time
+func crossingRate(data []SingleChannelSample, negative, positive bool) (float64, error) {
+	duration, err := recordDuration(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var detector crossingDetector
+	down, up := detector.count(data)
+	var crossings int
+	if negative {
+		crossings += down
+	}
+	if positive {
+		crossings += up
+	}
+
+	return float64(crossings) / duration, nil
+}
+
+// recordDuration returns the time spanned by the data.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The time from the first to the last sample
+//   - error: ErrEmptyData if data is empty, or ErrZeroDuration if the span is not positive
+func recordDuration(data []SingleChannelSample) (float64, error) {
+	if len(data) == 0 {
+		return 0, ErrEmptyData
+	}
+	duration := data[len(data)-1].Time - data[0].Time
+	// the negated comparison also rejects a NaN duration
+	if !(duration > 0) {
+		return 0, ErrZeroDuration
+	}
+	return duration, nil
+}
+
+// checkTimeOrder checks that the data is in non-decreasing time order.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - error: ErrUnsortedData, naming the first sample that is earlier than its predecessor
+func checkTimeOrder(data []SingleChannelSample) error {
+	for i := 1; i < len(data); i++ {
+		if data[i].Time < data[i-1].Time {
+			return fmt.Errorf("%w: sample %d at time %g is earlier than sample %d at time %g", ErrUnsortedData, i, data[i].Time, i-1, data[i-1].Time)
+		}
+	}
+	return nil
+}
+
+// SineOption configures a call to GenerateSineWave, GenerateSineWaveE or GenerateSineWaveInto.
+type SineOption func(*sineConfig)
+
+// sineConfig holds the settings made by SineOptions.
+type sineConfig struct {
+	phase    float64 // at time 0, in radians
+	dcOffset float64
+}
+
+// WithPhase starts the sine wave at the given phase in radians in place of
+// zero, so WithPhase(math.Pi/2) gives a cosine.
+func WithPhase(radians float64) SineOption {
+	return func(c *sineConfig) {
+		c.phase = radians
+	}
+}
+
+// WithDCOffset adds a constant to every value of the sine wave, so that it
+// oscillates about the offset in place of zero.
+func WithDCOffset(offset float64) SineOption {
+	return func(c *sineConfig) {
+		c.dcOffset = offset
+	}
+}
+
+// newSineConfig applies the options to the default configuration, reporting
+// a phase or offset that is not finite.
+func newSineConfig(opts []SineOption) (sineConfig, error) {
+	// applying an option moves the config to the heap, so skip it when there are none
+	if len(opts) == 0 {
+		return sineConfig{}, nil
+	}
+	var c sineConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if math.IsNaN(c.phase) || math.IsInf(c.phase, 0) {
+		return c, errors.New("dynamics: sine wave phase is not finite")
+	}
+	if math.IsNaN(c.dcOffset) || math.IsInf(c.dcOffset, 0) {
+		return c, errors.New("dynamics: sine wave DC offset is not finite")
+	}
+	return c, nil
+}
+
+// GenerateSineWave generates a sine wave with the specified parameters.
+// Invalid parameters yield an empty slice; see GenerateSineWaveE.
+//
+// Parameters:
+//   - frequency: The frequency of the sine wave
+//   - amplitude: The amplitude of the sine wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//   - opts: Options such as WithPhase and WithDCOffset
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated sine wave
+func GenerateSineWave(frequency, amplitude, duration float64, sampleRate int, opts ...SineOption) []SingleChannelSample {
+	data, err := GenerateSineWaveE(frequency, amplitude, duration, sampleRate, opts...)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateSineWaveE generates a sine wave with the specified parameters, reporting invalid ones.
+//
+// The wave holds duration·sampleRate samples, the first at time 0, so the last
+// sample is one step short of duration. A fractional sample count is rounded
+// down, except that a count within one part per million below a whole number
+// is rounded up to it, so a duration such as 0.9999999 seconds that carries
+// rounding error does not lose its final sample. A duration shorter than one
+// sample gives an empty slice.
+//
+// Without options the wave starts at zero, rising, and oscillates about zero.
+// WithPhase shifts its start along the cycle and WithDCOffset raises it.
+//
+// Parameters:
+//   - frequency: The frequency of the sine wave
+//   - amplitude: The amplitude of the sine wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//   - opts: Options such as WithPhase and WithDCOffset
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated sine wave
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or frequency, amplitude, phase or offset is not finite
+func GenerateSineWaveE(frequency, amplitude, duration float64, sampleRate int, opts ...SineOption) ([]SingleChannelSample, error) {
+	if err := checkSineWave(frequency, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	config, err := newSineConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillSineWave(data, frequency, amplitude, sampleRate, config)
+	return data, nil
+}
+
+// sampleCount returns the number of samples a generator makes for the
+// duration, as GenerateSineWaveE describes.
+func sampleCount(duration float64, sampleRate int) int {
+	count := duration * float64(sampleRate)
+	samples := int(math.Floor(count))
+	if whole := math.Ceil(count); whole-count < 1e-6*whole {
+		samples = int(whole)
+	}
+	return samples
+}
+
+// GenerateSineWaveInto generates a sine wave into dst, overwriting every
+// sample, and returns dst. The wave is the same, sample for sample, as the one
+// GenerateSineWave produces for len(dst) samples, but nothing is allocated, so
+// a caller generating many signals can reuse one buffer. Invalid parameters
+// yield dst[:0]; see GenerateSineWaveE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - frequency: The frequency of the sine wave
+//   - amplitude: The amplitude of the sine wave
+//   - sampleRate: The number of samples per second
+//   - opts: Options such as WithPhase and WithDCOffset
+//
+// Returns:
+//   - []Sample: dst holding the generated sine wave
+func GenerateSineWaveInto(dst []SingleChannelSample, frequency, amplitude float64, sampleRate int, opts ...SineOption) []SingleChannelSample {
+	if checkSineWave(frequency, amplitude, sampleRate) != nil {
+		return dst[:0]
+	}
+	config, err := newSineConfig(opts)
+	if err != nil {
+		return dst[:0]
+	}
+	fillSineWave(dst, frequency, amplitude, sampleRate, config)
+	return dst
+}
+
+// checkSineWave validates the sine wave parameters shared by the generators.
+func checkSineWave(frequency, amplitude float64, sampleRate int) error {
+	if sampleRate <= 0 {
+		return errors.New("dynamics: sample rate must be positive")
+	}
+	if math.IsNaN(frequency) || math.IsInf(frequency, 0) {
+		return errors.New("dynamics: sine wave frequency is not finite")
+	}
+	if math.IsNaN(amplitude) || math.IsInf(amplitude, 0) {
+		return errors.New("dynamics: sine wave amplitude is not finite")
+	}
+	return nil
+}
+
+// sineReseedInterval is the number of samples after which fillSineWave
+// re-seeds its recurrence from the closed form. The recurrence's rounding
+// error grows with each sample, but over this many it stays within about
+// 1e-11 of the amplitude, while the two calls to math.Sin add little to the
+// cost. Over long records the rounding of the time itself then dominates.
+const sineReseedInterval = 1024
+
+// fillSineWave fills data with a sine wave starting at time 0.
+func fillSineWave(data []SingleChannelSample, frequency, amplitude float64, sampleRate int, config sineConfig) {
+	samples := len(data)
+	if samples == 0 {
+		return
+	}
+
+	// Constants
+	angularFrequency := 2 * math.Pi * frequency
+	timeStep := 1.0 / float64(sampleRate)
+
+	// Initialize first two samples
+	data[0] = SingleChannelSample{Time: 0, Value: amplitude * math.Sin(config.phase)}
+	if samples > 1 {
+		data[1] = SingleChannelSample{Time: timeStep, Value: amplitude * math.Sin(angularFrequency*timeStep+config.phase)}
+	}
+
+	// Recurrence coefficients
+	c := 2 * math.Cos(angularFrequency*timeStep)
+
+	// Generate sine wave using recurrence relation
+	for i := 2; i < samples; i++ {
+		t := float64(i) * timeStep
+		var value float64
+		switch i % sineReseedInterval {
+		case 0:
+			// Re-seed the recurrence from the closed form so its rounding error
+			// cannot build up. The two seeds share one argument, so that
+			// rounding in it shifts the phase without changing the step.
+			arg := angularFrequency*t + config.phase
+			value = amplitude * math.Sin(arg)
+			if i+1 < samples {
+				data[i+1].Value = amplitude * math.Sin(arg+angularFrequency*timeStep)
+			}
+		case 1:
+			value = data[i].Value
+		default:
+			// Recurrence relation: y[n] = c * y[n-1] - y[n-2]
+			value = c*data[i-1].Value - data[i-2].Value
+		}
+		data[i] = SingleChannelSample{Time: t, Value: value}
+	}
+
+	// Add the offset once the recurrence, which needs a wave about zero, is done
+	if config.dcOffset != 0 {
+		for i := range data {
+			data[i].Value += config.dcOffset
+		}
+	}
+}
+
+// GenerateSquareWave generates a square wave with the specified parameters,
+// on the same timebase as GenerateSineWave. Invalid parameters yield an empty
+// slice; see GenerateSquareWaveE.
+//
+// Parameters:
+//   - frequency: The frequency of the square wave
+//   - amplitude: The amplitude of the square wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//   - dutyCycle: The fraction of each cycle spent high, from 0 to 1
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated square wave
+func GenerateSquareWave(frequency, amplitude, duration float64, sampleRate int, dutyCycle float64) []SingleChannelSample {
+	data, err := GenerateSquareWaveE(frequency, amplitude, duration, sampleRate, dutyCycle)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateSquareWaveE generates a square wave with the specified parameters,
+// reporting invalid ones.
+//
+// Each cycle starts high, at +amplitude, for the first dutyCycle of its period
+// and is low, at -amplitude, for the rest, so the wave rises through zero
+// where a sine of the same frequency does. A sample falling exactly on a
+// falling edge is low and one exactly on a rising edge is high, so each cycle
+// holds one crossing in each direction. A dutyCycle of 0 gives a wave that is
+// low throughout and one of 1 a wave that is high throughout. The samples are
+// timed as GenerateSineWaveE times them.
+//
+// Parameters:
+//   - frequency: The frequency of the square wave
+//   - amplitude: The amplitude of the square wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//   - dutyCycle: The fraction of each cycle spent high, from 0 to 1
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated square wave
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, frequency or amplitude is not finite, or dutyCycle is outside 0 to 1
+func GenerateSquareWaveE(frequency, amplitude, duration float64, sampleRate int, dutyCycle float64) ([]SingleChannelSample, error) {
+	if err := checkSineWave(frequency, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+	if err := checkDutyCycle(dutyCycle); err != nil {
+		return nil, err
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillSquareWave(data, frequency, amplitude, sampleRate, dutyCycle)
+	return data, nil
+}
+
+// GenerateSquareWaveInto generates a square wave into dst, overwriting every
+// sample, and returns dst. The wave is the same, sample for sample, as the one
+// GenerateSquareWave produces for len(dst) samples, but nothing is allocated.
+// Invalid parameters yield dst[:0]; see GenerateSquareWaveE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - frequency: The frequency of the square wave
+//   - amplitude: The amplitude of the square wave
+//   - sampleRate: The number of samples per second
+//   - dutyCycle: The fraction of each cycle spent high, from 0 to 1
+//
+// Returns:
+//   - []Sample: dst holding the generated square wave
+func GenerateSquareWaveInto(dst []SingleChannelSample, frequency, amplitude float64, sampleRate int, dutyCycle float64) []SingleChannelSample {
+	if checkSineWave(frequency, amplitude, sampleRate) != nil || checkDutyCycle(dutyCycle) != nil {
+		return dst[:0]
+	}
+	fillSquareWave(dst, frequency, amplitude, sampleRate, dutyCycle)
+	return dst
+}
+
+// checkDutyCycle validates the duty cycle of a square wave.
+func checkDutyCycle(dutyCycle float64) error {
+	if !(dutyCycle >= 0 && dutyCycle <= 1) {
+		return errors.New("dynamics: duty cycle must be from 0 to 1")
+	}
+	return nil
+}
+
+// fillSquareWave fills data with a square wave starting at time 0.
+func fillSquareWave(data []SingleChannelSample, frequency, amplitude float64, sampleRate int, dutyCycle float64) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		value := amplitude * squareShape(wavePhase(frequency, 0, i, sampleRate), dutyCycle)
+		data[i] = SingleChannelSample{Time: float64(i) * timeStep, Value: value}
+	}
+}
+
+// GenerateTriangleWave generates a symmetric triangle wave with the specified
+// parameters, on the same timebase as GenerateSineWave. Invalid parameters
+// yield an empty slice; see GenerateTriangleWaveE.
+//
+// Parameters:
+//   - frequency: The frequency of the triangle wave
+//   - amplitude: The amplitude of the triangle wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated triangle wave
+func GenerateTriangleWave(frequency, amplitude, duration float64, sampleRate int) []SingleChannelSample {
+	data, err := GenerateTriangleWaveE(frequency, amplitude, duration, sampleRate)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateTriangleWaveE generates a symmetric triangle wave with the specified
+// parameters, reporting invalid ones.
+//
+// The wave is centred on zero and follows the phase of a sine of the same
+// frequency: it starts at zero ramping upward, peaks at +amplitude a quarter
+// cycle in, falls through zero at the half cycle and reaches -amplitude at
+// three quarters. Its RMS is amplitude/√3. The samples are timed as
+// GenerateSineWaveE times them.
+//
+// Parameters:
+//   - frequency: The frequency of the triangle wave
+//   - amplitude: The amplitude of the triangle wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated triangle wave
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or frequency or amplitude is not finite
+func GenerateTriangleWaveE(frequency, amplitude, duration float64, sampleRate int) ([]SingleChannelSample, error) {
+	if err := checkSineWave(frequency, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillTriangleWave(data, frequency, amplitude, sampleRate)
+	return data, nil
+}
+
+// GenerateTriangleWaveInto generates a triangle wave into dst, overwriting every
+// sample, and returns dst. The wave is the same, sample for sample, as the one
+// GenerateTriangleWave produces for len(dst) samples, but nothing is allocated.
+// Invalid parameters yield dst[:0]; see GenerateTriangleWaveE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - frequency: The frequency of the triangle wave
+//   - amplitude: The amplitude of the triangle wave
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: dst holding the generated triangle wave
+func GenerateTriangleWaveInto(dst []SingleChannelSample, frequency, amplitude float64, sampleRate int) []SingleChannelSample {
+	if checkSineWave(frequency, amplitude, sampleRate) != nil {
+		return dst[:0]
+	}
+	fillTriangleWave(dst, frequency, amplitude, sampleRate)
+	return dst
+}
+
+// fillTriangleWave fills data with a triangle wave starting at time 0.
+func fillTriangleWave(data []SingleChannelSample, frequency, amplitude float64, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		value := amplitude * triangleShape(wavePhase(frequency, 0, i, sampleRate))
+		data[i] = SingleChannelSample{Time: float64(i) * timeStep, Value: value}
+	}
+}
+
+// GenerateSawtoothWave generates a sawtooth wave with the specified
+// parameters, on the same timebase as GenerateSineWave. Invalid parameters
+// yield an empty slice; see GenerateSawtoothWaveE.
+//
+// Parameters:
+//   - frequency: The frequency of the sawtooth wave
+//   - amplitude: The amplitude of the sawtooth wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated sawtooth wave
+func GenerateSawtoothWave(frequency, amplitude, duration float64, sampleRate int) []SingleChannelSample {
+	data, err := GenerateSawtoothWaveE(frequency, amplitude, duration, sampleRate)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateSawtoothWaveE generates a sawtooth wave with the specified
+// parameters, reporting invalid ones.
+//
+// Each cycle ramps from -amplitude at its start to +amplitude at its end and
+// drops back at once, so the wave rises through zero at each half cycle and
+// falls through it at each drop. A sample falling exactly on a drop takes the
+// low value. When the sample rate is more than twice the frequency, each cycle
+// holds a sample on either side of its rising zero, so the data crosses zero
+// downward once per cycle whether or not a sample falls on the drop. Its RMS
+// is amplitude/√3. The samples are timed as GenerateSineWaveE times them.
+//
+// Parameters:
+//   - frequency: The frequency of the sawtooth wave
+//   - amplitude: The amplitude of the sawtooth wave
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated sawtooth wave
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or frequency or amplitude is not finite
+func GenerateSawtoothWaveE(frequency, amplitude, duration float64, sampleRate int) ([]SingleChannelSample, error) {
+	if err := checkSineWave(frequency, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillSawtoothWave(data, frequency, amplitude, sampleRate)
+	return data, nil
+}
+
+// GenerateSawtoothWaveInto generates a sawtooth wave into dst, overwriting every
+// sample, and returns dst. The wave is the same, sample for sample, as the one
+// GenerateSawtoothWave produces for len(dst) samples, but nothing is allocated.
+// Invalid parameters yield dst[:0]; see GenerateSawtoothWaveE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - frequency: The frequency of the sawtooth wave
+//   - amplitude: The amplitude of the sawtooth wave
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: dst holding the generated sawtooth wave
+func GenerateSawtoothWaveInto(dst []SingleChannelSample, frequency, amplitude float64, sampleRate int) []SingleChannelSample {
+	if checkSineWave(frequency, amplitude, sampleRate) != nil {
+		return dst[:0]
+	}
+	fillSawtoothWave(dst, frequency, amplitude, sampleRate)
+	return dst
+}
+
+// fillSawtoothWave fills data with a sawtooth wave starting at time 0.
+func fillSawtoothWave(data []SingleChannelSample, frequency, amplitude float64, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		value := amplitude * sawtoothShape(wavePhase(frequency, 0, i, sampleRate))
+		data[i] = SingleChannelSample{Time: float64(i) * timeStep, Value: value}
+	}
+}
+
+// GenerateChirp generates a linear swept sine with the specified parameters,
+// on the same timebase as GenerateSineWave. Invalid parameters yield an empty
+// slice; see GenerateChirpE.
+//
+// Parameters:
+//   - startFreq: The frequency at the start of the sweep
+//   - endFreq: The frequency at the end of the sweep
+//   - amplitude: The amplitude of the sweep
+//   - duration: The duration of the generated sweep in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated sweep
+func GenerateChirp(startFreq, endFreq, amplitude, duration float64, sampleRate int) []SingleChannelSample {
+	data, err := GenerateChirpE(startFreq, endFreq, amplitude, duration, sampleRate)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateChirpE generates a linear swept sine with the specified parameters,
+// reporting invalid ones.
+//
+// The instantaneous frequency rises, or falls, linearly from startFreq at time
+// 0 to endFreq at duration, and the phase is its integral, 2π(f₀t +
+// (f₁−f₀)t²/2T), evaluated afresh at each sample, so the wave is continuous
+// and starts at zero as GenerateSineWave does. The samples are timed as
+// GenerateSineWaveE times them.
+//
+// Parameters:
+//   - startFreq: The frequency at the start of the sweep
+//   - endFreq: The frequency at the end of the sweep
+//   - amplitude: The amplitude of the sweep
+//   - duration: The duration of the generated sweep in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated sweep
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or either frequency or amplitude is not finite
+func GenerateChirpE(startFreq, endFreq, amplitude, duration float64, sampleRate int) ([]SingleChannelSample, error) {
+	if err := checkSineWave(startFreq, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(endFreq) || math.IsInf(endFreq, 0) {
+		return nil, errors.New("dynamics: chirp end frequency is not finite")
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillChirp(data, startFreq, endFreq, amplitude, duration, sampleRate)
+	return data, nil
+}
+
+// GenerateChirpInto generates a linear swept sine into dst, overwriting every
+// sample, and returns dst. The sweep runs from startFreq to endFreq over
+// len(dst)/sampleRate seconds, the same, sample for sample, as the one
+// GenerateChirp produces for that duration, but nothing is allocated. Invalid
+// parameters yield dst[:0]; see GenerateChirpE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - startFreq: The frequency at the start of the sweep
+//   - endFreq: The frequency at the end of the sweep
+//   - amplitude: The amplitude of the sweep
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: dst holding the generated sweep
+func GenerateChirpInto(dst []SingleChannelSample, startFreq, endFreq, amplitude float64, sampleRate int) []SingleChannelSample {
+	if checkSineWave(startFreq, amplitude, sampleRate) != nil || math.IsNaN(endFreq) || math.IsInf(endFreq, 0) {
+		return dst[:0]
+	}
+	fillChirp(dst, startFreq, endFreq, amplitude, float64(len(dst))/float64(sampleRate), sampleRate)
+	return dst
+}
+
+// fillChirp fills data with a sweep from startFreq at time 0 to endFreq at duration.
+func fillChirp(data []SingleChannelSample, startFreq, endFreq, amplitude, duration float64, sampleRate int) {
+	if len(data) == 0 {
+		return
+	}
+	sweepRate := (endFreq - startFreq) / duration // Hz per second
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		t := float64(i) * timeStep
+		phase := 2 * math.Pi * (startFreq*t + sweepRate*t*t/2)
+		data[i] = SingleChannelSample{Time: t, Value: amplitude * math.Sin(phase)}
+	}
+}
+
+// GenerateImpulse generates a signal that is zero but for one sample, on the
+// same timebase as GenerateSineWave. Invalid parameters yield an empty slice;
+// see GenerateImpulseE.
+//
+// Parameters:
+//   - amplitude: The value of the impulse
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//   - impulseTime: The time of the impulse in seconds
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated impulse
+func GenerateImpulse(amplitude, duration float64, sampleRate int, impulseTime float64) []SingleChannelSample {
+	data, err := GenerateImpulseE(amplitude, duration, sampleRate, impulseTime)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateImpulseE generates a signal that is zero but for one sample,
+// reporting invalid parameters. The sample nearest impulseTime takes the
+// amplitude, and an impulseTime outside 0 to duration gives a signal of zeros.
+// The samples are timed as GenerateSineWaveE times them.
+//
+// Parameters:
+//   - amplitude: The value of the impulse
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//   - impulseTime: The time of the impulse in seconds
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated impulse
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, amplitude is not finite or impulseTime is NaN
+func GenerateImpulseE(amplitude, duration float64, sampleRate int, impulseTime float64) ([]SingleChannelSample, error) {
+	data, err := zeroSignal(amplitude, duration, sampleRate, impulseTime)
+	if err != nil {
+		return nil, err
+	}
+	fillImpulse(data, amplitude, duration, sampleRate, impulseTime)
+	return data, nil
+}
+
+// GenerateImpulseInto generates an impulse into dst, overwriting every
+// sample, and returns dst. The signal spans len(dst)/sampleRate seconds and is
+// the same, sample for sample, as the one GenerateImpulse produces for that
+// duration, but nothing is allocated. Invalid parameters yield dst[:0]; see
+// GenerateImpulseE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - amplitude: The value of the impulse
+//   - sampleRate: The number of samples per second
+//   - impulseTime: The time of the impulse in seconds
+//
+// Returns:
+//   - []Sample: dst holding the generated impulse
+func GenerateImpulseInto(dst []SingleChannelSample, amplitude float64, sampleRate int, impulseTime float64) []SingleChannelSample {
+	if checkTransition(amplitude, sampleRate, impulseTime) != nil {
+		return dst[:0]
+	}
+	fillZeros(dst, sampleRate)
+	fillImpulse(dst, amplitude, float64(len(dst))/float64(sampleRate), sampleRate, impulseTime)
+	return dst
+}
+
+// fillImpulse sets the sample of a signal of zeros nearest impulseTime to the amplitude.
+func fillImpulse(data []SingleChannelSample, amplitude, duration float64, sampleRate int, impulseTime float64) {
+	if impulseTime >= 0 && impulseTime <= duration && len(data) > 0 {
+		i := min(int(math.Round(impulseTime*float64(sampleRate))), len(data)-1)
+		data[i].Value = amplitude
+	}
+}
+
+// GenerateStep generates a signal that steps from zero to the amplitude, on
+// the same timebase as GenerateSineWave. Invalid parameters yield an empty
+// slice; see GenerateStepE.
+//
+// Parameters:
+//   - amplitude: The value after the step
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//   - stepTime: The time of the step in seconds
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated step
+func GenerateStep(amplitude, duration float64, sampleRate int, stepTime float64) []SingleChannelSample {
+	data, err := GenerateStepE(amplitude, duration, sampleRate, stepTime)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateStepE generates a signal that steps from zero to the amplitude,
+// reporting invalid parameters. Samples before stepTime are zero and those at
+// or after it take the amplitude, so a stepTime at or before 0 gives a signal
+// of the amplitude throughout and one beyond the last sample a signal of
+// zeros. The samples are timed as GenerateSineWaveE times them.
+//
+// Parameters:
+//   - amplitude: The value after the step
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//   - stepTime: The time of the step in seconds
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated step
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, amplitude is not finite or stepTime is NaN
+func GenerateStepE(amplitude, duration float64, sampleRate int, stepTime float64) ([]SingleChannelSample, error) {
+	data, err := zeroSignal(amplitude, duration, sampleRate, stepTime)
+	if err != nil {
+		return nil, err
+	}
+	fillStep(data, amplitude, sampleRate, stepTime)
+	return data, nil
+}
+
+// GenerateStepInto generates a step into dst, overwriting every sample, and
+// returns dst. The signal is the same, sample for sample, as the one
+// GenerateStep produces for len(dst) samples, but nothing is allocated.
+// Invalid parameters yield dst[:0]; see GenerateStepE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - amplitude: The value after the step
+//   - sampleRate: The number of samples per second
+//   - stepTime: The time of the step in seconds
+//
+// Returns:
+//   - []Sample: dst holding the generated step
+func GenerateStepInto(dst []SingleChannelSample, amplitude float64, sampleRate int, stepTime float64) []SingleChannelSample {
+	if checkTransition(amplitude, sampleRate, stepTime) != nil {
+		return dst[:0]
+	}
+	fillZeros(dst, sampleRate)
+	fillStep(dst, amplitude, sampleRate, stepTime)
+	return dst
+}
+
+// fillStep sets the samples of a signal of zeros at or after stepTime to the amplitude.
+func fillStep(data []SingleChannelSample, amplitude float64, sampleRate int, stepTime float64) {
+	// the first sample at or after stepTime, taken from the index so that a
+	// step on a sample is not moved by rounding in the time
+	first := math.Ceil(stepTime*float64(sampleRate) - 1e-9)
+	for i := range data {
+		if float64(i) >= first {
+			data[i].Value = amplitude
+		}
+	}
+}
+
+// zeroSignal validates the parameters of an impulse or step and returns a
+// signal of zeros on the timebase of GenerateSineWaveE.
+func zeroSignal(amplitude, duration float64, sampleRate int, transition float64) ([]SingleChannelSample, error) {
+	if err := checkTransition(amplitude, sampleRate, transition); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillZeros(data, sampleRate)
+	return data, nil
+}
+
+// checkTransition validates the parameters of an impulse or step other than
+// its duration.
+func checkTransition(amplitude float64, sampleRate int, transition float64) error {
+	if err := checkSineWave(0, amplitude, sampleRate); err != nil {
+		return err
+	}
+	if math.IsNaN(transition) {
+		return errors.New("dynamics: transition time is NaN")
+	}
+	return nil
+}
+
+// fillZeros fills data with zeros on the timebase of GenerateSineWaveE.
+func fillZeros(data []SingleChannelSample, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		data[i] = SingleChannelSample{Time: float64(i) * timeStep}
+	}
+}
+
+// BurstOption configures a call to GenerateToneBurst or GenerateToneBurstE.
+type BurstOption func(*burstConfig)
+
+// burstConfig holds the settings made by BurstOptions.
+type burstConfig struct {
+	ramp float64 // length of each edge's taper in seconds
+}
+
+// WithBurstRamp tapers the edges of a tone burst with raised-cosine ramps of
+// the given length in seconds, in place of switching the tone on and off at
+// once. Ramps longer than half the burst are shortened to half.
+func WithBurstRamp(seconds float64) BurstOption {
+	return func(c *burstConfig) {
+		c.ramp = seconds
+	}
+}
+
+// GenerateToneBurst generates a burst of sine wave in silence, on the same
+// timebase as GenerateSineWave. Invalid parameters yield an empty slice; see
+// GenerateToneBurstE.
+//
+// Parameters:
+//   - frequency: The frequency of the tone
+//   - amplitude: The amplitude of the tone
+//   - burstStart: The time the burst starts in seconds
+//   - burstDuration: The length of the burst in seconds
+//   - totalDuration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//   - opts: Options such as WithBurstRamp
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated burst
+func GenerateToneBurst(frequency, amplitude float64, burstStart, burstDuration, totalDuration float64, sampleRate int, opts ...BurstOption) []SingleChannelSample {
+	data, err := GenerateToneBurstE(frequency, amplitude, burstStart, burstDuration, totalDuration, sampleRate, opts...)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateToneBurstE generates a burst of sine wave in silence, reporting
+// invalid parameters. Samples from burstStart up to, but not including,
+// burstStart+burstDuration hold a sine starting at zero phase at burstStart,
+// and the rest are zero. The samples are timed as GenerateSineWaveE times
+// them, over the whole record.
+//
+// Parameters:
+//   - frequency: The frequency of the tone
+//   - amplitude: The amplitude of the tone
+//   - burstStart: The time the burst starts in seconds
+//   - burstDuration: The length of the burst in seconds
+//   - totalDuration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//   - opts: Options such as WithBurstRamp
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated burst
+//   - error: An error if sampleRate is not positive, totalDuration or
+//     burstDuration is negative or not finite, burstStart, frequency or
+//     amplitude is not finite, or the ramp is negative or not finite
+func GenerateToneBurstE(frequency, amplitude float64, burstStart, burstDuration, totalDuration float64, sampleRate int, opts ...BurstOption) ([]SingleChannelSample, error) {
+	if err := checkSineWave(frequency, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(totalDuration >= 0) || math.IsInf(totalDuration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+	config, err := newBurstConfig(burstStart, burstDuration, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]SingleChannelSample, sampleCount(totalDuration, sampleRate))
+	fillToneBurst(data, frequency, amplitude, burstStart, burstDuration, sampleRate, config)
+	return data, nil
+}
+
+// GenerateToneBurstInto generates a tone burst into dst, overwriting every
+// sample, and returns dst. The signal is the same, sample for sample, as the
+// one GenerateToneBurst produces for len(dst) samples, but nothing is
+// allocated. Invalid parameters yield dst[:0]; see GenerateToneBurstE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - frequency: The frequency of the tone
+//   - amplitude: The amplitude of the tone
+//   - burstStart: The time the burst starts in seconds
+//   - burstDuration: The length of the burst in seconds
+//   - sampleRate: The number of samples per second
+//   - opts: Options such as WithBurstRamp
+//
+// Returns:
+//   - []Sample: dst holding the generated burst
+func GenerateToneBurstInto(dst []SingleChannelSample, frequency, amplitude float64, burstStart, burstDuration float64, sampleRate int, opts ...BurstOption) []SingleChannelSample {
+	if checkSineWave(frequency, amplitude, sampleRate) != nil {
+		return dst[:0]
+	}
+	config, err := newBurstConfig(burstStart, burstDuration, opts)
+	if err != nil {
+		return dst[:0]
+	}
+	fillToneBurst(dst, frequency, amplitude, burstStart, burstDuration, sampleRate, config)
+	return dst
+}
+
+// newBurstConfig validates the burst and applies its options.
+func newBurstConfig(burstStart, burstDuration float64, opts []BurstOption) (burstConfig, error) {
+	if !(burstDuration >= 0) || math.IsInf(burstDuration, 1) || math.IsNaN(burstStart) || math.IsInf(burstStart, 0) {
+		return burstConfig{}, errors.New("dynamics: burst must have a finite start and a finite, non-negative duration")
+	}
+	// applying an option moves the config to the heap, so skip it when there are none
+	if len(opts) == 0 {
+		return burstConfig{}, nil
+	}
+	var config burstConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if !(config.ramp >= 0) || math.IsInf(config.ramp, 1) {
+		return config, errors.New("dynamics: burst ramp must be a finite, non-negative number")
+	}
+	return config, nil
+}
+
+// fillToneBurst fills data with a tone burst in silence starting at time 0.
+func fillToneBurst(data []SingleChannelSample, frequency, amplitude float64, burstStart, burstDuration float64, sampleRate int, config burstConfig) {
+	ramp := min(config.ramp, burstDuration/2)
+	timeStep := 1.0 / float64(sampleRate)
+	end := burstStart + burstDuration
+	for i := range data {
+		t := float64(i) * timeStep
+		data[i] = SingleChannelSample{Time: t}
+		if t < burstStart || t >= end {
+			continue
+		}
+		value := amplitude * math.Sin(2*math.Pi*frequency*(t-burstStart))
+		if edge := min(t-burstStart, end-t); edge < ramp {
+			value *= 0.5 * (1 - math.Cos(math.Pi*edge/ramp))
+		}
+		data[i].Value = value
+	}
+}
+
+// Waveform is the shape of a channel made by GenerateMultiChannel.
+type Waveform int
+
+const (
+	WaveformSine     Waveform = iota // as GenerateSineWave makes
+	WaveformSquare                   // as GenerateSquareWave makes
+	WaveformTriangle                 // as GenerateTriangleWave makes
+	WaveformSawtooth                 // as GenerateSawtoothWave makes
+)
+
+// ChannelConfig describes one channel made by GenerateMultiChannel.
+type ChannelConfig struct {
+	Waveform  Waveform `json:"waveform"`
+	Frequency float64  `json:"frequency"` // in Hz
+	Amplitude float64  `json:"amplitude"`
+	Phase     float64  `json:"phase"` // at time 0, in radians; π/2 starts a sine wave at its peak
+	// DutyCycle is the fraction of each cycle a square wave is high, from 0
+	// to 1; nil means 0.5.
+	DutyCycle *float64 `json:"dutyCycle,omitempty"`
+}
+
+// GenerateMultiChannel generates a multi-channel signal, one channel per
+// config, on the same timebase as GenerateSineWave. Invalid parameters yield
+// an empty slice; see GenerateMultiChannelE.
+//
+// Parameters:
+//   - configs: The waveform of each channel
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []MultiChannelSample: The generated samples, each holding len(configs) values
+func GenerateMultiChannel(configs []ChannelConfig, duration float64, sampleRate int) []MultiChannelSample {
+	data, err := GenerateMultiChannelE(configs, duration, sampleRate)
+	if err != nil {
+		return []MultiChannelSample{}
+	}
+	return data
+}
+
+// GenerateMultiChannelE generates a multi-channel signal, one channel per
+// config, reporting invalid parameters. Each channel follows the single-channel
+// generator of its waveform, shifted along its cycle by the config's phase, and
+// every sample holds one value per config. The samples are timed as
+// GenerateSineWaveE times them.
+//
+// Parameters:
+//   - configs: The waveform of each channel
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []MultiChannelSample: The generated samples, each holding len(configs) values
+//   - error: ErrNoChannels if configs is empty, or an error if sampleRate is
+//     not positive, duration is negative or not finite, or a config has an
+//     unknown waveform, a frequency, amplitude or phase that is not finite, or
+//     a duty cycle outside 0 to 1
+func GenerateMultiChannelE(configs []ChannelConfig, duration float64, sampleRate int) ([]MultiChannelSample, error) {
+	if len(configs) == 0 {
+		return nil, ErrNoChannels
+	}
+	if sampleRate <= 0 {
+		return nil, errors.New("dynamics: sample rate must be positive")
+	}
+	if err := checkChannels(configs); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]MultiChannelSample, sampleCount(duration, sampleRate))
+	values := make([]float64, len(data)*len(configs)) // one backing array for every sample
+	for i := range data {
+		data[i].Value = values[i*len(configs) : (i+1)*len(configs) : (i+1)*len(configs)]
+	}
+	fillMultiChannel(data, configs, sampleRate)
+	return data, nil
+}
+
+// GenerateMultiChannelInto generates several waveforms into dst, overwriting
+// every sample, and returns dst. The signals are the same, sample for sample,
+// as those GenerateMultiChannel produces for len(dst) samples. The value slice
+// of each sample is reused when it has the capacity for every channel, so
+// nothing is allocated when dst is reused for the same channels. Invalid
+// parameters yield dst[:0]; see GenerateMultiChannelE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - configs: The waveform of each channel, in channel order
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []MultiChannelSample: dst holding the generated samples
+func GenerateMultiChannelInto(dst []MultiChannelSample, configs []ChannelConfig, sampleRate int) []MultiChannelSample {
+	if len(configs) == 0 || sampleRate <= 0 {
+		return dst[:0]
+	}
+	for _, config := range configs {
+		// the error is not returned, so the channel need not be named
+		if err := config.check("channel"); err != nil {
+			return dst[:0]
+		}
+	}
+	for i := range dst {
+		if cap(dst[i].Value) < len(configs) {
+			dst[i].Value = make([]float64, len(configs))
+		}
+		dst[i].Value = dst[i].Value[:len(configs)]
+	}
+	fillMultiChannel(dst, configs, sampleRate)
+	return dst
+}
+
+// checkChannels validates the channel configs.
+func checkChannels(configs []ChannelConfig) error {
+	for ch, config := range configs {
+		if err := config.check(fmt.Sprintf("channel %d", ch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillMultiChannel fills the times and values of data, whose value slices
+// already hold one element per channel, starting at time 0.
+func fillMultiChannel(data []MultiChannelSample, configs []ChannelConfig, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		data[i].Time = float64(i) * timeStep
+		for ch, config := range configs {
+			data[i].Value[ch] = config.value(i, sampleRate)
+		}
+	}
+}
+
+// check validates the config, naming it in any error.
+func (c ChannelConfig) check(name string) error {
+	if sum := c.Frequency + c.Amplitude + c.Phase; math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return fmt.Errorf("dynamics: %s frequency, amplitude or phase is not finite", name)
+	}
+	if c.Waveform < WaveformSine || c.Waveform > WaveformSawtooth {
+		return fmt.Errorf("dynamics: %s has unknown waveform %d", name, c.Waveform)
+	}
+	if checkDutyCycle(c.dutyCycle()) != nil {
+		return fmt.Errorf("dynamics: %s duty cycle must be from 0 to 1", name)
+	}
+	return nil
+}
+
+// dutyCycle returns the config's duty cycle, 0.5 when it has none.
+func (c ChannelConfig) dutyCycle() float64 {
+	if c.DutyCycle == nil {
+		return 0.5
+	}
+	return *c.DutyCycle
+}
+
+// value returns sample i of the channel's waveform.
+func (c ChannelConfig) value(i, sampleRate int) float64 {
+	phase := wavePhase(c.Frequency, c.Phase/(2*math.Pi), i, sampleRate)
+	switch c.Waveform {
+	case WaveformSquare:
+		return c.Amplitude * squareShape(phase, c.dutyCycle())
+	case WaveformTriangle:
+		return c.Amplitude * triangleShape(phase)
+	case WaveformSawtooth:
+		return c.Amplitude * sawtoothShape(phase)
+	}
+	t := float64(i) * (1.0 / float64(sampleRate))
+	return c.Amplitude * math.Sin(2*math.Pi*c.Frequency*t+c.Phase)
+}
+
+// GenerateAMSine generates an amplitude-modulated sine wave with the
+// specified parameters, on the same timebase as GenerateSineWave. Invalid
+// parameters yield an empty slice; see GenerateAMSineE.
+//
+// Parameters:
+//   - carrierFreq: The frequency of the carrier
+//   - modFreq: The frequency of the modulation
+//   - carrierAmp: The amplitude of the unmodulated carrier
+//   - modDepth: The depth of the modulation, 0 for none and 1 for full
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated wave
+func GenerateAMSine(carrierFreq, modFreq, carrierAmp, modDepth, duration float64, sampleRate int) []SingleChannelSample {
+	data, err := GenerateAMSineE(carrierFreq, modFreq, carrierAmp, modDepth, duration, sampleRate)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateAMSineE generates an amplitude-modulated sine wave with the
+// specified parameters, reporting invalid ones. The wave is
+// carrierAmp·(1 + modDepth·sin(2π·modFreq·t))·sin(2π·carrierFreq·t), so its
+// envelope swings between carrierAmp·(1 − modDepth) and carrierAmp·(1 +
+// modDepth) and its RMS is carrierAmp·√((1 + modDepth²/2)/2). A modDepth above
+// 1 over-modulates the carrier, whose envelope then passes through zero and
+// inverts it. The samples are timed as GenerateSineWaveE times them.
+//
+// Parameters:
+//   - carrierFreq: The frequency of the carrier
+//   - modFreq: The frequency of the modulation
+//   - carrierAmp: The amplitude of the unmodulated carrier
+//   - modDepth: The depth of the modulation, 0 for none and 1 for full
+//   - duration: The duration of the generated wave in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated wave
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or a frequency, the amplitude or the depth is not finite
+func GenerateAMSineE(carrierFreq, modFreq, carrierAmp, modDepth, duration float64, sampleRate int) ([]SingleChannelSample, error) {
+	if err := checkAMSine(carrierFreq, modFreq, carrierAmp, modDepth, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillAMSine(data, carrierFreq, modFreq, carrierAmp, modDepth, sampleRate)
+	return data, nil
+}
+
+// GenerateAMSineInto generates an amplitude-modulated sine wave into dst,
+// overwriting every sample, and returns dst. The wave is the same, sample for
+// sample, as the one GenerateAMSine produces for len(dst) samples, but nothing
+// is allocated. Invalid parameters yield dst[:0]; see GenerateAMSineE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - carrierFreq: The frequency of the carrier
+//   - modFreq: The frequency of the modulation
+//   - carrierAmp: The amplitude of the unmodulated carrier
+//   - modDepth: The depth of the modulation, 0 for none and 1 for full
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: dst holding the generated wave
+func GenerateAMSineInto(dst []SingleChannelSample, carrierFreq, modFreq, carrierAmp, modDepth float64, sampleRate int) []SingleChannelSample {
+	if checkAMSine(carrierFreq, modFreq, carrierAmp, modDepth, sampleRate) != nil {
+		return dst[:0]
+	}
+	fillAMSine(dst, carrierFreq, modFreq, carrierAmp, modDepth, sampleRate)
+	return dst
+}
+
+// checkAMSine validates the parameters of an amplitude-modulated sine wave
+// other than its duration.
+func checkAMSine(carrierFreq, modFreq, carrierAmp, modDepth float64, sampleRate int) error {
+	if err := checkSineWave(carrierFreq, carrierAmp, sampleRate); err != nil {
+		return err
+	}
+	if sum := modFreq + modDepth; math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return errors.New("dynamics: modulation frequency or depth is not finite")
+	}
+	return nil
+}
+
+// fillAMSine fills data with an amplitude-modulated sine wave starting at time 0.
+func fillAMSine(data []SingleChannelSample, carrierFreq, modFreq, carrierAmp, modDepth float64, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		t := float64(i) * timeStep
+		envelope := carrierAmp * (1 + modDepth*math.Sin(2*math.Pi*modFreq*t))
+		data[i] = SingleChannelSample{Time: t, Value: envelope * math.Sin(2*math.Pi*carrierFreq*t)}
+	}
+}
+
+// GenerateFromFunc generates a signal from a function of time, on the same
+// timebase as GenerateSineWave. Invalid parameters yield an empty slice; see
+// GenerateFromFuncE.
+//
+// Parameters:
+//   - f: The function giving the value at each time in seconds
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated signal
+func GenerateFromFunc(f func(t float64) float64, duration float64, sampleRate int) []SingleChannelSample {
+	data, err := GenerateFromFuncE(f, duration, sampleRate)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateFromFuncE generates a signal from a function of time, reporting
+// invalid parameters. f is called once for each sample, in time order, with
+// the sample's time, and the samples are timed as GenerateSineWaveE times
+// them, so the last is one step short of duration.
+//
+// Parameters:
+//   - f: The function giving the value at each time in seconds
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated signal
+//   - error: An error if f is nil, sampleRate is not positive, or duration is
+//     negative or not finite
+func GenerateFromFuncE(f func(t float64) float64, duration float64, sampleRate int) ([]SingleChannelSample, error) {
+	if f == nil {
+		return nil, errors.New("dynamics: signal function is nil")
+	}
+	if sampleRate <= 0 {
+		return nil, errors.New("dynamics: sample rate must be positive")
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillFromFunc(data, f, sampleRate)
+	return data, nil
+}
+
+// GenerateFromFuncInto samples a function of time into dst, overwriting every
+// sample, and returns dst. The signal is the same, sample for sample, as the
+// one GenerateFromFunc produces for len(dst) samples, and nothing is allocated
+// beyond what f allocates. A nil f or a sample rate that is not positive
+// yields dst[:0]; see GenerateFromFuncE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - f: The signal as a function of time in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: dst holding the sampled signal
+func GenerateFromFuncInto(dst []SingleChannelSample, f func(t float64) float64, sampleRate int) []SingleChannelSample {
+	if f == nil || sampleRate <= 0 {
+		return dst[:0]
+	}
+	fillFromFunc(dst, f, sampleRate)
+	return dst
+}
+
+// fillFromFunc fills data with f sampled from time 0.
+func fillFromFunc(data []SingleChannelSample, f func(t float64) float64, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		t := float64(i) * timeStep
+		data[i] = SingleChannelSample{Time: t, Value: f(t)}
+	}
+}
+
+// Tone is one sinusoid of a GenerateMultiTone signal, A·sin(2πft + φ).
+type Tone struct {
+	Frequency float64 `json:"frequency"` // in Hz
+	Amplitude float64 `json:"amplitude"`
+	Phase     float64 `json:"phase"` // at time 0, in radians
+}
+
+// GenerateMultiTone generates the sum of several sinusoids, on the same
+// timebase as GenerateSineWave. Invalid parameters yield an empty slice; see
+// GenerateMultiToneE.
+//
+// Parameters:
+//   - tones: The sinusoids to sum
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated signal
+func GenerateMultiTone(tones []Tone, duration float64, sampleRate int) []SingleChannelSample {
+	data, err := GenerateMultiToneE(tones, duration, sampleRate)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateMultiToneE generates the sum of several sinusoids, reporting invalid
+// parameters. Each sample is the sum of every tone evaluated at its time, so
+// the tones share one timebase, the one GenerateSineWaveE uses. No tones give
+// a signal of zeros.
+//
+// Parameters:
+//   - tones: The sinusoids to sum
+//   - duration: The duration of the generated signal in seconds
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated signal
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or a tone's frequency, amplitude or phase is not finite
+func GenerateMultiToneE(tones []Tone, duration float64, sampleRate int) ([]SingleChannelSample, error) {
+	if err := checkTones(tones, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillMultiTone(data, tones, sampleRate)
+	return data, nil
+}
+
+// GenerateMultiToneInto generates a sum of sine waves into dst, overwriting
+// every sample, and returns dst. The signal is the same, sample for sample, as
+// the one GenerateMultiTone produces for len(dst) samples, but nothing is
+// allocated. Invalid parameters yield dst[:0]; see GenerateMultiToneE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - tones: The sine waves to add together
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - []Sample: dst holding the generated signal
+func GenerateMultiToneInto(dst []SingleChannelSample, tones []Tone, sampleRate int) []SingleChannelSample {
+	if checkTones(tones, sampleRate) != nil {
+		return dst[:0]
+	}
+	fillMultiTone(dst, tones, sampleRate)
+	return dst
+}
+
+// checkTones validates the tones and sample rate of a multi-tone signal.
+func checkTones(tones []Tone, sampleRate int) error {
+	if sampleRate <= 0 {
+		return errors.New("dynamics: sample rate must be positive")
+	}
+	for i, tone := range tones {
+		if sum := tone.Frequency + tone.Amplitude + tone.Phase; math.IsNaN(sum) || math.IsInf(sum, 0) {
+			return fmt.Errorf("dynamics: tone %d is not finite", i)
+		}
+	}
+	return nil
+}
+
+// fillMultiTone fills data with the sum of the tones starting at time 0.
+func fillMultiTone(data []SingleChannelSample, tones []Tone, sampleRate int) {
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		t := float64(i) * timeStep
+		var value float64
+		for _, tone := range tones {
+			value += tone.Amplitude * math.Sin(2*math.Pi*tone.Frequency*t+tone.Phase)
+		}
+		data[i] = SingleChannelSample{Time: t, Value: value}
+	}
+}
+
+// NoiseOption configures a call to GenerateWhiteNoise or GenerateWhiteNoiseE.
+type NoiseOption func(*noiseConfig)
+
+// noiseConfig holds the settings made by NoiseOptions.
+type noiseConfig struct {
+	gaussian bool
+}
+
+// WithGaussian makes the noise normally distributed with a standard deviation
+// of the amplitude, in place of uniformly distributed.
+func WithGaussian() NoiseOption {
+	return func(c *noiseConfig) {
+		c.gaussian = true
+	}
+}
+
+// GenerateWhiteNoise generates white noise with the specified parameters, on
+// the same timebase as GenerateSineWave. Invalid parameters yield an empty
+// slice; see GenerateWhiteNoiseE.
+//
+// Parameters:
+//   - amplitude: The amplitude of the noise
+//   - duration: The duration of the generated noise in seconds
+//   - sampleRate: The number of samples per second
+//   - seed: The seed of the random source
+//   - opts: Options such as WithGaussian
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated noise
+func GenerateWhiteNoise(amplitude, duration float64, sampleRate int, seed int64, opts ...NoiseOption) []SingleChannelSample {
+	data, err := GenerateWhiteNoiseE(amplitude, duration, sampleRate, seed, opts...)
+	if err != nil {
+		return []SingleChannelSample{}
+	}
+	return data
+}
+
+// GenerateWhiteNoiseE generates white noise with the specified parameters,
+// reporting invalid ones.
+//
+// The values are uniformly distributed between -amplitude and +amplitude, so
+// their RMS is amplitude/√3, or with WithGaussian normally distributed about
+// zero with a standard deviation, and so an RMS, of amplitude. They are drawn
+// from a source of their own seeded with seed, so the same seed always gives
+// the same noise and concurrent calls do not disturb one another. The samples
+// are timed as GenerateSineWaveE times them.
+//
+// Parameters:
+//   - amplitude: The amplitude of the noise
+//   - duration: The duration of the generated noise in seconds
+//   - sampleRate: The number of samples per second
+//   - seed: The seed of the random source
+//   - opts: Options such as WithGaussian
+//
+// Returns:
+//   - []Sample: A slice of Sample structs representing the generated noise
+//   - error: An error if sampleRate is not positive, duration is negative or not
+//     finite, or amplitude is not finite
+func GenerateWhiteNoiseE(amplitude, duration float64, sampleRate int, seed int64, opts ...NoiseOption) ([]SingleChannelSample, error) {
+	if err := checkSineWave(0, amplitude, sampleRate); err != nil {
+		return nil, err
+	}
+	if !(duration >= 0) || math.IsInf(duration, 1) {
+		return nil, errors.New("dynamics: duration must be a finite, non-negative number")
+	}
+	data := make([]SingleChannelSample, sampleCount(duration, sampleRate))
+	fillWhiteNoise(data, amplitude, sampleRate, seed, opts)
+	return data, nil
+}
+
+// GenerateWhiteNoiseInto generates white noise into dst, overwriting every
+// sample, and returns dst. The noise is the same, sample for sample, as the
+// one GenerateWhiteNoise produces for len(dst) samples from the same seed.
+// The samples themselves are not allocated, though seeding the random source
+// is. Invalid parameters yield dst[:0]; see GenerateWhiteNoiseE.
+//
+// Parameters:
+//   - dst: The buffer to fill; its length sets the number of samples
+//   - amplitude: The amplitude of the noise
+//   - sampleRate: The number of samples per second
+//   - seed: The seed of the random source
+//   - opts: Options such as WithGaussian
+//
+// Returns:
+//   - []Sample: dst holding the generated noise
+func GenerateWhiteNoiseInto(dst []SingleChannelSample, amplitude float64, sampleRate int, seed int64, opts ...NoiseOption) []SingleChannelSample {
+	if checkSineWave(0, amplitude, sampleRate) != nil {
+		return dst[:0]
+	}
+	fillWhiteNoise(dst, amplitude, sampleRate, seed, opts)
+	return dst
+}
+
+// fillWhiteNoise fills data with white noise starting at time 0.
+func fillWhiteNoise(data []SingleChannelSample, amplitude float64, sampleRate int, seed int64, opts []NoiseOption) {
+	var config noiseConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	timeStep := 1.0 / float64(sampleRate)
+	for i := range data {
+		var value float64
+		if config.gaussian {
+			value = amplitude * rng.NormFloat64()
+		} else {
+			value = amplitude * (2*rng.Float64() - 1)
+		}
+		data[i] = SingleChannelSample{Time: float64(i) * timeStep, Value: value}
+	}
+}
+
+// AddNoise returns a copy of the data with Gaussian noise added at the given
+// signal-to-noise ratio, leaving the input untouched. Where the noise cannot
+// be scaled, because the data is empty, silent or not finite or snrDB is not
+// finite, the copy is returned without noise; see AddNoiseE.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the signal
+//   - snrDB: The ratio of the signal's power to the noise's, in dB
+//   - seed: The seed of the random source
+//
+// Returns:
+//   - []SingleChannelSample: The noisy copy
+func AddNoise(data []SingleChannelSample, snrDB float64, seed int64) []SingleChannelSample {
+	noisy, err := AddNoiseE(data, snrDB, seed)
+	if err != nil {
+		return append([]SingleChannelSample(nil), data...)
+	}
+	return noisy
+}
+
+// AddNoiseE returns a copy of the data with Gaussian noise added at the given
+// signal-to-noise ratio, reporting why the noise cannot be scaled. The noise
+// has a standard deviation of the data's RMS divided by 10^(snrDB/20), so a
+// DC level counts as signal, and is drawn from a source of its own seeded with
+// seed, as GenerateWhiteNoise draws it.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the signal
+//   - snrDB: The ratio of the signal's power to the noise's, in dB
+//   - seed: The seed of the random source
+//
+// Returns:
+//   - []SingleChannelSample: The noisy copy
+//   - error: ErrEmptyData if there is no data, an error wrapping ErrNonFinite
+//     if the data's RMS is not finite, or an error if the RMS is zero or
+//     snrDB is not finite
+func AddNoiseE(data []SingleChannelSample, snrDB float64, seed int64) ([]SingleChannelSample, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyData
+	}
+	if math.IsNaN(snrDB) || math.IsInf(snrDB, 0) {
+		return nil, errors.New("dynamics: signal-to-noise ratio is not finite")
+	}
+	rms := calculateRMS(data)
+	if math.IsNaN(rms) || math.IsInf(rms, 0) {
+		return nil, fmt.Errorf("%w: signal RMS is %g", ErrNonFinite, rms)
+	}
+	if rms == 0 {
+		return nil, errors.New("dynamics: cannot scale noise to a silent signal")
+	}
+
+	sigma := rms / math.Pow(10, snrDB/20)
+	rng := rand.New(rand.NewSource(seed))
+	noisy := make([]SingleChannelSample, len(data))
+	for i, sample := range data {
+		noisy[i] = SingleChannelSample{Time: sample.Time, Value: sample.Value + sigma*rng.NormFloat64()}
+	}
+	return noisy, nil
+}
+
+// wavePhase returns the phase of sample i of a wave starting shift cycles
+// into its cycle, as a fraction of a cycle from 0 to 1. It is taken from the
+// sample index rather than the time, so that an edge falling on a sample is
+// not moved by rounding in the time, and a phase a rounding error short of a
+// whole cycle is taken as the whole cycle.
+func wavePhase(frequency, shift float64, i, sampleRate int) float64 {
+	cycles := frequency*float64(i)/float64(sampleRate) + shift
+	phase := cycles - math.Floor(cycles)
+	if 1-phase < 1e-9 {
+		return 0
+	}
+	return phase
+}
+
+// squareShape returns the unit square wave at a phase from 0 to 1, high for
+// the first dutyCycle of the cycle.
+func squareShape(phase, dutyCycle float64) float64 {
+	if phase < dutyCycle-1e-9 || dutyCycle == 1 {
+		return 1
+	}
+	return -1
+}
+
+// triangleShape returns the unit triangle wave at a phase from 0 to 1, rising
+// from zero at the start of the cycle.
+func triangleShape(phase float64) float64 {
+	switch {
+	case phase < 0.25:
+		return 4 * phase
+	case phase < 0.75:
+		return 2 - 4*phase
+	default:
+		return 4*phase - 4
+	}
+}
+
+// sawtoothShape returns the unit sawtooth wave at a phase from 0 to 1, rising
+// from -1 at the start of the cycle.
+func sawtoothShape(phase float64) float64 {
+	return 2*phase - 1
+}
+
+// KeepXSecondsOfData keeps the last X seconds of data from the given slice.
+// The data must be in time order; out-of-order data yields a wrong window
+// without warning, so use KeepXSecondsOfDataE when the order is not certain.
+//
+// The result is a sub-slice of fastDataArray and shares its backing array:
+// writing to its elements changes the input, and since the result ends where
+// the input ends, appending to it may overwrite the input's spare capacity.
+// Use KeepXSecondsOfDataCopy for an independent slice.
+//
+// Parameters:
+//   - fastDataArray: A slice of Sample structs containing time and value data
+//   - seconds: The number of seconds of data to keep
+//
+// Returns:
+//   - []Sample: A slice of Sample structs containing the last X seconds of data
 func KeepXSecondsOfData(fastDataArray []SingleChannelSample, seconds float64) []SingleChannelSample {
 	if len(fastDataArray) == 0 {
 		return fastDataArray
@@ -337,3 +3049,37 @@ func KeepXSecondsOfData(fastDataArray []SingleChannelSample, seconds float64) []
 	// if the cutoff is not found, return an empty array
 	return []SingleChannelSample{}
 }
+
+// KeepXSecondsOfDataE keeps the last X seconds of data from the given slice,
+// checking that the data is in time order. Like KeepXSecondsOfData, it returns
+// a sub-slice sharing the input's backing array.
+//
+// Parameters:
+//   - fastDataArray: A slice of Sample structs containing time and value data
+//   - seconds: The number of seconds of data to keep
+//
+// Returns:
+//   - []Sample: A slice of Sample structs containing the last X seconds of data
+//   - error: ErrUnsortedData if a sample is earlier than the one before it
+func KeepXSecondsOfDataE(fastDataArray []SingleChannelSample, seconds float64) ([]SingleChannelSample, error) {
+	if err := checkTimeOrder(fastDataArray); err != nil {
+		return nil, err
+	}
+	return KeepXSecondsOfData(fastDataArray, seconds), nil
+}
+
+// KeepXSecondsOfDataCopy keeps the last X seconds of data from the given slice,
+// returning a copy that shares no memory with the input.
+//
+// Parameters:
+//   - fastDataArray: A slice of Sample structs containing time and value data
+//   - seconds: The number of seconds of data to keep
+//
+// Returns:
+//   - []Sample: A new slice of Sample structs containing the last X seconds of data
+func KeepXSecondsOfDataCopy(fastDataArray []SingleChannelSample, seconds float64) []SingleChannelSample {
+	kept := KeepXSecondsOfData(fastDataArray, seconds)
+	result := make([]SingleChannelSample, len(kept))
+	copy(result, kept)
+	return result
+}
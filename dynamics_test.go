@@ -1,8 +1,13 @@
 package dynamics
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -50,6 +55,407 @@ func TestGenerateSineWave(t *testing.T) {
 	}
 }
 
+func TestPeakToPeak(t *testing.T) {
+	// Generate sample data: 50 Hz at 1 kHz puts samples on both peaks
+	plain := GenerateSineWave(50, 2.5, 1, 1000)
+	offset := GenerateSineWave(50, 2.5, 1, 1000, WithDCOffset(7))
+
+	// Run the test
+	if p2p := PeakToPeak(plain); math.Abs(p2p-5) > 1e-9 {
+		t.Errorf("PeakToPeak %v, expected 5", p2p)
+	}
+	if p2p := PeakToPeak(offset); math.Abs(p2p-5) > 1e-9 {
+		t.Errorf("PeakToPeak %v with a DC offset, expected 5", p2p)
+	}
+	if p2p := PeakToPeak(nil); p2p != 0 {
+		t.Errorf("PeakToPeak %v of no data, expected 0", p2p)
+	}
+	if p2p := PeakToPeak(offset[:1]); p2p != 0 {
+		t.Errorf("PeakToPeak %v of one sample, expected 0", p2p)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { PeakToPeak(offset) }); allocs != 0 {
+		t.Errorf("PeakToPeak allocated %v times per call, expected 0", allocs)
+	}
+
+	// the buffer wraps, holding the last 30 samples across the end of its storage
+	cb := NewCircularBuffer(30)
+	for _, sample := range offset[:45] {
+		cb.Update(sample)
+	}
+	if p2p, want := cb.GetBufferPeakToPeak(), PeakToPeak(offset[15:45]); p2p != want {
+		t.Errorf("GetBufferPeakToPeak %v, expected %v", p2p, want)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { cb.GetBufferPeakToPeak() }); allocs != 0 {
+		t.Errorf("GetBufferPeakToPeak allocated %v times per call, expected 0", allocs)
+	}
+	if p2p := NewCircularBuffer(4).GetBufferPeakToPeak(); p2p != 0 {
+		t.Errorf("GetBufferPeakToPeak %v of an empty buffer, expected 0", p2p)
+	}
+}
+
+func TestCrestFactor(t *testing.T) {
+	// Generate sample data: a spike of 10 on a 0.01 hum, 1000 samples long
+	spike := GenerateSineWave(50, 0.01, 1, 1000)
+	spike[500].Value = 10
+
+	cases := []struct {
+		name     string
+		data     []SingleChannelSample
+		expected float64
+	}{
+		{"sine", GenerateSineWave(50, 2, 1, 1000), math.Sqrt2},
+		{"square", GenerateSquareWave(50, 2, 1, 1000, 0.5), 1},
+		{"spike", spike, 10 / math.Sqrt((100+0.01*0.01*1000/2)/1000)}, // the spike replaces a zero of the hum
+		{"silence", make([]SingleChannelSample, 100), 0},
+		{"empty", nil, 0},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		if cf := CrestFactor(c.data); math.Abs(cf-c.expected) > 1e-6 {
+			t.Errorf("%s: CrestFactor %v, expected %v", c.name, cf, c.expected)
+		}
+	}
+
+	result, err := AnalyzeDetailed(GenerateSineWave(50, 2, 1, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the RMS covers the whole cycles the crossings measure, not quite every sample
+	if math.Abs(result.CrestFactor-math.Sqrt2) > 1e-3 || result.CrestFactor != result.Peak/result.RMS {
+		t.Errorf("AnalyzeDetailed CrestFactor %v, expected Peak/RMS, about √2", result.CrestFactor)
+	}
+}
+
+func TestVariance(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		expected float64
+	}{
+		{"known", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 32.0 / 7},
+		{"pair", []float64{1, 3}, 2},
+		{"constant", []float64{3, 3, 3}, 0},
+		{"one", []float64{5}, 0},
+		{"empty", nil, 0},
+		// the deviations are lost to cancellation by Σx² − (Σx)²/n
+		{"offset", []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}, 30},
+		{"tiny", []float64{1e9 + 0x1p-10, 1e9 - 0x1p-10, 1e9 + 0x1p-10, 1e9 - 0x1p-10}, 4 * 0x1p-20 / 3},
+	}
+
+	for _, c := range cases {
+		// Generate sample data
+		data := make([]SingleChannelSample, len(c.values))
+		for i, v := range c.values {
+			data[i] = SingleChannelSample{Time: float64(i), Value: v}
+		}
+
+		// Run the test
+		if v := Variance(data); math.Abs(v-c.expected) > 1e-9*math.Max(c.expected, 1e-6) {
+			t.Errorf("%s: Variance %v, expected %v", c.name, v, c.expected)
+		}
+		if sd := StdDev(data); math.Abs(sd-math.Sqrt(c.expected)) > 1e-9*math.Max(math.Sqrt(c.expected), 1e-3) {
+			t.Errorf("%s: StdDev %v, expected %v", c.name, sd, math.Sqrt(c.expected))
+		}
+	}
+
+	// a 1 V sine has a standard deviation of 1/√2 whatever its offset
+	sine := GenerateSineWave(50, 1, 1, 1000, WithDCOffset(1e9))
+	if sd := StdDev(sine); math.Abs(sd-1/math.Sqrt2) > 1e-3 {
+		t.Errorf("StdDev %v of a sine on an offset of 1e9, expected %v", sd, 1/math.Sqrt2)
+	}
+
+	// the buffer wraps, holding the last 30 samples across the end of its storage
+	cb := NewCircularBuffer(30)
+	if v := cb.GetBufferVariance(); v != 0 {
+		t.Errorf("GetBufferVariance %v of an empty buffer, expected 0", v)
+	}
+	for _, sample := range sine[:45] {
+		cb.Update(sample)
+	}
+	if v, want := cb.GetBufferVariance(), Variance(sine[15:45]); v != want {
+		t.Errorf("GetBufferVariance %v, expected %v", v, want)
+	}
+	if sd, want := cb.GetBufferStdDev(), StdDev(sine[15:45]); sd != want {
+		t.Errorf("GetBufferStdDev %v, expected %v", sd, want)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { cb.GetBufferStdDev() }); allocs != 0 {
+		t.Errorf("GetBufferStdDev allocated %v times per call, expected 0", allocs)
+	}
+}
+
+func TestSkewnessKurtosis(t *testing.T) {
+	// Generate sample data
+	noise := GenerateWhiteNoise(1, 10, 10000, 1, WithGaussian())
+	sine := GenerateSineWave(50, 1, 1, 1000, WithDCOffset(1e6))
+	spike := GenerateSineWave(50, 0.01, 1, 1000)
+	spike[500].Value = 10
+	skewed := []SingleChannelSample{{Time: 0, Value: 0}, {Time: 1, Value: 0}, {Time: 2, Value: 0}, {Time: 3, Value: 1}}
+
+	cases := []struct {
+		name      string
+		data      []SingleChannelSample
+		skewness  float64
+		kurtosis  float64
+		tolerance float64
+	}{
+		{"gaussian", noise, 0, 0, 0.1},
+		{"sine", sine, 0, -1.5, 1e-6},
+		{"spike", spike, 31.5, 995, 1},
+		{"skewed", skewed, 2 / math.Sqrt(3), -2.0 / 3, 1e-12},
+		{"constant", make([]SingleChannelSample, 100), 0, 0, 0},
+		{"three", sine[:3], 0, 0, 0},
+		{"empty", nil, 0, 0, 0},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		if s := Skewness(c.data); math.Abs(s-c.skewness) > c.tolerance {
+			t.Errorf("%s: Skewness %v, expected %v", c.name, s, c.skewness)
+		}
+		if k := Kurtosis(c.data); math.Abs(k-c.kurtosis) > c.tolerance {
+			t.Errorf("%s: Kurtosis %v, expected %v", c.name, k, c.kurtosis)
+		}
+	}
+
+	// the buffer wraps, holding the last 300 samples across the end of its storage
+	cb := NewCircularBuffer(300)
+	for _, sample := range spike[300:750] {
+		cb.Update(sample)
+	}
+	if s, want := cb.GetBufferSkewness(), Skewness(spike[450:750]); s != want {
+		t.Errorf("GetBufferSkewness %v, expected %v", s, want)
+	}
+	if k, want := cb.GetBufferKurtosis(), Kurtosis(spike[450:750]); k != want {
+		t.Errorf("GetBufferKurtosis %v, expected %v", k, want)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { cb.GetBufferKurtosis() }); allocs != 0 {
+		t.Errorf("GetBufferKurtosis allocated %v times per call, expected 0", allocs)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		p        float64
+		expected float64
+	}{
+		{"odd median", []float64{5, 1, 3}, 50, 3},
+		{"even median", []float64{4, 1, 3, 2}, 50, 2.5},
+		{"odd min", []float64{5, 1, 3}, 0, 1},
+		{"odd max", []float64{5, 1, 3}, 100, 5},
+		{"even interpolated", []float64{4, 1, 3, 2}, 25, 1.75},
+		{"duplicates", []float64{2, 7, 2, 2, 9}, 50, 2},
+		{"duplicates interpolated", []float64{2, 7, 2, 2, 9}, 62.5, 4.5},
+		{"one", []float64{6}, 90, 6},
+		{"empty", nil, 50, 0},
+		{"below range", []float64{1, 2}, -1, math.NaN()},
+		{"above range", []float64{1, 2}, 100.5, math.NaN()},
+		{"NaN p", []float64{1, 2}, math.NaN(), math.NaN()},
+	}
+
+	for _, c := range cases {
+		// Generate sample data
+		data := make([]SingleChannelSample, len(c.values))
+		for i, v := range c.values {
+			data[i] = SingleChannelSample{Time: float64(i), Value: v}
+		}
+		original := append([]SingleChannelSample(nil), data...)
+
+		// Run the test
+		got := Percentile(data, c.p)
+		if math.IsNaN(c.expected) != math.IsNaN(got) || !math.IsNaN(c.expected) && got != c.expected {
+			t.Errorf("%s: Percentile %v at %v, expected %v", c.name, got, c.p, c.expected)
+		}
+		if c.p == 50 {
+			if median := Median(data); median != c.expected {
+				t.Errorf("%s: Median %v, expected %v", c.name, median, c.expected)
+			}
+		}
+		for i := range data {
+			if data[i] != original[i] {
+				t.Fatalf("%s: Percentile changed sample %d of its input from %v to %v", c.name, i, original[i], data[i])
+			}
+		}
+	}
+
+	// a few spikes move the mean but not the median
+	data := GenerateSineWave(50, 1, 1, 1000, WithDCOffset(2))
+	for i := 0; i < len(data); i += 100 {
+		data[i].Value = 1000
+	}
+	if median := Median(data); math.Abs(median-2) > 0.05 {
+		t.Errorf("Median %v of a spiky signal, expected about 2", median)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		min, max int // indices of the expected extremes
+	}{
+		{"ties", []float64{1, 3, -2, 3, -2, 0}, 2, 1},
+		{"single", []float64{4}, 0, 0},
+		{"rising", []float64{-3, -1, 0, 2, 5}, 0, 4},
+		{"falling", []float64{5, 2, 0, -1, -3}, 4, 0},
+		{"constant", []float64{7, 7, 7}, 0, 0},
+		{"NaN first", []float64{math.NaN(), 2, 1, 3}, 2, 3},
+	}
+
+	for _, c := range cases {
+		// Generate sample data
+		data := make([]SingleChannelSample, len(c.values))
+		for i, v := range c.values {
+			data[i] = SingleChannelSample{Time: 0.5 * float64(i), Value: v}
+		}
+
+		// Run the test
+		low, high, err := MinMax(data)
+		if err != nil {
+			t.Fatalf("%s: MinMax returned error: %v", c.name, err)
+		}
+		if low != data[c.min] {
+			t.Errorf("%s: MinMax min %+v, expected %+v", c.name, low, data[c.min])
+		}
+		if high != data[c.max] {
+			t.Errorf("%s: MinMax max %+v, expected %+v", c.name, high, data[c.max])
+		}
+	}
+
+	if _, _, err := MinMax(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("MinMax of no data returned %v, expected ErrEmptyData", err)
+	}
+
+	// the buffer wraps, holding the last 30 samples across the end of its storage
+	sine := GenerateSineWave(50, 1, 1, 1000, WithPhase(0.3))
+	cb := NewCircularBuffer(30)
+	if _, _, err := cb.MinMax(); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("MinMax of an empty buffer returned %v, expected ErrEmptyData", err)
+	}
+	for _, sample := range sine[:45] {
+		cb.Update(sample)
+	}
+	low, high, err := cb.MinMax()
+	wantLow, wantHigh, _ := MinMax(sine[15:45])
+	if err != nil || low != wantLow || high != wantHigh {
+		t.Errorf("CircularBuffer.MinMax returned %+v, %+v, %v; expected %+v, %+v", low, high, err, wantLow, wantHigh)
+	}
+}
+
+func TestZeroCrossingRateHysteresis(t *testing.T) {
+	// Generate sample data: a 100 Hz sine at 100 kHz with white noise at 20 dB
+	// SNR, an RMS of 0.07, which chatters about each crossing
+	clean := GenerateSineWave(100, 1, 1, 100000)
+	noisy := AddNoise(clean, 20, 1)
+
+	// Run the test
+	if zcr := ZeroCrossingRate(noisy); zcr < 1000 {
+		t.Errorf("ZeroCrossingRate %v of the noisy sine, expected thousands", zcr)
+	}
+	if zcr := ZeroCrossingRateHysteresis(noisy, 0.4); math.Abs(zcr-200) > 2 {
+		t.Errorf("ZeroCrossingRateHysteresis %v of the noisy sine, expected 200", zcr)
+	}
+	if nzcr := NegativeZeroCrossingRateHysteresis(noisy, 0.4); math.Abs(nzcr-100) > 1 {
+		t.Errorf("NegativeZeroCrossingRateHysteresis %v of the noisy sine, expected 100", nzcr)
+	}
+	if zcr := ZeroCrossingRateHysteresis(noisy, -0.4); zcr != ZeroCrossingRateHysteresis(noisy, 0.4) {
+		t.Errorf("ZeroCrossingRateHysteresis %v with a negative threshold, expected its magnitude to be used", zcr)
+	}
+
+	// a threshold of 0 reproduces the plain counters, zeros included
+	zeros := []SingleChannelSample{{0, 0}, {1, 1}, {2, 0}, {3, -1}, {4, 0}, {5, 0}, {6, 1}, {7, 0}, {8, 1}}
+	for name, data := range map[string][]SingleChannelSample{"noisy": noisy, "clean": clean, "zeros": zeros} {
+		if zcr, want := ZeroCrossingRateHysteresis(data, 0), ZeroCrossingRate(data); zcr != want {
+			t.Errorf("%s: ZeroCrossingRateHysteresis %v with no threshold, expected %v", name, zcr, want)
+		}
+		if nzcr, want := NegativeZeroCrossingRateHysteresis(data, 0), NegativeZeroCrossingRate(data); nzcr != want {
+			t.Errorf("%s: NegativeZeroCrossingRateHysteresis %v with no threshold, expected %v", name, nzcr, want)
+		}
+	}
+
+	if zcr := ZeroCrossingRateHysteresis(nil, 0.1); zcr != 0 {
+		t.Errorf("ZeroCrossingRateHysteresis %v of no data, expected 0", zcr)
+	}
+}
+
+func TestFormFactor(t *testing.T) {
+	cases := []struct {
+		name       string
+		data       []SingleChannelSample
+		arv        float64
+		formFactor float64
+	}{
+		{"sine", GenerateSineWave(50, 2, 1, 10000), 4 / math.Pi, math.Pi / (2 * math.Sqrt2)},
+		{"square", GenerateSquareWave(50, 2, 1, 10000, 0.5), 2, 1},
+		{"triangle", GenerateTriangleWave(50, 2, 1, 10000), 1, 2 / math.Sqrt(3)},
+		{"silence", make([]SingleChannelSample, 100), 0, 0},
+		{"empty", nil, 0, 0},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		if arv := AverageRectified(c.data); math.Abs(arv-c.arv) > 1e-3*math.Max(c.arv, 1) {
+			t.Errorf("%s: AverageRectified %v, expected %v", c.name, arv, c.arv)
+		}
+		if ff := FormFactor(c.data, 50); math.Abs(ff-c.formFactor) > 1e-3 {
+			t.Errorf("%s: FormFactor %v, expected %v", c.name, ff, c.formFactor)
+		}
+	}
+
+	// the form factor is taken over the same whole cycles as RMS
+	data := GenerateSineWave(50, 2, 1.013, 10000)
+	window := KeepXSecondsOfData(data, 1)
+	if ff, want := FormFactor(data, 50), RMS(data, 50)/AverageRectified(window); ff != want {
+		t.Errorf("FormFactor %v, expected RMS over ARV of the last whole cycles, %v", ff, want)
+	}
+	if ff := FormFactor(data, 0); ff != 0 {
+		t.Errorf("FormFactor %v with a zero frequency, expected 0", ff)
+	}
+}
+
+func TestMeanRemoveDC(t *testing.T) {
+	// Generate sample data: a 1 V, 50 Hz sine riding on a 5 V offset
+	data := GenerateSineWave(50, 1, 1, 1000, WithDCOffset(5))
+	original := append([]SingleChannelSample(nil), data...)
+
+	// Run the test
+	if mean := Mean(data); math.Abs(mean-5) > 1e-9 {
+		t.Errorf("Mean %v, expected 5", mean)
+	}
+	if mean := Mean(nil); mean != 0 {
+		t.Errorf("Mean %v of no data, expected 0", mean)
+	}
+
+	// the offset swamps the RMS and hides every crossing
+	if rms := calculateRMS(data); math.Abs(rms-math.Sqrt(25.5)) > 1e-6 {
+		t.Errorf("RMS %v with the offset, expected %v", rms, math.Sqrt(25.5))
+	}
+	if zcr := ZeroCrossingRate(data); zcr != 0 {
+		t.Errorf("ZeroCrossingRate %v with the offset, expected 0", zcr)
+	}
+
+	ac := RemoveDC(data)
+	if mean := Mean(ac); math.Abs(mean) > 1e-9 {
+		t.Errorf("Mean %v after RemoveDC, expected 0", mean)
+	}
+	if rms := calculateRMS(ac); math.Abs(rms-1/math.Sqrt2) > 1e-6 {
+		t.Errorf("RMS %v after RemoveDC, expected %v", rms, 1/math.Sqrt2)
+	}
+	if zcr := ZeroCrossingRate(ac); math.Abs(zcr-100) > 1 {
+		t.Errorf("ZeroCrossingRate %v after RemoveDC, expected about 100", zcr)
+	}
+	for i := range data {
+		if data[i] != original[i] {
+			t.Fatalf("RemoveDC changed sample %d of its input from %v to %v", i, original[i], data[i])
+		}
+	}
+	if ac := RemoveDC(nil); ac != nil {
+		t.Errorf("RemoveDC of no data returned %v, expected nil", ac)
+	}
+}
+
 func TestRMS(t *testing.T) {
 	// Generate sample data
 	frequency := 200.0
@@ -128,8 +534,6 @@ func TestAnalyzeMultiChannel(t *testing.T) {
 	// Run the test
 	rms, zcr := AnalyzeMultiChannel(data)
 
-	fmt.Printf("len(rms): %d, len(zcr): %d\n", len(rms), len(zcr))
-
 	expectedRMS := []float64{0.7071, 1.4144}
 	expectedZCR := []float64{440.0, 150.0}
 	toleranceRMS := 0.0001
@@ -146,60 +550,1717 @@ func TestAnalyzeMultiChannel(t *testing.T) {
 	}
 }
 
-// BENCHMARKS
+func TestAnalyzeMultiChannelMatchesSingleChannel(t *testing.T) {
+	// Generate sample data: channels at different frequencies, with a slowly
+	// varying amplitude, a constant channel and non-finite values in some channels
+	const channels = 6
+	data := make([]MultiChannelSample, 3000)
+	for i := range data {
+		tm := float64(i) / 2000
+		values := make([]float64, channels)
+		for c := range 4 {
+			values[c] = (1 + tm) * math.Sin(2*math.Pi*float64(37+61*c)*tm+float64(c))
+		}
+		values[4] = 0.5
+		values[5] = math.Sin(2 * math.Pi * 3 * tm)
+		data[i] = MultiChannelSample{Time: tm, Value: values}
+	}
+	data[700].Value[2] = math.NaN()
+	data[2999].Value[3] = math.Inf(1)
+	data[0].Value[5] = math.Inf(-1)
 
-func BenchmarkGenerateSineWave(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		GenerateSineWave(440, 1, 2, 1000)
+	// reference: analyse a copy of each channel on its own
+	reference := func(config analyzeConfig) (rms, zcr []float64, err error) {
+		for c := range channels {
+			single := make([]SingleChannelSample, len(data))
+			for j := range data {
+				single[j] = SingleChannelSample{Time: data[j].Time, Value: data[j].Value[c]}
+			}
+			single, err := config.prepare(single)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w in channel %d", err, c)
+			}
+			r, z, _ := analyze(single, config)
+			rms = append(rms, r)
+			zcr = append(zcr, z)
+		}
+		return rms, zcr, nil
+	}
+
+	// Run the test
+	for _, policy := range []NonFinitePolicy{NonFinitePropagate, NonFiniteStrict, NonFiniteSkip} {
+		for _, cycles := range []int{0, 1, 10, DefaultMaxCycles} {
+			for _, extra := range []struct {
+				name string
+				opts []AnalyzeOption
+			}{
+				{"no options", nil},
+				{"WithRemoveDC", []AnalyzeOption{WithRemoveDC()}},
+				{"WithACFFrequency", []AnalyzeOption{WithACFFrequency()}},
+				{"both", []AnalyzeOption{WithRemoveDC(), WithACFFrequency()}},
+			} {
+				opts := append([]AnalyzeOption{WithNonFinite(policy), WithMaxCycles(cycles)}, extra.opts...)
+				expectedRMS, expectedZCR, expectedErr := reference(newAnalyzeConfig(opts))
+				rms, zcr, err := AnalyzeMultiChannelE(data, opts...)
+
+				if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+					t.Errorf("policy %d, %d cycles, %s: AnalyzeMultiChannelE returned error %v, expected %v", policy, cycles, extra.name, err, expectedErr)
+					continue
+				}
+				for c := range rms {
+					// compare bit patterns so that NaN matches NaN
+					if math.Float64bits(rms[c]) != math.Float64bits(expectedRMS[c]) || math.Float64bits(zcr[c]) != math.Float64bits(expectedZCR[c]) {
+						t.Errorf("policy %d, %d cycles, %s, channel %d: AnalyzeMultiChannelE returned %v, %v; expected %v, %v", policy, cycles, extra.name, c, rms[c], zcr[c], expectedRMS[c], expectedZCR[c])
+					}
+				}
+			}
+		}
 	}
 }
 
-func BenchmarkRMS(b *testing.B) {
+func TestAnalyzeMultiChannelEmpty(t *testing.T) {
+	// Run the test
+	rms, zcr := AnalyzeMultiChannel(nil)
+	if rms != nil || zcr != nil {
+		t.Errorf("AnalyzeMultiChannel on empty data returned %v and %v, expected nil slices", rms, zcr)
+	}
+
+	_, _, err := AnalyzeMultiChannelE([]MultiChannelSample{})
+	if !errors.Is(err, ErrEmptyData) {
+		t.Errorf("AnalyzeMultiChannelE on empty data returned %v, expected ErrEmptyData", err)
+	}
+}
+
+func TestAnalyzeMultiChannelNoChannels(t *testing.T) {
+	data := []MultiChannelSample{{Time: 0, Value: nil}, {Time: 1, Value: []float64{}}}
+
+	// Run the test
+	rms, zcr := AnalyzeMultiChannel(data)
+	if rms != nil || zcr != nil {
+		t.Errorf("AnalyzeMultiChannel on zero channels returned %v and %v, expected nil slices", rms, zcr)
+	}
+
+	_, _, err := AnalyzeMultiChannelE(data)
+	if !errors.Is(err, ErrNoChannels) {
+		t.Errorf("AnalyzeMultiChannelE on zero channels returned %v, expected ErrNoChannels", err)
+	}
+}
+
+func TestAnalyzeMultiChannelSingleSample(t *testing.T) {
+	data := []MultiChannelSample{{Time: 0, Value: []float64{1, -2}}}
+
+	// Run the test
+	rms, zcr, err := AnalyzeMultiChannelE(data)
+	if err != nil {
+		t.Fatalf("AnalyzeMultiChannelE on a single sample returned error: %v", err)
+	}
+	if len(rms) != 2 || len(zcr) != 2 {
+		t.Fatalf("AnalyzeMultiChannelE returned %d RMS and %d NZCR values, expected 2 of each", len(rms), len(zcr))
+	}
+	// a single sample spans no time, so there is no crossing rate
+	if zcr[0] != 0 || zcr[1] != 0 {
+		t.Errorf("AnalyzeMultiChannelE on a single sample returned NZCR %v, expected zeros", zcr)
+	}
+}
+
+func TestAnalyzeMultiChannelRagged(t *testing.T) {
+	data := []MultiChannelSample{
+		{Time: 0, Value: []float64{1, 2}},
+		{Time: 0.001, Value: []float64{1, 2}},
+		{Time: 0.002, Value: []float64{1}},
+		{Time: 0.003, Value: []float64{1, 2}},
+	}
+
+	// Run the test
+	rms, zcr := AnalyzeMultiChannel(data)
+	if rms != nil || zcr != nil {
+		t.Errorf("AnalyzeMultiChannel on ragged data returned %v and %v, expected nil slices", rms, zcr)
+	}
+
+	_, _, err := AnalyzeMultiChannelE(data)
+	if !errors.Is(err, ErrChannelMismatch) {
+		t.Fatalf("AnalyzeMultiChannelE on ragged data returned %v, expected ErrChannelMismatch", err)
+	}
+	for _, want := range []string{"sample 2", "time 0.002", "has 1 channels", "expected 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("AnalyzeMultiChannelE error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	// Run the test
+	AnalyzeMultiChannel([]MultiChannelSample{{Time: 0, Value: []float64{1, 2}}, {Time: 1, Value: []float64{3, 4}}})
+	if !strings.Contains(buf.String(), "channels=2") {
+		t.Errorf("AnalyzeMultiChannel logged %q, expected the channel count", buf.String())
+	}
+
+	buf.Reset()
+	SetLogger(nil)
+	AnalyzeMultiChannel([]MultiChannelSample{{Time: 0, Value: []float64{1, 2}}, {Time: 1, Value: []float64{3, 4}}})
+	if buf.Len() != 0 {
+		t.Errorf("AnalyzeMultiChannel logged %q with no logger set", buf.String())
+	}
+}
+
+func TestCrossingRatesDegenerateDuration(t *testing.T) {
+	cases := []struct {
+		name string
+		data []SingleChannelSample
+		err  error
+	}{
+		{"empty", nil, ErrEmptyData},
+		{"single sample", []SingleChannelSample{{Time: 1, Value: 1}}, ErrZeroDuration},
+		{"identical timestamps", []SingleChannelSample{{Time: 2, Value: 1}, {Time: 2, Value: -1}, {Time: 2, Value: 1}}, ErrZeroDuration},
+		{"reversed timestamps", []SingleChannelSample{{Time: 3, Value: 1}, {Time: 2, Value: -1}, {Time: 1, Value: 1}}, ErrZeroDuration},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		for name, rate := range map[string]func([]SingleChannelSample) (float64, error){
+			"ZeroCrossingRateE":         ZeroCrossingRateE,
+			"NegativeZeroCrossingRateE": NegativeZeroCrossingRateE,
+		} {
+			value, err := rate(c.data)
+			if !errors.Is(err, c.err) || value != 0 {
+				t.Errorf("%s on %s returned %f, %v; expected 0, %v", name, c.name, value, err, c.err)
+			}
+		}
+		if value := ZeroCrossingRate(c.data); value != 0 {
+			t.Errorf("ZeroCrossingRate on %s returned %f, expected 0", c.name, value)
+		}
+		if value := NegativeZeroCrossingRate(c.data); value != 0 {
+			t.Errorf("NegativeZeroCrossingRate on %s returned %f, expected 0", c.name, value)
+		}
+
+		rms, zcr := Analyze(c.data)
+		if math.IsNaN(rms) || math.IsInf(rms, 0) || zcr != 0 {
+			t.Errorf("Analyze on %s returned %f, %f; expected a finite RMS and 0 NZCR", c.name, rms, zcr)
+		}
+	}
+}
+
+func TestRMSInvalidFrequency(t *testing.T) {
 	// Generate sample data
-	frequency := 200.0
-	data := GenerateSineWave(frequency, 1, 5, 2000)
+	data := GenerateSineWave(50, 1.25, 1, 1000)
+	expected := calculateRMS(data)
 
-	// Run the benchmark
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		RMS(data, frequency)
+	// Run the test: without a usable frequency RMS falls back to the whole window
+	for _, frequency := range []float64{-50, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if result := RMS(data, frequency); result != expected {
+			t.Errorf("RMS with frequency %f returned %f, expected %f", frequency, result, expected)
+		}
+		if result, err := RMSE(data, frequency); !errors.Is(err, ErrInvalidFrequency) || result != 0 {
+			t.Errorf("RMSE with frequency %f returned %f, %v; expected 0, ErrInvalidFrequency", frequency, result, err)
+		}
+	}
+	if result, err := RMSE(data, 0); !errors.Is(err, ErrInvalidFrequency) || result != 0 {
+		t.Errorf("RMSE with frequency 0 returned %f, %v; expected 0, ErrInvalidFrequency", result, err)
 	}
 }
 
-func BenchmarkZeroCrossingRate(b *testing.B) {
+func TestRMSWithMethod(t *testing.T) {
+	// Generate sample data: a clean 2 V sine, and the same sine clipped at ±1 V
+	clean := GenerateSineWave(50, 2, 1, 10000)
+	clipped := append([]SingleChannelSample(nil), clean...)
+	for i := range clipped {
+		clipped[i].Value = math.Max(-1, math.Min(1, clipped[i].Value))
+	}
+
+	// Run the test: the default is the average method
+	if rms, want := RMSWithMethod(clean, 50, RMSAverage), RMS(clean, 50); rms != want {
+		t.Errorf("RMSWithMethod average returned %f, expected RMS %f", rms, want)
+	}
+
+	// both methods agree on a clean sine
+	average := RMSWithMethod(clean, 50, RMSAverage)
+	peak := RMSWithMethod(clean, 50, RMSPeak)
+	if math.Abs(average-math.Sqrt2) > 1e-4 || math.Abs(peak-math.Sqrt2) > 1e-4 {
+		t.Errorf("on a clean sine RMSWithMethod returned %f average, %f peak; expected both %f", average, peak, math.Sqrt2)
+	}
+
+	// clipping flattens the peaks, so the peak method reads 1/√2 while the
+	// average method reads the true RMS, 2/3 of the time at 1 V and the rest on the sine
+	average = RMSWithMethod(clipped, 50, RMSAverage)
+	peak = RMSWithMethod(clipped, 50, RMSPeak)
+	trueRMS := math.Sqrt(2.0/3 + (4*math.Pi/6-2*math.Sin(math.Pi/3))/math.Pi)
+	if math.Abs(average-trueRMS) > 1e-3 {
+		t.Errorf("on a clipped sine RMSWithMethod average returned %f, expected %f", average, trueRMS)
+	}
+	if math.Abs(peak-1/math.Sqrt2) > 1e-9 {
+		t.Errorf("on a clipped sine RMSWithMethod peak returned %f, expected %f", peak, 1/math.Sqrt2)
+	}
+
+	if rms := RMSWithMethod(clean, 50, RMSMethod(7)); rms != 0 {
+		t.Errorf("RMSWithMethod with an unknown method returned %f, expected 0", rms)
+	}
+	if rms := RMSWithMethod(clean, math.NaN(), RMSPeak); rms != calculateRMSPeak(clean) {
+		t.Errorf("RMSWithMethod peak without a frequency returned %f, expected %f", rms, calculateRMSPeak(clean))
+	}
+}
+
+func TestRMSE(t *testing.T) {
 	// Generate sample data
-	data := GenerateSineWave(440, 1, 1, 1000)
+	data := GenerateSineWave(50, 1, 1.01, 1000)
 
-	// Run the benchmark
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		ZeroCrossingRate(data)
+	// Run the test
+	result, err := RMSE(data, 50)
+	if err != nil {
+		t.Fatalf("RMSE returned error: %v", err)
+	}
+	if expected := RMS(data, 50); result != expected {
+		t.Errorf("RMSE returned %f, expected %f", result, expected)
+	}
+
+	if _, err := RMSE(nil, 50); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("RMSE on empty data returned %v, expected ErrEmptyData", err)
+	}
+
+	data[10], data[11] = data[11], data[10]
+	if _, err := RMSE(data, 50); !errors.Is(err, ErrUnsortedData) {
+		t.Errorf("RMSE on unsorted data returned %v, expected ErrUnsortedData", err)
 	}
 }
 
-func BenchmarkNegativeZeroCrossingRate(b *testing.B) {
+func TestAnalyzeNonFiniteRate(t *testing.T) {
+	// Two samples a subnormal interval apart make the crossing rate overflow
+	data := []SingleChannelSample{{Time: 0, Value: 1}, {Time: 1e-320, Value: -1}}
+
+	// Run the test
+	rms, zcr := Analyze(data)
+	if zcr != 0 || rms != 1 {
+		t.Errorf("Analyze returned %f, %f; expected 1, 0", rms, zcr)
+	}
+}
+
+func TestKeepXSecondsOfDataE(t *testing.T) {
 	// Generate sample data
-	data := GenerateSineWave(440, 1, 1, 1000)
+	data := GenerateSineWave(50, 1, 1, 1000)
 
-	// Run the benchmark
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		NegativeZeroCrossingRate(data)
+	// Run the test: sorted data gives the same window as KeepXSecondsOfData
+	kept, err := KeepXSecondsOfDataE(data, 0.2)
+	if err != nil {
+		t.Fatalf("KeepXSecondsOfDataE on sorted data returned error: %v", err)
+	}
+	if expected := KeepXSecondsOfData(data, 0.2); len(kept) != len(expected) || kept[0] != expected[0] {
+		t.Errorf("KeepXSecondsOfDataE kept %d samples, expected %d", len(kept), len(expected))
 	}
 }
 
-func BenchmarkAnalyze(b *testing.B) {
+func TestKeepXSecondsOfDataUnsorted(t *testing.T) {
+	cases := []struct {
+		name    string
+		times   []float64
+		seconds float64
+	}{
+		// the last sample is not the latest, so the window runs past its end
+		{"swapped at end", []float64{0, 1, 2, 3, 5, 4}, 2},
+		// the scan stops at the first late sample and keeps an earlier one after it
+		{"swapped at start", []float64{0, 2, 1, 3, 4}, 2.5},
+	}
+
+	for _, c := range cases {
+		data := make([]SingleChannelSample, len(c.times))
+		for i, time := range c.times {
+			data[i] = SingleChannelSample{Time: time, Value: 1}
+		}
+		cutoff := c.times[len(c.times)-1] - c.seconds
+
+		// Run the test: the lenient function silently returns samples outside the window
+		wrong := false
+		for _, sample := range KeepXSecondsOfData(data, c.seconds) {
+			if sample.Time < cutoff || sample.Time > c.times[len(c.times)-1] {
+				wrong = true
+			}
+		}
+		if !wrong {
+			t.Errorf("KeepXSecondsOfData on %s unexpectedly returned a correct window", c.name)
+		}
+
+		if kept, err := KeepXSecondsOfDataE(data, c.seconds); !errors.Is(err, ErrUnsortedData) || kept != nil {
+			t.Errorf("KeepXSecondsOfDataE on %s returned %d samples and %v, expected ErrUnsortedData", c.name, len(kept), err)
+		}
+	}
+}
+
+func TestAnalyzeE(t *testing.T) {
 	// Generate sample data
-	data := GenerateSineWave(440, 1, 1, 1000)
+	data := GenerateSineWave(50, 1, 1, 1000)
 
-	// Run the benchmark
-	b.ResetTimer()
+	// Run the test: valid data gives the same values as Analyze
+	rms, zcr, err := AnalyzeE(data)
+	if err != nil {
+		t.Fatalf("AnalyzeE returned error: %v", err)
+	}
+	if expectedRMS, expectedZCR := Analyze(data); rms != expectedRMS || zcr != expectedZCR {
+		t.Errorf("AnalyzeE returned %f, %f; expected %f, %f", rms, zcr, expectedRMS, expectedZCR)
+	}
+
+	// a constant offset has no crossings but is not silent
+	offset := []SingleChannelSample{{Time: 0, Value: 2}, {Time: 1, Value: 2}, {Time: 2, Value: 2}}
+	if rms, zcr, err := AnalyzeE(offset); err != nil || rms != 2 || zcr != 0 {
+		t.Errorf("AnalyzeE on a constant offset returned %f, %f, %v; expected 2, 0, nil", rms, zcr, err)
+	}
+}
+
+func TestAnalyzeDetailed(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 2, 1, 1000)
+
+	// Run the test
+	result, err := AnalyzeDetailed(data)
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed returned error: %v", err)
+	}
+	rms, zcr := Analyze(data)
+	if result.RMS != rms || result.NZCR != zcr {
+		t.Errorf("AnalyzeDetailed returned %f, %f; expected %f, %f", result.RMS, result.NZCR, rms, zcr)
+	}
+	if result.Samples != 1000 || result.Time != data[999].Time || math.Abs(result.Peak-2) > 1e-9 {
+		t.Errorf("AnalyzeDetailed returned %+v, expected 1000 samples to %f with peak 2", result, data[999].Time)
+	}
+
+	if _, err := AnalyzeDetailed(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("AnalyzeDetailed on empty data returned %v, expected ErrEmptyData", err)
+	}
+}
+
+func TestAnalyzeShortWindows(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []SingleChannelSample
+		expectedRMS float64
+		expectedZCR float64
+	}{
+		{"no samples", nil, 0, 0},
+		{"one sample", []SingleChannelSample{{Time: 0, Value: 3}}, 0, 0},
+		{"two samples with a crossing", []SingleChannelSample{{Time: 0, Value: 1}, {Time: 0.001, Value: -1}}, 1, 1000},
+		{"two samples without a crossing", []SingleChannelSample{{Time: 0, Value: 2}, {Time: 0.001, Value: 2}}, 2, 0},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		rms, zcr := Analyze(c.data)
+		if diff := math.Abs(rms - c.expectedRMS); diff > 1e-12 {
+			t.Errorf("Analyze on %s returned RMS %f, expected %f (difference: %f)", c.name, rms, c.expectedRMS, diff)
+		}
+		if diff := math.Abs(zcr - c.expectedZCR); diff > 1e-9 {
+			t.Errorf("Analyze on %s returned NZCR %f, expected %f (difference: %f)", c.name, zcr, c.expectedZCR, diff)
+		}
+	}
+}
+
+func TestAnalyzeHalfCycle(t *testing.T) {
+	// Generate sample data: the positive half cycle of a 50 Hz sine, which holds no negative crossing
+	data := GenerateSineWave(50, 1, 0.01, 1000)
+
+	// Run the test: the RMS falls back to the whole window rather than 0
+	rms, zcr := Analyze(data)
+	if expected := calculateRMS(data); rms != expected || zcr != 0 {
+		t.Errorf("Analyze on a half cycle returned %f, %f; expected %f, 0", rms, zcr, expected)
+	}
+
+	result, err := AnalyzeDetailed(data)
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed on a half cycle returned error: %v", err)
+	}
+	if result.CycleAligned || result.RMS != rms || result.RMSSpan != data[len(data)-1].Time {
+		t.Errorf("AnalyzeDetailed on a half cycle returned %+v, expected an unaligned RMS %f over %f seconds", result, rms, data[len(data)-1].Time)
+	}
+
+	// a window of whole cycles is aligned
+	result, _ = AnalyzeDetailed(GenerateSineWave(50, 1, 0.1, 1000))
+	if !result.CycleAligned {
+		t.Errorf("AnalyzeDetailed on five cycles returned %+v, expected a cycle-aligned RMS", result)
+	}
+}
+
+func TestAnalyzeEErrors(t *testing.T) {
+	swapped := GenerateSineWave(50, 1, 0.1, 1000)
+	swapped[20], swapped[21] = swapped[21], swapped[20]
+
+	cases := []struct {
+		name string
+		data []SingleChannelSample
+		err  error
+	}{
+		{"empty", nil, ErrEmptyData},
+		{"single sample", []SingleChannelSample{{Time: 0, Value: 1}}, ErrZeroDuration},
+		{"identical timestamps", []SingleChannelSample{{Time: 1, Value: 1}, {Time: 1, Value: -1}}, ErrZeroDuration},
+		{"reversed timestamps", []SingleChannelSample{{Time: 1, Value: 1}, {Time: 0, Value: -1}}, ErrZeroDuration},
+		{"swapped pair", swapped, ErrUnsortedData},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		rms, zcr, err := AnalyzeE(c.data)
+		if !errors.Is(err, c.err) || rms != 0 || zcr != 0 {
+			t.Errorf("AnalyzeE on %s returned %f, %f, %v; expected 0, 0, %v", c.name, rms, zcr, err, c.err)
+		}
+		if _, err := ZeroCrossingRateE(c.data); !errors.Is(err, c.err) {
+			t.Errorf("ZeroCrossingRateE on %s returned %v, expected %v", c.name, err, c.err)
+		}
+		if _, err := NegativeZeroCrossingRateE(c.data); !errors.Is(err, c.err) {
+			t.Errorf("NegativeZeroCrossingRateE on %s returned %v, expected %v", c.name, err, c.err)
+		}
+		if _, err := PositiveZeroCrossingRateE(c.data); !errors.Is(err, c.err) {
+			t.Errorf("PositiveZeroCrossingRateE on %s returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+}
+
+func TestKeepXSecondsOfDataCopy(t *testing.T) {
+	// Generate sample data with spare capacity after the last sample
+	data := make([]SingleChannelSample, 0, 1100)
+	data = append(data, GenerateSineWave(50, 1, 1, 1000)...)
+	original := append([]SingleChannelSample{}, data...)
+
+	// Run the test
+	kept := KeepXSecondsOfDataCopy(data, 0.5)
+	if expected := KeepXSecondsOfData(data, 0.5); len(kept) != len(expected) || kept[0] != expected[0] {
+		t.Fatalf("KeepXSecondsOfDataCopy kept %d samples, expected %d", len(kept), len(expected))
+	}
+	for i := range kept {
+		kept[i].Value = 99
+	}
+	kept = append(kept, SingleChannelSample{Time: 2, Value: 99})
+
+	for i := range original {
+		if data[i] != original[i] {
+			t.Fatalf("KeepXSecondsOfDataCopy result shares memory with the input: sample %d changed", i)
+		}
+	}
+	if extended := data[:len(data)+1]; extended[len(data)].Value == 99 {
+		t.Errorf("appending to the KeepXSecondsOfDataCopy result wrote into the input's spare capacity")
+	}
+
+	// the view, by contrast, writes through
+	view := KeepXSecondsOfData(data, 0.5)
+	_ = append(view, SingleChannelSample{Time: 2, Value: 99})
+	if extended := data[:len(data)+1]; extended[len(data)].Value != 99 {
+		t.Errorf("appending to the KeepXSecondsOfData result did not reach the input's spare capacity")
+	}
+}
+
+func TestGenerateSineWaveDegenerate(t *testing.T) {
+	cases := []struct {
+		name       string
+		frequency  float64
+		duration   float64
+		sampleRate int
+	}{
+		{"zero sample rate", 50, 1, 0},
+		{"negative sample rate", 50, 1, -1000},
+		{"negative duration", 50, -1, 1000},
+		{"NaN duration", 50, math.NaN(), 1000},
+		{"infinite duration", 50, math.Inf(1), 1000},
+		{"NaN frequency", math.NaN(), 1, 1000},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		if data, err := GenerateSineWaveE(c.frequency, 1, c.duration, c.sampleRate); err == nil || data != nil {
+			t.Errorf("GenerateSineWaveE with %s returned %d samples and no error", c.name, len(data))
+		}
+		if data := GenerateSineWave(c.frequency, 1, c.duration, c.sampleRate); data == nil || len(data) != 0 {
+			t.Errorf("GenerateSineWave with %s returned %v, expected an empty slice", c.name, data)
+		}
+	}
+}
+
+func TestGenerateSineWaveInto(t *testing.T) {
+	dst := make([]SingleChannelSample, 1000)
+	for _, n := range []int{0, 1, 2, 3, 999, 1000} {
+		// Generate sample data
+		expected := GenerateSineWave(50, 1.5, float64(n)/1000, 1000)
+
+		// Run the test
+		data := GenerateSineWaveInto(dst[:n], 50, 1.5, 1000)
+		if len(data) != len(expected) {
+			t.Fatalf("GenerateSineWaveInto with %d samples returned %d, expected %d", n, len(data), len(expected))
+		}
+		for i := range data {
+			if data[i] != expected[i] {
+				t.Fatalf("GenerateSineWaveInto with %d samples: sample %d is %v, expected %v", n, i, data[i], expected[i])
+			}
+		}
+	}
+
+	if data := GenerateSineWaveInto(dst, math.NaN(), 1, 1000); len(data) != 0 {
+		t.Errorf("GenerateSineWaveInto with NaN frequency returned %d samples, expected 0", len(data))
+	}
+	if data := GenerateSineWaveInto(dst, 50, 1, 0); len(data) != 0 {
+		t.Errorf("GenerateSineWaveInto with zero sample rate returned %d samples, expected 0", len(data))
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		GenerateSineWaveInto(dst, 50, 1, 1000)
+	})
+	if allocs != 0 {
+		t.Errorf("GenerateSineWaveInto allocated %v times per call, expected 0", allocs)
+	}
+}
+
+func TestGenerateInto(t *testing.T) {
+	tones := []Tone{{Frequency: 50, Amplitude: 1}, {Frequency: 120, Amplitude: 0.5, Phase: 1}}
+	square := func(x float64) float64 { return x * x }
+	cases := []struct {
+		name    string
+		alloc   func(duration float64) []SingleChannelSample
+		into    func(dst []SingleChannelSample) []SingleChannelSample
+		invalid func(dst []SingleChannelSample) []SingleChannelSample
+	}{
+		{
+			"square",
+			func(d float64) []SingleChannelSample { return GenerateSquareWave(50, 2, d, 1000, 0.3) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateSquareWaveInto(dst, 50, 2, 1000, 0.3)
+			},
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateSquareWaveInto(dst, 50, 2, 1000, 1.5)
+			},
+		},
+		{
+			"triangle",
+			func(d float64) []SingleChannelSample { return GenerateTriangleWave(50, 2, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateTriangleWaveInto(dst, 50, 2, 1000)
+			},
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateTriangleWaveInto(dst, math.NaN(), 2, 1000)
+			},
+		},
+		{
+			"sawtooth",
+			func(d float64) []SingleChannelSample { return GenerateSawtoothWave(50, 2, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateSawtoothWaveInto(dst, 50, 2, 1000)
+			},
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateSawtoothWaveInto(dst, 50, 2, 0) },
+		},
+		{
+			"chirp",
+			func(d float64) []SingleChannelSample { return GenerateChirp(10, 200, 1, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateChirpInto(dst, 10, 200, 1, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateChirpInto(dst, 10, math.Inf(1), 1, 1000)
+			},
+		},
+		{
+			"impulse",
+			func(d float64) []SingleChannelSample { return GenerateImpulse(3, d, 1000, 0.5) },
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateImpulseInto(dst, 3, 1000, 0.5) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateImpulseInto(dst, 3, 1000, math.NaN())
+			},
+		},
+		{
+			"step",
+			func(d float64) []SingleChannelSample { return GenerateStep(3, d, 1000, 0.5) },
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateStepInto(dst, 3, 1000, 0.5) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateStepInto(dst, math.NaN(), 1000, 0.5)
+			},
+		},
+		{
+			"tone burst",
+			func(d float64) []SingleChannelSample { return GenerateToneBurst(100, 1, 0.2, 0.3, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateToneBurstInto(dst, 100, 1, 0.2, 0.3, 1000)
+			},
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateToneBurstInto(dst, 100, 1, 0.2, -0.3, 1000)
+			},
+		},
+		{
+			"AM sine",
+			func(d float64) []SingleChannelSample { return GenerateAMSine(100, 5, 1, 0.5, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateAMSineInto(dst, 100, 5, 1, 0.5, 1000)
+			},
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateAMSineInto(dst, 100, 5, 1, math.NaN(), 1000)
+			},
+		},
+		{
+			"from func",
+			func(d float64) []SingleChannelSample { return GenerateFromFunc(square, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateFromFuncInto(dst, square, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateFromFuncInto(dst, nil, 1000) },
+		},
+		{
+			"multi-tone",
+			func(d float64) []SingleChannelSample { return GenerateMultiTone(tones, d, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateMultiToneInto(dst, tones, 1000) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateMultiToneInto(dst, []Tone{{Frequency: math.NaN()}}, 1000)
+			},
+		},
+		{
+			"white noise",
+			func(d float64) []SingleChannelSample { return GenerateWhiteNoise(1, d, 1000, 7, WithGaussian()) },
+			func(dst []SingleChannelSample) []SingleChannelSample {
+				return GenerateWhiteNoiseInto(dst, 1, 1000, 7, WithGaussian())
+			},
+			func(dst []SingleChannelSample) []SingleChannelSample { return GenerateWhiteNoiseInto(dst, 1, -1, 7) },
+		},
+	}
+
+	dst := make([]SingleChannelSample, 1000)
+	for _, c := range cases {
+		for _, n := range []int{0, 1, 2, 999, 1000} {
+			// Generate sample data
+			expected := c.alloc(float64(n) / 1000)
+
+			// Run the test: dirty the buffer first, so every sample must be overwritten
+			for i := range dst {
+				dst[i] = SingleChannelSample{Time: -1, Value: -1}
+			}
+			if data := c.into(dst[:n]); !reflect.DeepEqual(data, expected) {
+				t.Errorf("%s with %d samples differs from the allocating generator", c.name, n)
+			}
+		}
+		if data := c.invalid(dst); len(data) != 0 {
+			t.Errorf("%s with invalid parameters returned %d samples, expected 0", c.name, len(data))
+		}
+		if c.name == "white noise" {
+			continue // seeding the random source allocates
+		}
+		if allocs := testing.AllocsPerRun(10, func() { c.into(dst) }); allocs != 0 {
+			t.Errorf("%s allocated %v times per call, expected 0", c.name, allocs)
+		}
+	}
+
+	// options apply as they do to the allocating generators
+	expected := GenerateToneBurst(100, 1, 0.2, 0.3, 1, 1000, WithBurstRamp(0.05))
+	if data := GenerateToneBurstInto(dst, 100, 1, 0.2, 0.3, 1000, WithBurstRamp(0.05)); !reflect.DeepEqual(data, expected) {
+		t.Error("tone burst with a ramp differs from the allocating generator")
+	}
+}
+
+func TestGenerateMultiChannelInto(t *testing.T) {
+	configs := []ChannelConfig{
+		{Waveform: WaveformSine, Frequency: 50, Amplitude: 1},
+		{Waveform: WaveformSquare, Frequency: 50, Amplitude: 2},
+		{Waveform: WaveformTriangle, Frequency: 25, Amplitude: 3, Phase: 1},
+	}
+	// Generate sample data
+	expected := GenerateMultiChannel(configs, 1, 1000)
+
+	// Run the test: the first call allocates the value slices, later ones reuse them
+	dst := make([]MultiChannelSample, 1000)
+	if data := GenerateMultiChannelInto(dst, configs, 1000); !reflect.DeepEqual(data, expected) {
+		t.Error("GenerateMultiChannelInto differs from GenerateMultiChannel")
+	}
+	if data := GenerateMultiChannelInto(dst, configs, 1000); !reflect.DeepEqual(data, expected) {
+		t.Error("GenerateMultiChannelInto into a reused buffer differs from GenerateMultiChannel")
+	}
+	if allocs := testing.AllocsPerRun(10, func() { GenerateMultiChannelInto(dst, configs, 1000) }); allocs != 0 {
+		t.Errorf("GenerateMultiChannelInto allocated %v times per call, expected 0", allocs)
+	}
+
+	for _, invalid := range [][]ChannelConfig{nil, {{Waveform: Waveform(9)}}} {
+		if data := GenerateMultiChannelInto(dst, invalid, 1000); len(data) != 0 {
+			t.Errorf("configs %v: returned %d samples, expected 0", invalid, len(data))
+		}
+	}
+	if data := GenerateMultiChannelInto(dst, configs, 0); len(data) != 0 {
+		t.Errorf("zero sample rate: returned %d samples, expected 0", len(data))
+	}
+}
+
+func TestGenerateSineWaveLong(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates ten minutes at 48 kHz")
+	}
+	// Generate sample data: ten minutes of 440 Hz at 48 kHz
+	data := GenerateSineWave(440, 1, 600, 48000)
+
+	// Run the test
+	var peak, worst float64
+	for i, sample := range data {
+		peak = max(peak, math.Abs(sample.Value))
+		want := math.Sin(2 * math.Pi * 440 * sample.Time)
+		if err := math.Abs(sample.Value - want); err > worst {
+			worst = err
+			if err > 1e-9 {
+				t.Fatalf("sample %d is %v, expected %v", i, sample.Value, want)
+			}
+		}
+	}
+	// a sample falls on the peak every 1200 samples
+	if math.Abs(peak-1) > 1e-6 {
+		t.Errorf("peak %v after ten minutes, expected 1", peak)
+	}
+}
+
+func TestGenerateSineWaveOptions(t *testing.T) {
+	// Generate sample data
+	cosine := GenerateSineWave(50, 2, 1, 1000, WithPhase(math.Pi/2))
+	offset := GenerateSineWave(50, 2, 1, 1000, WithDCOffset(2))
+	plain := GenerateSineWave(50, 2, 1, 1000)
+
+	// Run the test
+	for i, sample := range cosine {
+		if want := 2 * math.Cos(2*math.Pi*50*sample.Time); math.Abs(sample.Value-want) > 1e-9 {
+			t.Fatalf("phase π/2: sample %d is %v, expected the cosine's %v", i, sample.Value, want)
+		}
+	}
+	if cosine[0].Value != 2 {
+		t.Errorf("phase π/2 starts at %v, expected the amplitude", cosine[0].Value)
+	}
+	if rms := RMS(cosine, 50); math.Abs(rms-math.Sqrt2) > 1e-9 {
+		t.Errorf("phase π/2: RMS %v, expected %v", rms, math.Sqrt2)
+	}
+
+	var sum float64
+	for i, sample := range offset {
+		if sample.Value != plain[i].Value+2 {
+			t.Fatalf("offset: sample %d is %v, expected %v", i, sample.Value, plain[i].Value+2)
+		}
+		if sample.Value < -1e-12 {
+			t.Fatalf("offset: sample %d is %v, below zero", i, sample.Value)
+		}
+		sum += sample.Value
+	}
+	if mean := sum / float64(len(offset)); math.Abs(mean-2) > 1e-9 {
+		t.Errorf("offset: mean %v, expected 2", mean)
+	}
+	// the RMS takes in the offset: √(2² + 2²/2)
+	if rms := RMS(offset, 50); math.Abs(rms-math.Sqrt(6)) > 1e-9 {
+		t.Errorf("offset: RMS %v, expected %v", rms, math.Sqrt(6))
+	}
+
+	dst := make([]SingleChannelSample, len(cosine))
+	if into := GenerateSineWaveInto(dst, 50, 2, 1000, WithPhase(math.Pi/2)); !reflect.DeepEqual(into, cosine) {
+		t.Error("GenerateSineWaveInto with a phase differs from GenerateSineWave")
+	}
+	if data, err := GenerateSineWaveE(50, 1, 1, 1000, WithPhase(math.Inf(1))); err == nil || data != nil {
+		t.Error("infinite phase: expected an error")
+	}
+	if data := GenerateSineWave(50, 1, 1, 1000, WithDCOffset(math.NaN())); data == nil || len(data) != 0 {
+		t.Errorf("NaN offset: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateSquareWave(t *testing.T) {
+	// Generate sample data
+	frequency := 50.0
+	amplitude := 3.0
+	data := GenerateSquareWave(frequency, amplitude, 2, 1000, 0.5)
+	sine := GenerateSineWave(frequency, amplitude, 2, 1000)
+
+	// Run the test
+	if len(data) != len(sine) {
+		t.Fatalf("%d samples, expected %d as from GenerateSineWave", len(data), len(sine))
+	}
+	for i := range data {
+		if data[i].Time != sine[i].Time {
+			t.Fatalf("sample %d at %v s, expected %v s as from GenerateSineWave", i, data[i].Time, sine[i].Time)
+		}
+		if math.Abs(data[i].Value) != amplitude {
+			t.Fatalf("sample %d has value %v, expected ±%v", i, data[i].Value, amplitude)
+		}
+	}
+	if rms := RMS(data, frequency); math.Abs(rms-amplitude) > 1e-12 {
+		t.Errorf("RMS %v, expected the amplitude %v", rms, amplitude)
+	}
+	// one falling edge per cycle, the last at 1.99 s of the 1.999 s the data spans
+	if nzcr := NegativeZeroCrossingRate(data); math.Abs(nzcr-frequency*2/1.999) > 1e-9 {
+		t.Errorf("NZCR %v, expected %v", nzcr, frequency*2/1.999)
+	}
+
+	// a sample on a falling edge is low, one on a rising edge high
+	quarter := GenerateSquareWave(10, 1, 0.2, 100, 0.25)
+	for i, sample := range quarter {
+		high := i%10 < 3 // edges fall at sample 2.5 of each 10
+		if (sample.Value > 0) != high {
+			t.Errorf("25%% duty: sample %d has value %v", i, sample.Value)
+		}
+	}
+	edges := GenerateSquareWave(10, 1, 0.2, 100, 0.3)
+	for i, sample := range edges {
+		high := i%10 < 3 // the edge falls on sample 3 of each 10
+		if (sample.Value > 0) != high {
+			t.Errorf("30%% duty: sample %d has value %v", i, sample.Value)
+		}
+	}
+}
+
+func TestGenerateTriangleWave(t *testing.T) {
+	// Generate sample data
+	frequency := 40.0
+	amplitude := 2.0
+	data := GenerateTriangleWave(frequency, amplitude, 2, 1000)
+	sine := GenerateSineWave(frequency, amplitude, 2, 1000)
+
+	// Run the test
+	if len(data) != len(sine) {
+		t.Fatalf("%d samples, expected %d as from GenerateSineWave", len(data), len(sine))
+	}
+	for i := range data {
+		if data[i].Time != sine[i].Time {
+			t.Fatalf("sample %d at %v s, expected %v s as from GenerateSineWave", i, data[i].Time, sine[i].Time)
+		}
+	}
+	if data[0].Value != 0 || !(data[1].Value > 0) {
+		t.Errorf("starts %v, %v, expected zero ramping upward", data[0].Value, data[1].Value)
+	}
+	// 25 samples per cycle put no sample on the peaks
+	if rms, want := RMS(data, frequency), amplitude/math.Sqrt(3); math.Abs(rms-want) > 0.01*want {
+		t.Errorf("RMS %v, expected %v", rms, want)
+	}
+	// two crossings per cycle, less the rise from the zero at time 0, which has no sign before it
+	if zcr, want := ZeroCrossingRate(data), (2*frequency*2-1)/1.999; math.Abs(zcr-want) > 1e-9 {
+		t.Errorf("ZCR %v, expected %v", zcr, want)
+	}
+
+	// with a sample on each peak and zero, the sampled RMS is within 0.02% of the analytic one
+	exact := GenerateTriangleWave(50, amplitude, 1, 10000)
+	if rms, want := RMS(exact, 50), amplitude/math.Sqrt(3); math.Abs(rms-want) > 2e-4*want {
+		t.Errorf("RMS %v at 200 samples per cycle, expected %v", rms, want)
+	}
+	for i := 0; i < len(exact); i += 50 {
+		want := [4]float64{0, amplitude, 0, -amplitude}[i/50%4]
+		if math.Abs(exact[i].Value-want) > 1e-9 {
+			t.Errorf("sample %d has value %v, expected %v", i, exact[i].Value, want)
+		}
+	}
+
+	if data, err := GenerateTriangleWaveE(50, 1, 1, 0); err == nil || data != nil {
+		t.Error("zero sample rate: expected an error")
+	}
+	if data := GenerateTriangleWave(50, 1, -1, 1000); data == nil || len(data) != 0 {
+		t.Errorf("negative duration: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateSawtoothWave(t *testing.T) {
+	// Generate sample data
+	frequency := 50.0
+	amplitude := 2.0
+	data := GenerateSawtoothWave(frequency, amplitude, 2, 10000)
+
+	// Run the test
+	if data[0].Value != -amplitude || !(data[1].Value > data[0].Value) {
+		t.Errorf("starts %v, %v, expected -amplitude ramping upward", data[0].Value, data[1].Value)
+	}
+	if rms, want := RMS(data, frequency), amplitude/math.Sqrt(3); math.Abs(rms-want) > 1e-4*want {
+		t.Errorf("RMS %v, expected %v", rms, want)
+	}
+	// one drop per cycle but the first, at 0.02 s to 1.98 s of the 1.9999 s the data spans
+	if nzcr, want := NegativeZeroCrossingRate(data), (frequency*2-1)/1.9999; math.Abs(nzcr-want) > 1e-9 {
+		t.Errorf("NZCR %v, expected %v", nzcr, want)
+	}
+
+	// at 2⅓ to 4⅓ samples per cycle most drops fall between samples
+	for _, rate := range []int{100, 110, 130, 70} {
+		sparse := GenerateSawtoothWave(30, amplitude, 10, rate)
+		var drops int
+		for i := 1; i < len(sparse); i++ {
+			if sparse[i].Value < sparse[i-1].Value {
+				drops++
+			}
+		}
+		duration := sparse[len(sparse)-1].Time
+		if nzcr, want := NegativeZeroCrossingRate(sparse), float64(drops)/duration; nzcr != want {
+			t.Errorf("%d Hz sampling: NZCR %v, expected one crossing per drop, %v", rate, nzcr, want)
+		}
+		if drops < 299 || drops > 300 {
+			t.Errorf("%d Hz sampling: %d drops in 10 s, expected one per cycle", rate, drops)
+		}
+	}
+
+	if data, err := GenerateSawtoothWaveE(50, 1, 1, 0); err == nil || data != nil {
+		t.Error("zero sample rate: expected an error")
+	}
+	if data := GenerateSawtoothWave(math.Inf(1), 1, 1, 1000); data == nil || len(data) != 0 {
+		t.Errorf("infinite frequency: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateChirp(t *testing.T) {
+	// Generate sample data: a sweep from 100 Hz to 200 Hz over 10 s
+	data := GenerateChirp(100, 200, 1, 10, 10000)
+	tenth := len(data) / 10
+
+	// Run the test
+	if len(data) != 100000 || data[0].Value != 0 {
+		t.Fatalf("%d samples starting at %v, expected 100000 starting at 0", len(data), data[0].Value)
+	}
+	// the frequency averages 105 Hz over the first tenth and 195 Hz over the last
+	if rate := NegativeZeroCrossingRate(data[:tenth]); math.Abs(rate-105) > 1 {
+		t.Errorf("NZCR %v over the first tenth, expected 105", rate)
+	}
+	if rate := NegativeZeroCrossingRate(data[len(data)-tenth:]); math.Abs(rate-195) > 1 {
+		t.Errorf("NZCR %v over the last tenth, expected 195", rate)
+	}
+	// the phase is continuous, so no step is larger than the steepest slope allows
+	limit := 2 * math.Pi * 200 / 10000
+	for i := 1; i < len(data); i++ {
+		if step := math.Abs(data[i].Value - data[i-1].Value); step > limit {
+			t.Fatalf("step %v at sample %d, beyond the %v the slope allows", step, i, limit)
+		}
+	}
+
+	// a sweep with equal ends is a sine, but for rounding in the sine's recurrence
+	constant, sine := GenerateChirp(50, 50, 1, 1, 1000), GenerateSineWave(50, 1, 1, 1000)
+	for i := range constant {
+		if diff := math.Abs(constant[i].Value - sine[i].Value); diff > 1e-9 {
+			t.Fatalf("constant chirp differs from the sine by %v at sample %d", diff, i)
+		}
+	}
+
+	if data, err := GenerateChirpE(100, math.Inf(1), 1, 1, 1000); err == nil || data != nil {
+		t.Error("infinite end frequency: expected an error")
+	}
+	if data := GenerateChirp(100, 200, 1, -1, 1000); data == nil || len(data) != 0 {
+		t.Errorf("negative duration: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateMultiTone(t *testing.T) {
+	// Generate sample data: 50 Hz with a tenth of its third harmonic
+	tones := []Tone{{Frequency: 50, Amplitude: 2}, {Frequency: 150, Amplitude: 0.2, Phase: math.Pi / 3}}
+	data := GenerateMultiTone(tones, 2, 10000)
+
+	// Run the test
+	if len(data) != 20000 {
+		t.Fatalf("%d samples, expected 20000", len(data))
+	}
+	want := math.Sqrt(2*2/2.0 + 0.2*0.2/2)
+	if rms := RMS(data, 50); math.Abs(rms-want) > 1e-9 {
+		t.Errorf("RMS %v, expected %v", rms, want)
+	}
+	// 100 falling crossings, near 0.01 s to 1.99 s, over the 1.9999 s the data spans
+	if nzcr := NegativeZeroCrossingRate(data); math.Abs(nzcr-100/1.9999) > 1e-9 {
+		t.Errorf("NZCR %v, expected the fundamental's %v", nzcr, 100/1.9999)
+	}
+	for _, i := range []int{0, 37, 19999} {
+		tm := float64(i) / 10000
+		value := 2*math.Sin(2*math.Pi*50*tm) + 0.2*math.Sin(2*math.Pi*150*tm+math.Pi/3)
+		if math.Abs(data[i].Value-value) > 1e-12 || data[i].Time != tm {
+			t.Errorf("sample %d is %+v, expected %v at %v s", i, data[i], value, tm)
+		}
+	}
+
+	if silence := GenerateMultiTone(nil, 1, 1000); len(silence) != 1000 || silence[500].Value != 0 {
+		t.Errorf("no tones: got %d samples, expected 1000 zeros", len(silence))
+	}
+	if data, err := GenerateMultiToneE([]Tone{{Frequency: 50, Amplitude: math.NaN()}}, 1, 1000); err == nil || data != nil {
+		t.Error("NaN amplitude: expected an error")
+	}
+	if data := GenerateMultiTone(tones, 1, 0); data == nil || len(data) != 0 {
+		t.Errorf("zero sample rate: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateImpulse(t *testing.T) {
+	cases := []struct {
+		name        string
+		impulseTime float64
+		index       int // -1 for none
+	}{
+		{"on a sample", 0.25, 250},
+		{"nearest below", 0.2504, 250},
+		{"nearest above", 0.2506, 251},
+		{"at the start", 0, 0},
+		{"at the end", 1, 999},
+		{"before the start", -0.1, -1},
+		{"after the end", 1.5, -1},
+	}
+	for _, c := range cases {
+		// Generate sample data
+		data := GenerateImpulse(3, 1, 1000, c.impulseTime)
+
+		// Run the test
+		if len(data) != 1000 {
+			t.Fatalf("%s: %d samples, expected 1000", c.name, len(data))
+		}
+		for i, sample := range data {
+			want := 0.0
+			if i == c.index {
+				want = 3
+			}
+			if sample.Value != want || sample.Time != float64(i)*(1.0/1000) {
+				t.Errorf("%s: sample %d is %+v, expected %v at %v s", c.name, i, sample, want, float64(i)/1000)
+			}
+		}
+	}
+
+	if data, err := GenerateImpulseE(1, 1, 1000, math.NaN()); err == nil || data != nil {
+		t.Error("NaN impulse time: expected an error")
+	}
+}
+
+func TestGenerateStep(t *testing.T) {
+	cases := []struct {
+		name     string
+		stepTime float64
+		first    int // index of the first sample at the amplitude
+	}{
+		{"on a sample", 0.3, 300},
+		{"between samples", 0.3004, 301},
+		{"at the start", 0, 0},
+		{"before the start", -1, 0},
+		{"after the end", 2, 1000},
+	}
+	for _, c := range cases {
+		// Generate sample data
+		data := GenerateStep(-2, 1, 1000, c.stepTime)
+
+		// Run the test
+		if len(data) != 1000 {
+			t.Fatalf("%s: %d samples, expected 1000", c.name, len(data))
+		}
+		for i, sample := range data {
+			want := 0.0
+			if i >= c.first {
+				want = -2
+			}
+			if sample.Value != want {
+				t.Errorf("%s: sample %d is %v, expected %v", c.name, i, sample.Value, want)
+				break
+			}
+		}
+	}
+
+	// a step on a sample is kept there however the time rounds
+	for i := range 1000 {
+		data := GenerateStep(1, 1, 1000, float64(i)*0.001)
+		if data[i].Value != 1 || (i > 0 && data[i-1].Value != 0) {
+			t.Fatalf("step at sample %d falls elsewhere", i)
+		}
+	}
+
+	if data := GenerateStep(1, 1, 0, 0.5); data == nil || len(data) != 0 {
+		t.Errorf("zero sample rate: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateToneBurst(t *testing.T) {
+	// Generate sample data: 0.5 s of 50 Hz from 1 s in a 2 s record
+	data := GenerateToneBurst(50, 2, 1, 0.5, 2, 1000)
+
+	// Run the test
+	if len(data) != 2000 {
+		t.Fatalf("%d samples, expected 2000", len(data))
+	}
+	for i, sample := range data {
+		if sample.Time != float64(i)*(1.0/1000) {
+			t.Fatalf("sample %d at %v s, expected the timebase of GenerateSineWave", i, sample.Time)
+		}
+	}
+	// the burst holds a quarter of the samples, so the RMS is the sine's times √0.25
+	if rms, want := calculateRMS(data), 2/math.Sqrt2*0.5; math.Abs(rms-want) > 1e-9 {
+		t.Errorf("RMS %v, expected %v", rms, want)
+	}
+	for _, silent := range [][]SingleChannelSample{data[:1000], data[1500:]} {
+		for _, sample := range silent {
+			if sample.Value != 0 {
+				t.Fatalf("sample at %v s is %v, expected silence", sample.Time, sample.Value)
+			}
+		}
+		if zcr := ZeroCrossingRate(silent); zcr != 0 {
+			t.Errorf("ZCR %v over the silence from %v s, expected 0", zcr, silent[0].Time)
+		}
+	}
+	if data[1000].Value != 0 || !(data[1001].Value > 0) {
+		t.Errorf("burst starts %v, %v, expected zero rising", data[1000].Value, data[1001].Value)
+	}
+
+	// with ramps no step between samples exceeds what the sine's slope allows
+	ramped := GenerateToneBurst(50, 2, 1, 0.5, 2, 1000, WithBurstRamp(0.1))
+	limit := 2 * 2 * math.Pi * 50 / 1000
+	for i := 1; i < len(ramped); i++ {
+		if step := math.Abs(ramped[i].Value - ramped[i-1].Value); step > limit {
+			t.Fatalf("ramped: step %v at %v s, beyond the slope's %v", step, ramped[i].Time, limit)
+		}
+	}
+	// the middle of the burst is untouched by the ramps
+	for i := 1100; i < 1400; i++ {
+		if ramped[i].Value != data[i].Value {
+			t.Fatalf("ramped: sample %d is %v, expected the burst's %v", i, ramped[i].Value, data[i].Value)
+		}
+	}
+	// the ramps are a quarter of the burst at each end; on average a raised cosine passes 3/8 of the power
+	if rms, want := calculateRMS(ramped), math.Sqrt(2*(0.3+0.2*3.0/8)/2); math.Abs(rms-want) > 1e-3 {
+		t.Errorf("ramped: RMS %v, expected %v", rms, want)
+	}
+
+	if data, err := GenerateToneBurstE(50, 1, 0, -1, 1, 1000); err == nil || data != nil {
+		t.Error("negative burst duration: expected an error")
+	}
+	if data := GenerateToneBurst(50, 1, 0, 1, 1, 1000, WithBurstRamp(math.NaN())); data == nil || len(data) != 0 {
+		t.Errorf("NaN ramp: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateMultiChannel(t *testing.T) {
+	// Generate sample data
+	configs := []ChannelConfig{
+		{Waveform: WaveformSine, Frequency: 440, Amplitude: 1},
+		{Waveform: WaveformSquare, Frequency: 50, Amplitude: 2},
+		{Waveform: WaveformTriangle, Frequency: 100, Amplitude: 3, Phase: math.Pi},
+		{Waveform: WaveformSawtooth, Frequency: 25, Amplitude: 1.5},
+		{Waveform: WaveformSine, Frequency: 150, Amplitude: 2, Phase: math.Pi / 2},
+	}
+	data := GenerateMultiChannel(configs, 1, 2000)
+
+	// Run the test
+	if len(data) != 2000 {
+		t.Fatalf("%d samples, expected 2000", len(data))
+	}
+	for i, sample := range data {
+		if len(sample.Value) != len(configs) {
+			t.Fatalf("sample %d holds %d values, expected %d", i, len(sample.Value), len(configs))
+		}
+	}
+	// the channels without a phase match their single-channel generators
+	singles := [][]SingleChannelSample{
+		GenerateSineWave(440, 1, 1, 2000),
+		GenerateSquareWave(50, 2, 1, 2000, 0.5),
+		nil,
+		GenerateSawtoothWave(25, 1.5, 1, 2000),
+		GenerateSineWave(150, 2, 1, 2000, WithPhase(math.Pi/2)),
+	}
+	for ch, single := range singles {
+		for i := range single {
+			if data[i].Time != single[i].Time || math.Abs(data[i].Value[ch]-single[i].Value) > 1e-9 {
+				t.Fatalf("channel %d sample %d is %v at %v s, expected %v at %v s", ch, i, data[i].Value[ch], data[i].Time, single[i].Value, single[i].Time)
+			}
+		}
+	}
+	// a half-cycle shift inverts the triangle
+	triangle := GenerateTriangleWave(100, 3, 1, 2000)
+	for i := range triangle {
+		if math.Abs(data[i].Value[2]+triangle[i].Value) > 1e-9 {
+			t.Fatalf("triangle sample %d is %v, expected %v", i, data[i].Value[2], -triangle[i].Value)
+		}
+	}
+
+	rms, zcr, err := AnalyzeMultiChannelE(data, WithMaxCycles(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRMS := []float64{1 / math.Sqrt2, 2, 3 / math.Sqrt(3), 1.5 / math.Sqrt(3), 2 / math.Sqrt2}
+	for ch, config := range configs {
+		if math.Abs(rms[ch]-expectedRMS[ch]) > 0.02*expectedRMS[ch] {
+			t.Errorf("channel %d: RMS %v, expected %v", ch, rms[ch], expectedRMS[ch])
+		}
+		if math.Abs(zcr[ch]-config.Frequency) > 1.5 {
+			t.Errorf("channel %d: ZCR %v, expected %v", ch, zcr[ch], config.Frequency)
+		}
+	}
+
+	if _, err := GenerateMultiChannelE(nil, 1, 1000); !errors.Is(err, ErrNoChannels) {
+		t.Errorf("no configs: got %v, expected ErrNoChannels", err)
+	}
+	tooHigh := 1.5
+	invalid := []ChannelConfig{
+		{Waveform: Waveform(7), Frequency: 50, Amplitude: 1},
+		{Frequency: math.NaN(), Amplitude: 1},
+		{Waveform: WaveformSquare, Frequency: 50, Amplitude: 1, DutyCycle: &tooHigh},
+	}
+	for _, config := range invalid {
+		if data, err := GenerateMultiChannelE([]ChannelConfig{configs[0], config}, 1, 1000); err == nil || data != nil {
+			t.Errorf("%+v: expected an error", config)
+		}
+		if data := GenerateMultiChannel([]ChannelConfig{config}, 1, 1000); data == nil || len(data) != 0 {
+			t.Errorf("%+v: got %d samples, expected an empty slice", config, len(data))
+		}
+	}
+}
+
+func TestGenerateMultiChannelDutyCycle(t *testing.T) {
+	for _, dutyCycle := range []float64{0, 0.25, 1} {
+		// Generate sample data
+		configs := []ChannelConfig{{Waveform: WaveformSquare, Frequency: 50, Amplitude: 2, DutyCycle: &dutyCycle}}
+		data := GenerateMultiChannel(configs, 1, 2000)
+		single := GenerateSquareWave(50, 2, 1, 2000, dutyCycle)
+
+		// Run the test
+		if len(data) != len(single) {
+			t.Fatalf("duty cycle %v: %d samples, expected %d", dutyCycle, len(data), len(single))
+		}
+		for i := range single {
+			if data[i].Value[0] != single[i].Value {
+				t.Fatalf("duty cycle %v: sample %d is %v, expected %v", dutyCycle, i, data[i].Value[0], single[i].Value)
+			}
+		}
+	}
+}
+
+func TestGenerateAMSine(t *testing.T) {
+	for _, depth := range []float64{0.5, 1.5} {
+		// Generate sample data: the carrier and modulation peak together at 0.05 s, sample 500
+		data := GenerateAMSine(105, 5, 2, depth, 1, 10000)
+
+		// Run the test
+		if len(data) != 10000 {
+			t.Fatalf("depth %v: %d samples, expected 10000", depth, len(data))
+		}
+		var peak float64
+		for _, sample := range data {
+			peak = max(peak, math.Abs(sample.Value))
+		}
+		if want := 2 * (1 + depth); math.Abs(peak-want) > 1e-9 || math.Abs(data[500].Value-want) > 1e-9 {
+			t.Errorf("depth %v: peak %v, sample 500 %v, expected %v", depth, peak, data[500].Value, want)
+		}
+		// over whole periods of the modulation the mean square is A²/2·(1 + m²/2)
+		if rms, want := calculateRMS(data), 2*math.Sqrt((1+depth*depth/2)/2); math.Abs(rms-want) > 1e-9 {
+			t.Errorf("depth %v: RMS %v, expected %v", depth, rms, want)
+		}
+	}
+
+	// over-modulation inverts the carrier where the envelope goes negative, at 0.15 s
+	over := GenerateAMSine(105, 5, 1, 2, 1, 10000)
+	if want := (1 + 2*math.Sin(2*math.Pi*5*0.15)) * math.Sin(2*math.Pi*105*0.15); math.Abs(over[1500].Value-want) > 1e-9 || !(over[1500].Value > 0) {
+		t.Errorf("over-modulated sample 1500 is %v, expected %v", over[1500].Value, want)
+	}
+
+	if data, err := GenerateAMSineE(100, math.NaN(), 1, 0.5, 1, 1000); err == nil || data != nil {
+		t.Error("NaN modulation frequency: expected an error")
+	}
+	if data := GenerateAMSine(100, 5, 1, math.Inf(1), 1, 1000); data == nil || len(data) != 0 {
+		t.Errorf("infinite depth: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateFromFunc(t *testing.T) {
+	// Generate sample data
+	sine := GenerateSineWave(60, 3, 2, 5000)
+	data := GenerateFromFunc(func(t float64) float64 { return 3 * math.Sin(2*math.Pi*60*t) }, 2, 5000)
+
+	// Run the test
+	if len(data) != len(sine) {
+		t.Fatalf("%d samples, expected %d as from GenerateSineWave", len(data), len(sine))
+	}
+	for i := range data {
+		if data[i].Time != sine[i].Time || math.Abs(data[i].Value-sine[i].Value) > 1e-9 {
+			t.Fatalf("sample %d is %+v, expected %+v", i, data[i], sine[i])
+		}
+	}
+	if last := data[len(data)-1].Time; !(last < 2) {
+		t.Errorf("last sample at %v s, expected before the 2 s duration", last)
+	}
+
+	// a duration one part in 10⁸ short keeps its last sample, as GenerateSineWave does
+	if short := GenerateFromFunc(math.Cos, 0.99999999, 1000); len(short) != 1000 {
+		t.Errorf("%d samples for a duration a rounding error short of 1 s, expected 1000", len(short))
+	}
+	if empty := GenerateFromFunc(math.Cos, 0, 1000); empty == nil || len(empty) != 0 {
+		t.Errorf("zero duration: got %v, expected an empty slice", empty)
+	}
+	if data, err := GenerateFromFuncE(nil, 1, 1000); err == nil || data != nil {
+		t.Error("nil function: expected an error")
+	}
+	if data, err := GenerateFromFuncE(math.Cos, 1, 0); err == nil || data != nil {
+		t.Error("zero sample rate: expected an error")
+	}
+	if data := GenerateFromFunc(math.Cos, -1, 1000); data == nil || len(data) != 0 {
+		t.Errorf("negative duration: got %v, expected an empty slice", data)
+	}
+}
+
+func TestGenerateWhiteNoise(t *testing.T) {
+	// Generate sample data: 100 s of noise, whose RMS has a relative standard error of about 0.0005
+	gaussian := GenerateWhiteNoise(1, 100, 10000, 1, WithGaussian())
+	uniform := GenerateWhiteNoise(2, 100, 10000, 1)
+
+	// Run the test
+	if rms, _ := Analyze(gaussian, WithMaxCycles(0)); math.Abs(rms-1) > 0.005 {
+		t.Errorf("Gaussian RMS %v, expected 1", rms)
+	}
+	if rms, _ := Analyze(uniform, WithMaxCycles(0)); math.Abs(rms-2/math.Sqrt(3)) > 0.005 {
+		t.Errorf("uniform RMS %v, expected %v", rms, 2/math.Sqrt(3))
+	}
+	for i, sample := range uniform {
+		if math.Abs(sample.Value) > 2 {
+			t.Fatalf("uniform sample %d has value %v, beyond the amplitude", i, sample.Value)
+		}
+	}
+	sine := GenerateSineWave(50, 1, 100, 10000)
+	if len(gaussian) != len(sine) || gaussian[len(gaussian)-1].Time != sine[len(sine)-1].Time {
+		t.Errorf("%d samples, expected the timebase of GenerateSineWave", len(gaussian))
+	}
+
+	// the same seed gives the same noise, a different one different noise
+	again := GenerateWhiteNoise(1, 100, 10000, 1, WithGaussian())
+	if !reflect.DeepEqual(again, gaussian) {
+		t.Error("same seed gave different noise")
+	}
+	if other := GenerateWhiteNoise(1, 100, 10000, 2, WithGaussian()); other[0].Value == gaussian[0].Value {
+		t.Error("different seeds gave the same noise")
+	}
+
+	if data, err := GenerateWhiteNoiseE(math.NaN(), 1, 1000, 1); err == nil || data != nil {
+		t.Error("NaN amplitude: expected an error")
+	}
+	if data := GenerateWhiteNoise(1, 1, 0, 1); data == nil || len(data) != 0 {
+		t.Errorf("zero sample rate: got %v, expected an empty slice", data)
+	}
+}
+
+func TestAddNoise(t *testing.T) {
+	// Generate sample data
+	clean := GenerateSineWave(50, 1, 10, 10000)
+	original := append([]SingleChannelSample(nil), clean...)
+
+	// Run the test
+	noisy := AddNoise(clean, 20, 7)
+	if !reflect.DeepEqual(clean, original) {
+		t.Fatal("AddNoise changed its input")
+	}
+	if len(noisy) != len(clean) {
+		t.Fatalf("%d samples, expected %d", len(noisy), len(clean))
+	}
+	var signalPower, noisePower float64
+	for i := range noisy {
+		if noisy[i].Time != clean[i].Time {
+			t.Fatalf("sample %d moved to %v s", i, noisy[i].Time)
+		}
+		noise := noisy[i].Value - clean[i].Value
+		signalPower += clean[i].Value * clean[i].Value
+		noisePower += noise * noise
+	}
+	if snr := 10 * math.Log10(signalPower/noisePower); math.Abs(snr-20) > 1 {
+		t.Errorf("measured SNR %v dB, expected 20 dB", snr)
+	}
+	if again := AddNoise(clean, 20, 7); !reflect.DeepEqual(again, noisy) {
+		t.Error("same seed gave different noise")
+	}
+
+	silent := make([]SingleChannelSample, 100)
+	if _, err := AddNoiseE(silent, 20, 1); err == nil {
+		t.Error("silent signal: expected an error")
+	}
+	if unchanged := AddNoise(silent, 20, 1); !reflect.DeepEqual(unchanged, silent) || &unchanged[0] == &silent[0] {
+		t.Error("silent signal: expected an unchanged copy")
+	}
+	if _, err := AddNoiseE(nil, 20, 1); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("no data: got %v, expected ErrEmptyData", err)
+	}
+	if _, err := AddNoiseE(clean, math.Inf(1), 1); err == nil {
+		t.Error("infinite SNR: expected an error")
+	}
+}
+
+func TestGenerateSquareWaveFlat(t *testing.T) {
+	for _, duty := range []float64{0, 1} {
+		// Generate sample data
+		data := GenerateSquareWave(50, 2, 1, 1000, duty)
+
+		// Run the test
+		want := 2.0
+		if duty == 0 {
+			want = -2
+		}
+		for i, sample := range data {
+			if sample.Value != want {
+				t.Fatalf("duty %v: sample %d has value %v, expected %v", duty, i, sample.Value, want)
+			}
+		}
+		if nzcr := NegativeZeroCrossingRate(data); nzcr != 0 {
+			t.Errorf("duty %v: NZCR %v, expected 0", duty, nzcr)
+		}
+	}
+}
+
+func TestGenerateSquareWaveDegenerate(t *testing.T) {
+	cases := []struct {
+		name       string
+		frequency  float64
+		duration   float64
+		sampleRate int
+		dutyCycle  float64
+	}{
+		{"zero sample rate", 50, 1, 0, 0.5},
+		{"negative duration", 50, -1, 1000, 0.5},
+		{"NaN frequency", math.NaN(), 1, 1000, 0.5},
+		{"negative duty cycle", 50, 1, 1000, -0.1},
+		{"duty cycle above 1", 50, 1, 1000, 1.1},
+		{"NaN duty cycle", 50, 1, 1000, math.NaN()},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		if data, err := GenerateSquareWaveE(c.frequency, 1, c.duration, c.sampleRate, c.dutyCycle); err == nil || data != nil {
+			t.Errorf("GenerateSquareWaveE with %s returned %d samples and no error", c.name, len(data))
+		}
+		if data := GenerateSquareWave(c.frequency, 1, c.duration, c.sampleRate, c.dutyCycle); data == nil || len(data) != 0 {
+			t.Errorf("GenerateSquareWave with %s returned %v, expected an empty slice", c.name, data)
+		}
+	}
+}
+
+func TestGenerateSineWaveSampleCount(t *testing.T) {
+	cases := []struct {
+		duration float64
+		expected int
+	}{
+		{0, 0},
+		{0.0005, 0},       // shorter than one sample
+		{0.001, 1},        // exactly one sample
+		{0.9999999, 1000}, // rounding error below a whole count
+		{0.9995, 999},     // a genuine fraction is rounded down
+		{1, 1000},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		data, err := GenerateSineWaveE(50, 1, c.duration, 1000)
+		if err != nil {
+			t.Fatalf("GenerateSineWaveE with duration %g returned error: %v", c.duration, err)
+		}
+		if len(data) != c.expected {
+			t.Errorf("GenerateSineWaveE with duration %g returned %d samples, expected %d", c.duration, len(data), c.expected)
+		}
+	}
+}
+
+func TestSumSquaresAccuracy(t *testing.T) {
+	// Generate sample data: a tiny signal on a large offset, and the same with alternating signs
+	for _, alternate := range []bool{false, true} {
+		data := make([]SingleChannelSample, 1000003)
+		for i := range data {
+			value := 1e6 + 1e-3*math.Sin(float64(i)/7)
+			if alternate && i%2 == 1 {
+				value = -value
+			}
+			data[i] = SingleChannelSample{Time: float64(i), Value: value}
+		}
+
+		// Run the test against a naive running sum
+		naive := 0.0
+		for _, sample := range data {
+			naive += sample.Value * sample.Value
+		}
+		result := sumSquares(data)
+		if diff := math.Abs(result-naive) / naive; diff > 1e-9 {
+			t.Errorf("sumSquares returned %g, expected %g (relative difference: %g)", result, naive, diff)
+		}
+	}
+
+	// every tail length is summed in full
+	for n := range 9 {
+		data := make([]SingleChannelSample, n)
+		for i := range data {
+			data[i].Value = float64(i + 1)
+		}
+		if result, expected := sumSquares(data), float64(n*(n+1)*(2*n+1)/6); result != expected {
+			t.Errorf("sumSquares of 1..%d returned %f, expected %f", n, result, expected)
+		}
+	}
+}
+
+func TestCircularBufferSegments(t *testing.T) {
+	cases := []struct {
+		name    string
+		updates int
+		older   int
+		newer   int
+	}{
+		{"empty", 0, 0, 0},
+		{"partially filled", 3, 3, 0},
+		{"exactly full", 5, 5, 0},
+		{"wrapped", 7, 3, 2},
+		{"wrapped back to the start", 10, 5, 0},
+	}
+
+	for _, c := range cases {
+		// Generate sample data
+		cb := NewCircularBuffer(5)
+		for i := range c.updates {
+			cb.Update(SingleChannelSample{Time: float64(i), Value: float64(i)})
+		}
+
+		// Run the test
+		older, newer := cb.Segments()
+		if len(older) != c.older || len(newer) != c.newer {
+			t.Errorf("%s: got segments of %d and %d samples, expected %d and %d", c.name, len(older), len(newer), c.older, c.newer)
+			continue
+		}
+		joined := append(append([]SingleChannelSample(nil), older...), newer...)
+		expected := cb.GetData()
+		for i := range expected {
+			if joined[i] != expected[i] {
+				t.Errorf("%s: sample %d is %v, expected %v", c.name, i, joined[i], expected[i])
+			}
+		}
+	}
+}
+
+// BENCHMARKS
+
+func BenchmarkGenerateSineWave(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GenerateSineWave(440, 1, 2, 1000)
+	}
+}
+
+func BenchmarkGenerateSineWaveInto(b *testing.B) {
+	dst := make([]SingleChannelSample, 2000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GenerateSineWaveInto(dst, 440, 1, 1000)
+	}
+}
+
+func BenchmarkGenerateInto(b *testing.B) {
+	dst := make([]SingleChannelSample, 2000)
+	tones := []Tone{{Frequency: 440, Amplitude: 1}, {Frequency: 880, Amplitude: 0.5}}
+	for _, bm := range []struct {
+		name string
+		fn   func()
+	}{
+		{"Square", func() { GenerateSquareWaveInto(dst, 440, 1, 1000, 0.5) }},
+		{"Triangle", func() { GenerateTriangleWaveInto(dst, 440, 1, 1000) }},
+		{"Sawtooth", func() { GenerateSawtoothWaveInto(dst, 440, 1, 1000) }},
+		{"Chirp", func() { GenerateChirpInto(dst, 10, 400, 1, 1000) }},
+		{"Impulse", func() { GenerateImpulseInto(dst, 1, 1000, 1) }},
+		{"Step", func() { GenerateStepInto(dst, 1, 1000, 1) }},
+		{"ToneBurst", func() { GenerateToneBurstInto(dst, 440, 1, 0.5, 1, 1000) }},
+		{"AMSine", func() { GenerateAMSineInto(dst, 440, 5, 1, 0.5, 1000) }},
+		{"MultiTone", func() { GenerateMultiToneInto(dst, tones, 1000) }},
+		{"WhiteNoise", func() { GenerateWhiteNoiseInto(dst, 1, 1000, 1) }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.fn()
+			}
+		})
+	}
+}
+
+// BenchmarkGenerateSineWaveLong generates 10 s at 48 kHz with the re-seeded recurrence.
+func BenchmarkGenerateSineWaveLong(b *testing.B) {
+	dst := make([]SingleChannelSample, 480000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateSineWaveInto(dst, 440, 1, 48000)
+	}
+}
+
+// BenchmarkGenerateSineWaveLongDirect generates the same wave with a call to
+// math.Sin per sample, for comparison with BenchmarkGenerateSineWaveLong.
+func BenchmarkGenerateSineWaveLongDirect(b *testing.B) {
+	dst := make([]SingleChannelSample, 480000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range dst {
+			t := float64(j) * (1.0 / 48000)
+			dst[j] = SingleChannelSample{Time: t, Value: math.Sin(2 * math.Pi * 440 * t)}
+		}
+	}
+}
+
+func BenchmarkRMS(b *testing.B) {
+	// Generate sample data
+	frequency := 200.0
+	data := GenerateSineWave(frequency, 1, 5, 2000)
+
+	// Run the benchmark
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RMS(data, frequency)
+	}
+}
+
+var benchmarkSink float64
+
+func BenchmarkSumSquares(b *testing.B) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 10000)
+
+	// Run the benchmark
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += sumSquares(data)
+	}
+}
+
+func BenchmarkNegativeZeroCrossingRateLarge(b *testing.B) {
+	// Generate sample data: 10M samples
+	data := GenerateSineWave(440, 1, 1000, 10000)
+
+	// Run the benchmark
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += NegativeZeroCrossingRate(data)
+	}
+}
+
+func BenchmarkSumSquaresLarge(b *testing.B) {
+	// Generate sample data: 10M samples
+	data := GenerateSineWave(440, 1, 1000, 10000)
+
+	// Run the benchmark
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += sumSquares(data)
+	}
+}
+
+func BenchmarkZeroCrossingRate(b *testing.B) {
+	// Generate sample data
+	data := GenerateSineWave(440, 1, 1, 1000)
+
+	// Run the benchmark
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ZeroCrossingRate(data)
+	}
+}
+
+func BenchmarkNegativeZeroCrossingRate(b *testing.B) {
+	// Generate sample data
+	data := GenerateSineWave(440, 1, 1, 1000)
+
+	// Run the benchmark
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NegativeZeroCrossingRate(data)
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	// Generate sample data
+	data := GenerateSineWave(440, 1, 1, 1000)
+
+	// Run the benchmark
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		Analyze(data)
 	}
 }
 
-// a function that has a ticker every 1ms and adds a sample to the circular buffer, then every 100ms it prints the RMS and NZCR of the buffer
+func BenchmarkAnalyzeMultiChannel(b *testing.B) {
+	// Generate sample data: 32 channels of 1M samples
+	wave := GenerateSineWave(440, 1, 1000, 1000)
+	data := make([]MultiChannelSample, len(wave))
+	for i, sample := range wave {
+		values := make([]float64, 32)
+		for c := range values {
+			values[c] = sample.Value
+		}
+		data[i] = MultiChannelSample{Time: sample.Time, Value: values}
+	}
+
+	// Run the benchmark
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		AnalyzeMultiChannel(data)
+	}
+}
+
+// a function that has a ticker every 1ms and adds a sample to the circular buffer, then every 100ms it analyzes the buffer
 func BenchmarkCircularBuffer(b *testing.B) {
 	sineWave := GenerateSineWave(440, 1, 1, 1000)
 	// Create a new CircularBuffer with a size of 1000
@@ -210,8 +2271,7 @@ func BenchmarkCircularBuffer(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		cb.Update(SingleChannelSample{Time: float64(i), Value: sineWave[i%len(sineWave)].Value})
 		if i%100 == 0 {
-			rms, zcr := cb.AnalyzeBuffer()
-			fmt.Printf("Length: %d, RMS: %f, NZCR: %f\n", cb.count, rms, zcr)
+			cb.AnalyzeBuffer()
 		}
 	}
 }
@@ -229,8 +2289,7 @@ func BenchmarkSlice(b *testing.B) {
 				// Keep only the last 1000 samples
 				data = data[len(data)-1000:]
 			}
-			rms, zcr := Analyze(data)
-			fmt.Printf("Length: %d, RMS: %f, NZCR: %f\n", len(data), rms, zcr)
+			Analyze(data)
 		}
 	}
 }
@@ -0,0 +1,158 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Envelope returns the slow amplitude envelope of the data on the same
+// timebase, as EnvelopeE does, or nil when EnvelopeE would report an error.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - smoothingTimeConstant: The time constant in seconds of each smoothing stage
+//
+// Returns:
+//   - []SingleChannelSample: The envelope, one sample for each of data
+func Envelope(data []SingleChannelSample, smoothingTimeConstant float64) []SingleChannelSample {
+	envelope, err := EnvelopeE(data, smoothingTimeConstant)
+	if err != nil {
+		return nil
+	}
+	return envelope
+}
+
+// EnvelopeE returns the slow amplitude envelope of the data on the same
+// timebase, for finding the modulation a bearing defect or gear fault puts on
+// a vibration. The signal is full-wave rectified and smoothed by two single-pole
+// low-pass stages in turn, each with the given time constant, and scaled by
+// π/2 so that a steady sine gives its amplitude. Two stages smooth away the
+// ripple at twice the carrier frequency far better than one of the same
+// delay, so the envelope crosses its mean once each way per cycle of the
+// modulation. The time constant should be several periods of the carrier and
+// well under one of the modulation, which it lags by about twice the time
+// constant. The stages start from rest, so the first few time constants of the
+// envelope rise towards the level of the signal.
+//
+// Each step uses the actual interval between the samples, so the data need
+// not be evenly spaced.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - smoothingTimeConstant: The time constant in seconds of each smoothing stage
+//
+// Returns:
+//   - []SingleChannelSample: The envelope, one sample for each of data
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span
+//     no time, ErrUnsortedData if the data is not in time order, or an error if
+//     the time constant is not finite or is shorter than the mean interval
+//     between samples
+func EnvelopeE(data []SingleChannelSample, smoothingTimeConstant float64) ([]SingleChannelSample, error) {
+	duration, err := recordDuration(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(smoothingTimeConstant) || math.IsInf(smoothingTimeConstant, 0) {
+		return nil, errors.New("dynamics: envelope time constant must be finite")
+	}
+	if step := duration / float64(len(data)-1); smoothingTimeConstant < step {
+		return nil, fmt.Errorf("dynamics: envelope time constant %g s is shorter than the sample interval of %g s", smoothingTimeConstant, step)
+	}
+
+	envelope := make([]SingleChannelSample, len(data))
+	var first, second float64
+	for i, sample := range data {
+		if i > 0 {
+			alpha := 1 - math.Exp(-(sample.Time-data[i-1].Time)/smoothingTimeConstant)
+			first += alpha * (math.Abs(sample.Value) - first)
+			second += alpha * (first - second)
+		}
+		envelope[i] = SingleChannelSample{Time: sample.Time, Value: math.Pi / 2 * second}
+	}
+	return envelope, nil
+}
+
+// EnvelopeSpectrum is the spectrum of the envelope of a band of a vibration
+// signal, the standard way to find the repetition rate of bearing defects:
+// the impacts of a defect ring a structural resonance, and the envelope of
+// the band around the resonance repeats at the defect frequency.
+//
+// The band is taken from the spectrum of the record, and its envelope is the
+// magnitude of the analytic signal, the band's positive frequencies doubled
+// and transformed back (the Hilbert transform). The mean of the envelope is
+// removed so that the line at 0 Hz does not dwarf the rest. The samples are
+// taken to be evenly spaced.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - bandLow: The lower edge of the band in Hz
+//   - bandHigh: The upper edge of the band in Hz, no higher than the Nyquist frequency
+//
+// Returns:
+//   - freqs: The frequency of each line in Hz, from 0 to the Nyquist frequency
+//   - mags: The peak amplitude of the envelope at each frequency; both are nil
+//     when the band is empty or not within 0 Hz to the Nyquist frequency, or
+//     the data spans no time or is not in time order
+func EnvelopeSpectrum(data []SingleChannelSample, bandLow, bandHigh float64) (freqs, mags []float64) {
+	if len(data) < 2 || !(bandLow >= 0) || !(bandHigh > bandLow) || checkTimeOrder(data) != nil {
+		return nil, nil
+	}
+	duration := data[len(data)-1].Time - data[0].Time
+	if !(duration > 0) {
+		return nil, nil
+	}
+	n := len(data)
+	resolution := float64(n-1) / duration / float64(n)
+	if bandHigh > resolution*float64(n)/2*(1+1e-9) {
+		return nil, nil
+	}
+
+	envelope := bandEnvelope(sampleValues(data), int(math.Ceil(bandLow/resolution)), int(math.Floor(bandHigh/resolution)))
+	var mean float64
+	for _, v := range envelope {
+		mean += v
+	}
+	mean /= float64(n)
+	for i := range envelope {
+		envelope[i] -= mean
+	}
+
+	mags = amplitudeSpectrum(envelope)
+	freqs = make([]float64, len(mags))
+	for k := range freqs {
+		freqs[k] = float64(k) * resolution
+	}
+	return freqs, mags
+}
+
+// bandEnvelope returns the magnitude of the analytic signal of the spectral
+// lines lo to hi of the values.
+func bandEnvelope(values []float64, lo, hi int) []float64 {
+	n := len(values)
+	x := make([]complex128, n)
+	for i, v := range values {
+		x[i] = complex(v, 0)
+	}
+	fft(x)
+	for k := range x {
+		switch {
+		case k < lo || k > hi || k > n/2:
+			x[k] = 0
+		case k > 0 && 2*k != n:
+			// a positive frequency stands in for its negative twin too
+			x[k] *= 2
+		}
+	}
+	ifft(x)
+
+	envelope := make([]float64, n)
+	for i, v := range x {
+		envelope[i] = cmplx.Abs(v)
+	}
+	return envelope
+}
@@ -0,0 +1,107 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEnvelopeSpectrum(t *testing.T) {
+	// Generate sample data: 1 s at 20 kHz of a 3 kHz carrier, modulated to
+	// depth 0.5 at 137 Hz, over a strong 50 Hz tone outside the band
+	data := make([]SingleChannelSample, 20000)
+	for i := range data {
+		tm := float64(i) / 20000
+		carrier := (1 + 0.5*math.Cos(2*math.Pi*137*tm)) * math.Sin(2*math.Pi*3000*tm)
+		data[i] = SingleChannelSample{Time: tm, Value: carrier + 5*math.Sin(2*math.Pi*50*tm)}
+	}
+
+	// Run the test: the envelope 1 + 0.5·cos(2π·137t) has a line of 0.5 at 137 Hz
+	freqs, mags := EnvelopeSpectrum(data, 2000, 4000)
+	if len(freqs) == 0 || len(freqs) != len(mags) {
+		t.Fatalf("got %d frequencies and %d magnitudes", len(freqs), len(mags))
+	}
+	peak := 0
+	for k := range mags {
+		if mags[k] > mags[peak] {
+			peak = k
+		}
+	}
+	if math.Abs(freqs[peak]-137) > 1 {
+		t.Fatalf("peak at %v Hz, expected 137", freqs[peak])
+	}
+	if math.Abs(mags[peak]-0.5) > 0.01 {
+		t.Errorf("amplitude %v at 137 Hz, expected 0.5", mags[peak])
+	}
+	for k, mag := range mags {
+		if k != peak && mag > 0.01 {
+			t.Errorf("amplitude %v at %v Hz, expected none", mag, freqs[k])
+		}
+	}
+}
+
+func TestEnvelopeSpectrumInvalid(t *testing.T) {
+	data := GenerateSineWave(100, 1, 1, 1000)
+	for _, band := range [][2]float64{{-1, 100}, {200, 100}, {100, 100}, {100, 600}} {
+		if freqs, mags := EnvelopeSpectrum(data, band[0], band[1]); freqs != nil || mags != nil {
+			t.Errorf("band %v: expected nil", band)
+		}
+	}
+	if freqs, _ := EnvelopeSpectrum(nil, 0, 100); freqs != nil {
+		t.Error("empty data: expected nil")
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	// Generate sample data: 2 s at 50 kHz of a 2 kHz carrier of amplitude 1,
+	// modulated to depth 0.5 at 5 Hz
+	data := GenerateAMSine(2000, 5, 1, 0.5, 2, 50000)
+
+	// Run the test
+	envelope, err := EnvelopeE(data, 0.005)
+	if err != nil {
+		t.Fatalf("EnvelopeE returned error: %v", err)
+	}
+	if len(envelope) != len(data) {
+		t.Fatalf("EnvelopeE returned %d samples, expected %d", len(envelope), len(data))
+	}
+	for i := range envelope {
+		if envelope[i].Time != data[i].Time {
+			t.Fatalf("envelope sample %d at %v s, expected the time of the data, %v s", i, envelope[i].Time, data[i].Time)
+		}
+	}
+
+	// once settled, the envelope runs between 0.5 and 1.5 and crosses its
+	// mean twice a cycle of the modulation
+	settled := KeepXSecondsOfData(envelope, 1.5)
+	low, high := valueRange(settled, math.Inf(1), math.Inf(-1))
+	if math.Abs(low-0.5) > 0.05 || math.Abs(high-1.5) > 0.05 {
+		t.Errorf("envelope ran from %v to %v, expected 0.5 to 1.5", low, high)
+	}
+	if zcr := ZeroCrossingRate(RemoveDC(settled)); math.Abs(zcr/2-5) > 0.1 {
+		t.Errorf("envelope crossed its mean %v times a second, expected twice the 5 Hz modulation", zcr)
+	}
+	// the carrier itself crosses zero at its own rate
+	if zcr := ZeroCrossingRate(data); math.Abs(zcr/2-2000) > 1 {
+		t.Errorf("carrier crossed zero %v times a second, expected twice 2000 Hz", zcr)
+	}
+}
+
+func TestEnvelopeInvalid(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 0.1, 1000)
+
+	// Run the test
+	if envelope := Envelope(data, 0.0005); envelope != nil {
+		t.Errorf("Envelope with a time constant under the sample interval returned %d samples, expected nil", len(envelope))
+	}
+	if _, err := EnvelopeE(data, math.Inf(1)); err == nil {
+		t.Error("EnvelopeE with an infinite time constant: expected an error")
+	}
+	if _, err := EnvelopeE(nil, 0.01); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("EnvelopeE of no data returned %v, expected ErrEmptyData", err)
+	}
+	if envelope := Envelope(data, 0.01); len(envelope) != len(data) {
+		t.Errorf("Envelope returned %d samples, expected %d", len(envelope), len(data))
+	}
+}
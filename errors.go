@@ -0,0 +1,60 @@
+package dynamics
+
+import "errors"
+
+// ErrEmptyData is returned when an analysis is given no samples.
+var ErrEmptyData = errors.New("dynamics: empty data")
+
+// ErrZeroDuration is returned when the samples span no time, because there is
+// only one sample or every sample has the same timestamp, or when the last
+// sample's time is before the first's.
+var ErrZeroDuration = errors.New("dynamics: zero or negative duration")
+
+// ErrUnsortedData is returned when sample times are not in non-decreasing order.
+var ErrUnsortedData = errors.New("dynamics: samples are not in time order")
+
+// ErrInvalidFrequency is returned when a frequency is zero, negative or not finite.
+var ErrInvalidFrequency = errors.New("dynamics: invalid frequency")
+
+// ErrNonFinite is returned, wrapped with the offending sample's details, when
+// data analysed with NonFiniteStrict holds a NaN or infinite value.
+var ErrNonFinite = errors.New("dynamics: non-finite value")
+
+// ErrMalformedSample is returned, wrapped with the details, when a sample
+// cannot be decoded from JSON.
+var ErrMalformedSample = errors.New("dynamics: malformed sample")
+
+// ErrNoChannels is returned when multi-channel samples carry no values.
+var ErrNoChannels = errors.New("dynamics: samples have no channels")
+
+// ErrChannelMismatch is returned, wrapped with the offending sample's details,
+// when a multi-channel sample carries a different number of values than expected.
+var ErrChannelMismatch = errors.New("dynamics: inconsistent channel count")
+
+// ErrClosed is returned when a sample is pushed to a streaming component after
+// it has been closed.
+var ErrClosed = errors.New("dynamics: push after close")
+
+// ErrTooShort is returned when data spans less than the one whole cycle of a
+// given frequency that an analysis needs.
+var ErrTooShort = errors.New("dynamics: data is shorter than one cycle")
+
+// ErrNoDecay is returned when the peaks of a free response do not decay, as
+// for an undamped or growing oscillation, so no damping can be measured.
+var ErrNoDecay = errors.New("dynamics: peaks do not decay")
+
+// ErrUnsupportedOption is returned, wrapped with the option's name, when an
+// analysis is given an option it cannot apply.
+var ErrUnsupportedOption = errors.New("dynamics: option not supported by this analysis")
+
+// ErrMisaligned is returned, wrapped with the details, when channels that must
+// be sampled together have different lengths or timestamps.
+var ErrMisaligned = errors.New("dynamics: channels are not sampled at the same times")
+
+// ErrNoModulation is returned when a signal's envelope varies too little to
+// measure its modulation.
+var ErrNoModulation = errors.New("dynamics: no significant modulation")
+
+// ErrUnevenSpacing is returned, wrapped with the offending interval's details,
+// when an analysis that needs evenly spaced samples is given samples that are not.
+var ErrUnevenSpacing = errors.New("dynamics: samples are not evenly spaced")
@@ -0,0 +1,99 @@
+package dynamics
+
+import "math"
+
+// exposureReference is the reference duration of a daily exposure, 8 hours in seconds.
+const exposureReference = 8 * 3600
+
+// ExposureKind is the quantity an exposure is measured in.
+type ExposureKind int
+
+const (
+	ExposureNoise     ExposureKind = iota + 1 // levels in dB, such as a Leq series in dB(A)
+	ExposureVibration                         // RMS values, such as frequency-weighted acceleration in m/s²
+)
+
+// ExposureSpec describes how levels add up to a daily exposure and the limit
+// it is judged against.
+type ExposureSpec struct {
+	Kind  ExposureKind `json:"kind"`
+	Limit float64      `json:"limit"` // the limit of the daily exposure, in dB or the units of the values
+	// ExchangeRate is the increase in dB that halves the permitted time, for
+	// noise: 3 for energy averaging as ISO 9612 and the EU directive use, or 5
+	// as OSHA does. Zero means 3.
+	ExchangeRate float64 `json:"exchangeRate"`
+	// ShiftDuration is the length in seconds of the working day the levels
+	// sample. When it is longer than the levels cover, their average is
+	// extrapolated over the whole shift; zero takes the levels to be the
+	// whole day's exposure.
+	ShiftDuration float64 `json:"shiftDuration"`
+}
+
+// ExposureDose normalises a series of levels to the daily exposure of an
+// 8-hour reference day: LEX,8h for noise, or A(8) for hand-arm or whole-body
+// vibration. Each level covers the time since the level before it, as a Leq
+// series timed at the ends of its intervals does, and the first covers the
+// usual interval. A longer interval, such as a gap in the record, counts only
+// the usual interval and the rest as no exposure.
+//
+// Parameters:
+//   - levels: A slice of Sample structs holding levels in time order
+//   - reference: How the levels are averaged and the limit
+//
+// Returns:
+//   - dose: LEX,8h in dB for noise, or A(8) in the units of the values for vibration
+//   - percentOfLimit: The share of the allowed daily dose received, which
+//     doubles with twice the exposure time: 100·2^((LEX,8h − limit)/exchange
+//     rate) for noise, or 100·(A(8)/limit)² for vibration; both are NaN when
+//     the kind, limit or exchange rate is invalid or there are no levels
+func ExposureDose(levels []SingleChannelSample, reference ExposureSpec) (dose float64, percentOfLimit float64) {
+	exchange := reference.ExchangeRate
+	if exchange == 0 {
+		exchange = 3
+	}
+	if len(levels) == 0 || !(reference.Limit > 0) || !(exchange > 0) || reference.ShiftDuration < 0 ||
+		(reference.Kind != ExposureNoise && reference.Kind != ExposureVibration) {
+		return math.NaN(), math.NaN()
+	}
+
+	// the usual interval is the median spacing of the levels
+	nominal := 0.0
+	if len(levels) > 1 {
+		spacings := make([]float64, len(levels)-1)
+		for i := range spacings {
+			spacings[i] = levels[i+1].Time - levels[i].Time
+		}
+		nominal = median(spacings)
+	}
+
+	// energy is the time integral of the exposure: of 10^(L/k) for noise, a²
+	// for vibration, with k = 10 for the 3 dB rate and exchange/log10(2) otherwise
+	k := exchange / math.Log10(2)
+	if exchange == 3 {
+		k = 10
+	}
+	var energy float64
+	for i, level := range levels {
+		covered := nominal
+		if i > 0 {
+			covered = math.Min(level.Time-levels[i-1].Time, nominal)
+		}
+		if reference.Kind == ExposureNoise {
+			energy += math.Pow(10, level.Value/k) * covered
+		} else {
+			energy += level.Value * level.Value * covered
+		}
+	}
+
+	measured := levels[len(levels)-1].Time - levels[0].Time + nominal
+	if reference.ShiftDuration > measured && measured > 0 {
+		energy *= reference.ShiftDuration / measured
+	}
+
+	if reference.Kind == ExposureNoise {
+		dose = k * math.Log10(energy/exposureReference)
+		return dose, 100 * math.Pow(2, (dose-reference.Limit)/exchange)
+	}
+	dose = math.Sqrt(energy / exposureReference)
+	return dose, 100 * (dose / reference.Limit) * (dose / reference.Limit)
+}
@@ -0,0 +1,72 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// constantLevels returns a level every minute for the given number of hours,
+// timed at the end of each minute.
+func constantLevels(level, hours float64) []SingleChannelSample {
+	levels := make([]SingleChannelSample, int(hours*60))
+	for i := range levels {
+		levels[i] = SingleChannelSample{Time: float64(i+1) * 60, Value: level}
+	}
+	return levels
+}
+
+func TestExposureDoseNoise(t *testing.T) {
+	tests := []struct {
+		name          string
+		levels        []SingleChannelSample
+		spec          ExposureSpec
+		dose, percent float64
+	}{
+		// 90 dB for 8 hours is 5 dB over the limit, three times the allowed dose
+		{"full day", constantLevels(90, 8), ExposureSpec{Kind: ExposureNoise, Limit: 85}, 90, 100 * math.Pow(2, 5.0/3)},
+		// 88 dB for 4 hours halves the energy: 88 − 3.01 dB
+		{"half day", constantLevels(88, 4), ExposureSpec{Kind: ExposureNoise, Limit: 85}, 88 - 10*math.Log10(2), 100 * math.Pow(2, (3-10*math.Log10(2))/3)},
+		// 2 hours of an 8-hour shift at 90 dB extrapolate to the whole shift
+		{"partial shift", constantLevels(90, 2), ExposureSpec{Kind: ExposureNoise, Limit: 85, ShiftDuration: 8 * 3600}, 90, 100 * math.Pow(2, 5.0/3)},
+		// at the OSHA 5 dB rate, 4 hours at the 90 dB limit is half the dose
+		{"5 dB rate", constantLevels(90, 4), ExposureSpec{Kind: ExposureNoise, Limit: 90, ExchangeRate: 5}, 85, 50},
+	}
+	for _, tt := range tests {
+		// Run the test
+		dose, percent := ExposureDose(tt.levels, tt.spec)
+		if math.Abs(dose-tt.dose) > 1e-9 || math.Abs(percent-tt.percent) > 1e-6 {
+			t.Errorf("%s: got %v dB and %v%%, expected %v dB and %v%%", tt.name, dose, percent, tt.dose, tt.percent)
+		}
+	}
+}
+
+func TestExposureDoseGap(t *testing.T) {
+	// Generate sample data: 8 hours at 90 dB with the levels of hours 3 to 7 missing
+	var levels []SingleChannelSample
+	for _, level := range constantLevels(90, 8) {
+		if level.Time <= 3*3600 || level.Time > 7*3600 {
+			levels = append(levels, level)
+		}
+	}
+
+	// Run the test: the 4-hour gap is no exposure, so half the energy
+	dose, _ := ExposureDose(levels, ExposureSpec{Kind: ExposureNoise, Limit: 85})
+	if expected := 90 - 10*math.Log10(2); math.Abs(dose-expected) > 1e-9 {
+		t.Errorf("dose %v dB, expected %v", dose, expected)
+	}
+}
+
+func TestExposureDoseVibration(t *testing.T) {
+	// Run the test: 2 hours at 4 m/s² gives A(8) = 4·√(2/8) = 2 m/s², against a 5 m/s² limit
+	dose, percent := ExposureDose(constantLevels(4, 2), ExposureSpec{Kind: ExposureVibration, Limit: 5})
+	if math.Abs(dose-2) > 1e-12 || math.Abs(percent-16) > 1e-9 {
+		t.Errorf("got A(8) %v and %v%%, expected 2 and 16%%", dose, percent)
+	}
+
+	if dose, percent := ExposureDose(nil, ExposureSpec{Kind: ExposureVibration, Limit: 5}); !math.IsNaN(dose) || !math.IsNaN(percent) {
+		t.Error("no levels: expected NaN")
+	}
+	if dose, _ := ExposureDose(constantLevels(4, 2), ExposureSpec{Limit: 5}); !math.IsNaN(dose) {
+		t.Error("no kind: expected NaN")
+	}
+}
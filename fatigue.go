@@ -0,0 +1,169 @@
+package dynamics
+
+import (
+	"math"
+	"sort"
+)
+
+// SNCurve is a stress-life (S-N) curve, giving the number of cycles to failure
+// at a stress amplitude, half the range of a cycle.
+type SNCurve interface {
+	CyclesToFailure(amplitude float64) float64
+}
+
+// BasquinCurve is the S-N curve of Basquin's law, N = Coefficient·S⁻ᵐ for a
+// stress amplitude S and Exponent m.
+type BasquinCurve struct {
+	Coefficient float64 `json:"coefficient"`
+	Exponent    float64 `json:"exponent"`
+}
+
+// CyclesToFailure returns Coefficient·amplitude⁻ᴱˣᵖᵒⁿᵉⁿᵗ, or +Inf for a zero amplitude.
+func (c BasquinCurve) CyclesToFailure(amplitude float64) float64 {
+	return c.Coefficient * math.Pow(amplitude, -c.Exponent)
+}
+
+// SNPoint is a point of a tabulated S-N curve.
+type SNPoint struct {
+	Amplitude float64 `json:"amplitude"`
+	Cycles    float64 `json:"cycles"`
+}
+
+// SNTable is an S-N curve tabulated as points, interpolated linearly in
+// log-log space. Amplitudes below the lowest point are taken to be under the
+// endurance limit and never cause failure; amplitudes above the highest
+// extrapolate the last segment.
+type SNTable []SNPoint
+
+// CyclesToFailure interpolates the table at the amplitude. A table of fewer
+// than two points gives the cycles of its one point at or above its amplitude.
+func (t SNTable) CyclesToFailure(amplitude float64) float64 {
+	if len(t) == 0 {
+		return math.Inf(1)
+	}
+	points := make([]SNPoint, len(t))
+	copy(points, t)
+	sort.Slice(points, func(i, j int) bool { return points[i].Amplitude < points[j].Amplitude })
+	if amplitude < points[0].Amplitude {
+		return math.Inf(1)
+	}
+	if len(points) == 1 {
+		return points[0].Cycles
+	}
+
+	i := sort.Search(len(points)-1, func(i int) bool { return points[i+1].Amplitude >= amplitude })
+	i = min(i, len(points)-2)
+	lo, hi := points[i], points[i+1]
+	slope := math.Log(hi.Cycles/lo.Cycles) / math.Log(hi.Amplitude/lo.Amplitude)
+	return lo.Cycles * math.Pow(amplitude/lo.Amplitude, slope)
+}
+
+// DamageBin is the damage done by the cycles of one range and mean.
+type DamageBin struct {
+	Range           float64 `json:"range"`
+	Mean            float64 `json:"mean"`
+	Count           float64 `json:"count"`           // cycles counted, half cycles as 0.5
+	Amplitude       float64 `json:"amplitude"`       // equivalent fully reversed amplitude after any mean-stress correction
+	CyclesToFailure float64 `json:"cyclesToFailure"` // at Amplitude
+	Damage          float64 `json:"damage"`          // Count over CyclesToFailure
+}
+
+// DamageOption configures a call to MinersDamage.
+type DamageOption func(*damageConfig)
+
+// damageConfig holds the settings made by DamageOptions.
+type damageConfig struct {
+	ultimate float64 // ultimate strength for the Goodman correction, 0 for none
+}
+
+// WithGoodman corrects each cycle for its mean stress by the Goodman relation,
+// Sₐ/(1 − Sₘ/Sᵤ) for an ultimate strength Sᵤ. Compressive means are left
+// uncorrected, which is conservative, and a mean at or above the ultimate
+// strength fails in one cycle.
+func WithGoodman(ultimateStrength float64) DamageOption {
+	return func(c *damageConfig) {
+		c.ultimate = ultimateStrength
+	}
+}
+
+// MinersDamage accumulates the fatigue damage of rainflow cycles by the
+// Palmgren-Miner rule, D = Σ nᵢ/Nᵢ, where nᵢ cycles are counted at a stress
+// amplitude whose life on the S-N curve is Nᵢ cycles. Failure is predicted
+// when D reaches 1. Cycles of the same range and mean are gathered in a bin.
+//
+// Parameters:
+//   - cycles: The cycles counted by RainflowCount, in stress units
+//   - sn: The S-N curve of the material, in the same units
+//   - opts: Options such as WithGoodman
+//
+// Returns:
+//   - damage: The total damage
+//   - perBin: The damage of each bin, in order of range then mean
+func MinersDamage(cycles []RainflowCycle, sn SNCurve, opts ...DamageOption) (damage float64, perBin []DamageBin) {
+	var config damageConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	type key struct{ r, m float64 }
+	index := make(map[key]int)
+	for _, cycle := range cycles {
+		k := key{cycle.Range, cycle.Mean}
+		i, ok := index[k]
+		if !ok {
+			i = len(perBin)
+			index[k] = i
+			perBin = append(perBin, DamageBin{Range: cycle.Range, Mean: cycle.Mean})
+		}
+		perBin[i].Count += cycle.Count
+	}
+	sort.Slice(perBin, func(i, j int) bool {
+		if perBin[i].Range != perBin[j].Range {
+			return perBin[i].Range < perBin[j].Range
+		}
+		return perBin[i].Mean < perBin[j].Mean
+	})
+
+	for i := range perBin {
+		bin := &perBin[i]
+		bin.Amplitude = bin.Range / 2
+		if config.ultimate > 0 && bin.Mean > 0 {
+			if bin.Mean >= config.ultimate {
+				bin.Amplitude = math.Inf(1)
+			} else {
+				bin.Amplitude /= 1 - bin.Mean/config.ultimate
+			}
+		}
+		switch {
+		case bin.Amplitude == 0:
+			bin.CyclesToFailure = math.Inf(1)
+		case math.IsInf(bin.Amplitude, 1):
+			bin.CyclesToFailure = 1
+		default:
+			bin.CyclesToFailure = sn.CyclesToFailure(bin.Amplitude)
+		}
+		bin.Damage = bin.Count / bin.CyclesToFailure
+		damage += bin.Damage
+	}
+	return damage, perBin
+}
+
+// RemainingLife estimates the time left before failure if loading goes on as
+// in a record, taking the record to be the whole loading history so far.
+//
+// Parameters:
+//   - damage: The damage the record did, from MinersDamage
+//   - duration: The length of the record, in any unit of time
+//
+// Returns:
+//   - float64: The remaining life in the unit of duration, 0 once damage
+//     reaches 1 and +Inf when there is no damage
+func RemainingLife(damage, duration float64) float64 {
+	if damage >= 1 {
+		return 0
+	}
+	if !(damage > 0) {
+		return math.Inf(1)
+	}
+	return duration * (1 - damage) / damage
+}
@@ -0,0 +1,85 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// constantAmplitude returns a load alternating between mean-amplitude and
+// mean+amplitude, starting and ending on a valley, which rainflow counts as
+// the given number of cycles.
+func constantAmplitude(cycles int, mean, amplitude float64) []SingleChannelSample {
+	data := make([]SingleChannelSample, 2*cycles+1)
+	for i := range data {
+		value := mean - amplitude
+		if i%2 == 1 {
+			value = mean + amplitude
+		}
+		data[i] = SingleChannelSample{Time: float64(i) / 2, Value: value}
+	}
+	return data
+}
+
+func TestMinersDamage(t *testing.T) {
+	// Generate sample data: 1000 cycles of amplitude 200 MPa
+	curve := BasquinCurve{Coefficient: 1e12, Exponent: 3}
+	cycles := RainflowCount(constantAmplitude(1000, 0, 200))
+
+	// Run the test: 1000 / (10¹²·200⁻³)
+	expected := 1000 * math.Pow(200, 3) / 1e12
+	damage, bins := MinersDamage(cycles, curve)
+	if math.Abs(damage-expected) > 1e-12 {
+		t.Errorf("damage %v, expected %v", damage, expected)
+	}
+	if len(bins) != 1 || bins[0].Count != 1000 || bins[0].Amplitude != 200 {
+		t.Errorf("got bins %+v, expected 1000 cycles at amplitude 200", bins)
+	}
+}
+
+func TestMinersDamageGoodman(t *testing.T) {
+	// Generate sample data: 1000 cycles of amplitude 200 MPa about a mean of
+	// 100 MPa, for a material of ultimate strength 500 MPa
+	curve := BasquinCurve{Coefficient: 1e12, Exponent: 3}
+	cycles := RainflowCount(constantAmplitude(1000, 100, 200))
+
+	// Run the test: the equivalent amplitude is 200/(1 - 100/500) = 250
+	expected := 1000 * math.Pow(250, 3) / 1e12
+	damage, bins := MinersDamage(cycles, curve, WithGoodman(500))
+	if math.Abs(damage-expected) > 1e-12 {
+		t.Errorf("damage %v, expected %v", damage, expected)
+	}
+	if math.Abs(bins[0].Amplitude-250) > 1e-9 {
+		t.Errorf("equivalent amplitude %v, expected 250", bins[0].Amplitude)
+	}
+
+	// without the correction the mean is ignored
+	if damage, _ := MinersDamage(cycles, curve); math.Abs(damage-1000*math.Pow(200, 3)/1e12) > 1e-12 {
+		t.Errorf("uncorrected damage %v", damage)
+	}
+}
+
+func TestSNTable(t *testing.T) {
+	// the table follows N = 10¹²·S⁻³ between its points
+	table := SNTable{{Amplitude: 400, Cycles: 1e12 / 64e6}, {Amplitude: 100, Cycles: 1e6}}
+	curve := BasquinCurve{Coefficient: 1e12, Exponent: 3}
+	for _, amplitude := range []float64{100, 200, 300, 400, 800} {
+		if got, expected := table.CyclesToFailure(amplitude), curve.CyclesToFailure(amplitude); math.Abs(got-expected) > 1e-6*expected {
+			t.Errorf("cycles at %v: got %v, expected %v", amplitude, got, expected)
+		}
+	}
+	if got := table.CyclesToFailure(50); !math.IsInf(got, 1) {
+		t.Errorf("below the endurance limit: got %v, expected +Inf", got)
+	}
+}
+
+func TestRemainingLife(t *testing.T) {
+	if got := RemainingLife(0.25, 10); got != 30 {
+		t.Errorf("got %v, expected 30", got)
+	}
+	if got := RemainingLife(1.5, 10); got != 0 {
+		t.Errorf("past failure: got %v, expected 0", got)
+	}
+	if got := RemainingLife(0, 10); !math.IsInf(got, 1) {
+		t.Errorf("no damage: got %v, expected +Inf", got)
+	}
+}
@@ -0,0 +1,174 @@
+package dynamics
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// fft replaces x with its discrete Fourier transform, Xₖ = Σ xₙ·e^(−2πikn/N).
+// Powers of two use an iterative radix-2 transform; other lengths use
+// Bluestein's algorithm, which computes the transform as a convolution of
+// power-of-two length.
+func fft(x []complex128) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+	if n&(n-1) == 0 {
+		radix2(x)
+		return
+	}
+	bluestein(x)
+}
+
+// ifft replaces x with its inverse discrete Fourier transform, including the 1/N scaling.
+func ifft(x []complex128) {
+	for i, v := range x {
+		x[i] = cmplx.Conj(v)
+	}
+	fft(x)
+	scale := 1 / float64(len(x))
+	for i, v := range x {
+		x[i] = complex(real(v)*scale, -imag(v)*scale)
+	}
+}
+
+// radix2 is the in-place Cooley-Tukey transform for a power-of-two length.
+func radix2(x []complex128) {
+	n := len(x)
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range x {
+		if j := int(bits.Reverse64(uint64(i)) >> shift); j > i {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		step := -2 * math.Pi / float64(size)
+		for k := range half {
+			w := cmplx.Rect(1, step*float64(k))
+			for start := 0; start < n; start += size {
+				a, b := x[start+k], w*x[start+k+half]
+				x[start+k], x[start+k+half] = a+b, a-b
+			}
+		}
+	}
+}
+
+// bluestein transforms x of any length through the identity
+// kn = (k² + n² − (k−n)²)/2, which turns the transform into a convolution
+// with the chirp e^(iπk²/N).
+func bluestein(x []complex128) {
+	n := len(x)
+	m := 1 << bits.Len(uint(2*n-2))
+
+	// the chirp, with k² reduced modulo 2N so the angle stays accurate
+	chirpScratch, aScratch, bScratch := complexScratch.get(n), complexScratch.get(m), complexScratch.get(m)
+	defer complexScratch.put(chirpScratch)
+	defer complexScratch.put(aScratch)
+	defer complexScratch.put(bScratch)
+	chirp, a, b := *chirpScratch, *aScratch, *bScratch
+	for k := range chirp {
+		k2 := (k * k) % (2 * n)
+		chirp[k] = cmplx.Rect(1, -math.Pi*float64(k2)/float64(n))
+	}
+
+	for k, v := range x {
+		a[k] = v * chirp[k]
+	}
+	b[0] = cmplx.Conj(chirp[0])
+	for k := 1; k < n; k++ {
+		b[k] = cmplx.Conj(chirp[k])
+		b[m-k] = b[k]
+	}
+	radix2(a)
+	radix2(b)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	ifft(a)
+	for k := range x {
+		x[k] = a[k] * chirp[k]
+	}
+}
+
+// amplitudeSpectrum returns the one-sided amplitude spectrum of the values,
+// bins 0 to N/2, scaled so that a sinusoid centred on a bin gives its peak
+// amplitude there.
+func amplitudeSpectrum(values []float64) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	return amplitudeSpectrumInto(make([]float64, len(values)/2+1), values)
+}
+
+// amplitudeSpectrumInto is amplitudeSpectrum writing the spectrum into
+// amplitudes, which must hold N/2+1 values for N values.
+func amplitudeSpectrumInto(amplitudes, values []float64) []float64 {
+	n := len(values)
+	if n == 0 {
+		return amplitudes[:0]
+	}
+	scratch := complexScratch.get(n)
+	defer complexScratch.put(scratch)
+	x := *scratch
+	for i, v := range values {
+		x[i] = complex(v, 0)
+	}
+	fft(x)
+
+	for k := range amplitudes {
+		amplitudes[k] = 2 * cmplx.Abs(x[k]) / float64(n)
+	}
+	// the zero and Nyquist bins have no negative-frequency twin
+	amplitudes[0] /= 2
+	if n%2 == 0 {
+		amplitudes[n/2] /= 2
+	}
+	return amplitudes
+}
+
+// peakFrequency returns the frequency of the highest line of the spectrum of
+// evenly spaced values, step seconds apart, above lowest Hz. The mean is
+// removed and a Hann window applied to confine leakage, the values are padded
+// with zeros to eight times their length to interpolate the spectrum, and a
+// parabola through the highest line and its neighbours places the peak
+// between lines.
+func peakFrequency(values []float64, step, lowest float64) float64 {
+	n := len(values)
+	if n < 3 {
+		return 0
+	}
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+	paddedScratch := floatScratch.get(1 << bits.Len(uint(8*n-1)))
+	defer floatScratch.put(paddedScratch)
+	padded := *paddedScratch
+	for i, v := range values {
+		padded[i] = (v - mean) * (0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1)))
+	}
+	spectrumScratch := floatScratch.get(len(padded)/2 + 1)
+	defer floatScratch.put(spectrumScratch)
+	spectrum := amplitudeSpectrumInto(*spectrumScratch, padded)
+	resolution := 1 / (step * float64(len(padded)))
+
+	peak := 0
+	for k := max(int(math.Ceil(lowest/resolution)), 1); k < len(spectrum)-1; k++ {
+		if peak == 0 || spectrum[k] > spectrum[peak] {
+			peak = k
+		}
+	}
+	if peak == 0 {
+		return 0
+	}
+	a, b, c := spectrum[peak-1], spectrum[peak], spectrum[peak+1]
+	offset := 0.0
+	if curvature := a - 2*b + c; curvature < 0 {
+		offset = (a - c) / (2 * curvature)
+	}
+	return (float64(peak) + offset) * resolution
+}
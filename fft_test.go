@@ -0,0 +1,67 @@
+package dynamics
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestFFT(t *testing.T) {
+	for _, n := range []int{1, 2, 8, 64, 3, 12, 100, 257} {
+		// Generate sample data
+		rng := rand.New(rand.NewSource(int64(n)))
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rng.NormFloat64(), rng.NormFloat64())
+		}
+
+		// Run the test against the defining sum
+		got := make([]complex128, n)
+		copy(got, x)
+		fft(got)
+		for k := range n {
+			var expected complex128
+			for j, v := range x {
+				expected += v * cmplx.Rect(1, -2*math.Pi*float64(k*j%n)/float64(n))
+			}
+			if cmplx.Abs(got[k]-expected) > 1e-9*float64(n) {
+				t.Errorf("n=%d bin %d: got %v, expected %v", n, k, got[k], expected)
+			}
+		}
+
+		ifft(got)
+		for i := range x {
+			if cmplx.Abs(got[i]-x[i]) > 1e-12*float64(n) {
+				t.Errorf("n=%d: inverse gives %v at %d, expected %v", n, got[i], i, x[i])
+				break
+			}
+		}
+	}
+}
+
+func TestAmplitudeSpectrum(t *testing.T) {
+	// Generate sample data: an offset of 0.5 and a sine of amplitude 3 in bin 7
+	values := make([]float64, 90)
+	for i := range values {
+		values[i] = 0.5 + 3*math.Sin(2*math.Pi*7*float64(i)/90)
+	}
+
+	// Run the test
+	amplitudes := amplitudeSpectrum(values)
+	if len(amplitudes) != 46 {
+		t.Fatalf("got %d bins, expected 46", len(amplitudes))
+	}
+	for k, a := range amplitudes {
+		expected := 0.0
+		switch k {
+		case 0:
+			expected = 0.5
+		case 7:
+			expected = 3
+		}
+		if math.Abs(a-expected) > 1e-9 {
+			t.Errorf("bin %d: amplitude %v, expected %v", k, a, expected)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dynamics
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// biquad is a second-order IIR filter section, designed with the bilinear
+// transform as in R. Bristow-Johnson's Audio EQ Cookbook. Coefficients are
+// normalised so that a0 is 1.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+}
+
+// newBiquad normalises a set of cookbook coefficients.
+func newBiquad(b0, b1, b2, a0, a1, a2 float64) biquad {
+	return biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// lowPassBiquad returns a low-pass section with the given cutoff, as a
+// fraction of the sample rate, and quality factor; a q of 1/√2 is Butterworth.
+func lowPassBiquad(cutoff, q float64) biquad {
+	w0 := 2 * math.Pi * cutoff
+	alpha := math.Sin(w0) / (2 * q)
+	c := math.Cos(w0)
+	return newBiquad((1-c)/2, 1-c, (1-c)/2, 1+alpha, -2*c, 1-alpha)
+}
+
+// highPassBiquad returns a high-pass section with the given cutoff, as a
+// fraction of the sample rate, and quality factor; a q of 1/√2 is Butterworth.
+func highPassBiquad(cutoff, q float64) biquad {
+	w0 := 2 * math.Pi * cutoff
+	alpha := math.Sin(w0) / (2 * q)
+	c := math.Cos(w0)
+	return newBiquad((1+c)/2, -(1 + c), (1+c)/2, 1+alpha, -2*c, 1-alpha)
+}
+
+// bandPassBiquad returns a band-pass section with unit gain at its centre,
+// given as a fraction of the sample rate, and the given quality factor, the
+// centre frequency over the bandwidth.
+func bandPassBiquad(centre, q float64) biquad {
+	w0 := 2 * math.Pi * centre
+	alpha := math.Sin(w0) / (2 * q)
+	return newBiquad(alpha, 0, -alpha, 1+alpha, -2*math.Cos(w0), 1-alpha)
+}
+
+// filter runs the section over the values in place, starting from rest.
+func (f biquad) filter(values []float64) {
+	var x1, x2, y1, y2 float64
+	for i, x := range values {
+		y := f.b0*x + f.b1*x1 + f.b2*x2 - f.a1*y1 - f.a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		values[i] = y
+	}
+}
+
+// response returns the complex gain of the section at a frequency given as a
+// fraction of the sample rate.
+func (f biquad) response(frequency float64) complex128 {
+	z1 := cmplx.Rect(1, -2*math.Pi*frequency) // z⁻¹
+	z2 := z1 * z1
+	num := complex(f.b0, 0) + complex(f.b1, 0)*z1 + complex(f.b2, 0)*z2
+	den := 1 + complex(f.a1, 0)*z1 + complex(f.a2, 0)*z2
+	return num / den
+}
+
+// integrate replaces evenly spaced values with their running integral by the
+// trapezoidal rule, starting from 0.
+func integrate(values []float64, step float64) {
+	var sum, previous float64
+	for i, v := range values {
+		if i > 0 {
+			sum += (v + previous) / 2 * step
+		}
+		previous = v
+		values[i] = sum
+	}
+}
+
+// detrend removes the least-squares straight line from evenly spaced values.
+func detrend(values []float64) {
+	n := float64(len(values))
+	if n < 2 {
+		return
+	}
+	// fit v = mean + slope·(i − centre)
+	centre := (n - 1) / 2
+	var mean, slope, spread float64
+	for i, v := range values {
+		mean += v
+		slope += (float64(i) - centre) * v
+		spread += (float64(i) - centre) * (float64(i) - centre)
+	}
+	mean /= n
+	slope /= spread
+	for i := range values {
+		values[i] -= mean + slope*(float64(i)-centre)
+	}
+}
+
+// sampleValues returns the values of the samples.
+func sampleValues(data []SingleChannelSample) []float64 {
+	values := make([]float64, len(data))
+	for i, sample := range data {
+		values[i] = sample.Value
+	}
+	return values
+}
@@ -0,0 +1,66 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBiquadGain(t *testing.T) {
+	// steady-state gain of each section for a sine at the given fraction of the sample rate
+	gain := func(f biquad, frequency float64) float64 {
+		values := make([]float64, 20000)
+		for i := range values {
+			values[i] = math.Sin(2 * math.Pi * frequency * float64(i))
+		}
+		f.filter(values)
+		// the last 10000 samples hold whole cycles at every test frequency
+		var sumSq float64
+		for _, v := range values[10000:] {
+			sumSq += v * v
+		}
+		return math.Sqrt(2 * sumSq / 10000)
+	}
+
+	// Run the test
+	tests := []struct {
+		name      string
+		filter    biquad
+		frequency float64
+		expected  float64
+	}{
+		{"low-pass at cutoff", lowPassBiquad(0.01, 1/math.Sqrt2), 0.01, 1 / math.Sqrt2},
+		{"low-pass in band", lowPassBiquad(0.1, 1/math.Sqrt2), 0.001, 1},
+		{"high-pass at cutoff", highPassBiquad(0.01, 1/math.Sqrt2), 0.01, 1 / math.Sqrt2},
+		{"high-pass in band", highPassBiquad(0.001, 1/math.Sqrt2), 0.1, 1},
+		{"band-pass at centre", bandPassBiquad(0.01, 2), 0.01, 1},
+	}
+	for _, tt := range tests {
+		if got := gain(tt.filter, tt.frequency); math.Abs(got-tt.expected) > 1e-3 {
+			t.Errorf("%s: gain %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestIntegrateDetrend(t *testing.T) {
+	// Generate sample data: a line 3 + 2i with a cosine on it
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = 3 + 2*float64(i) + math.Cos(2*math.Pi*float64(i)/100)
+	}
+
+	// Run the test: the cosine survives, being orthogonal to the line over whole cycles
+	detrend(values)
+	for i, v := range values {
+		if expected := math.Cos(2 * math.Pi * float64(i) / 100); math.Abs(v-expected) > 0.05 {
+			t.Fatalf("detrended value %v at %d, expected about %v", v, i, expected)
+		}
+	}
+
+	ramp := []float64{0, 1, 2, 3}
+	integrate(ramp, 0.5)
+	for i, expected := range []float64{0, 0.25, 1, 2.25} {
+		if ramp[i] != expected {
+			t.Errorf("integral %v at %d, expected %v", ramp[i], i, expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package dynamics
+
+import (
+	"context"
+	"time"
+)
+
+// generatorTick is the wall-clock interval at which a Generator emits the
+// samples that have come due.
+const generatorTick = time.Millisecond
+
+// Generator is an endless source of samples of one waveform, paced in real
+// time, for soak-testing consumers such as a CircularBuffer.
+type Generator struct {
+	config ChannelConfig
+}
+
+// NewGenerator creates a Generator of the waveform the config describes, as
+// GenerateMultiChannel makes a channel of it.
+//
+// Parameters:
+//   - config: The waveform, frequency, amplitude and phase of the samples
+//
+// Returns:
+//   - *Generator: The new generator
+//   - error: An error if the config has an unknown waveform, a frequency,
+//     amplitude or phase that is not finite, or a duty cycle outside 0 to 1
+func NewGenerator(config ChannelConfig) (*Generator, error) {
+	if err := config.check("generator"); err != nil {
+		return nil, err
+	}
+	// keep a copy of the duty cycle, which the caller may go on to change
+	if config.DutyCycle != nil {
+		dutyCycle := *config.DutyCycle
+		config.DutyCycle = &dutyCycle
+	}
+	return &Generator{config: config}, nil
+}
+
+// Start emits samples on the returned channel until ctx is cancelled, and
+// then closes it. Sample i is timed i/sampleRate seconds after the call, the
+// origin, and is sent once the monotonic clock has passed that time; each
+// millisecond the samples that have come due are sent together. The samples
+// are counted from the origin rather than from tick to tick, so jitter in the
+// ticks neither drops nor repeats a sample, and the times are strictly
+// increasing. A consumer that falls behind delays the samples rather than
+// losing them, and the generator catches up once it is read again. A sample
+// rate that is not positive gives a channel that is closed at once.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the generator
+//   - sampleRate: The number of samples per second
+//
+// Returns:
+//   - <-chan SingleChannelSample: The samples, closed once the generator has stopped
+func (g *Generator) Start(ctx context.Context, sampleRate int) <-chan SingleChannelSample {
+	out := make(chan SingleChannelSample, max(sampleRate/int(time.Second/generatorTick), 1))
+	if sampleRate <= 0 {
+		close(out)
+		return out
+	}
+
+	origin := time.Now()
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(generatorTick)
+		defer ticker.Stop()
+
+		timeStep := 1.0 / float64(sampleRate)
+		next := 0 // index of the next sample to send
+		for {
+			// time.Since reads the monotonic clock, so wall-clock steps do not disturb the pacing
+			due := int(time.Since(origin).Seconds()*float64(sampleRate)) + 1
+			for ; next < due; next++ {
+				sample := SingleChannelSample{Time: float64(next) * timeStep, Value: g.config.value(next, sampleRate)}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- sample:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out
+}
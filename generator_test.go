@@ -0,0 +1,82 @@
+package dynamics
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGenerator(t *testing.T) {
+	// Generate sample data: about 100 ms of 200 Hz into a buffer holding 50 ms
+	generator, err := NewGenerator(ChannelConfig{Frequency: 200, Amplitude: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := NewCircularBuffer(500)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Run the test
+	var count int
+	for sample := range generator.Start(ctx, 10000) {
+		if want := float64(count) * (1.0 / 10000); sample.Time != want {
+			t.Fatalf("sample %d at %v s, expected %v s", count, sample.Time, want)
+		}
+		if want := math.Sin(2 * math.Pi * 200 * sample.Time); math.Abs(sample.Value-want) > 1e-9 {
+			t.Fatalf("sample %d is %v, expected %v", count, sample.Value, want)
+		}
+		buf.Update(sample)
+		count++
+	}
+
+	// the pacing follows the clock, give or take the scheduling of a loaded machine
+	if count < 500 || count > 1500 {
+		t.Errorf("%d samples in 100 ms, expected about 1000", count)
+	}
+	if result := buf.Analysis(); math.Abs(result.NZCR-200) > 25 {
+		t.Errorf("buffer NZCR %v, expected about 200", result.NZCR)
+	}
+	if rms := buf.GetBufferRMS(); math.Abs(rms-1/math.Sqrt2) > 0.05 {
+		t.Errorf("buffer RMS %v, expected about %v", rms, 1/math.Sqrt2)
+	}
+}
+
+func TestGeneratorSlowConsumer(t *testing.T) {
+	generator, err := NewGenerator(ChannelConfig{Waveform: WaveformSquare, Frequency: 10, Amplitude: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples := generator.Start(ctx, 1000)
+
+	// Run the test: a consumer that stalls misses nothing
+	time.Sleep(30 * time.Millisecond)
+	for i := range 100 {
+		sample := <-samples
+		if want := float64(i) * (1.0 / 1000); sample.Time != want {
+			t.Fatalf("sample %d at %v s, expected %v s", i, sample.Time, want)
+		}
+	}
+	cancel()
+	for range samples {
+		// drain until the generator closes the channel
+	}
+}
+
+func TestGeneratorInvalid(t *testing.T) {
+	if _, err := NewGenerator(ChannelConfig{Waveform: Waveform(9), Frequency: 50, Amplitude: 1}); err == nil {
+		t.Error("unknown waveform: expected an error")
+	}
+	if _, err := NewGenerator(ChannelConfig{Frequency: math.Inf(1), Amplitude: 1}); err == nil {
+		t.Error("infinite frequency: expected an error")
+	}
+	generator, err := NewGenerator(ChannelConfig{Frequency: 50, Amplitude: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-generator.Start(context.Background(), 0); ok {
+		t.Error("zero sample rate: expected a closed channel")
+	}
+}
@@ -0,0 +1,47 @@
+package dynamics
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Goertzel returns the phasor of the component of the data at the given
+// frequency, computed with the Goertzel algorithm: its magnitude is the peak
+// amplitude of the component and its angle the phase, in radians, of the
+// cosine at time 0, so phasors of channels sampled together can be compared.
+//
+// The samples are taken to be evenly spaced. The phasor is accurate when the
+// data spans a whole number of cycles of the frequency; trim the data to whole
+// cycles, as KeepXSecondsOfData with a whole number of periods does, to keep
+// other components from leaking into it.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency of the component in Hz
+//
+// Returns:
+//   - complex128: The phasor, 0 for fewer than two samples or a zero duration
+func Goertzel(data []SingleChannelSample, frequency float64) complex128 {
+	n := len(data)
+	if n < 2 {
+		return 0
+	}
+	first := data[0].Time
+	step := (data[n-1].Time - first) / float64(n-1)
+	if !(step > 0) {
+		return 0
+	}
+
+	omega := 2 * math.Pi * frequency * step
+	coefficient := 2 * math.Cos(omega)
+	var s1, s2 float64
+	for _, sample := range data {
+		s1, s2 = sample.Value+coefficient*s1-s2, s1
+	}
+
+	// y is the sum of x[k]·e^(jω(n-1-k)); turning it back by ω(n-1) gives the
+	// sum of x[k]·e^(-jωk), which is referred to the first sample
+	y := complex(s1-math.Cos(omega)*s2, math.Sin(omega)*s2)
+	sum := y * cmplx.Rect(1, -omega*float64(n-1))
+	return sum * complex(2/float64(n), 0) * cmplx.Rect(1, -2*math.Pi*frequency*first)
+}
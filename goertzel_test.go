@@ -0,0 +1,33 @@
+package dynamics
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestGoertzel(t *testing.T) {
+	// Generate sample data: 2 cos(2π·50t + 30°) plus a harmonic, starting at t = 12.3
+	const phase = math.Pi / 6
+	data := make([]SingleChannelSample, 10000)
+	for i := range data {
+		tm := 12.3 + float64(i)/10000
+		data[i] = SingleChannelSample{Time: tm, Value: 2*math.Cos(2*math.Pi*50*tm+phase) + 0.5*math.Sin(2*math.Pi*150*tm)}
+	}
+	data = KeepXSecondsOfData(data, 0.99)
+
+	// Run the test
+	phasor := Goertzel(data, 50)
+	if got := cmplx.Abs(phasor); math.Abs(got-2) > 1e-3 {
+		t.Errorf("magnitude = %v, expected 2", got)
+	}
+	if got := cmplx.Phase(phasor); math.Abs(got-phase) > 1e-3 {
+		t.Errorf("phase = %v, expected %v", got, phase)
+	}
+	if got := cmplx.Abs(Goertzel(data, 150)); math.Abs(got-0.5) > 1e-3 {
+		t.Errorf("harmonic magnitude = %v, expected 0.5", got)
+	}
+	if got := Goertzel(data[:1], 50); got != 0 {
+		t.Errorf("single sample gave %v, expected 0", got)
+	}
+}
@@ -0,0 +1,75 @@
+package dynamics
+
+import "math"
+
+// gridFilterQ is the quality factor of the band-pass filter that
+// GridFrequencyProfile applies around the nominal frequency. It attenuates the
+// third harmonic about fivefold while the phase of the filter output changes
+// by under a hundredth of a cycle for a 0.1 Hz step in frequency.
+const gridFilterQ = 2
+
+// GridFrequencyProfile reports the frequency of a supply over successive
+// intervals of a recording. The signal is first band-pass filtered around
+// the nominal frequency, so harmonics cannot add crossings, and the
+// positive-going zero crossings of the result are located between samples
+// by linear interpolation. As in IEC 61000-4-30, the frequency of an interval
+// is the number of whole cycles between its first and last crossing divided
+// by the time they span. The deviation from nominal, and the largest
+// excursions, follow directly from the profile.
+//
+// The samples are taken to be evenly spaced. The first five nominal cycles,
+// while the filter settles, are not measured.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - nominal: The nominal frequency of the supply in Hz
+//   - reportInterval: The length of each interval in seconds
+//
+// Returns:
+//   - []SingleChannelSample: The frequency of each interval holding at least
+//     one whole cycle, timed at the interval's end; none when nominal or
+//     reportInterval is not positive and finite
+func GridFrequencyProfile(data []SingleChannelSample, nominal, reportInterval float64) []SingleChannelSample {
+	if !(nominal > 0) || math.IsInf(nominal, 1) || !(reportInterval > 0) || math.IsInf(reportInterval, 1) || len(data) < 2 {
+		return nil
+	}
+	step := (data[len(data)-1].Time - data[0].Time) / float64(len(data)-1)
+	if !(step > 0) {
+		return nil
+	}
+
+	filtered := sampleValues(data)
+	bandPassBiquad(nominal*step, gridFilterQ).filter(filtered)
+	settled := data[0].Time + 5/nominal
+
+	var profile []SingleChannelSample
+	interval := -1
+	var first, last float64 // crossing times in the current interval
+	var cycles int
+	report := func() {
+		if cycles > 0 {
+			end := data[0].Time + float64(interval+1)*reportInterval
+			profile = append(profile, SingleChannelSample{Time: end, Value: float64(cycles) / (last - first)})
+		}
+	}
+	for i := 1; i < len(filtered); i++ {
+		if !(filtered[i-1] < 0 && filtered[i] >= 0) {
+			continue
+		}
+		crossing := data[i-1].Time + step*filtered[i-1]/(filtered[i-1]-filtered[i])
+		if crossing < settled {
+			continue
+		}
+
+		k := int(math.Floor((crossing - data[0].Time) / reportInterval))
+		if k != interval {
+			report()
+			interval, first, cycles = k, crossing, 0
+		} else {
+			cycles++
+		}
+		last = crossing
+	}
+	report()
+	return profile
+}
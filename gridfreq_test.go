@@ -0,0 +1,56 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGridFrequencyProfile(t *testing.T) {
+	// Generate sample data: 20 s of a 50 Hz supply at 10 kHz that dips to
+	// 49.9 Hz from 5 s to 10 s, with third and fifth harmonics
+	data := make([]SingleChannelSample, 200000)
+	phase := 0.0
+	for i := range data {
+		tm := float64(i) / 10000
+		frequency := 50.0
+		if tm >= 5 && tm < 10 {
+			frequency = 49.9
+		}
+		data[i] = SingleChannelSample{Time: tm, Value: 325*math.Sin(phase) + 30*math.Sin(3*phase) + 15*math.Sin(5*phase)}
+		phase += 2 * math.Pi * frequency / 10000
+	}
+
+	// Run the test
+	profile := GridFrequencyProfile(data, 50, 1)
+	if len(profile) != 20 {
+		t.Fatalf("got %d intervals, expected 20", len(profile))
+	}
+	for k, point := range profile {
+		if expected := float64(k + 1); math.Abs(point.Time-expected) > 1e-9 {
+			t.Errorf("interval %d ends at %v, expected %v", k, point.Time, expected)
+		}
+		// the filter takes a few cycles to follow each change, at the start of intervals 5 and 10
+		if k == 5 || k == 10 {
+			continue
+		}
+		expected := 50.0
+		if k > 5 && k < 10 {
+			expected = 49.9
+		}
+		if math.Abs(point.Value-expected) > 0.002 {
+			t.Errorf("interval %d frequency = %v, expected %v", k, point.Value, expected)
+		}
+	}
+}
+
+func TestGridFrequencyProfileInvalid(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+
+	// Run the test
+	for _, c := range []struct{ nominal, interval float64 }{{0, 1}, {50, 0}, {math.NaN(), 1}, {50, math.Inf(1)}} {
+		if profile := GridFrequencyProfile(data, c.nominal, c.interval); profile != nil {
+			t.Errorf("nominal %v, interval %v gave %v, expected nothing", c.nominal, c.interval, profile)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package dynamics
+
+import "math"
+
+// HalfCycleOption configures a call to HalfCycleRMS.
+type HalfCycleOption func(*halfCycleConfig)
+
+// halfCycleConfig holds the settings made by HalfCycleOptions.
+type halfCycleConfig struct {
+	filtered bool
+}
+
+// WithCrossingFilter finds the cycle boundaries on a copy of the signal
+// band-pass filtered around the fundamental, as GridFrequencyProfile does, so
+// that harmonics and noise cannot add crossings. The filter has no phase shift
+// at the fundamental, but takes a few cycles to settle at the start.
+func WithCrossingFilter() HalfCycleOption {
+	return func(c *halfCycleConfig) {
+		c.filtered = true
+	}
+}
+
+// HalfCycleRMS returns the RMS over one cycle of the fundamental, refreshed
+// every half cycle, the Urms(1/2) of IEC 61000-4-30. The cycles run between
+// zero crossings of the signal, located between samples by linear
+// interpolation, so each value covers one whole cycle however the frequency
+// drifts. A crossing less than a quarter cycle after the one before is taken
+// for noise and ignored, and where the signal collapses and no crossing comes
+// within three quarters of a cycle the boundaries carry on at the nominal
+// half-cycle spacing. Samples before the first crossing and after the last
+// boundary are left out.
+//
+// Each value is timed at the end of its cycle. A step in level is smeared over
+// one cycle: the values of cycles straddling the step lie between the old and
+// new levels, and the first value wholly after it, at most one cycle and a half
+// later, has the new level. The mean square of a cycle is the integral of the
+// squared values, by the trapezoidal rule with intervals straddling a crossing
+// split at it, over the time the samples cover, so it does not change with the
+// number of samples that happen to fall between the crossings. An interval
+// more than one and a half times the median interval is a gap in the data and
+// is left out of both; a cycle wholly within a gap yields no level.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - fundamental: The frequency of the fundamental in Hz
+//   - opts: Options such as WithCrossingFilter
+//
+// Returns:
+//   - []SingleChannelSample: The one-cycle RMS values, none when fundamental
+//     is not positive and finite or the data holds less than one cycle
+func HalfCycleRMS(data []SingleChannelSample, fundamental float64, opts ...HalfCycleOption) []SingleChannelSample {
+	if !(fundamental > 0) || math.IsInf(fundamental, 1) || len(data) < 2 {
+		return nil
+	}
+	var config halfCycleConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	step := (data[len(data)-1].Time - data[0].Time) / float64(len(data)-1)
+	if !(step > 0) {
+		return nil
+	}
+	values := sampleValues(data)
+	if config.filtered {
+		bandPassBiquad(fundamental*step, gridFilterQ).filter(values)
+	}
+	boundaries := halfCycleBoundaries(data, values, 1/(2*fundamental))
+	if len(boundaries) < 3 {
+		return nil
+	}
+
+	// the integral of the squared values and the time covered in each half cycle
+	intervals := make([]float64, len(data)-1)
+	for i := range intervals {
+		intervals[i] = data[i+1].Time - data[i].Time
+	}
+	gap := 1.5 * median(intervals)
+	sumSq := make([]float64, len(boundaries)-1)
+	covered := make([]float64, len(boundaries)-1)
+	h := 0
+	for i := 1; i < len(data); i++ {
+		a, b := data[i-1].Time, data[i].Time
+		if b <= a || b-a > gap || b <= boundaries[0] {
+			continue
+		}
+		for h < len(sumSq) && boundaries[h+1] <= a {
+			h++
+		}
+		if h == len(sumSq) {
+			break
+		}
+		density := (data[i-1].Value*data[i-1].Value + data[i].Value*data[i].Value) / 2
+		for j := h; j < len(sumSq) && boundaries[j] < b; j++ {
+			if overlap := math.Min(b, boundaries[j+1]) - math.Max(a, boundaries[j]); overlap > 0 {
+				sumSq[j] += density * overlap
+				covered[j] += overlap
+			}
+		}
+	}
+
+	levels := make([]SingleChannelSample, 0, len(sumSq)-1)
+	for k := 0; k+1 < len(sumSq); k++ {
+		// a gap in the data leaves no level rather than a false zero
+		span := covered[k] + covered[k+1]
+		if span == 0 {
+			continue
+		}
+		rms := math.Sqrt((sumSq[k] + sumSq[k+1]) / span)
+		levels = append(levels, SingleChannelSample{Time: boundaries[k+2], Value: rms})
+	}
+	return levels
+}
+
+// halfCycleBoundaries returns the times of the zero crossings of the values,
+// either way, that bound the half cycles of HalfCycleRMS.
+func halfCycleBoundaries(data []SingleChannelSample, values []float64, half float64) []float64 {
+	var boundaries []float64
+	for i := 1; i < len(values); i++ {
+		a, b := values[i-1], values[i]
+		if n := len(boundaries); n > 0 {
+			// carry on at the nominal spacing through a collapse
+			for data[i].Time-boundaries[n-1] > 1.5*half {
+				boundaries = append(boundaries, boundaries[n-1]+half)
+				n++
+			}
+		}
+		if !(a < 0 && b >= 0) && !(a > 0 && b <= 0) {
+			continue
+		}
+		crossing := data[i-1].Time + (data[i].Time-data[i-1].Time)*a/(a-b)
+		if n := len(boundaries); n > 0 && crossing-boundaries[n-1] < half/2 {
+			continue
+		}
+		boundaries = append(boundaries, crossing)
+	}
+	return boundaries
+}
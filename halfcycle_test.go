@@ -0,0 +1,115 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHalfCycleRMS(t *testing.T) {
+	// Generate sample data: 0.2 s at 10 kHz of a 50 Hz, 230 V RMS supply
+	// whose amplitude halves at 0.1 s, a quarter of the way into a cycle
+	data := GenerateSineWave(50, 230*math.Sqrt2, 0.2, 10000)
+	for i := range data {
+		if data[i].Time >= 0.105 {
+			data[i].Value /= 2
+		}
+	}
+
+	// Run the test
+	levels := HalfCycleRMS(data, 50)
+	if len(levels) < 15 {
+		t.Fatalf("got %d levels, expected one every half cycle", len(levels))
+	}
+	for k, level := range levels {
+		// each value ends at a zero crossing, half a cycle after the one before
+		if k > 0 && math.Abs(level.Time-levels[k-1].Time-0.01) > 1e-6 {
+			t.Errorf("level %d at %v s, expected 10 ms after %v s", k, level.Time, levels[k-1].Time)
+		}
+		switch {
+		case level.Time <= 0.105:
+			if math.Abs(level.Value-230) > 0.5 {
+				t.Errorf("before the step: %v V at %v s, expected 230", level.Value, level.Time)
+			}
+		case level.Time >= 0.105+0.02:
+			if math.Abs(level.Value-115) > 0.5 {
+				t.Errorf("after the step: %v V at %v s, expected 115", level.Value, level.Time)
+			}
+		default:
+			// the cycles straddling the step lie between the levels
+			if level.Value <= 115 || level.Value >= 230 {
+				t.Errorf("during the step: %v V at %v s, expected between 115 and 230", level.Value, level.Time)
+			}
+		}
+	}
+}
+
+func TestHalfCycleRMSFiltered(t *testing.T) {
+	// Generate sample data: a 50 Hz supply with a strong 7th harmonic that
+	// adds crossings of its own
+	data := GenerateSineWave(50, 100, 0.5, 10000)
+	for i := range data {
+		data[i].Value += 60 * math.Sin(2*math.Pi*350*data[i].Time)
+	}
+	expected := math.Sqrt(100*100/2 + 60*60/2)
+
+	// Run the test: after the filter settles, the cycles are whole
+	for _, level := range HalfCycleRMS(data, 50, WithCrossingFilter()) {
+		if level.Time > 0.2 && math.Abs(level.Value-expected) > 0.01*expected {
+			t.Errorf("%v at %v s, expected %v", level.Value, level.Time, expected)
+		}
+	}
+}
+
+func TestHalfCycleRMSCollapse(t *testing.T) {
+	// Generate sample data: a supply that drops to zero from 0.1 s to 0.2 s
+	data := GenerateSineWave(50, 100, 0.3, 10000)
+	for i := range data {
+		if data[i].Time >= 0.1 && data[i].Time < 0.2 {
+			data[i].Value = 0
+		}
+	}
+
+	// Run the test: the half cycles carry on through the interruption
+	levels := HalfCycleRMS(data, 50)
+	var zero int
+	for k, level := range levels {
+		if k > 0 && math.Abs(level.Time-levels[k-1].Time-0.01) > 1e-3 {
+			t.Errorf("level %d at %v s, expected about 10 ms after %v s", k, level.Time, levels[k-1].Time)
+		}
+		if level.Value == 0 {
+			zero++
+		}
+	}
+	if zero < 8 {
+		t.Errorf("got %d zero levels during the interruption, expected 8 or more", zero)
+	}
+	if HalfCycleRMS(data, 0) != nil {
+		t.Error("zero fundamental: expected nil")
+	}
+}
+
+func TestHalfCycleRMSGap(t *testing.T) {
+	// Generate sample data: 0.3 s at 10 kHz of a 50 Hz, 230 V RMS supply with
+	// the samples from 0.100 s to 0.105 s missing
+	var data []SingleChannelSample
+	for _, sample := range GenerateSineWave(50, 230*math.Sqrt2, 0.3, 10000) {
+		if sample.Time < 0.1 || sample.Time > 0.105 {
+			data = append(data, sample)
+		}
+	}
+
+	// Run the test: the cycles around the gap are measured over the time
+	// their samples cover
+	levels := HalfCycleRMS(data, 50)
+	if len(levels) < 25 {
+		t.Fatalf("got %d levels, expected one every half cycle", len(levels))
+	}
+	for _, level := range levels {
+		if math.Abs(level.Value-230) > 1 {
+			t.Errorf("%v V at %v s, expected 230", level.Value, level.Time)
+		}
+	}
+	if events := DetectSagsSwells(data, 230, 50); len(events) != 0 {
+		t.Errorf("DetectSagsSwells returned %v, expected no events", events)
+	}
+}
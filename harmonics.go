@@ -0,0 +1,91 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// harmonicWindowCycles is the number of cycles of the fundamental that
+// HarmonicReport analyses, the window of IEC 61000-4-7 for 50 Hz systems.
+const harmonicWindowCycles = 10
+
+// maxHarmonicOrder is the highest harmonic HarmonicReport measures.
+const maxHarmonicOrder = 50
+
+// HarmonicDistortion is a report of the harmonic content of a signal.
+type HarmonicDistortion struct {
+	Fundamental float64         `json:"fundamental"` // RMS magnitude of the fundamental
+	THD         float64         `json:"thd"`         // total harmonic distortion in percent of the fundamental
+	Harmonics   []HarmonicLevel `json:"harmonics"`   // orders 2 to 50, or up to the highest below the Nyquist frequency
+	Pass        bool            `json:"pass"`        // every harmonic is within its limit
+}
+
+// HarmonicLevel is the level of one harmonic and its verdict against a limit.
+type HarmonicLevel struct {
+	Order     int     `json:"order"`
+	Magnitude float64 `json:"magnitude"` // RMS magnitude
+	Percent   float64 `json:"percent"`   // magnitude in percent of the fundamental
+	Limit     float64 `json:"limit"`     // limit in percent, 0 when the order has none
+	Pass      bool    `json:"pass"`      // the percentage is within the limit, or there is none
+}
+
+// HarmonicReport measures the harmonics of the fundamental, orders 2 to 50,
+// over the last ten whole cycles of the data as IEC 61000-4-7 does (or every
+// whole cycle when there are fewer), and checks each against a limit in
+// percent of the fundamental, as IEEE 519 tabulates them. Harmonics at or above
+// the Nyquist frequency are left out.
+//
+// Parameters:
+//   - data: A slice of evenly spaced Sample structs
+//   - fundamental: The frequency of the fundamental in Hz
+//   - limits: The limit in percent for each harmonic order that has one
+//
+// Returns:
+//   - HarmonicDistortion: The report, zero on error
+//   - error: ErrEmptyData if data is empty, ErrInvalidFrequency if fundamental
+//     is not positive and finite, ErrUnsortedData if the data is not in time
+//     order, or ErrTooShort if it spans less than one cycle
+func HarmonicReport(data []SingleChannelSample, fundamental float64, limits map[int]float64) (HarmonicDistortion, error) {
+	if len(data) == 0 {
+		return HarmonicDistortion{}, ErrEmptyData
+	}
+	if !(fundamental > 0) || math.IsInf(fundamental, 1) {
+		return HarmonicDistortion{}, fmt.Errorf("%w: %g Hz", ErrInvalidFrequency, fundamental)
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return HarmonicDistortion{}, err
+	}
+	start, end, cycles := wholeCycles(data, fundamental, harmonicWindowCycles)
+	if cycles == 0 {
+		return HarmonicDistortion{}, ErrTooShort
+	}
+	data = data[start:end]
+
+	report := HarmonicDistortion{Pass: true}
+	report.Fundamental = cmplx.Abs(Goertzel(data, fundamental)) / math.Sqrt2
+	nyquist := float64(len(data)-1) / (data[len(data)-1].Time - data[0].Time) / 2
+
+	var sumSq float64
+	for order := 2; order <= maxHarmonicOrder && float64(order)*fundamental < nyquist*(1-1e-9); order++ {
+		level := HarmonicLevel{
+			Order:     order,
+			Magnitude: cmplx.Abs(Goertzel(data, float64(order)*fundamental)) / math.Sqrt2,
+			Limit:     limits[order],
+			Pass:      true,
+		}
+		sumSq += level.Magnitude * level.Magnitude
+		if report.Fundamental > 0 {
+			level.Percent = 100 * level.Magnitude / report.Fundamental
+		}
+		if level.Limit > 0 && level.Percent > level.Limit {
+			level.Pass = false
+			report.Pass = false
+		}
+		report.Harmonics = append(report.Harmonics, level)
+	}
+	if report.Fundamental > 0 {
+		report.THD = 100 * math.Sqrt(sumSq) / report.Fundamental
+	}
+	return report, nil
+}
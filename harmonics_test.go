@@ -0,0 +1,78 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestHarmonicReport(t *testing.T) {
+	// Generate sample data: 50 Hz with 3%, 8% and 2% of the 3rd, 5th and 7th harmonics
+	levels := map[int]float64{3: 3, 5: 8, 7: 2}
+	data := GenerateSineWave(50, 100, 1, 10000)
+	for i := range data {
+		for order, percent := range levels {
+			data[i].Value += percent * math.Sin(2*math.Pi*50*float64(order)*data[i].Time)
+		}
+	}
+	limits := map[int]float64{3: 5, 5: 6, 7: 5, 11: 3.5}
+
+	// Run the test
+	report, err := HarmonicReport(data, 50, limits)
+	if err != nil {
+		t.Fatalf("HarmonicReport returned error: %v", err)
+	}
+	if math.Abs(report.Fundamental-100/math.Sqrt2) > 1e-3 {
+		t.Errorf("Fundamental = %v, expected %v", report.Fundamental, 100/math.Sqrt2)
+	}
+	if expected := math.Sqrt(9 + 64 + 4); math.Abs(report.THD-expected) > 1e-3 {
+		t.Errorf("THD = %v%%, expected %v%%", report.THD, expected)
+	}
+	if report.Pass {
+		t.Error("Pass = true, expected the 5th harmonic to fail")
+	}
+	if len(report.Harmonics) != 49 {
+		t.Fatalf("got %d harmonics, expected orders 2 to 50", len(report.Harmonics))
+	}
+	for _, level := range report.Harmonics {
+		if math.Abs(level.Percent-levels[level.Order]) > 1e-3 {
+			t.Errorf("order %d: Percent = %v, expected %v", level.Order, level.Percent, levels[level.Order])
+		}
+		if level.Limit != limits[level.Order] {
+			t.Errorf("order %d: Limit = %v, expected %v", level.Order, level.Limit, limits[level.Order])
+		}
+		if level.Pass != (level.Order != 5) {
+			t.Errorf("order %d: Pass = %v", level.Order, level.Pass)
+		}
+	}
+}
+
+func TestHarmonicReportNyquist(t *testing.T) {
+	// Generate sample data: at 1 kHz only orders below 10 fit under the Nyquist frequency
+	data := GenerateSineWave(50, 1, 1, 1000)
+
+	// Run the test
+	report, err := HarmonicReport(data, 50, nil)
+	if err != nil {
+		t.Fatalf("HarmonicReport returned error: %v", err)
+	}
+	if len(report.Harmonics) != 8 || !report.Pass {
+		t.Errorf("got %d harmonics and Pass %v, expected orders 2 to 9 passing", len(report.Harmonics), report.Pass)
+	}
+}
+
+func TestHarmonicReportErrors(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+
+	// Run the test
+	if _, err := HarmonicReport(nil, 50, nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got error %v, expected ErrEmptyData", err)
+	}
+	if _, err := HarmonicReport(data, -50, nil); !errors.Is(err, ErrInvalidFrequency) {
+		t.Errorf("negative fundamental: got error %v, expected ErrInvalidFrequency", err)
+	}
+	if _, err := HarmonicReport(data[:15], 50, nil); !errors.Is(err, ErrTooShort) {
+		t.Errorf("less than a cycle: got error %v, expected ErrTooShort", err)
+	}
+}
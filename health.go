@@ -0,0 +1,234 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Direction is the way an indicator of a HealthScore moves as a machine's
+// health worsens.
+type Direction int
+
+const (
+	DirectionRising  Direction = iota // a value above the baseline is worse, as for RMS
+	DirectionFalling                  // a value below the baseline is worse
+	DirectionEither                   // any departure from the baseline is worse
+)
+
+// Indicator is one measure combined by a HealthScore.
+//
+// Extract computes the indicator from a window of data. It cannot be stored
+// as JSON, so an indicator that is to be reproduced from its configuration
+// names a registered extractor in Extractor instead, and Extract is left nil;
+// see RegisterExtractor.
+type Indicator struct {
+	Name      string                              `json:"name"`
+	Extract   func([]SingleChannelSample) float64 `json:"-"`
+	Extractor string                              `json:"extractor,omitempty"` // registered extractor used when Extract is nil
+	Baseline  float64                             `json:"baseline"`            // value of a healthy machine, positive
+	Weight    float64                             `json:"weight"`              // relative weight, not negative
+	Direction Direction                           `json:"direction"`
+}
+
+// IndicatorResult is the part one indicator played in a score.
+type IndicatorResult struct {
+	Name         string  `json:"name"`
+	Value        float64 `json:"value"`        // the extracted value
+	Deviation    float64 `json:"deviation"`    // relative departure from the baseline in the worsening direction, 0 or more
+	Contribution float64 `json:"contribution"` // the part of the score due to the indicator
+	Share        float64 `json:"share"`        // Contribution as a fraction of the score, 0 when the score is 0
+}
+
+// HealthScore combines indicators of a machine's condition, such as RMS,
+// crest factor, kurtosis and band energies, into a single score. Each value is
+// normalised against its baseline as a relative deviation, (value −
+// baseline)/baseline, counted only in the worsening direction, and the score
+// is the weighted mean of the deviations. A machine at its baseline scores 0,
+// and one whose indicators have all doubled scores 1.
+//
+// A HealthScore, and the results of scoring, encode to JSON, so a score can be
+// reproduced from its stored configuration when its indicators use registered
+// extractors.
+type HealthScore struct {
+	Indicators []Indicator `json:"indicators"`
+}
+
+// Built-in extractors registered under these names.
+const (
+	ExtractorRMS         = "rms"
+	ExtractorPeak        = "peak"
+	ExtractorCrestFactor = "crest_factor"
+	ExtractorKurtosis    = "kurtosis"
+)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = map[string]func([]SingleChannelSample) float64{
+		ExtractorRMS:         calculateRMS,
+		ExtractorPeak:        peakValue,
+		ExtractorCrestFactor: CrestFactor,
+		ExtractorKurtosis:    kurtosis,
+	}
+)
+
+// RegisterExtractor registers an extractor under a name, so that indicators
+// naming it in Extractor can be decoded from JSON and scored. The names rms,
+// peak, crest_factor and kurtosis are registered by the package. It is safe
+// to call concurrently with scoring.
+//
+// Parameters:
+//   - name: The name of the extractor
+//   - fn: The function computing the indicator from a window of data
+//
+// Returns:
+//   - error: An error if name is empty or already registered, or fn is nil
+func RegisterExtractor(name string, fn func([]SingleChannelSample) float64) error {
+	if name == "" {
+		return errors.New("dynamics: extractor name is empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("dynamics: extractor %q is nil", name)
+	}
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	if _, ok := extractors[name]; ok {
+		return fmt.Errorf("dynamics: extractor %q is already registered", name)
+	}
+	extractors[name] = fn
+	return nil
+}
+
+// Score scores a window of data. It returns NaN and no results when the score
+// is undefined; see ScoreE.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the window
+//
+// Returns:
+//   - float64: The weighted mean deviation from the baselines, 0 at baseline
+//   - []IndicatorResult: The result of each indicator, largest contribution first
+func (h HealthScore) Score(data []SingleChannelSample) (float64, []IndicatorResult) {
+	score, results, err := h.ScoreE(data)
+	if err != nil {
+		return math.NaN(), nil
+	}
+	return score, results
+}
+
+// ScoreE is Score, reporting why the score is undefined. Indicators of equal
+// contribution keep their configured order.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the window
+//
+// Returns:
+//   - float64: The weighted mean deviation from the baselines, 0 at baseline
+//   - []IndicatorResult: The result of each indicator, largest contribution first
+//   - error: ErrEmptyData if there is no data, an error wrapping ErrNonFinite
+//     if an indicator's value is not finite, or an error if there are no
+//     indicators, an indicator is unnamed, duplicated, has no extractor or an
+//     unregistered one, a baseline is not positive and finite, a weight is
+//     negative or not finite, every weight is zero or a direction is unknown
+func (h HealthScore) ScoreE(data []SingleChannelSample) (float64, []IndicatorResult, error) {
+	if len(data) == 0 {
+		return 0, nil, ErrEmptyData
+	}
+	extract, err := h.extractors()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var totalWeight float64
+	for _, ind := range h.Indicators {
+		totalWeight += ind.Weight
+	}
+	if totalWeight == 0 {
+		return 0, nil, errors.New("dynamics: health indicator weights are all zero")
+	}
+
+	results := make([]IndicatorResult, len(h.Indicators))
+	var score float64
+	for i, ind := range h.Indicators {
+		value := extract[i](data)
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, nil, fmt.Errorf("%w: health indicator %q has value %g", ErrNonFinite, ind.Name, value)
+		}
+		deviation := (value - ind.Baseline) / ind.Baseline
+		switch ind.Direction {
+		case DirectionRising:
+			deviation = max(deviation, 0)
+		case DirectionFalling:
+			deviation = max(-deviation, 0)
+		case DirectionEither:
+			deviation = math.Abs(deviation)
+		}
+		contribution := ind.Weight / totalWeight * deviation
+		results[i] = IndicatorResult{Name: ind.Name, Value: value, Deviation: deviation, Contribution: contribution}
+		score += contribution
+	}
+	for i := range results {
+		if score > 0 {
+			results[i].Share = results[i].Contribution / score
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Contribution > results[j].Contribution })
+	return score, results, nil
+}
+
+// extractors validates the configuration and returns the extractor of each indicator.
+func (h HealthScore) extractors() ([]func([]SingleChannelSample) float64, error) {
+	if len(h.Indicators) == 0 {
+		return nil, errors.New("dynamics: health score has no indicators")
+	}
+	extract := make([]func([]SingleChannelSample) float64, len(h.Indicators))
+	names := make(map[string]bool, len(h.Indicators))
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	for i, ind := range h.Indicators {
+		switch {
+		case ind.Name == "":
+			return nil, fmt.Errorf("dynamics: health indicator %d is unnamed", i)
+		case names[ind.Name]:
+			return nil, fmt.Errorf("dynamics: health indicator %q is duplicated", ind.Name)
+		case !(ind.Baseline > 0) || math.IsInf(ind.Baseline, 1):
+			return nil, fmt.Errorf("dynamics: baseline of health indicator %q must be positive", ind.Name)
+		case !(ind.Weight >= 0) || math.IsInf(ind.Weight, 1):
+			return nil, fmt.Errorf("dynamics: weight of health indicator %q must not be negative", ind.Name)
+		case ind.Direction < DirectionRising || ind.Direction > DirectionEither:
+			return nil, fmt.Errorf("dynamics: health indicator %q has unknown direction %d", ind.Name, ind.Direction)
+		}
+		names[ind.Name] = true
+
+		extract[i] = ind.Extract
+		if extract[i] == nil {
+			if ind.Extractor == "" {
+				return nil, fmt.Errorf("dynamics: health indicator %q has no extractor", ind.Name)
+			}
+			if extract[i] = extractors[ind.Extractor]; extract[i] == nil {
+				return nil, fmt.Errorf("dynamics: health indicator %q names unregistered extractor %q", ind.Name, ind.Extractor)
+			}
+		}
+	}
+	return extract, nil
+}
+
+// peakValue returns the largest absolute value of the data.
+func peakValue(data []SingleChannelSample) float64 {
+	var peak float64
+	for _, sample := range data {
+		peak = max(peak, math.Abs(sample.Value))
+	}
+	return peak
+}
+
+// kurtosis returns the kurtosis of the data about its mean, 3 for Gaussian
+// noise and 1.5 for a sine, NaN when the data is constant. Unlike Kurtosis it
+// does not subtract 3, so that its baseline is positive.
+func kurtosis(data []SingleChannelSample) float64 {
+	var m moments
+	m.add(data)
+	return m.kurtosis()
+}
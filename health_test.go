@@ -0,0 +1,177 @@
+package dynamics
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHealthScore(t *testing.T) {
+	// Generate sample data: a sine of amplitude 2, so RMS √2, crest factor √2 and kurtosis 1.5
+	data := GenerateSineWave(10, 2, 1, 1000)
+	health := HealthScore{Indicators: []Indicator{
+		{Name: "offset", Extract: func([]SingleChannelSample) float64 { return 5 }, Baseline: 4, Weight: 1, Direction: DirectionEither},
+		{Name: "crest", Extractor: ExtractorCrestFactor, Baseline: 2, Weight: 1, Direction: DirectionFalling},
+		{Name: "rms", Extractor: ExtractorRMS, Baseline: 1, Weight: 2, Direction: DirectionRising},
+		{Name: "kurtosis", Extractor: ExtractorKurtosis, Baseline: 3, Weight: 4, Direction: DirectionRising},
+	}}
+
+	// Run the test
+	score, results := health.Score(data)
+
+	// weights sum to 8: rms 2/8·(√2−1), crest 1/8·(1−√2/2), offset 1/8·0.25, kurtosis below baseline
+	want := []IndicatorResult{
+		{Name: "rms", Value: math.Sqrt2, Deviation: math.Sqrt2 - 1, Contribution: (math.Sqrt2 - 1) / 4},
+		{Name: "crest", Value: math.Sqrt2, Deviation: 1 - math.Sqrt2/2, Contribution: (1 - math.Sqrt2/2) / 8},
+		{Name: "offset", Value: 5, Deviation: 0.25, Contribution: 0.25 / 8},
+		{Name: "kurtosis", Value: 1.5},
+	}
+	wantScore := want[0].Contribution + want[1].Contribution + want[2].Contribution
+	if math.Abs(score-wantScore) > 1e-9 {
+		t.Errorf("score %v, expected %v", score, wantScore)
+	}
+	if len(results) != len(want) {
+		t.Fatalf("%d results, expected %d", len(results), len(want))
+	}
+	var shares float64
+	for i, r := range results {
+		w := want[i]
+		if r.Name != w.Name {
+			t.Errorf("result %d is %q, expected %q", i, r.Name, w.Name)
+			continue
+		}
+		if math.Abs(r.Value-w.Value) > 1e-9 || math.Abs(r.Deviation-w.Deviation) > 1e-9 || math.Abs(r.Contribution-w.Contribution) > 1e-9 {
+			t.Errorf("%s: got %+v, expected %+v", r.Name, r, w)
+		}
+		if math.Abs(r.Share-w.Contribution/wantScore) > 1e-9 {
+			t.Errorf("%s: share %v, expected %v", r.Name, r.Share, w.Contribution/wantScore)
+		}
+		shares += r.Share
+	}
+	if math.Abs(shares-1) > 1e-9 {
+		t.Errorf("shares sum to %v, expected 1", shares)
+	}
+}
+
+func TestHealthScoreBaseline(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(10, 1, 1, 1000)
+	health := HealthScore{Indicators: []Indicator{
+		{Name: "rms", Extractor: ExtractorRMS, Baseline: math.Sqrt2 / 2, Weight: 1},
+		{Name: "peak", Extractor: ExtractorPeak, Baseline: 1, Weight: 1, Direction: DirectionEither},
+	}}
+
+	// Run the test
+	score, results := health.Score(data)
+
+	if math.Abs(score) > 1e-9 {
+		t.Errorf("score %v at baseline, expected 0", score)
+	}
+	if len(results) != 2 {
+		t.Errorf("%d results, expected 2", len(results))
+	}
+
+	// an indicator exactly at its baseline contributes nothing, and has no share of a zero score
+	exact := HealthScore{Indicators: []Indicator{{Name: "level", Extract: func([]SingleChannelSample) float64 { return 2 }, Baseline: 2, Weight: 1}}}
+	if score, results := exact.Score(data); score != 0 || results[0].Share != 0 {
+		t.Errorf("exact baseline: score %v share %v, expected 0 and 0", score, results[0].Share)
+	}
+}
+
+func TestHealthScoreJSON(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 3, 0.2, 5000)
+	if err := RegisterExtractor("test_mean_abs", func(data []SingleChannelSample) float64 {
+		var sum float64
+		for _, sample := range data {
+			sum += math.Abs(sample.Value)
+		}
+		return sum / float64(len(data))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	health := HealthScore{Indicators: []Indicator{
+		{Name: "rms", Extractor: ExtractorRMS, Baseline: 1.5, Weight: 3},
+		{Name: "crest", Extractor: ExtractorCrestFactor, Baseline: 1.6, Weight: 1, Direction: DirectionFalling},
+		{Name: "mean", Extractor: "test_mean_abs", Baseline: 2, Weight: 1, Direction: DirectionEither},
+	}}
+
+	// Run the test
+	encoded, err := json.Marshal(health)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded HealthScore
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, health) {
+		t.Fatalf("decoded %+v, expected %+v", decoded, health)
+	}
+
+	score, results := health.Score(data)
+	decodedScore, decodedResults := decoded.Score(data)
+	if math.IsNaN(score) || score != decodedScore || !reflect.DeepEqual(results, decodedResults) {
+		t.Errorf("decoded configuration scores %v %+v, expected %v %+v", decodedScore, decodedResults, score, results)
+	}
+
+	encodedResults, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTrip []IndicatorResult
+	if err := json.Unmarshal(encodedResults, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roundTrip, results) {
+		t.Errorf("results decoded as %+v, expected %+v", roundTrip, results)
+	}
+}
+
+func TestHealthScoreInvalid(t *testing.T) {
+	data := GenerateSineWave(10, 1, 1, 1000)
+	rms := Indicator{Name: "rms", Extractor: ExtractorRMS, Baseline: 1, Weight: 1}
+	with := func(change func(*Indicator)) HealthScore {
+		ind := rms
+		change(&ind)
+		return HealthScore{Indicators: []Indicator{ind}}
+	}
+	tests := []struct {
+		name   string
+		health HealthScore
+	}{
+		{"no indicators", HealthScore{}},
+		{"unnamed", with(func(ind *Indicator) { ind.Name = "" })},
+		{"duplicated", HealthScore{Indicators: []Indicator{rms, rms}}},
+		{"zero baseline", with(func(ind *Indicator) { ind.Baseline = 0 })},
+		{"negative weight", with(func(ind *Indicator) { ind.Weight = -1 })},
+		{"zero weights", with(func(ind *Indicator) { ind.Weight = 0 })},
+		{"unknown direction", with(func(ind *Indicator) { ind.Direction = 3 })},
+		{"no extractor", with(func(ind *Indicator) { ind.Extractor = "" })},
+		{"unregistered extractor", with(func(ind *Indicator) { ind.Extractor = "missing" })},
+	}
+	for _, tt := range tests {
+		if _, _, err := tt.health.ScoreE(data); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if score, results := tt.health.Score(data); !math.IsNaN(score) || results != nil {
+			t.Errorf("%s: Score gave %v %v, expected NaN and no results", tt.name, score, results)
+		}
+	}
+
+	if _, _, err := (HealthScore{Indicators: []Indicator{rms}}).ScoreE(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("no data: got %v, expected ErrEmptyData", err)
+	}
+	kurt := HealthScore{Indicators: []Indicator{{Name: "kurtosis", Extractor: ExtractorKurtosis, Baseline: 3, Weight: 1}}}
+	if _, _, err := kurt.ScoreE(make([]SingleChannelSample, 10)); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("silent data: got %v, expected ErrNonFinite", err)
+	}
+	if err := RegisterExtractor(ExtractorRMS, calculateRMS); err == nil {
+		t.Error("registering rms again: expected an error")
+	}
+	if err := RegisterExtractor("test_nil", nil); err == nil {
+		t.Error("nil extractor: expected an error")
+	}
+}
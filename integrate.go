@@ -0,0 +1,111 @@
+package dynamics
+
+// IntegrateOption configures a call to Integrate.
+type IntegrateOption func(*integrateConfig)
+
+// integrateConfig holds the settings made by IntegrateOptions.
+type integrateConfig struct {
+	removeDrift bool
+}
+
+// WithDriftRemoval removes the drift that integration turns an offset into.
+// The mean of the data, weighted by the time each sample covers, is
+// subtracted before integrating, so a bias in an accelerometer does not
+// become a ramp in velocity, and the mean of the integral, which depends only
+// on where integration started, is subtracted after. Integrating a sine then
+// gives a cosine centred on zero. Drift from noise below the frequencies of
+// interest is not removed; AccelerationToDisplacement filters it out.
+func WithDriftRemoval() IntegrateOption {
+	return func(c *integrateConfig) {
+		c.removeDrift = true
+	}
+}
+
+// Integrate returns the running integral of the data by the trapezoidal rule,
+// starting from 0 at the first sample, over the actual intervals between the
+// timestamps, so the samples need not be evenly spaced. Integrating an
+// acceleration gives a velocity, in the units of the acceleration times
+// seconds.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - opts: Options such as WithDriftRemoval
+//
+// Returns:
+//   - []SingleChannelSample: The integral on the timebase of the data; nil
+//     when the data is empty or not in time order
+func Integrate(data []SingleChannelSample, opts ...IntegrateOption) []SingleChannelSample {
+	if len(data) == 0 || checkTimeOrder(data) != nil {
+		return nil
+	}
+	var config integrateConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var offset float64
+	if duration := data[len(data)-1].Time - data[0].Time; config.removeDrift && duration > 0 {
+		// the mean the trapezoidal rule sees, the integral over the duration
+		offset = trapezoid(data) / duration
+	}
+	integral := make([]SingleChannelSample, len(data))
+	var sum float64
+	for i, sample := range data {
+		if i > 0 {
+			prev := data[i-1]
+			sum += ((sample.Value - offset) + (prev.Value - offset)) / 2 * (sample.Time - prev.Time)
+		}
+		integral[i] = SingleChannelSample{Time: sample.Time, Value: sum}
+	}
+	if config.removeDrift {
+		subtractMean(integral)
+	}
+	return integral
+}
+
+// trapezoid returns the integral of the data over its duration by the
+// trapezoidal rule.
+func trapezoid(data []SingleChannelSample) float64 {
+	var sum float64
+	for i := 1; i < len(data); i++ {
+		sum += (data[i].Value + data[i-1].Value) / 2 * (data[i].Time - data[i-1].Time)
+	}
+	return sum
+}
+
+// Differentiate returns the rate of change of the data, as from a velocity to
+// an acceleration or an acceleration to a jerk, in the units of the data per
+// second. Each interior sample takes the three-point central difference over
+// its neighbours, weighted for unequal intervals so that it is exact for a
+// quadratic through the three samples, and the same as (x[i+1] −
+// x[i−1])/(2·step) for evenly spaced samples. The first and last samples take
+// the one-sided difference to their only neighbour.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - []SingleChannelSample: The derivative on the timebase of the data; nil
+//     for fewer than two samples, or data not in strictly increasing time order
+func Differentiate(data []SingleChannelSample) []SingleChannelSample {
+	if len(data) < 2 {
+		return nil
+	}
+	for i := 1; i < len(data); i++ {
+		if !(data[i].Time > data[i-1].Time) {
+			return nil
+		}
+	}
+
+	n := len(data)
+	derivative := make([]SingleChannelSample, n)
+	derivative[0] = SingleChannelSample{Time: data[0].Time, Value: (data[1].Value - data[0].Value) / (data[1].Time - data[0].Time)}
+	for i := 1; i < n-1; i++ {
+		h1 := data[i].Time - data[i-1].Time
+		h2 := data[i+1].Time - data[i].Time
+		value := (h1*h1*data[i+1].Value - h2*h2*data[i-1].Value + (h2*h2-h1*h1)*data[i].Value) / (h1 * h2 * (h1 + h2))
+		derivative[i] = SingleChannelSample{Time: data[i].Time, Value: value}
+	}
+	derivative[n-1] = SingleChannelSample{Time: data[n-1].Time, Value: (data[n-1].Value - data[n-2].Value) / (data[n-1].Time - data[n-2].Time)}
+	return derivative
+}
@@ -0,0 +1,106 @@
+package dynamics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestIntegrate(t *testing.T) {
+	// Generate sample data: 1 s of a 10 Hz sine of amplitude 3 at 10 kHz,
+	// riding on a bias of 0.5
+	const f, a = 10.0, 3.0
+	data := GenerateSineWave(f, a, 1, 10000, WithDCOffset(0.5))
+
+	// Run the test: without drift removal the bias ramps to 0.5 by the end
+	raw := Integrate(data)
+	if len(raw) != len(data) || raw[0].Value != 0 {
+		t.Fatalf("Integrate returned %d samples starting at %v, expected %d starting at 0", len(raw), raw[0].Value, len(data))
+	}
+	if end := raw[len(raw)-1].Value; math.Abs(end-0.5*0.9999) > 1e-3 {
+		t.Errorf("raw integral ends at %v, expected the ramp of the bias, about 0.5", end)
+	}
+
+	// with drift removal the integral is −A/(2πf)·cos(2πft)
+	velocity := Integrate(data, WithDriftRemoval())
+	amplitude := a / (2 * math.Pi * f)
+	for i, sample := range velocity {
+		if sample.Time != data[i].Time {
+			t.Fatalf("sample %d at %v s, expected the time of the data, %v s", i, sample.Time, data[i].Time)
+		}
+		if expected := -amplitude * math.Cos(2*math.Pi*f*sample.Time); math.Abs(sample.Value-expected) > 1e-3*amplitude {
+			t.Fatalf("integral %v at %v s, expected %v", sample.Value, sample.Time, expected)
+		}
+	}
+	if rms := calculateRMS(velocity); math.Abs(rms-amplitude/math.Sqrt2) > 1e-3*amplitude {
+		t.Errorf("integral has RMS %v, expected %v", rms, amplitude/math.Sqrt2)
+	}
+}
+
+func TestIntegrateUneven(t *testing.T) {
+	// Generate sample data: a ramp v = 2t at irregular times, whose
+	// trapezoidal integral t² is exact
+	rng := rand.New(rand.NewSource(1))
+	var data []SingleChannelSample
+	for tm := 0.0; tm < 5; tm += 0.01 + 0.1*rng.Float64() {
+		data = append(data, SingleChannelSample{Time: tm, Value: 2 * tm})
+	}
+
+	// Run the test
+	for i, sample := range Integrate(data) {
+		if expected := data[i].Time * data[i].Time; math.Abs(sample.Value-expected) > 1e-9 {
+			t.Fatalf("integral %v at %v s, expected %v", sample.Value, sample.Time, expected)
+		}
+	}
+	if Integrate(nil) != nil {
+		t.Error("Integrate of no data: expected nil")
+	}
+	data[3], data[4] = data[4], data[3]
+	if Integrate(data) != nil {
+		t.Error("Integrate of unsorted data: expected nil")
+	}
+}
+
+func TestDifferentiate(t *testing.T) {
+	// Generate sample data: 1 s of a 10 Hz sine of amplitude 3 at 10 kHz
+	const f, a = 10.0, 3.0
+	data := GenerateSineWave(f, a, 1, 10000)
+
+	// Run the test: the derivative is 2πfA·cos(2πft)
+	derivative := Differentiate(data)
+	if len(derivative) != len(data) {
+		t.Fatalf("Differentiate returned %d samples, expected %d", len(derivative), len(data))
+	}
+	for i, sample := range derivative[1 : len(derivative)-1] {
+		expected := 2 * math.Pi * f * a * math.Cos(2*math.Pi*f*sample.Time)
+		if math.Abs(sample.Value-expected) > 1e-4*2*math.Pi*f*a {
+			t.Fatalf("derivative %v at sample %d, expected %v", sample.Value, i+1, expected)
+		}
+	}
+	// differentiating the integral recovers the signal
+	for i, sample := range Differentiate(Integrate(data))[1 : len(data)-1] {
+		if math.Abs(sample.Value-data[i+1].Value) > 1e-3 {
+			t.Fatalf("derivative of the integral %v at sample %d, expected %v", sample.Value, i+1, data[i+1].Value)
+		}
+	}
+
+	// the weighted difference is exact for a quadratic at irregular times
+	rng := rand.New(rand.NewSource(2))
+	var quadratic []SingleChannelSample
+	for tm := 0.0; tm < 5; tm += 0.01 + 0.1*rng.Float64() {
+		quadratic = append(quadratic, SingleChannelSample{Time: tm, Value: 3*tm*tm - tm})
+	}
+	derivative = Differentiate(quadratic)
+	for i := 1; i < len(quadratic)-1; i++ {
+		if expected := 6*quadratic[i].Time - 1; math.Abs(derivative[i].Value-expected) > 1e-9 {
+			t.Fatalf("derivative %v at %v s, expected %v", derivative[i].Value, quadratic[i].Time, expected)
+		}
+	}
+
+	if Differentiate(data[:1]) != nil {
+		t.Error("Differentiate of one sample: expected nil")
+	}
+	if Differentiate([]SingleChannelSample{{Time: 0, Value: 1}, {Time: 0, Value: 2}}) != nil {
+		t.Error("Differentiate of samples at the same time: expected nil")
+	}
+}
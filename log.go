@@ -0,0 +1,28 @@
+package dynamics
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// logger receives the package's diagnostic output; nil discards it.
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger routes the package's diagnostic output to l. Output is logged at
+// debug level, or warning level for suspicious input that is still analysed.
+// By default, and after SetLogger(nil), nothing is logged. SetLogger is safe to
+// call concurrently with the analysis functions.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// loggerFor returns the configured logger if it is enabled for the level, or
+// nil, so that callers only build log attributes when they will be used.
+func loggerFor(level slog.Level) *slog.Logger {
+	l := logger.Load()
+	if l == nil || !l.Enabled(context.Background(), level) {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,137 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ChannelResult holds the analysis of one channel of a multi-channel window.
+type ChannelResult struct {
+	Channel int     `json:"channel"`
+	RMS     float64 `json:"rms"`
+	Peak    float64 `json:"peak"`
+	NZCR    float64 `json:"nzcr"`
+}
+
+// MultiChannelResult holds the per-channel analysis of a multi-channel window.
+type MultiChannelResult struct {
+	Time     float64         `json:"time"`     // time of the newest sample analysed
+	Samples  int             `json:"samples"`  // number of samples analysed per channel
+	Channels []ChannelResult `json:"channels"` // one result per channel, in channel order
+	Partial  bool            `json:"partial"`  // the result was flushed by Close before it was due
+}
+
+// MultiChannelStreamAnalyzer is the multi-channel counterpart of
+// StreamAnalyzer: it analyses interleaved multi-channel frames over a sliding
+// window, keeping independent running sums per channel, and produces one
+// MultiChannelResult every hop seconds once the window has filled.
+//
+// A MultiChannelStreamAnalyzer is safe for concurrent use. The callback is
+// invoked while the analyzer's lock is held and must not call back into the
+// analyzer.
+type MultiChannelStreamAnalyzer struct {
+	mu       sync.Mutex
+	window   *slidingWindow
+	schedule hopSchedule
+	onResult func(MultiChannelResult)
+	last     float64 // time of the most recent sample
+	pushed   bool
+	pending  bool // samples have been analysed since the last result
+	closed   bool
+}
+
+// NewMultiChannelStreamAnalyzer creates a MultiChannelStreamAnalyzer.
+//
+// Parameters:
+//   - channels: The number of values in every pushed sample
+//   - window: The length of the analysis window in seconds
+//   - hop: The sample time between successive results in seconds
+//   - fn: Callback receiving each result
+//
+// Returns:
+//   - *MultiChannelStreamAnalyzer: The new analyzer
+//   - error: An error if channels is not positive, window or hop is not a
+//     positive, finite number, or fn is nil
+func NewMultiChannelStreamAnalyzer(channels int, window, hop float64, fn func(MultiChannelResult)) (*MultiChannelStreamAnalyzer, error) {
+	if channels < 1 {
+		return nil, errors.New("dynamics: stream must have at least one channel")
+	}
+	if !(window > 0) || math.IsInf(window, 1) {
+		return nil, errors.New("dynamics: stream window must be positive")
+	}
+	if !(hop > 0) || math.IsInf(hop, 1) {
+		return nil, errors.New("dynamics: stream hop must be positive")
+	}
+	if fn == nil {
+		return nil, errors.New("dynamics: stream callback is nil")
+	}
+
+	return &MultiChannelStreamAnalyzer{
+		window:   newSlidingWindow(window, channels),
+		schedule: hopSchedule{window: window, hop: hop},
+		onResult: fn,
+	}, nil
+}
+
+// Push adds a multi-channel sample to the stream. Samples must carry exactly
+// one value per configured channel and arrive in time order; other samples
+// are rejected with an error, as is any sample pushed after Close.
+func (ma *MultiChannelStreamAnalyzer) Push(sample MultiChannelSample) error {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+
+	if ma.closed {
+		return ErrClosed
+	}
+	if len(sample.Value) != ma.window.channels {
+		return fmt.Errorf("%w: sample at time %g has %d channels, expected %d", ErrChannelMismatch, sample.Time, len(sample.Value), ma.window.channels)
+	}
+	if ma.pushed && sample.Time < ma.last {
+		return errors.New("dynamics: sample time is earlier than the previous sample")
+	}
+	ma.pushed = true
+	ma.last = sample.Time
+
+	ma.window.push(sample.Time, sample.Value)
+	ma.pending = true
+	if ma.schedule.advance(sample.Time) {
+		ma.emit(false)
+	}
+	return nil
+}
+
+// Close ends the stream. If any samples have arrived since the last result, a
+// final result flagged as Partial is emitted for the current window. Closing
+// an analyzer more than once is harmless.
+func (ma *MultiChannelStreamAnalyzer) Close() error {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+
+	if ma.closed {
+		return nil
+	}
+	ma.closed = true
+	if ma.pending {
+		ma.emit(true)
+	}
+	return nil
+}
+
+// emit delivers the analysis of the current window. The caller must hold ma.mu.
+func (ma *MultiChannelStreamAnalyzer) emit(partial bool) {
+	ma.pending = false
+
+	result := MultiChannelResult{
+		Channels: make([]ChannelResult, ma.window.channels),
+		Partial:  partial,
+	}
+	for c := range result.Channels {
+		channel := ma.window.result(c)
+		result.Time = channel.Time
+		result.Samples = channel.Samples
+		result.Channels[c] = ChannelResult{Channel: c, RMS: channel.RMS, Peak: channel.Peak, NZCR: channel.NZCR}
+	}
+	ma.onResult(result)
+}
@@ -0,0 +1,89 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMultiChannelStreamAnalyzer(t *testing.T) {
+	// Generate sample data
+	channel1 := GenerateSineWave(60, 1, 2, 2000)
+	channel2 := GenerateSineWave(150, 3, 2, 2000)
+	data := make([]MultiChannelSample, len(channel1))
+	for i := range channel1 {
+		data[i] = MultiChannelSample{
+			Time:  channel1[i].Time,
+			Value: []float64{channel1[i].Value, channel2[i].Value},
+		}
+	}
+
+	// Run the test
+	var results []MultiChannelResult
+	ma, err := NewMultiChannelStreamAnalyzer(2, 0.5, 0.25, func(result MultiChannelResult) {
+		results = append(results, result)
+	})
+	if err != nil {
+		t.Fatalf("NewMultiChannelStreamAnalyzer returned error: %v", err)
+	}
+	for _, sample := range data {
+		if err := ma.Push(sample); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+	}
+
+	if len(results) != 6 {
+		t.Fatalf("Analyzer produced %d results, expected 6", len(results))
+	}
+	expectedRMS := []float64{0.7071, 2.1213}
+	expectedPeak := []float64{1, 3}
+	expectedNZCR := []float64{60, 150}
+	for _, result := range results {
+		if len(result.Channels) != 2 || result.Samples != 1000 {
+			t.Fatalf("Result at %f has %d channels over %d samples, expected 2 over 1000", result.Time, len(result.Channels), result.Samples)
+		}
+		for c, channel := range result.Channels {
+			if diff := math.Abs(channel.RMS - expectedRMS[c]); diff > 0.001 {
+				t.Errorf("Channel %d RMS %f, expected %f (difference: %f)", c, channel.RMS, expectedRMS[c], diff)
+			}
+			if diff := math.Abs(channel.Peak - expectedPeak[c]); diff > 0.01 {
+				t.Errorf("Channel %d peak %f, expected %f (difference: %f)", c, channel.Peak, expectedPeak[c], diff)
+			}
+			if diff := math.Abs(channel.NZCR - expectedNZCR[c]); diff > 2.1 {
+				t.Errorf("Channel %d NZCR %f, expected %f (difference: %f)", c, channel.NZCR, expectedNZCR[c], diff)
+			}
+		}
+	}
+}
+
+func TestMultiChannelStreamAnalyzerChannelMismatch(t *testing.T) {
+	ma, _ := NewMultiChannelStreamAnalyzer(2, 1, 1, func(MultiChannelResult) {})
+
+	// Run the test
+	if err := ma.Push(MultiChannelSample{Time: 0, Value: []float64{1, 2, 3}}); !errors.Is(err, ErrChannelMismatch) {
+		t.Errorf("Push accepted a sample with 3 channels into a 2-channel analyzer")
+	}
+	if err := ma.Push(MultiChannelSample{Time: 0, Value: nil}); err == nil {
+		t.Errorf("Push accepted a sample with no channels")
+	}
+}
+
+func TestMultiChannelStreamAnalyzerClose(t *testing.T) {
+	var results []MultiChannelResult
+	ma, _ := NewMultiChannelStreamAnalyzer(1, 1, 1, func(result MultiChannelResult) {
+		results = append(results, result)
+	})
+
+	// Run the test
+	for _, sample := range GenerateSineWave(50, 2, 0.4, 1000) {
+		_ = ma.Push(MultiChannelSample{Time: sample.Time, Value: []float64{sample.Value}})
+	}
+	_ = ma.Close()
+
+	if len(results) != 1 || !results[0].Partial || results[0].Samples != 400 {
+		t.Fatalf("Close produced %+v, expected one partial result over 400 samples", results)
+	}
+	if err := ma.Push(MultiChannelSample{Time: 1, Value: []float64{0}}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push after Close returned %v, expected ErrClosed", err)
+	}
+}
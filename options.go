@@ -0,0 +1,246 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+)
+
+// NonFinitePolicy selects how the Analyze family treats NaN and ±Inf sample values.
+type NonFinitePolicy int
+
+const (
+	// NonFinitePropagate analyses non-finite values as they are, so a NaN or
+	// ±Inf carries into the RMS. It is the default.
+	NonFinitePropagate NonFinitePolicy = iota
+	// NonFiniteStrict rejects data holding a non-finite value with an error
+	// wrapping ErrNonFinite that names the first offending sample.
+	NonFiniteStrict
+	// NonFiniteSkip drops samples with non-finite values and analyses the rest;
+	// the duration runs from the first to the last finite sample.
+	NonFiniteSkip
+)
+
+// DefaultMaxCycles is the number of whole cycles at the end of the data that
+// RMS, and the Analyze family without WithMaxCycles, take the RMS over.
+const DefaultMaxCycles = 1000
+
+// AnalyzeOption configures a call to Analyze, AnalyzeE, AnalyzeMultiChannel or AnalyzeMultiChannelE.
+type AnalyzeOption func(*analyzeConfig)
+
+// analyzeConfig holds the settings made by AnalyzeOptions.
+type analyzeConfig struct {
+	nonFinite   NonFinitePolicy
+	maxCycles   int  // 0 for no limit
+	compensated bool // always sum squares with compensation
+	removeDC    bool // subtract the mean before analysing
+	acf         bool // take the frequency from EstimateFrequencyACF
+}
+
+// WithNonFinite sets the policy for NaN and ±Inf sample values.
+func WithNonFinite(policy NonFinitePolicy) AnalyzeOption {
+	return func(c *analyzeConfig) {
+		c.nonFinite = policy
+	}
+}
+
+// WithMaxCycles sets the most whole cycles at the end of the data that the RMS
+// is taken over, in place of DefaultMaxCycles. Zero or less removes the limit,
+// so the RMS covers every whole cycle in the data.
+func WithMaxCycles(n int) AnalyzeOption {
+	return func(c *analyzeConfig) {
+		c.maxCycles = max(n, 0)
+	}
+}
+
+// WithCompensatedSum makes the RMS sum the squared values with compensated
+// (Neumaier) summation whatever the length of the data. Without it,
+// compensation is only used for windows of a million samples or more, where
+// rounding in a plain sum starts to show; shorter windows use the faster plain
+// sum unless values of very different magnitude are mixed.
+func WithCompensatedSum() AnalyzeOption {
+	return func(c *analyzeConfig) {
+		c.compensated = true
+	}
+}
+
+// WithRemoveDC subtracts the mean of the data, as RemoveDC does, before
+// analysing it, so that the RMS and crossing rate are those of the AC part of
+// a signal riding on an offset. The mean is taken after the non-finite policy
+// has been applied, and the data passed in is not changed. It applies to the
+// Analyze family, Analyzer and AnalyzeMultiChannel, which takes the mean of
+// each channel. ChunkedAnalyze and Analyze32E, which analyse the data in a
+// single pass before its mean is known, reject it with ErrUnsupportedOption,
+// and Analyze32 then yields zeros.
+func WithRemoveDC() AnalyzeOption {
+	return func(c *analyzeConfig) {
+		c.removeDC = true
+	}
+}
+
+// WithACFFrequency takes the frequency that the RMS window is aligned to from
+// EstimateFrequencyACF instead of the negative zero crossing rate, for noisy
+// signals whose crossings the noise multiplies. The NZCR reported is then the
+// estimated frequency. Data the estimator rejects, such as unevenly spaced
+// samples, is analysed as having no frequency, its RMS taken over all the
+// samples. Like WithRemoveDC it applies to the Analyze family, Analyzer and
+// AnalyzeMultiChannel, which estimates the frequency of each channel, and
+// ChunkedAnalyze and Analyze32E reject it with ErrUnsupportedOption.
+func WithACFFrequency() AnalyzeOption {
+	return func(c *analyzeConfig) {
+		c.acf = true
+	}
+}
+
+// checkSinglePass reports the options that need the whole record before the
+// analysis can start, which ChunkedAnalyze and Analyze32E cannot apply.
+func (c analyzeConfig) checkSinglePass() error {
+	if c.removeDC {
+		return fmt.Errorf("%w: WithRemoveDC", ErrUnsupportedOption)
+	}
+	if c.acf {
+		return fmt.Errorf("%w: WithACFFrequency", ErrUnsupportedOption)
+	}
+	return nil
+}
+
+// newAnalyzeConfig applies the options to the default configuration.
+func newAnalyzeConfig(opts []AnalyzeOption) analyzeConfig {
+	// applying an option moves the config to the heap, so skip it when there are none
+	if len(opts) == 0 {
+		return analyzeConfig{maxCycles: DefaultMaxCycles}
+	}
+	c := analyzeConfig{maxCycles: DefaultMaxCycles}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// prepare applies the non-finite policy to the data, and subtracts its mean
+// under WithRemoveDC. The data is only copied when samples have to be dropped
+// or values changed.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - []SingleChannelSample: The data to analyse
+//   - error: An error wrapping ErrNonFinite under NonFiniteStrict
+func (c analyzeConfig) prepare(data []SingleChannelSample) ([]SingleChannelSample, error) {
+	return c.prepareInto(nil, data)
+}
+
+// prepareInto is prepare, copying any samples it keeps into dst[:0] so that
+// a caller can reuse the same storage from call to call.
+//
+// Parameters:
+//   - dst: Storage for the kept samples, used only when samples are dropped or values changed
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - []SingleChannelSample: The data to analyse, either data itself or a slice of dst
+//   - error: An error wrapping ErrNonFinite under NonFiniteStrict
+func (c analyzeConfig) prepareInto(dst, data []SingleChannelSample) ([]SingleChannelSample, error) {
+	prepared, err := c.finiteInto(dst, data)
+	if err != nil || !c.removeDC || len(prepared) == 0 {
+		return prepared, err
+	}
+	if !copied(prepared, data) {
+		prepared = append(dst[:0], data...)
+	}
+	return subtractMean(prepared), nil
+}
+
+// copied reports whether prepareInto copied the samples it kept out of data
+// rather than returning data itself.
+func copied(prepared, data []SingleChannelSample) bool {
+	return len(prepared) < len(data) || len(prepared) > 0 && &prepared[0] != &data[0]
+}
+
+// admits reports whether the non-finite policy lets a value into the
+// analysis, as finiteInto would.
+func (c analyzeConfig) admits(value float64) bool {
+	return c.nonFinite == NonFinitePropagate || !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// finiteInto applies the non-finite policy to the data as prepareInto does.
+func (c analyzeConfig) finiteInto(dst, data []SingleChannelSample) ([]SingleChannelSample, error) {
+	if c.nonFinite == NonFinitePropagate {
+		return data, nil
+	}
+
+	for i, sample := range data {
+		if !math.IsNaN(sample.Value) && !math.IsInf(sample.Value, 0) {
+			continue
+		}
+		if c.nonFinite == NonFiniteStrict {
+			return nil, nonFiniteError(i, sample.Time, sample.Value)
+		}
+
+		finite := append(dst[:0], data[:i]...)
+		for _, sample := range data[i+1:] {
+			if !math.IsNaN(sample.Value) && !math.IsInf(sample.Value, 0) {
+				finite = append(finite, sample)
+			}
+		}
+		return finite, nil
+	}
+	return data, nil
+}
+
+// frequency returns the frequency of the data that the RMS window is aligned
+// to, the NZCR or, under WithACFFrequency, the autocorrelation estimate, 0
+// when the estimator rejects the data.
+func (c analyzeConfig) frequency(data []SingleChannelSample) float64 {
+	if !c.acf {
+		return NegativeZeroCrossingRate(data)
+	}
+	frequency, err := EstimateFrequencyACF(data)
+	if err != nil {
+		return 0
+	}
+	return frequency
+}
+
+// rms returns the RMS of the data, summing with compensation when configured
+// or when the data is long enough to need it.
+func (c analyzeConfig) rms(data []SingleChannelSample) float64 {
+	if !c.compensated {
+		return calculateRMS(data)
+	}
+	if len(data) == 0 {
+		return 0
+	}
+	return math.Sqrt(sumSquaresCompensated(data) / float64(len(data)))
+}
+
+// prepareScratch is prepare, taking the storage for any copy from
+// sampleScratch. The caller must hand the returned scratch, which may be nil,
+// back with sampleScratch.put once it has finished with the prepared data.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - []SingleChannelSample: The data to analyse
+//   - *[]SingleChannelSample: The scratch storage holding it, or nil
+//   - error: An error wrapping ErrNonFinite under NonFiniteStrict
+func (c analyzeConfig) prepareScratch(data []SingleChannelSample) ([]SingleChannelSample, *[]SingleChannelSample, error) {
+	if c.nonFinite != NonFiniteSkip && !c.removeDC {
+		prepared, err := c.prepare(data)
+		return prepared, nil, err
+	}
+
+	scratch := sampleScratch.get(0)
+	prepared, err := c.prepareInto(*scratch, data)
+	// keep the copy, which may have outgrown the scratch; data itself must never go to the pool
+	if copied(prepared, data) {
+		*scratch = prepared
+	}
+	return prepared, scratch, err
+}
+
+// nonFiniteError reports a non-finite value found under NonFiniteStrict.
+func nonFiniteError(index int, t, value float64) error {
+	return fmt.Errorf("%w: sample %d at time %g has value %g", ErrNonFinite, index, t, value)
+}
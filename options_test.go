@@ -0,0 +1,175 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNonFinitePolicies(t *testing.T) {
+	for _, bad := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		for _, index := range []int{0, 500, 999} {
+			// Generate sample data with one non-finite value
+			data := GenerateSineWave(50, 1, 1, 1000)
+			data[index].Value = bad
+			finite := append(append([]SingleChannelSample{}, data[:index]...), data[index+1:]...)
+
+			// Run the test: propagate carries the value into the RMS, unless it
+			// is the first sample, which falls outside the whole cycles RMS uses
+			if rms, _ := Analyze(data); index > 0 && !math.IsNaN(rms) && !math.IsInf(rms, 0) {
+				t.Errorf("Analyze with %f at %d returned RMS %f, expected a non-finite value", bad, index, rms)
+			}
+
+			// strict names the offending sample
+			_, _, err := AnalyzeE(data, WithNonFinite(NonFiniteStrict))
+			if !errors.Is(err, ErrNonFinite) {
+				t.Errorf("AnalyzeE strict with %f at %d returned %v, expected ErrNonFinite", bad, index, err)
+			} else if !strings.Contains(err.Error(), fmt.Sprintf("sample %d ", index)) {
+				t.Errorf("AnalyzeE strict error %q does not name sample %d", err, index)
+			}
+			if rms, zcr := Analyze(data, WithNonFinite(NonFiniteStrict)); rms != 0 || zcr != 0 {
+				t.Errorf("Analyze strict with %f at %d returned %f, %f; expected zeros", bad, index, rms, zcr)
+			}
+
+			// skip analyses the remaining samples, over their own duration
+			rms, zcr, err := AnalyzeE(data, WithNonFinite(NonFiniteSkip))
+			if err != nil {
+				t.Fatalf("AnalyzeE skip with %f at %d returned error: %v", bad, index, err)
+			}
+			expectedRMS, expectedZCR := Analyze(finite)
+			if rms != expectedRMS || zcr != expectedZCR {
+				t.Errorf("AnalyzeE skip with %f at %d returned %f, %f; expected %f, %f", bad, index, rms, zcr, expectedRMS, expectedZCR)
+			}
+			if math.Abs(rms-0.7071) > 0.001 || math.Abs(zcr-50) > 1 {
+				t.Errorf("AnalyzeE skip with %f at %d returned %f, %f; expected about 0.7071, 50", bad, index, rms, zcr)
+			}
+		}
+	}
+}
+
+func TestNonFiniteSkipAll(t *testing.T) {
+	data := []SingleChannelSample{{Time: 0, Value: math.NaN()}, {Time: 1, Value: math.Inf(1)}}
+
+	// Run the test
+	if _, _, err := AnalyzeE(data, WithNonFinite(NonFiniteSkip)); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("AnalyzeE skip on all non-finite data returned %v, expected ErrEmptyData", err)
+	}
+}
+
+func TestNonFiniteMultiChannel(t *testing.T) {
+	// Generate sample data with a NaN in the second channel only
+	wave := GenerateSineWave(50, 1, 1, 1000)
+	data := make([]MultiChannelSample, len(wave))
+	for i, sample := range wave {
+		data[i] = MultiChannelSample{Time: sample.Time, Value: []float64{sample.Value, sample.Value}}
+	}
+	data[100].Value[1] = math.NaN()
+
+	// Run the test
+	_, _, err := AnalyzeMultiChannelE(data, WithNonFinite(NonFiniteStrict))
+	if !errors.Is(err, ErrNonFinite) || !strings.Contains(err.Error(), "channel 1") {
+		t.Errorf("AnalyzeMultiChannelE strict returned %v, expected ErrNonFinite in channel 1", err)
+	}
+
+	rms, _, err := AnalyzeMultiChannelE(data, WithNonFinite(NonFiniteSkip))
+	if err != nil {
+		t.Fatalf("AnalyzeMultiChannelE skip returned error: %v", err)
+	}
+	for c, value := range rms {
+		if math.Abs(value-0.7071) > 0.001 {
+			t.Errorf("AnalyzeMultiChannelE skip returned RMS %f for channel %d, expected 0.7071", value, c)
+		}
+	}
+}
+
+func TestRemoveDC(t *testing.T) {
+	// Generate sample data: a 1 V, 50 Hz sine riding on a 5 V offset
+	data := GenerateSineWave(50, 1, 1, 1000, WithDCOffset(5))
+	original := append([]SingleChannelSample(nil), data...)
+
+	// Run the test: without the option the offset dominates and there are no crossings
+	if rms, zcr := Analyze(data); math.Abs(rms-math.Sqrt(25.5)) > 1e-6 || zcr != 0 {
+		t.Errorf("Analyze returned %f, %f; expected %f, 0", rms, zcr, math.Sqrt(25.5))
+	}
+
+	rms, zcr := Analyze(data, WithRemoveDC())
+	if math.Abs(rms-1/math.Sqrt2) > 1e-3 || math.Abs(zcr-50) > 1 {
+		t.Errorf("Analyze with WithRemoveDC returned %f, %f; expected about %f, 50", rms, zcr, 1/math.Sqrt2)
+	}
+	result, err := AnalyzeDetailed(data, WithRemoveDC())
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed with WithRemoveDC returned error: %v", err)
+	}
+	if result.RMS != rms || result.NZCR != zcr {
+		t.Errorf("AnalyzeDetailed with WithRemoveDC returned %f, %f; expected %f, %f", result.RMS, result.NZCR, rms, zcr)
+	}
+	if math.Abs(result.Peak-1) > 1e-6 {
+		t.Errorf("AnalyzeDetailed with WithRemoveDC returned peak %f, expected 1", result.Peak)
+	}
+	if got := NewAnalyzer(WithRemoveDC()).Analyze(data); got != result {
+		t.Errorf("Analyzer with WithRemoveDC returned %+v, expected %+v", got, result)
+	}
+	for i := range data {
+		if data[i] != original[i] {
+			t.Fatalf("WithRemoveDC changed sample %d of the input from %v to %v", i, original[i], data[i])
+		}
+	}
+
+	// the mean is taken over the samples the non-finite policy keeps
+	data[10].Value = math.NaN()
+	skipped, err := AnalyzeDetailed(data, WithRemoveDC(), WithNonFinite(NonFiniteSkip))
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed skip with WithRemoveDC returned error: %v", err)
+	}
+	if math.Abs(skipped.RMS-1/math.Sqrt2) > 1e-3 || math.Abs(skipped.NZCR-50) > 1 {
+		t.Errorf("AnalyzeDetailed skip with WithRemoveDC returned %f, %f; expected about %f, 50", skipped.RMS, skipped.NZCR, 1/math.Sqrt2)
+	}
+}
+
+func TestMaxCycles(t *testing.T) {
+	// Generate sample data: a 1 kHz carrier whose amplitude ramps from 0 to 2 over 2 seconds
+	const sampleRate = 20000
+	data := make([]SingleChannelSample, 2*sampleRate)
+	for i := range data {
+		tm := float64(i) / sampleRate
+		data[i] = SingleChannelSample{Time: tm, Value: tm * math.Sin(2*math.Pi*1000*tm)}
+	}
+
+	// Run the test: the default cap uses only the last second, where the amplitude runs from 1 to 2
+	capped, err := AnalyzeDetailed(data)
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed returned error: %v", err)
+	}
+	if diff := math.Abs(capped.RMS - math.Sqrt(7.0/6)); diff > 0.001 {
+		t.Errorf("AnalyzeDetailed returned RMS %f, expected %f (difference: %f)", capped.RMS, math.Sqrt(7.0/6), diff)
+	}
+	if diff := math.Abs(capped.RMSSpan - 1); diff > 0.001 {
+		t.Errorf("AnalyzeDetailed used %f seconds, expected 1 (difference: %f)", capped.RMSSpan, diff)
+	}
+	if rms, _ := Analyze(data); rms != capped.RMS {
+		t.Errorf("Analyze returned RMS %f, expected %f", rms, capped.RMS)
+	}
+
+	// without a cap every whole cycle is used, where the amplitude runs from 0 to 2
+	uncapped, err := AnalyzeDetailed(data, WithMaxCycles(0))
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed uncapped returned error: %v", err)
+	}
+	if diff := math.Abs(uncapped.RMS - math.Sqrt(2.0/3)); diff > 0.001 {
+		t.Errorf("AnalyzeDetailed uncapped returned RMS %f, expected %f (difference: %f)", uncapped.RMS, math.Sqrt(2.0/3), diff)
+	}
+	if diff := math.Abs(uncapped.RMSSpan - 1.999); diff > 0.001 {
+		t.Errorf("AnalyzeDetailed uncapped used %f seconds, expected 1.999 (difference: %f)", uncapped.RMSSpan, diff)
+	}
+	if rms, _ := Analyze(data, WithMaxCycles(0)); rms != uncapped.RMS {
+		t.Errorf("Analyze uncapped returned RMS %f, expected %f", rms, uncapped.RMS)
+	}
+
+	// a tighter cap narrows the span further
+	tight, _ := AnalyzeDetailed(data, WithMaxCycles(100))
+	if diff := math.Abs(tight.RMSSpan - 0.1); diff > 0.001 {
+		t.Errorf("AnalyzeDetailed with 100 cycles used %f seconds, expected 0.1 (difference: %f)", tight.RMSSpan, diff)
+	}
+}
@@ -0,0 +1,211 @@
+package dynamics
+
+import (
+	"math"
+	"sort"
+)
+
+// OrderResample resamples a vibration signal at constant increments of shaft
+// angle, so that a component locked to the shaft speed, an order, has a fixed
+// number of cycles per revolution however the speed changes. The tacho pulses
+// are timed as TachoToRPM times them, at a threshold midway between the lowest
+// and highest tacho values. The time at each angle of the grid is found by
+// fitting a quadratic through the pulse times either side of it and the next
+// pulse, which follows a steady acceleration exactly, and the vibration is
+// interpolated linearly at that time.
+//
+// The Time of each resampled point is the shaft angle in revolutions since the
+// first pulse, so a spectrum of the result has orders on its frequency axis.
+// The grid covers the whole revolutions between the first and last pulses.
+//
+// Parameters:
+//   - vibration: A slice of Sample structs containing the vibration
+//   - tacho: A slice of Sample structs containing the tacho pulses
+//   - samplesPerRev: The number of points per revolution
+//   - pulsesPerRev: The number of tacho pulses per revolution
+//
+// Returns:
+//   - []SingleChannelSample: The vibration against shaft angle, none when
+//     either count is not positive or the tacho holds less than one revolution
+func OrderResample(vibration, tacho []SingleChannelSample, samplesPerRev int, pulsesPerRev int) []SingleChannelSample {
+	resampled, _ := orderResample(vibration, tacho, samplesPerRev, pulsesPerRev)
+	return resampled
+}
+
+// orderResample is OrderResample, also returning the time at which the shaft
+// reached the angle of each resampled point.
+func orderResample(vibration, tacho []SingleChannelSample, samplesPerRev int, pulsesPerRev int) (resampled []SingleChannelSample, times []float64) {
+	if samplesPerRev <= 0 || pulsesPerRev <= 0 || len(vibration) < 2 || len(tacho) == 0 {
+		return nil, nil
+	}
+	low, high := tacho[0].Value, tacho[0].Value
+	for _, sample := range tacho {
+		low = math.Min(low, sample.Value)
+		high = math.Max(high, sample.Value)
+	}
+	pulses := pulseTimes(tacho, (low+high)/2)
+	revs := (len(pulses) - 1) / pulsesPerRev
+	if revs == 0 {
+		return nil, nil
+	}
+
+	resampled = make([]SingleChannelSample, revs*samplesPerRev)
+	times = make([]float64, len(resampled))
+	for i := range resampled {
+		angle := float64(i) / float64(samplesPerRev) // revolutions
+		pulse := angle * float64(pulsesPerRev)       // in pulse intervals
+		k := min(int(pulse), len(pulses)-2)
+		times[i] = angleTime(pulses, k, pulse)
+		resampled[i] = SingleChannelSample{Time: angle, Value: interpolateAt(vibration, times[i])}
+	}
+	return resampled, times
+}
+
+// angleTime returns the time at which the shaft reached the given angle, in
+// pulse intervals, lying between pulses k and k+1. A quadratic is fitted
+// through those pulses and the one after, or the one before at the last
+// interval, or a line when there are only two pulses.
+func angleTime(pulses []float64, k int, pulse float64) float64 {
+	if len(pulses) == 2 {
+		return pulses[0] + (pulses[1]-pulses[0])*pulse
+	}
+	j := k
+	if j+2 >= len(pulses) {
+		j = len(pulses) - 3
+	}
+	// Lagrange interpolation through pulses j, j+1 and j+2
+	x := pulse - float64(j)
+	return pulses[j]*(x-1)*(x-2)/2 - pulses[j+1]*x*(x-2) + pulses[j+2]*x*(x-1)/2
+}
+
+// interpolateAt returns the value of the data at time t, interpolating
+// linearly between samples and holding the end values beyond them.
+func interpolateAt(data []SingleChannelSample, t float64) float64 {
+	i := sort.Search(len(data), func(i int) bool { return data[i].Time >= t })
+	switch {
+	case i == 0:
+		return data[0].Value
+	case i == len(data):
+		return data[len(data)-1].Value
+	}
+	a, b := data[i-1], data[i]
+	if b.Time == a.Time {
+		return b.Value
+	}
+	return a.Value + (b.Value-a.Value)*(t-a.Time)/(b.Time-a.Time)
+}
+
+// OrderSpectrum is the amplitude spectrum of a vibration signal resampled by
+// OrderResample, against order. The resampled record holds whole revolutions,
+// so each whole order falls on a spectral line.
+//
+// Parameters:
+//   - vibration: A slice of Sample structs containing the vibration
+//   - tacho: A slice of Sample structs containing the tacho pulses
+//   - samplesPerRev: The number of points per revolution, twice the highest order
+//   - pulsesPerRev: The number of tacho pulses per revolution
+//
+// Returns:
+//   - orders: The order of each line, from 0 to samplesPerRev/2
+//   - mags: The peak amplitude at each order
+func OrderSpectrum(vibration, tacho []SingleChannelSample, samplesPerRev int, pulsesPerRev int) (orders, mags []float64) {
+	resampled := OrderResample(vibration, tacho, samplesPerRev, pulsesPerRev)
+	if len(resampled) == 0 {
+		return nil, nil
+	}
+	mags = amplitudeSpectrum(sampleValues(resampled))
+	orders = make([]float64, len(mags))
+	revs := float64(len(resampled)) / float64(samplesPerRev)
+	for k := range orders {
+		orders[k] = float64(k) / revs
+	}
+	return orders, mags
+}
+
+// OrderWaterfallConfig configures OrderWaterfall. Zero fields take their defaults.
+type OrderWaterfallConfig struct {
+	SamplesPerRev   int     `json:"samplesPerRev"`   // points per revolution, twice the highest order; default 64
+	PulsesPerRev    int     `json:"pulsesPerRev"`    // tacho pulses per revolution; default 1
+	RevsPerSpectrum int     `json:"revsPerSpectrum"` // revolutions in each spectrum, the reciprocal of the order resolution; default 16
+	RPMResolution   float64 `json:"rpmResolution"`   // width of each speed bin in RPM; default 100
+}
+
+// OrderWaterfall maps the order content of a run-up or run-down against shaft
+// speed. The vibration is resampled by shaft angle as OrderResample does and
+// cut into blocks of RevsPerSpectrum whole revolutions, each giving an order
+// spectrum at the mean speed over the block. The spectra are then gathered
+// into speed bins RPMResolution wide, those falling in the same bin being
+// averaged, and bins no block falls in are left out. As in a short-time
+// Fourier transform, mags holds one spectrum per row.
+//
+// Parameters:
+//   - vibration: A slice of Sample structs containing the vibration
+//   - tacho: A slice of Sample structs containing the tacho pulses
+//   - cfg: The resolution of the waterfall
+//
+// Returns:
+//   - rpms: The centre of each speed bin in RPM, in increasing order
+//   - orders: The order of each column, from 0 to SamplesPerRev/2
+//   - mags: The peak amplitude at each speed and order, mags[bin][order];
+//     all are nil when a field of cfg is negative or the tacho holds less
+//     than one block
+func OrderWaterfall(vibration, tacho []SingleChannelSample, cfg OrderWaterfallConfig) (rpms, orders []float64, mags [][]float64) {
+	if cfg.SamplesPerRev == 0 {
+		cfg.SamplesPerRev = 64
+	}
+	if cfg.PulsesPerRev == 0 {
+		cfg.PulsesPerRev = 1
+	}
+	if cfg.RevsPerSpectrum == 0 {
+		cfg.RevsPerSpectrum = 16
+	}
+	if cfg.RPMResolution == 0 {
+		cfg.RPMResolution = 100
+	}
+	if cfg.SamplesPerRev < 0 || cfg.PulsesPerRev < 0 || cfg.RevsPerSpectrum < 0 || !(cfg.RPMResolution > 0) {
+		return nil, nil, nil
+	}
+	resampled, times := orderResample(vibration, tacho, cfg.SamplesPerRev, cfg.PulsesPerRev)
+	block := cfg.SamplesPerRev * cfg.RevsPerSpectrum
+	if len(resampled) < block {
+		return nil, nil, nil
+	}
+
+	sums := make(map[int][]float64) // summed spectra by speed bin
+	counts := make(map[int]int)
+	for start := 0; start+block <= len(resampled); start += block {
+		span := times[start+block-1] - times[start]
+		if !(span > 0) {
+			continue
+		}
+		rpm := 60 * float64(block-1) / float64(cfg.SamplesPerRev) / span
+		bin := int(math.Floor(rpm / cfg.RPMResolution))
+		spectrum := amplitudeSpectrum(sampleValues(resampled[start : start+block]))
+		if sums[bin] == nil {
+			sums[bin] = make([]float64, len(spectrum))
+		}
+		for k, m := range spectrum {
+			sums[bin][k] += m
+		}
+		counts[bin]++
+	}
+
+	bins := make([]int, 0, len(sums))
+	for bin := range sums {
+		bins = append(bins, bin)
+	}
+	sort.Ints(bins)
+	for _, bin := range bins {
+		rpms = append(rpms, (float64(bin)+0.5)*cfg.RPMResolution)
+		row := sums[bin]
+		for k := range row {
+			row[k] /= float64(counts[bin])
+		}
+		mags = append(mags, row)
+	}
+	orders = make([]float64, block/2+1)
+	for k := range orders {
+		orders[k] = float64(k) / float64(cfg.RevsPerSpectrum)
+	}
+	return rpms, orders, mags
+}
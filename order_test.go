@@ -0,0 +1,111 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// runUp returns a vibration holding the given orders of a speed that ramps
+// from 1000 to 3000 RPM over 10 s, with the once-per-rev tacho from tachoRamp.
+func runUp(orders map[float64]float64) (vibration, tacho []SingleChannelSample) {
+	tacho, _ = tachoRamp()
+	vibration = make([]SingleChannelSample, len(tacho))
+	for i, sample := range tacho {
+		revs := (1000*sample.Time + 100*sample.Time*sample.Time) / 60
+		var v float64
+		for order, amplitude := range orders {
+			// the pulses rise through 2.5 V just before half a revolution into each turn
+			v += amplitude * math.Sin(2*math.Pi*order*(revs-0.5+0.05*math.Sqrt(math.Ln2)))
+		}
+		vibration[i] = SingleChannelSample{Time: sample.Time, Value: v}
+	}
+	return vibration, tacho
+}
+
+func TestOrderResample(t *testing.T) {
+	// Generate sample data
+	vibration, tacho := runUp(map[float64]float64{2: 1})
+
+	// Run the test: against angle the 2nd order is a sine of two cycles per revolution
+	resampled := OrderResample(vibration, tacho, 64, 1)
+	if len(resampled) == 0 || len(resampled)%64 != 0 {
+		t.Fatalf("got %d points, expected whole revolutions of 64", len(resampled))
+	}
+	for _, point := range resampled {
+		if expected := math.Sin(2 * math.Pi * 2 * point.Time); math.Abs(point.Value-expected) > 1e-3 {
+			t.Fatalf("value %v at %v revolutions, expected %v", point.Value, point.Time, expected)
+		}
+	}
+}
+
+func TestOrderSpectrum(t *testing.T) {
+	// Generate sample data
+	vibration, tacho := runUp(map[float64]float64{2: 1})
+
+	// Run the test: a single line of amplitude 1 at order 2
+	orders, mags := OrderSpectrum(vibration, tacho, 64, 1)
+	if len(orders) == 0 || len(mags) != len(orders) || orders[len(orders)-1] != 32 {
+		t.Fatalf("got %d orders and %d magnitudes, expected orders up to 32", len(orders), len(mags))
+	}
+	peak := 0
+	for k := range mags {
+		if mags[k] > mags[peak] {
+			peak = k
+		}
+	}
+	if orders[peak] != 2 {
+		t.Fatalf("peak at order %v, expected 2", orders[peak])
+	}
+	if math.Abs(mags[peak]-1) > 1e-3 {
+		t.Errorf("amplitude %v at order 2, expected 1", mags[peak])
+	}
+	for k, mag := range mags {
+		if k != peak && mag > 1e-3 {
+			t.Errorf("amplitude %v at order %v, expected none", mag, orders[k])
+		}
+	}
+}
+
+func TestOrderResampleInvalid(t *testing.T) {
+	vibration, tacho := runUp(map[float64]float64{1: 1})
+	if OrderResample(vibration, tacho, 0, 1) != nil {
+		t.Error("zero samples per revolution: expected no points")
+	}
+	if OrderResample(vibration, tacho, 64, 0) != nil {
+		t.Error("zero pulses per revolution: expected no points")
+	}
+	if OrderResample(vibration, tacho[:100], 64, 1) != nil {
+		t.Error("no whole revolution: expected no points")
+	}
+}
+
+func TestOrderWaterfall(t *testing.T) {
+	// Generate sample data: 1st and 2nd orders throughout the run-up
+	vibration, tacho := runUp(map[float64]float64{1: 1, 2: 0.5})
+
+	// Run the test: a ridge at orders 1 and 2 at every speed, 1000 to 3000 RPM
+	rpms, orders, mags := OrderWaterfall(vibration, tacho, OrderWaterfallConfig{RevsPerSpectrum: 8})
+	if len(rpms) < 15 || len(mags) != len(rpms) {
+		t.Fatalf("got %d speeds and %d rows, expected about 20", len(rpms), len(mags))
+	}
+	if len(orders) != 257 || orders[8] != 1 || orders[16] != 2 {
+		t.Fatalf("got %d orders, expected 257 at 1/8 order spacing", len(orders))
+	}
+	for i, row := range mags {
+		if rpms[i] < 1000 || rpms[i] > 3000 {
+			t.Errorf("speed bin at %v RPM, outside the run-up", rpms[i])
+		}
+		if math.Abs(row[8]-1) > 0.01 || math.Abs(row[16]-0.5) > 0.01 {
+			t.Errorf("%v RPM: orders 1 and 2 at %v and %v, expected 1 and 0.5", rpms[i], row[8], row[16])
+		}
+		for k, mag := range row {
+			if k != 8 && k != 16 && mag > 0.01 {
+				t.Errorf("%v RPM: amplitude %v at order %v, expected none", rpms[i], mag, orders[k])
+			}
+		}
+	}
+
+	if rpms, _, _ := OrderWaterfall(vibration, tacho, OrderWaterfallConfig{RPMResolution: -1}); rpms != nil {
+		t.Error("negative resolution: expected nil")
+	}
+}
@@ -0,0 +1,117 @@
+package dynamics
+
+import (
+	"math"
+	"sort"
+)
+
+// Peak is a local maximum found by FindPeaks.
+type Peak struct {
+	Index int     `json:"index"` // index of the sample in the data
+	Time  float64 `json:"time"`
+	Value float64 `json:"value"`
+}
+
+// PeakOptions sets the limits a local maximum must meet to be reported by
+// FindPeaks. A nil MinHeight and a zero MinProminence or MinSpacing set no
+// limit, so the zero value reports every local maximum.
+type PeakOptions struct {
+	MinHeight     *float64 // the least value of a peak, nil for none
+	MinProminence float64  // the least height of a peak above the higher of the lowest points separating it from higher ground either side
+	MinSpacing    float64  // the least seconds between peaks; of two closer peaks the lower is dropped
+}
+
+// FindPeaks finds the local maxima of the data, such as the impacts of a
+// hammer test. A maximum is a sample, or a run of equal samples, higher than
+// its neighbours either side; a run yields one peak at its centre, the earlier
+// of the two middle samples for a run of even length. The first and last
+// samples have only one neighbour and are never peaks.
+//
+// The limits of opts are applied in turn: MinHeight, then MinProminence, then
+// MinSpacing, which keeps the highest peaks first, the earlier of two equal
+// peaks winning, and drops any peak within MinSpacing seconds of one kept.
+// Prominence is the height of a peak above the higher of the two minima that
+// lie between it and the nearest higher sample on each side, or the end of the
+// data where there is none, as in scipy.signal.find_peaks. NaN values are
+// never peaks and end the search for a minimum.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - opts: The limits peaks must meet
+//
+// Returns:
+//   - []Peak: The peaks in time order
+func FindPeaks(data []SingleChannelSample, opts PeakOptions) []Peak {
+	var peaks []Peak
+	for i := 1; i < len(data)-1; i++ {
+		if !(data[i].Value > data[i-1].Value) {
+			continue
+		}
+		// follow a plateau to its end
+		end := i
+		for end+1 < len(data)-1 && data[end+1].Value == data[i].Value {
+			end++
+		}
+		if data[end+1].Value < data[i].Value {
+			centre := (i + end) / 2
+			peaks = append(peaks, Peak{Index: centre, Time: data[centre].Time, Value: data[centre].Value})
+		}
+		i = end
+	}
+
+	kept := peaks[:0]
+	for _, peak := range peaks {
+		if opts.MinHeight != nil && peak.Value < *opts.MinHeight {
+			continue
+		}
+		if opts.MinProminence != 0 && prominence(data, peak.Index) < opts.MinProminence {
+			continue
+		}
+		kept = append(kept, peak)
+	}
+	peaks = kept
+
+	if opts.MinSpacing > 0 && len(peaks) > 1 {
+		order := make([]int, len(peaks))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return peaks[order[a]].Value > peaks[order[b]].Value })
+		dropped := make([]bool, len(peaks))
+		for _, i := range order {
+			if dropped[i] {
+				continue
+			}
+			// the peaks are in time order, so those too close lie either side of i
+			for j := i - 1; j >= 0 && peaks[i].Time-peaks[j].Time < opts.MinSpacing; j-- {
+				dropped[j] = true
+			}
+			for j := i + 1; j < len(peaks) && peaks[j].Time-peaks[i].Time < opts.MinSpacing; j++ {
+				dropped[j] = true
+			}
+		}
+		kept := peaks[:0]
+		for i, peak := range peaks {
+			if !dropped[i] {
+				kept = append(kept, peak)
+			}
+		}
+		peaks = kept
+	}
+	return peaks
+}
+
+// prominence returns the prominence of the peak at index i of the data, as
+// FindPeaks describes it.
+func prominence(data []SingleChannelSample, i int) float64 {
+	peak := data[i].Value
+	leftMin := peak
+	for j := i - 1; j >= 0 && !(data[j].Value > peak) && !math.IsNaN(data[j].Value); j-- {
+		leftMin = math.Min(leftMin, data[j].Value)
+	}
+	rightMin := peak
+	for j := i + 1; j < len(data) && !(data[j].Value > peak) && !math.IsNaN(data[j].Value); j++ {
+		rightMin = math.Min(rightMin, data[j].Value)
+	}
+	return peak - math.Max(leftMin, rightMin)
+}
@@ -0,0 +1,100 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// valuesToSamples returns the values as samples one second apart.
+func valuesToSamples(values []float64) []SingleChannelSample {
+	data := make([]SingleChannelSample, len(values))
+	for i, v := range values {
+		data[i] = SingleChannelSample{Time: float64(i), Value: v}
+	}
+	return data
+}
+
+// height returns a pointer to v, for PeakOptions.MinHeight.
+func height(v float64) *float64 {
+	return &v
+}
+
+func TestFindPeaks(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		opts     PeakOptions
+		expected []int
+	}{
+		{"simple", []float64{0, 2, 0, 3, 1}, PeakOptions{}, []int{1, 3}},
+		{"odd plateau", []float64{0, 1, 3, 3, 3, 1, 0}, PeakOptions{}, []int{3}},
+		{"even plateau", []float64{0, 2, 2, 1}, PeakOptions{}, []int{1}},
+		{"plateau then rise", []float64{0, 2, 2, 3, 0}, PeakOptions{}, []int{3}},
+		{"edges", []float64{5, 1, 2, 1, 5}, PeakOptions{}, []int{2}},
+		{"plateau at the end", []float64{0, 1, 2, 2}, PeakOptions{}, nil},
+		{"negative peaks", []float64{-5, -2, -5, -3, -5}, PeakOptions{}, []int{1, 3}},
+		{"height", []float64{0, 2, 0, 3, 1}, PeakOptions{MinHeight: height(2.5)}, []int{3}},
+		{"height zero", []float64{0, -2, -1, -2, 1, 0}, PeakOptions{MinHeight: height(0)}, []int{4}},
+		{"no height", []float64{0, -2, -1, -2, 1, 0}, PeakOptions{}, []int{2, 4}},
+		// the bump at 3 stands 1 above the dip at 2 on the shoulder of the peak at 5
+		{"prominence", []float64{0, 4, 3, 4, 5, 9, 0}, PeakOptions{MinProminence: 2}, []int{5}},
+		// nothing is higher than 9, so its minima run to the ends
+		{"prominence to the edge", []float64{2, 9, 1, 5, 1}, PeakOptions{MinProminence: 5}, []int{1}},
+		{"spacing", []float64{0, 3, 0, 5, 0, 0, 0, 4, 0}, PeakOptions{MinSpacing: 3}, []int{3, 7}},
+		{"spacing ties", []float64{0, 4, 0, 4, 0}, PeakOptions{MinSpacing: 3}, []int{1}},
+		{"NaN", []float64{0, math.NaN(), 0, 1, 0}, PeakOptions{}, []int{3}},
+	}
+
+	for _, c := range cases {
+		// Generate sample data
+		data := valuesToSamples(c.values)
+
+		// Run the test
+		peaks := FindPeaks(data, c.opts)
+		if len(peaks) != len(c.expected) {
+			t.Errorf("%s: FindPeaks returned %+v, expected peaks at %v", c.name, peaks, c.expected)
+			continue
+		}
+		for i, peak := range peaks {
+			if want := data[c.expected[i]]; peak.Index != c.expected[i] || peak.Time != want.Time || peak.Value != want.Value {
+				t.Errorf("%s: FindPeaks returned %+v, expected peaks at %v", c.name, peaks, c.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestFindPeaksBeats(t *testing.T) {
+	// Generate sample data: tones of 100 and 104 Hz beat at 4 Hz, their sum
+	// reaching 2 near 0, 0.25, 0.5 and 0.75 s
+	data := GenerateMultiTone([]Tone{{Frequency: 100, Amplitude: 1}, {Frequency: 104, Amplitude: 1}}, 0.9, 10000)
+
+	// Run the test: every carrier cycle peaks, but only one a beat both near
+	// the top and clear of the others by more than a carrier cycle
+	if all := FindPeaks(data, PeakOptions{}); len(all) < 80 {
+		t.Errorf("FindPeaks found %d peaks without limits, expected one a carrier cycle", len(all))
+	}
+	peaks := FindPeaks(data, PeakOptions{MinHeight: height(1.5), MinSpacing: 0.1})
+	if len(peaks) != 4 {
+		t.Fatalf("FindPeaks returned %d peaks, expected one each beat: %+v", len(peaks), peaks)
+	}
+	for i, peak := range peaks {
+		if beat := 0.25 * float64(i); math.Abs(peak.Time-beat) > 0.005 || peak.Value < 1.99 {
+			t.Errorf("peak %d is %v at %v s, expected about 2 within half a carrier cycle of %v s", i, peak.Value, peak.Time, beat)
+		}
+	}
+
+	// two impacts 20 ms apart under a 50 ms spacing leave only the larger
+	impacts := make([]SingleChannelSample, 1000)
+	for i := range impacts {
+		tm := float64(i) / 1000
+		impacts[i] = SingleChannelSample{Time: tm, Value: 3*math.Exp(-math.Pow((tm-0.4)/0.002, 2)) + 5*math.Exp(-math.Pow((tm-0.42)/0.002, 2))}
+	}
+	peaks = FindPeaks(impacts, PeakOptions{MinHeight: height(1), MinSpacing: 0.05})
+	if len(peaks) != 1 || math.Abs(peaks[0].Time-0.42) > 1e-9 {
+		t.Errorf("FindPeaks returned %+v, expected only the larger impact at 0.42 s", peaks)
+	}
+	if peaks := FindPeaks(impacts, PeakOptions{MinHeight: height(1)}); len(peaks) != 2 {
+		t.Errorf("FindPeaks without a spacing returned %+v, expected both impacts", peaks)
+	}
+}
@@ -0,0 +1,190 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// PowerResult holds the power drawn through a voltage and current pair.
+// Reactive power is positive when the current lags the voltage, as it does
+// into an inductive load.
+type PowerResult struct {
+	P      float64      `json:"p"`      // active power, the mean of v·i
+	Q      float64      `json:"q"`      // reactive power, signed
+	S      float64      `json:"s"`      // apparent power, Vrms·Irms
+	Cycles []PowerCycle `json:"cycles"` // the same quantities cycle by cycle
+	// PowerFactor is the true power factor P/S, which harmonics in either
+	// channel lower as well as the phase shift.
+	PowerFactor float64 `json:"powerFactor"`
+	// DisplacementPowerFactor is the cosine of the phase angle between the
+	// fundamentals of voltage and current, which harmonics do not affect.
+	DisplacementPowerFactor float64 `json:"displacementPowerFactor"`
+}
+
+// PowerCycle holds the power over one cycle of the fundamental.
+type PowerCycle struct {
+	Time float64 `json:"time"` // time of the first sample of the cycle
+	P    float64 `json:"p"`
+	Q    float64 `json:"q"`
+	S    float64 `json:"s"`
+}
+
+// PowerAnalysis calculates the active, reactive and apparent power from
+// simultaneously sampled voltage and current, over every whole cycle of the
+// fundamental at the end of the data, so that the averages are not biased by
+// a part cycle. Each cycle runs from its first sample up to, but not
+// including, the first sample of the next, and the sign of Q comes from the
+// phase of the fundamental of the current relative to the voltage. The power
+// factors are given for the whole span only. When either channel has no
+// fundamental, as with no load or a current of harmonics alone, Q and the
+// displacement power factor are 0.
+//
+// Parameters:
+//   - voltage: The voltage samples
+//   - current: The current samples, taken at the same times as the voltage
+//   - fundamental: The frequency of the supply in Hz
+//
+// Returns:
+//   - PowerResult: The power over all the whole cycles and per cycle, zero on error
+//   - error: ErrEmptyData if there are no samples, ErrInvalidFrequency if
+//     fundamental is not positive and finite, an error wrapping ErrMisaligned if
+//     the channels differ in length or timestamps, ErrUnsortedData if they are
+//     not in time order, or ErrTooShort if they span less than one cycle
+func PowerAnalysis(voltage, current []SingleChannelSample, fundamental float64) (PowerResult, error) {
+	if len(voltage) == 0 || len(current) == 0 {
+		return PowerResult{}, ErrEmptyData
+	}
+	if !(fundamental > 0) || math.IsInf(fundamental, 1) {
+		return PowerResult{}, fmt.Errorf("%w: %g Hz", ErrInvalidFrequency, fundamental)
+	}
+	if err := checkAligned(voltage, current); err != nil {
+		return PowerResult{}, err
+	}
+	if err := checkTimeOrder(voltage); err != nil {
+		return PowerResult{}, err
+	}
+
+	start, end, cycles := wholeCycles(voltage, fundamental, 0)
+	if cycles == 0 {
+		return PowerResult{}, ErrTooShort
+	}
+	voltage, current = voltage[start:end], current[start:end]
+
+	result := PowerResult{Cycles: make([]PowerCycle, 0, cycles)}
+	period := 1 / fundamental
+	first := 0
+	for k := range cycles {
+		// the cycle ends at the first sample of the next one
+		boundary := voltage[0].Time + float64(k+1)*period
+		last := first
+		for last < len(voltage) && voltage[last].Time < boundary-timeTolerance(boundary, period) {
+			last++
+		}
+		p, q, s, _ := power(voltage[first:last], current[first:last], fundamental)
+		result.Cycles = append(result.Cycles, PowerCycle{Time: voltage[first].Time, P: p, Q: q, S: s})
+		first = last
+	}
+	result.P, result.Q, result.S, result.DisplacementPowerFactor = power(voltage, current, fundamental)
+	if result.S > 0 {
+		result.PowerFactor = result.P / result.S
+	}
+	return result, nil
+}
+
+// fundamentalFloor is the fraction of a channel's RMS below which power takes
+// its fundamental to be absent. Over whole cycles a channel without one, such
+// as a current of harmonics alone, still leaves rounding noise in its phasor,
+// whose angle means nothing.
+const fundamentalFloor = 1e-9
+
+// power returns the active, reactive and apparent power of aligned voltage
+// and current, and the displacement power factor, the cosine of the angle by
+// which the fundamental of the current lags the voltage's, found with
+// Goertzel. When either channel has no fundamental above fundamentalFloor, as
+// with no load or a current of harmonics alone, there is no angle: Q and the
+// displacement power factor are 0.
+func power(voltage, current []SingleChannelSample, fundamental float64) (p, q, s, dpf float64) {
+	if len(voltage) == 0 {
+		return 0, 0, 0, 0
+	}
+	var sumVI float64
+	for i := range voltage {
+		sumVI += voltage[i].Value * current[i].Value
+	}
+	p = sumVI / float64(len(voltage))
+	vRMS, iRMS := calculateRMS(voltage), calculateRMS(current)
+	s = vRMS * iRMS
+	q = math.Sqrt(math.Max(s*s-p*p, 0))
+
+	v, i := Goertzel(voltage, fundamental), Goertzel(current, fundamental)
+	cross := v * cmplx.Conj(i)
+	// |cross| is |v|·|i|, so this compares each fundamental with its channel's RMS
+	if cmplx.Abs(cross) <= fundamentalFloor*math.Max(cmplx.Abs(v)*iRMS, vRMS*cmplx.Abs(i)) {
+		return p, 0, s, 0
+	}
+	// the current lags when its phase is behind the voltage's
+	lag := cmplx.Phase(cross)
+	if lag < 0 {
+		q = -q
+	}
+	return p, q, s, math.Cos(lag)
+}
+
+// wholeCycles finds the samples making up the whole cycles of the frequency at
+// the end of the data, taken as in KeepXSecondsOfData from the first sample at
+// or after the cutoff, but without the final sample that closes the last cycle.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - frequency: The frequency in Hz
+//   - maxCycles: The most cycles to take, 0 for no limit
+//
+// Returns:
+//   - start: The index of the first sample of the first cycle
+//   - end: The index one past the last sample of the last cycle
+//   - cycles: The number of whole cycles, 0 if the data spans less than one
+func wholeCycles(data []SingleChannelSample, frequency float64, maxCycles int) (start, end, cycles int) {
+	last := data[len(data)-1].Time
+	span := rmsSpan(last-data[0].Time, frequency, maxCycles)
+	if math.IsInf(span, 1) {
+		return 0, 0, 0
+	}
+	cycles = int(math.Round(span * frequency))
+
+	cutoff := last - span
+	for data[start].Time < cutoff {
+		start++
+	}
+	end = len(data)
+	boundary := data[start].Time + span
+	for end > start+1 && data[end-1].Time >= boundary-timeTolerance(boundary, 1/frequency) {
+		end--
+	}
+	return start, end, cycles
+}
+
+// checkAligned checks that two channels have the same number of samples taken
+// at the same times.
+//
+// Parameters:
+//   - a: The first channel
+//   - b: The second channel
+//
+// Returns:
+//   - error: An error wrapping ErrMisaligned naming the first difference
+func checkAligned(a, b []SingleChannelSample) error {
+	if len(a) != len(b) {
+		return fmt.Errorf("%w: %d samples against %d", ErrMisaligned, len(a), len(b))
+	}
+	if len(a) < 2 {
+		return nil
+	}
+	step := (a[len(a)-1].Time - a[0].Time) / float64(len(a)-1)
+	for i := range a {
+		if math.Abs(a[i].Time-b[i].Time) > timeTolerance(a[i].Time, step) {
+			return fmt.Errorf("%w: sample %d is at time %g in one channel and %g in the other", ErrMisaligned, i, a[i].Time, b[i].Time)
+		}
+	}
+	return nil
+}
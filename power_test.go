@@ -0,0 +1,148 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// voltageCurrent generates 230 V and 10 A RMS at 50 Hz for 1 s at 10 kHz, the
+// current shifted by the given phase, positive for a lag.
+func voltageCurrent(lag float64) (voltage, current []SingleChannelSample) {
+	voltage = make([]SingleChannelSample, 10000)
+	current = make([]SingleChannelSample, 10000)
+	for i := range voltage {
+		tm := float64(i) / 10000
+		angle := 2 * math.Pi * 50 * tm
+		voltage[i] = SingleChannelSample{Time: tm, Value: 230 * math.Sqrt2 * math.Cos(angle)}
+		current[i] = SingleChannelSample{Time: tm, Value: 10 * math.Sqrt2 * math.Cos(angle-lag)}
+	}
+	return voltage, current
+}
+
+func TestPowerAnalysis(t *testing.T) {
+	for _, lag := range []float64{math.Pi / 6, -math.Pi / 6} {
+		// Generate sample data
+		voltage, current := voltageCurrent(lag)
+
+		// Run the test: S = 2300 VA, P = S·cos 30°, Q = S·sin 30° signed by the lag
+		result, err := PowerAnalysis(voltage, current, 50)
+		if err != nil {
+			t.Fatalf("PowerAnalysis returned error: %v", err)
+		}
+		if math.Abs(result.S-2300) > 1e-6*2300 {
+			t.Errorf("lag %v: S = %v, expected 2300", lag, result.S)
+		}
+		if expected := 2300 * math.Cos(lag); math.Abs(result.P-expected) > 1e-6*2300 {
+			t.Errorf("lag %v: P = %v, expected %v", lag, result.P, expected)
+		}
+		if expected := 2300 * math.Sin(lag); math.Abs(result.Q-expected) > 1e-3*2300 {
+			t.Errorf("lag %v: Q = %v, expected %v", lag, result.Q, expected)
+		}
+
+		// 0.9999 s of data holds 49 whole cycles
+		if len(result.Cycles) != 49 {
+			t.Fatalf("lag %v: got %d cycles, expected 49", lag, len(result.Cycles))
+		}
+		for k, cycle := range result.Cycles {
+			if math.Abs(cycle.P-result.P) > 1e-6*2300 || math.Abs(cycle.Q-result.Q) > 1e-3*2300 {
+				t.Errorf("lag %v: cycle %d is %+v, expected P %v and Q %v", lag, k, cycle, result.P, result.Q)
+			}
+		}
+	}
+}
+
+func TestPowerAnalysisErrors(t *testing.T) {
+	// Generate sample data
+	voltage, current := voltageCurrent(0)
+	shifted := append([]SingleChannelSample(nil), current...)
+	shifted[300].Time += 1e-5
+
+	// Run the test
+	if _, err := PowerAnalysis(nil, nil, 50); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got error %v, expected ErrEmptyData", err)
+	}
+	if _, err := PowerAnalysis(voltage, current, math.NaN()); !errors.Is(err, ErrInvalidFrequency) {
+		t.Errorf("NaN fundamental: got error %v, expected ErrInvalidFrequency", err)
+	}
+	if _, err := PowerAnalysis(voltage, current[:9000], 50); !errors.Is(err, ErrMisaligned) {
+		t.Errorf("different lengths: got error %v, expected ErrMisaligned", err)
+	}
+	if _, err := PowerAnalysis(voltage, shifted, 50); !errors.Is(err, ErrMisaligned) {
+		t.Errorf("shifted timestamp: got error %v, expected ErrMisaligned", err)
+	}
+	if _, err := PowerAnalysis(voltage[:150], current[:150], 50); !errors.Is(err, ErrTooShort) {
+		t.Errorf("less than a cycle: got error %v, expected ErrTooShort", err)
+	}
+}
+
+func TestPowerFactor(t *testing.T) {
+	// Generate sample data: a current lagging by 30° with a third harmonic of half its amplitude
+	voltage, current := voltageCurrent(math.Pi / 6)
+	for i := range current {
+		current[i].Value += 5 * math.Sqrt2 * math.Cos(2*math.Pi*150*current[i].Time)
+	}
+
+	// Run the test: the harmonic carries no power against a pure voltage, so
+	// the true power factor is the displacement one times the distortion
+	// factor I1/Irms = 1/√1.25
+	result, err := PowerAnalysis(voltage, current, 50)
+	if err != nil {
+		t.Fatalf("PowerAnalysis returned error: %v", err)
+	}
+	displacement := math.Cos(math.Pi / 6)
+	if math.Abs(result.DisplacementPowerFactor-displacement) > 1e-4 {
+		t.Errorf("DisplacementPowerFactor = %v, expected %v", result.DisplacementPowerFactor, displacement)
+	}
+	if expected := displacement / math.Sqrt(1.25); math.Abs(result.PowerFactor-expected) > 1e-4 {
+		t.Errorf("PowerFactor = %v, expected %v", result.PowerFactor, expected)
+	}
+
+	// without the harmonic the two agree
+	voltage, current = voltageCurrent(math.Pi / 6)
+	result, _ = PowerAnalysis(voltage, current, 50)
+	if math.Abs(result.PowerFactor-result.DisplacementPowerFactor) > 1e-4 {
+		t.Errorf("sinusoidal current: PowerFactor %v and DisplacementPowerFactor %v differ", result.PowerFactor, result.DisplacementPowerFactor)
+	}
+}
+
+func TestPowerAnalysisNoLoad(t *testing.T) {
+	// Generate sample data: a supply with no current drawn
+	voltage, current := voltageCurrent(0)
+	for i := range current {
+		current[i].Value = 0
+	}
+
+	// Run the test: there is no phase to take, so nothing comes out NaN
+	result, err := PowerAnalysis(voltage, current, 50)
+	if err != nil {
+		t.Fatalf("PowerAnalysis returned error: %v", err)
+	}
+	if result.P != 0 || result.Q != 0 || result.S != 0 || result.PowerFactor != 0 || result.DisplacementPowerFactor != 0 {
+		t.Errorf("no load: %+v, expected zero power and power factors", result)
+	}
+	for k, cycle := range result.Cycles {
+		if cycle.Q != 0 || math.IsNaN(cycle.P) {
+			t.Errorf("no load: cycle %d is %+v, expected zero power", k, cycle)
+		}
+	}
+
+	// a current of a 3rd harmonic alone leaves only rounding noise at the fundamental
+	for _, phase := range []float64{0, 0.3, 1, 2.5} {
+		for i := range current {
+			current[i].Value = 10 * math.Sqrt2 * math.Cos(3*2*math.Pi*50*current[i].Time+phase)
+		}
+		result, err := PowerAnalysis(voltage, current, 50)
+		if err != nil {
+			t.Fatalf("harmonic current, phase %v: PowerAnalysis returned error: %v", phase, err)
+		}
+		if result.Q != 0 || result.DisplacementPowerFactor != 0 || math.Abs(result.S-2300) > 1e-6 {
+			t.Errorf("harmonic current, phase %v: %+v, expected S = 2300 with Q and DPF 0", phase, result)
+		}
+		for k, cycle := range result.Cycles {
+			if cycle.Q != 0 {
+				t.Errorf("harmonic current, phase %v: cycle %d has Q = %v, expected 0", phase, k, cycle.Q)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package dynamics
+
+import "math"
+
+// PQEventType is the kind of a power-quality event.
+type PQEventType int
+
+const (
+	PQSag   PQEventType = iota + 1 // the RMS fell below 90% of nominal
+	PQSwell                        // the RMS rose above 110% of nominal
+)
+
+// PQEvent is a voltage sag or swell found by DetectSagsSwells.
+type PQEvent struct {
+	Type     PQEventType `json:"type"`
+	Start    float64     `json:"start"`    // end time of the first one-cycle RMS beyond the threshold
+	Duration float64     `json:"duration"` // seconds until the first one-cycle RMS back within the thresholds
+	Extreme  float64     `json:"extreme"`  // lowest RMS during a sag, highest during a swell
+	Residual float64     `json:"residual"` // Extreme as a percentage of the nominal RMS
+}
+
+// Sag and swell thresholds as fractions of the nominal RMS.
+const (
+	sagThreshold   = 0.9
+	swellThreshold = 1.1
+)
+
+// DetectSagsSwells finds the voltage sags and swells in the data. As in
+// IEC 61000-4-30, the level is an RMS over one cycle of the fundamental,
+// refreshed every half cycle, as HalfCycleRMS gives it, and an event lasts from the first of these
+// beyond its threshold to the first back within both thresholds, so runs of
+// qualifying cycles make a single event. An event still in progress at the
+// end of the data is reported with the duration seen so far.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - nominalRMS: The nominal RMS voltage
+//   - fundamental: The frequency of the supply in Hz
+//
+// Returns:
+//   - []PQEvent: The events in time order, none when nominalRMS or
+//     fundamental is not positive and finite or the data spans less than a cycle
+func DetectSagsSwells(data []SingleChannelSample, nominalRMS, fundamental float64) []PQEvent {
+	if !(nominalRMS > 0) || math.IsInf(nominalRMS, 1) || !(fundamental > 0) || math.IsInf(fundamental, 1) {
+		return nil
+	}
+
+	var events []PQEvent
+	var current *PQEvent
+	var last float64
+	for _, level := range HalfCycleRMS(data, fundamental) {
+		last = level.Time
+		eventType := PQEventType(0)
+		switch {
+		case level.Value < sagThreshold*nominalRMS:
+			eventType = PQSag
+		case level.Value > swellThreshold*nominalRMS:
+			eventType = PQSwell
+		}
+
+		if current != nil && current.Type != eventType {
+			current.Duration = level.Time - current.Start
+			current = nil
+		}
+		if eventType == 0 {
+			continue
+		}
+		if current == nil {
+			events = append(events, PQEvent{Type: eventType, Start: level.Time, Extreme: level.Value})
+			current = &events[len(events)-1]
+		}
+		if eventType == PQSag {
+			current.Extreme = math.Min(current.Extreme, level.Value)
+		} else {
+			current.Extreme = math.Max(current.Extreme, level.Value)
+		}
+	}
+	if current != nil {
+		current.Duration = last - current.Start
+	}
+	for i := range events {
+		events[i].Residual = 100 * events[i].Extreme / nominalRMS
+	}
+	return events
+}
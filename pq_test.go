@@ -0,0 +1,87 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// supply generates 1 s of a 230 V RMS, 50 Hz supply at 10 kHz, scaled by
+// factor from start for duration seconds.
+func supply(factor, start, duration float64) []SingleChannelSample {
+	data := GenerateSineWave(50, 230*math.Sqrt2, 1, 10000)
+	for i := range data {
+		if data[i].Time >= start && data[i].Time < start+duration {
+			data[i].Value *= factor
+		}
+	}
+	return data
+}
+
+func TestDetectSagsSwellsSag(t *testing.T) {
+	// Generate sample data: a 200 ms sag to 70%
+	data := supply(0.7, 0.4, 0.2)
+
+	// Run the test
+	events := DetectSagsSwells(data, 230, 50)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, expected 1: %+v", len(events), events)
+	}
+	event := events[0]
+	if event.Type != PQSag {
+		t.Errorf("Type = %v, expected PQSag", event.Type)
+	}
+	// the one-cycle window blurs each edge by up to a cycle
+	if math.Abs(event.Duration-0.2) > 0.02 {
+		t.Errorf("Duration = %v, expected about 0.2", event.Duration)
+	}
+	if math.Abs(event.Start-0.4) > 0.02 {
+		t.Errorf("Start = %v, expected about 0.4", event.Start)
+	}
+	if math.Abs(event.Residual-70) > 0.1 {
+		t.Errorf("Residual = %v%%, expected 70%%", event.Residual)
+	}
+}
+
+func TestDetectSagsSwellsSwell(t *testing.T) {
+	// Generate sample data: a 100 ms swell to 120%
+	data := supply(1.2, 0.5, 0.1)
+
+	// Run the test
+	events := DetectSagsSwells(data, 230, 50)
+	if len(events) != 1 || events[0].Type != PQSwell {
+		t.Fatalf("got %+v, expected one swell", events)
+	}
+	if math.Abs(events[0].Residual-120) > 0.1 {
+		t.Errorf("Residual = %v%%, expected 120%%", events[0].Residual)
+	}
+}
+
+func TestDetectSagsSwellsNone(t *testing.T) {
+	// Generate sample data: a dip to 95% stays within the thresholds
+	data := supply(0.95, 0.4, 0.2)
+
+	// Run the test
+	if events := DetectSagsSwells(data, 230, 50); len(events) != 0 {
+		t.Errorf("got %+v, expected no events", events)
+	}
+	if events := DetectSagsSwells(data, 0, 50); events != nil {
+		t.Errorf("zero nominal RMS gave %+v, expected none", events)
+	}
+	if events := DetectSagsSwells(data[:150], 230, 50); events != nil {
+		t.Errorf("less than a cycle gave %+v, expected none", events)
+	}
+}
+
+func TestDetectSagsSwellsAtEnd(t *testing.T) {
+	// Generate sample data: a sag running to the end of the data
+	data := supply(0.5, 0.8, 1)
+
+	// Run the test
+	events := DetectSagsSwells(data, 230, 50)
+	if len(events) != 1 || events[0].Type != PQSag {
+		t.Fatalf("got %+v, expected one sag", events)
+	}
+	if events[0].Duration <= 0.15 {
+		t.Errorf("Duration = %v, expected the 0.2 s to the end less the edge", events[0].Duration)
+	}
+}
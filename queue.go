@@ -0,0 +1,67 @@
+package dynamics
+
+// OverflowPolicy selects what a bounded sample queue does when it is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the producer wait until the consumer has room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the sample being pushed.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued sample to make room.
+	OverflowDropOldest
+)
+
+// sampleQueue is a bounded queue of samples between a producer and a single
+// consumer goroutine, applying an OverflowPolicy when full. The channel is
+// never closed; closing stop releases producers blocked by OverflowBlock.
+type sampleQueue struct {
+	ch     chan SingleChannelSample
+	policy OverflowPolicy
+	stop   chan struct{}
+}
+
+// newSampleQueue creates a queue holding up to size samples, at least one.
+func newSampleQueue(size int, policy OverflowPolicy) *sampleQueue {
+	return &sampleQueue{
+		ch:     make(chan SingleChannelSample, max(size, 1)),
+		policy: policy,
+		stop:   make(chan struct{}),
+	}
+}
+
+// put queues a sample according to the policy and returns the number of
+// samples dropped to do so, or whether the queue was stopped while waiting.
+func (q *sampleQueue) put(sample SingleChannelSample) (dropped int, stopped bool) {
+	switch q.policy {
+	case OverflowDropNewest:
+		select {
+		case q.ch <- sample:
+			return 0, false
+		default:
+			return 1, false
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- sample:
+				return dropped, false
+			default:
+			}
+			// the consumer may empty the queue between the two selects, in
+			// which case there is nothing to drop and the send is retried
+			select {
+			case <-q.ch:
+				dropped++
+			default:
+			}
+		}
+	default:
+		select {
+		case q.ch <- sample:
+			return 0, false
+		case <-q.stop:
+			return 0, true
+		}
+	}
+}
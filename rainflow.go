@@ -0,0 +1,72 @@
+package dynamics
+
+import "math"
+
+// RainflowCycle is a load cycle counted by RainflowCount.
+type RainflowCycle struct {
+	Range float64 `json:"range"` // peak-to-valley range
+	Mean  float64 `json:"mean"`  // mean of the peak and valley
+	Count float64 `json:"count"` // 1 for a full cycle, 0.5 for a half cycle
+}
+
+// RainflowCount counts the load cycles in the data by the three-point
+// rainflow method of ASTM E1049. The values are first reduced to their
+// turning points; each closed cycle is then counted as it is found, and the
+// ranges left unclosed at the end of the data are counted as half cycles.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - []RainflowCycle: The cycles in the order they were closed, then the half cycles
+func RainflowCount(data []SingleChannelSample) []RainflowCycle {
+	var cycles []RainflowCycle
+	var stack []float64
+	for _, point := range turningPoints(data) {
+		stack = append(stack, point)
+		for len(stack) >= 3 {
+			n := len(stack)
+			x := math.Abs(stack[n-1] - stack[n-2])
+			y := math.Abs(stack[n-2] - stack[n-3])
+			if x < y {
+				break
+			}
+			cycle := RainflowCycle{Range: y, Mean: (stack[n-2] + stack[n-3]) / 2, Count: 1}
+			if n == 3 {
+				// the range holds the starting point, so it is half a cycle
+				cycle.Count = 0.5
+				stack = stack[1:]
+			} else {
+				stack = append(stack[:n-3], stack[n-1])
+			}
+			cycles = append(cycles, cycle)
+		}
+	}
+	for i := 1; i < len(stack); i++ {
+		cycles = append(cycles, RainflowCycle{
+			Range: math.Abs(stack[i] - stack[i-1]),
+			Mean:  (stack[i] + stack[i-1]) / 2,
+			Count: 0.5,
+		})
+	}
+	return cycles
+}
+
+// turningPoints returns the peaks and valleys of the values, with the first
+// and last values, dropping points that do not reverse the direction of the load.
+func turningPoints(data []SingleChannelSample) []float64 {
+	var points []float64
+	for _, sample := range data {
+		v := sample.Value
+		n := len(points)
+		switch {
+		case n > 0 && v == points[n-1]:
+		case n > 1 && (points[n-1]-points[n-2])*(v-points[n-1]) > 0:
+			// still moving the same way, so the last point was not a reversal
+			points[n-1] = v
+		default:
+			points = append(points, v)
+		}
+	}
+	return points
+}
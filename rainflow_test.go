@@ -0,0 +1,75 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRainflowCount(t *testing.T) {
+	// Generate sample data: the load history of the ASTM E1049 example
+	values := []float64{-2, 1, -3, 5, -1, 3, -4, 4, -2}
+	data := make([]SingleChannelSample, len(values))
+	for i, v := range values {
+		data[i] = SingleChannelSample{Time: float64(i), Value: v}
+	}
+
+	// Run the test: E1049 counts 0.5 of range 3, 1.5 of 4, 0.5 of 6,
+	// 1 of 8 and 0.5 of 9
+	counts := make(map[float64]float64)
+	for _, cycle := range RainflowCount(data) {
+		counts[cycle.Range] += cycle.Count
+	}
+	expected := map[float64]float64{3: 0.5, 4: 1.5, 6: 0.5, 8: 1, 9: 0.5}
+	if len(counts) != len(expected) {
+		t.Errorf("got ranges %v, expected %v", counts, expected)
+	}
+	for r, count := range expected {
+		if counts[r] != count {
+			t.Errorf("range %v counted %v times, expected %v", r, counts[r], count)
+		}
+	}
+}
+
+func TestRainflowCountSine(t *testing.T) {
+	// Generate sample data: 10 cycles of a sine of amplitude 2 about a mean of 1
+	data := GenerateSineWave(1, 2, 10, 1000)
+	for i := range data {
+		data[i].Value++
+	}
+
+	// Run the test: between the ten peaks and ten valleys lie 19 half cycles
+	// of range 4, and the rise from the start and the fall to the end are
+	// half cycles of range 2
+	var count float64
+	for _, cycle := range RainflowCount(data) {
+		if cycle.Count == 0.5 && math.Abs(cycle.Range-2) < 0.1 {
+			continue
+		}
+		count += cycle.Count
+		if math.Abs(cycle.Range-4) > 1e-3 || math.Abs(cycle.Mean-1) > 1e-3 {
+			t.Errorf("cycle of range %v about %v, expected 4 about 1", cycle.Range, cycle.Mean)
+		}
+	}
+	if count != 9.5 {
+		t.Errorf("counted %v cycles of range 4, expected 9.5", count)
+	}
+}
+
+func TestTurningPoints(t *testing.T) {
+	values := []float64{0, 1, 2, 2, 1, 1, 3}
+	data := make([]SingleChannelSample, len(values))
+	for i, v := range values {
+		data[i] = SingleChannelSample{Time: float64(i), Value: v}
+	}
+	got := turningPoints(data)
+	expected := []float64{0, 2, 1, 3}
+	if len(got) != len(expected) {
+		t.Fatalf("got %v, expected %v", got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("got %v, expected %v", got, expected)
+			break
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package dynamics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Replay pushes recorded samples through a StreamAnalyzer, either as fast as
+// possible or paced by the recorded timestamps. Because the analyzer works in
+// sample time, its results do not depend on the replay speed.
+//
+// The analyzer is not closed when the data runs out, so several recordings can
+// be replayed back to back; call Close to flush the final partial window.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the replay
+//   - data: The recorded samples, in time order
+//   - analyzer: The analyzer to push the samples to
+//   - speed: 0 to replay as fast as possible, 1 for real time, or a multiple of real time
+//
+// Returns:
+//   - error: ctx.Err() if the replay was cancelled, the first error returned by
+//     Push, or an error if speed is negative or not finite
+func Replay(ctx context.Context, data []SingleChannelSample, analyzer *StreamAnalyzer, speed float64) error {
+	if !(speed >= 0) || math.IsInf(speed, 1) {
+		return errors.New("dynamics: replay speed must not be negative")
+	}
+
+	start := time.Now()
+	for _, sample := range data {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if speed > 0 {
+			offset := time.Duration((sample.Time - data[0].Time) / speed * float64(time.Second))
+			if wait := time.Until(start.Add(offset)); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		if err := analyzer.Push(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
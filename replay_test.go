@@ -0,0 +1,65 @@
+package dynamics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	// Generate sample data with a burst in the middle
+	data := burstSignal(0.3, 0.1, 0.2, 4)
+
+	replay := func(speed float64) ([]AnalysisResult, time.Duration) {
+		var results []AnalysisResult
+		sa, _ := NewStreamAnalyzer(0.05, 0.01, func(result AnalysisResult) {
+			results = append(results, result)
+		})
+		start := time.Now()
+		if err := Replay(context.Background(), data, sa, speed); err != nil {
+			t.Fatalf("Replay at speed %f returned error: %v", speed, err)
+		}
+		elapsed := time.Since(start)
+		_ = sa.Close()
+		return results, elapsed
+	}
+
+	// Run the test
+	fast, _ := replay(0)
+	paced, elapsed := replay(5)
+
+	if len(fast) == 0 || !reflect.DeepEqual(fast, paced) {
+		t.Errorf("Replay produced different results at different speeds: %d vs %d results", len(fast), len(paced))
+	}
+
+	// 0.3s of data at five times real time takes about 60ms
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("Paced replay took %v, expected at least 50ms", elapsed)
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	data := GenerateSineWave(50, 1, 10, 1000)
+	sa, _ := NewStreamAnalyzer(1, 1, nil)
+
+	// Run the test
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := Replay(ctx, data, sa, 1)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Replay returned %v, expected context.DeadlineExceeded", err)
+	}
+	if in := sa.Stats().SamplesIn; in == 0 || in >= uint64(len(data)) {
+		t.Errorf("Replay pushed %d samples before cancellation, expected some but not all", in)
+	}
+}
+
+func TestReplayInvalidSpeed(t *testing.T) {
+	sa, _ := NewStreamAnalyzer(1, 1, nil)
+	if err := Replay(context.Background(), nil, sa, -1); err == nil {
+		t.Errorf("Replay accepted a negative speed")
+	}
+}
@@ -0,0 +1,143 @@
+package dynamics
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// resonancePadding is the factor by which IdentifyResonances at least pads
+// the record with zeros, to interpolate the spectrum finely enough to find
+// the half-power points of lightly damped modes.
+const resonancePadding = 8
+
+// resonanceProminence is the least prominence of a peak that IdentifyResonances
+// reports, as a fraction of the highest peak of the spectrum.
+const resonanceProminence = 0.05
+
+// Resonance is a mode of vibration found by IdentifyResonances.
+type Resonance struct {
+	Frequency    float64 `json:"frequency"`    // in Hz
+	Magnitude    float64 `json:"magnitude"`    // height of the spectral peak
+	Prominence   float64 `json:"prominence"`   // height above the higher of the valleys either side
+	DampingRatio float64 `json:"dampingRatio"` // from the half-power bandwidth, NaN if it cannot be measured
+}
+
+// IdentifyResonances finds the resonant frequencies in the record of a free
+// decay, such as the ring-down after a hammer blow, from the peaks of its
+// amplitude spectrum. The record is padded with zeros to interpolate the
+// spectrum, and the peaks are ranked by prominence, the height of a peak
+// above the higher of the lowest points between it and a higher peak on
+// either side, so a shoulder on a strong mode does not count as a mode.
+// Peaks within minSeparation of a more prominent one, or less prominent than
+// a twentieth of the highest peak, are left out. The damping ratio of each
+// mode is half its half-power (−3 dB) bandwidth over its frequency, the
+// bandwidth being measured where the spectrum falls to 1/√2 of the peak.
+//
+// The samples are taken to be evenly spaced, and the record should hold the
+// whole decay, so that the spectrum is not broadened by its truncation.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - maxPeaks: The most resonances to report
+//   - minSeparation: The least spacing in Hz between reported resonances
+//
+// Returns:
+//   - []Resonance: The resonances, most prominent first; none when maxPeaks
+//     is not positive or the data spans no time
+func IdentifyResonances(data []SingleChannelSample, maxPeaks int, minSeparation float64) []Resonance {
+	if maxPeaks <= 0 || len(data) < 2 {
+		return nil
+	}
+	duration := data[len(data)-1].Time - data[0].Time
+	if !(duration > 0) {
+		return nil
+	}
+
+	n := 1 << bits.Len(uint(resonancePadding*len(data)-1))
+	values := make([]float64, n)
+	for i, sample := range data {
+		values[i] = sample.Value
+	}
+	spectrum := amplitudeSpectrum(values)
+	for k := range spectrum {
+		spectrum[k] *= float64(n) / float64(len(data))
+	}
+	resolution := float64(len(data)-1) / duration / float64(n)
+
+	var candidates []Resonance
+	var highest float64
+	for k := 1; k < len(spectrum)-1; k++ {
+		if spectrum[k] > spectrum[k-1] && spectrum[k] >= spectrum[k+1] {
+			highest = math.Max(highest, spectrum[k])
+			candidates = append(candidates, resonanceAt(spectrum, k, resolution))
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Prominence > candidates[j].Prominence })
+
+	var resonances []Resonance
+	for _, candidate := range candidates {
+		if len(resonances) == maxPeaks || candidate.Prominence < resonanceProminence*highest {
+			break
+		}
+		separate := true
+		for _, kept := range resonances {
+			if math.Abs(candidate.Frequency-kept.Frequency) < minSeparation {
+				separate = false
+				break
+			}
+		}
+		if separate {
+			resonances = append(resonances, candidate)
+		}
+	}
+	return resonances
+}
+
+// resonanceAt measures the peak of the spectrum at line k.
+func resonanceAt(spectrum []float64, k int, resolution float64) Resonance {
+	peak := spectrum[k]
+
+	// the lowest point on each side before a higher peak or the end of the spectrum
+	left, right := peak, peak
+	for i := k - 1; i >= 0 && spectrum[i] <= peak; i-- {
+		left = math.Min(left, spectrum[i])
+	}
+	for i := k + 1; i < len(spectrum) && spectrum[i] <= peak; i++ {
+		right = math.Min(right, spectrum[i])
+	}
+
+	// a parabola through the peak line and its neighbours places the peak between lines
+	a, b, c := spectrum[k-1], spectrum[k], spectrum[k+1]
+	offset := 0.0
+	if curvature := a - 2*b + c; curvature < 0 {
+		offset = (a - c) / (2 * curvature)
+	}
+	resonance := Resonance{
+		Frequency:    (float64(k) + offset) * resolution,
+		Magnitude:    peak,
+		Prominence:   peak - math.Max(left, right),
+		DampingRatio: math.NaN(),
+	}
+
+	// the half-power points, interpolated between lines; the search gives up
+	// where the spectrum turns up again before falling far enough
+	half := peak / math.Sqrt2
+	lower, upper := math.NaN(), math.NaN()
+	for i := k - 1; i >= 0 && spectrum[i] <= spectrum[i+1]; i-- {
+		if spectrum[i] <= half {
+			lower = float64(i) + (half-spectrum[i])/(spectrum[i+1]-spectrum[i])
+			break
+		}
+	}
+	for i := k + 1; i < len(spectrum) && spectrum[i] <= spectrum[i-1]; i++ {
+		if spectrum[i] <= half {
+			upper = float64(i) - (half-spectrum[i])/(spectrum[i-1]-spectrum[i])
+			break
+		}
+	}
+	if !math.IsNaN(lower) && !math.IsNaN(upper) && resonance.Frequency > 0 {
+		resonance.DampingRatio = (upper - lower) * resolution / (2 * resonance.Frequency)
+	}
+	return resonance
+}
@@ -0,0 +1,40 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIdentifyResonances(t *testing.T) {
+	// Generate sample data: 4 s at 2 kHz of a ring-down of two modes, 40 Hz
+	// with ζ = 0.02 and 130 Hz with ζ = 0.01 at half the amplitude
+	first := dampedSine(40, 0.02, 4, 2000)
+	second := dampedSine(130, 0.01, 4, 2000)
+	data := make([]SingleChannelSample, len(first))
+	for i := range data {
+		data[i] = SingleChannelSample{Time: first[i].Time, Value: first[i].Value + 0.5*second[i].Value}
+	}
+
+	// Run the test
+	resonances := IdentifyResonances(data, 5, 10)
+	if len(resonances) != 2 {
+		t.Fatalf("got %d resonances, expected 2: %+v", len(resonances), resonances)
+	}
+	// the damped frequency sits a hair below the natural frequency
+	for i, expected := range []struct{ frequency, zeta float64 }{{40, 0.02}, {130, 0.01}} {
+		got := resonances[i]
+		if math.Abs(got.Frequency-expected.frequency) > 0.01*expected.frequency {
+			t.Errorf("resonance %d at %v Hz, expected %v", i, got.Frequency, expected.frequency)
+		}
+		if math.Abs(got.DampingRatio-expected.zeta) > 0.2*expected.zeta {
+			t.Errorf("resonance %d damping ratio %v, expected %v", i, got.DampingRatio, expected.zeta)
+		}
+	}
+
+	if got := IdentifyResonances(data, 1, 10); len(got) != 1 || math.Abs(got[0].Frequency-40) > 0.4 {
+		t.Errorf("one peak: got %+v, expected the 40 Hz mode", got)
+	}
+	if IdentifyResonances(data, 0, 10) != nil {
+		t.Error("no peaks: expected nil")
+	}
+}
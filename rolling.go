@@ -0,0 +1,238 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// rollingPartition is the least number of samples RollingAnalyze analyses as
+// one partition. Each partition replays the window's worth of samples before
+// it, so a partition is also made at least four windows long.
+const rollingPartition = 1 << 16
+
+// RollingAnalyze analyses a record over a window of window seconds that moves
+// on by hop seconds, giving the results a StreamAnalyzer pushed the same
+// samples would emit before Close: the first once a full window has been
+// seen, then one every hop seconds of sample time.
+//
+// The record is analysed in fixed partitions, each starting from a fresh
+// window primed with the samples before it, so that RollingAnalyzeParallel
+// can analyse the partitions concurrently and still give identical results.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - window: The length of the analysis window in seconds
+//   - hop: The sample time between successive results in seconds
+//
+// Returns:
+//   - []AnalysisResult: The results in time order
+//   - error: An error if window or hop is not a positive, finite number, or
+//     ErrUnsortedData if the data is not in time order
+func RollingAnalyze(data []SingleChannelSample, window, hop float64) ([]AnalysisResult, error) {
+	return RollingAnalyzeParallel(data, window, hop, 1)
+}
+
+// RollingAnalyzeParallel is RollingAnalyze with the partitions of the record
+// shared among workers goroutines. The results are identical to RollingAnalyze
+// whatever the number of workers.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - window: The length of the analysis window in seconds
+//   - hop: The sample time between successive results in seconds
+//   - workers: The number of goroutines, or 0 or less for runtime.GOMAXPROCS(0)
+//
+// Returns:
+//   - []AnalysisResult: The results in time order
+//   - error: As for RollingAnalyze
+func RollingAnalyzeParallel(data []SingleChannelSample, window, hop float64, workers int) ([]AnalysisResult, error) {
+	if !(window > 0) || math.IsInf(window, 1) {
+		return nil, errors.New("dynamics: rolling window must be positive")
+	}
+	if !(hop > 0) || math.IsInf(hop, 1) {
+		return nil, errors.New("dynamics: rolling hop must be positive")
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	// size partitions from the samples in the first window, so that the
+	// samples replayed to prime each one stay a small share of the work
+	perWindow := sort.Search(len(data), func(i int) bool { return data[i].Time > data[0].Time+window })
+	size := max(rollingPartition, 4*perWindow)
+	partitions := make([][]AnalysisResult, (len(data)+size-1)/size)
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(partitions))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				p := int(next.Add(1) - 1)
+				if p >= len(partitions) {
+					return
+				}
+				lo := p * size
+				partitions[p] = rollingPartitionResults(data, lo, min(lo+size, len(data)), window, hop)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count int
+	for _, results := range partitions {
+		count += len(results)
+	}
+	results := make([]AnalysisResult, 0, count)
+	for _, partition := range partitions {
+		results = append(results, partition...)
+	}
+	return results, nil
+}
+
+// rollingPartitionResults returns the results due at samples lo to hi-1. The
+// schedule is restored to where it stood after sample lo-1, and the window
+// is primed with every sample that can still be in it when sample lo is
+// pushed, which leaves its samples and crossings as they would be had the
+// whole record been pushed.
+//
+// Parameters:
+//   - data: The whole record
+//   - lo: The index of the first sample of the partition
+//   - hi: The index one past the last sample of the partition
+//   - window: The length of the analysis window in seconds
+//   - hop: The sample time between successive results in seconds
+//
+// Returns:
+//   - []AnalysisResult: The results due within the partition
+func rollingPartitionResults(data []SingleChannelSample, lo, hi int, window, hop float64) []AnalysisResult {
+	w := newSlidingWindow(window, 1)
+	w.borrowScratch()
+	defer w.releaseScratch()
+	schedule := hopSchedule{window: window, hop: hop}
+	if lo > 0 {
+		schedule.started = true
+		schedule.origin = data[0].Time
+		schedule.hops = schedule.hopsBy(data[lo-1].Time)
+
+		// the window keeps less than window seconds; the margin covers its tolerance
+		prime := sort.Search(lo, func(i int) bool { return data[i].Time >= data[lo].Time-2*window })
+		for _, sample := range data[prime:lo] {
+			w.push(sample.Time, []float64{sample.Value})
+		}
+	}
+
+	var results []AnalysisResult
+	for _, sample := range data[lo:hi] {
+		w.push(sample.Time, []float64{sample.Value})
+		if schedule.advance(sample.Time) {
+			results = append(results, w.result(0))
+		}
+	}
+	return results
+}
+
+// SlidingRMS returns the RMS of the data over a window of windowSeconds that
+// moves on by hopSeconds, as a time series for trend plots. Each value is
+// timed at the centre of its window, and the first window starts at the first
+// sample. A window holds the samples from its start up to, but not including,
+// its end. Only full windows are reported: the series stops at the last window
+// the samples reach the end of, the last sample lying within one mean sample
+// interval of it, so no value is taken over a partial window at the end of the
+// record. A window that holds no samples, in a gap in the data, is skipped.
+//
+// The sum of squares is kept running as the window moves, with samples
+// entering and leaving it once each, so the cost grows with the length of the
+// record and not with the length of the window. It is summed with
+// compensation, so it does not drift however many samples pass through. A
+// NaN or infinite value, or one whose square overflows, is kept out of the sum
+// and counted instead: the windows holding it are NaN, or +Inf for an
+// overflow, and the windows after it are unaffected.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - windowSeconds: The length of each window in seconds
+//   - hopSeconds: The time between the starts of successive windows in
+//     seconds, at least the mean sample interval
+//
+// Returns:
+//   - []SingleChannelSample: The RMS of each window, timed at its centre; none
+//     when windowSeconds or hopSeconds is not positive and finite, hopSeconds
+//     is shorter than the mean sample interval, whose windows would repeat,
+//     or the data spans no time or is not in time order
+func SlidingRMS(data []SingleChannelSample, windowSeconds, hopSeconds float64) []SingleChannelSample {
+	if !(windowSeconds > 0) || math.IsInf(windowSeconds, 1) || !(hopSeconds > 0) || math.IsInf(hopSeconds, 1) {
+		return nil
+	}
+	duration, err := recordDuration(data)
+	if err != nil || checkTimeOrder(data) != nil {
+		return nil
+	}
+	step := duration / float64(len(data)-1)
+	if hopSeconds < step*(1-spacingTolerance) {
+		return nil
+	}
+	origin, last := data[0].Time, data[len(data)-1].Time
+
+	// a hop of at least the step gives about one window per sample at most
+	windows := math.Max(0, (last+step-origin-windowSeconds)/hopSeconds+1)
+	series := make([]SingleChannelSample, 0, int(math.Min(windows, float64(len(data)))))
+	var sum compensatedSum
+	var nans, infs int // squares in the window kept out of the sum
+	move := func(value float64, enter bool) {
+		sign, count := 1.0, 1
+		if !enter {
+			sign, count = -1, -1
+		}
+		switch square := value * value; {
+		case math.IsNaN(square):
+			nans += count
+		case math.IsInf(square, 1):
+			infs += count
+		default:
+			sum.add(sign * square)
+		}
+	}
+	lo, hi := 0, 0 // the window holds data[lo:hi]
+	for k := 0; ; k++ {
+		start := origin + float64(k)*hopSeconds
+		end := start + windowSeconds
+		if last+step < end-timeTolerance(end, step) {
+			break
+		}
+		for hi < len(data) && data[hi].Time < end-timeTolerance(end, step) {
+			move(data[hi].Value, true)
+			hi++
+		}
+		for lo < hi && data[lo].Time < start-timeTolerance(start, step) {
+			move(data[lo].Value, false)
+			lo++
+		}
+		if hi == lo {
+			continue
+		}
+		// rounding can leave a sum of squares a hair below zero once large values have left
+		rms := math.Sqrt(math.Max(sum.value(), 0) / float64(hi-lo))
+		switch {
+		case nans > 0:
+			rms = math.NaN()
+		case infs > 0:
+			rms = math.Inf(1)
+		}
+		series = append(series, SingleChannelSample{Time: start + windowSeconds/2, Value: rms})
+	}
+	return series
+}
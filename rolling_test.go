@@ -0,0 +1,237 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// rollingRecord generates a record spanning several partitions, with a
+// frequency change, a stretch of silence and a gap that jumps several hops.
+func rollingRecord() []SingleChannelSample {
+	data := GenerateSineWave(50, 1, 300, 1000)
+	for i := range data {
+		switch {
+		case data[i].Time > 250:
+			data[i].Time += 1.234
+		case data[i].Time > 200:
+			data[i].Value = 0
+		case data[i].Time > 100:
+			data[i].Value = 2 * math.Sin(2*math.Pi*61*data[i].Time)
+		}
+	}
+	return data
+}
+
+func TestRollingAnalyzeParallelMatchesSerial(t *testing.T) {
+	// Generate sample data
+	data := rollingRecord()
+	expected, err := RollingAnalyze(data, 0.2, 0.01)
+	if err != nil {
+		t.Fatalf("RollingAnalyze returned error: %v", err)
+	}
+
+	for _, workers := range []int{0, 2, 3, 8} {
+		// Run the test
+		results, err := RollingAnalyzeParallel(data, 0.2, 0.01, workers)
+		if err != nil {
+			t.Fatalf("%d workers: RollingAnalyzeParallel returned error: %v", workers, err)
+		}
+		if len(results) != len(expected) {
+			t.Fatalf("%d workers: got %d results, expected %d", workers, len(results), len(expected))
+		}
+		for i := range results {
+			if results[i] != expected[i] {
+				t.Fatalf("%d workers: result %d is %+v, expected %+v", workers, i, results[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestRollingAnalyzeMatchesStream(t *testing.T) {
+	// Generate sample data
+	data := rollingRecord()
+	var streamed []AnalysisResult
+	sa, err := NewStreamAnalyzer(0.2, 0.01, func(result AnalysisResult) {
+		streamed = append(streamed, result)
+	})
+	if err != nil {
+		t.Fatalf("NewStreamAnalyzer returned error: %v", err)
+	}
+	for _, sample := range data {
+		if err := sa.Push(sample); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+	}
+
+	// Run the test: the windows are the same; only the rounding of the
+	// running sum of squares, which starts afresh in each partition, differs
+	results, err := RollingAnalyze(data, 0.2, 0.01)
+	if err != nil {
+		t.Fatalf("RollingAnalyze returned error: %v", err)
+	}
+	if len(results) != len(streamed) {
+		t.Fatalf("got %d results, expected %d", len(results), len(streamed))
+	}
+	for i := range results {
+		got, want := results[i], streamed[i]
+		if math.Abs(got.RMS-want.RMS) > 1e-12 {
+			t.Fatalf("result %d RMS = %v, expected %v", i, got.RMS, want.RMS)
+		}
+		got.RMS = want.RMS
+		if got != want {
+			t.Fatalf("result %d is %+v, expected %+v", i, got, want)
+		}
+	}
+}
+
+func TestHopScheduleHopsBy(t *testing.T) {
+	// Generate sample data: irregular steps, some jumping several hops
+	s := hopSchedule{window: 0.5, hop: 0.1}
+	t0 := 1000.0
+	step := []float64{0.001, 0.03, 0.1, 0.37, 0.0999999999999}
+
+	// Run the test
+	for i, tm := 0, t0; i < 2000; i++ {
+		s.advance(tm)
+		if got := s.hopsBy(tm); got != s.hops {
+			t.Fatalf("hopsBy(%v) = %d, expected %d", tm, got, s.hops)
+		}
+		tm += step[i%len(step)]
+	}
+}
+
+func TestRollingAnalyzeValidation(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+	swapped := append([]SingleChannelSample(nil), data...)
+	swapped[10], swapped[11] = swapped[11], swapped[10]
+
+	// Run the test
+	for _, c := range []struct{ window, hop float64 }{{0, 0.1}, {1, 0}, {math.NaN(), 0.1}, {1, math.Inf(1)}} {
+		if _, err := RollingAnalyze(data, c.window, c.hop); err == nil {
+			t.Errorf("window %v, hop %v: expected an error", c.window, c.hop)
+		}
+	}
+	if _, err := RollingAnalyze(swapped, 0.1, 0.1); !errors.Is(err, ErrUnsortedData) {
+		t.Errorf("unsorted data: got error %v, expected ErrUnsortedData", err)
+	}
+	if results, err := RollingAnalyze(nil, 0.1, 0.1); err != nil || len(results) != 0 {
+		t.Errorf("empty data: got %d results and error %v, expected none", len(results), err)
+	}
+}
+
+func BenchmarkRollingAnalyzeParallel(b *testing.B) {
+	// Generate sample data: 10M samples, 10 ms hops over a 100 ms window
+	data := GenerateSineWave(50, 1, 1000, 10000)
+
+	// Run the benchmark
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				RollingAnalyzeParallel(data, 0.1, 0.01, workers)
+			}
+		})
+	}
+}
+
+func TestSlidingRMS(t *testing.T) {
+	// Generate sample data: 2 s at 10 kHz, silent but for a 1 kHz burst of
+	// amplitude 2 from 0.5 to 1 s
+	data := GenerateToneBurst(1000, 2, 0.5, 0.5, 2, 10000)
+
+	// Run the test: 0.1 s windows every 0.05 s, the last ending at the end of the record
+	series := SlidingRMS(data, 0.1, 0.05)
+	if len(series) != 39 {
+		t.Fatalf("SlidingRMS returned %d values, expected 39", len(series))
+	}
+	for k, value := range series {
+		centre := 0.05*float64(k) + 0.05
+		if math.Abs(value.Time-centre) > 1e-9 {
+			t.Errorf("value %d at %v s, expected the window centre, %v s", k, value.Time, centre)
+		}
+		start := centre - 0.05
+		expected := math.NaN() // windows straddling an edge of the burst lie between
+		switch {
+		case start > 0.5-1e-9 && start+0.1 < 1+1e-9:
+			expected = 2 / math.Sqrt2
+		case start+0.1 < 0.5+1e-9 || start > 1-1e-9:
+			expected = 0
+		}
+		if !math.IsNaN(expected) && math.Abs(value.Value-expected) > 1e-6 {
+			t.Errorf("window at %v s has RMS %v, expected %v", start, value.Value, expected)
+		}
+		// the running sum agrees with the RMS of the window's samples
+		window := data[int(math.Round(start*10000)):int(math.Round((start+0.1)*10000))]
+		if direct := calculateRMS(window); math.Abs(value.Value-direct) > 1e-9 {
+			t.Errorf("window at %v s has RMS %v, expected %v from its samples", start, value.Value, direct)
+		}
+	}
+
+	// hops longer than the window leave gaps between windows
+	if sparse := SlidingRMS(data, 0.1, 0.3); len(sparse) != 7 {
+		t.Errorf("SlidingRMS with hops longer than the window returned %d values, expected 7", len(sparse))
+	}
+}
+
+func TestSlidingRMSNonFinite(t *testing.T) {
+	// Generate sample data: 1 s of a 50 Hz sine at 1 kHz with a NaN at 0.1 s,
+	// a value whose square overflows at 0.5 s and an infinity at 0.75 s
+	data := GenerateSineWave(50, 1, 1, 1000)
+	data[100].Value = math.NaN()
+	data[500].Value = 1e200
+	data[750].Value = math.Inf(-1)
+
+	// Run the test: only the windows holding them are affected
+	series := SlidingRMS(data, 0.1, 0.1)
+	if len(series) != 10 {
+		t.Fatalf("SlidingRMS returned %d values, expected 10", len(series))
+	}
+	for k, value := range series {
+		switch k {
+		case 1:
+			if !math.IsNaN(value.Value) {
+				t.Errorf("window %d has RMS %v, expected NaN", k, value.Value)
+			}
+		case 5, 7:
+			if !math.IsInf(value.Value, 1) {
+				t.Errorf("window %d has RMS %v, expected +Inf", k, value.Value)
+			}
+		default:
+			if math.Abs(value.Value-1/math.Sqrt2) > 1e-6 {
+				t.Errorf("window %d has RMS %v, expected %v", k, value.Value, 1/math.Sqrt2)
+			}
+		}
+	}
+}
+
+func TestSlidingRMSInvalid(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 1, 1000)
+
+	// Run the test
+	for _, tt := range []struct {
+		name        string
+		data        []SingleChannelSample
+		window, hop float64
+	}{
+		{"zero window", data, 0, 0.1},
+		{"negative hop", data, 0.1, -1},
+		{"infinite window", data, math.Inf(1), 0.1},
+		{"NaN hop", data, 0.1, math.NaN()},
+		{"window longer than the record", data, 2, 0.1},
+		{"hop shorter than the sample step", data, 0.5, 0.0005},
+		{"tiny hop", data, 0.5, 1e-15},
+		{"single sample", data[:1], 0.1, 0.1},
+		{"empty", nil, 0.1, 0.1},
+	} {
+		if series := SlidingRMS(tt.data, tt.window, tt.hop); len(series) != 0 {
+			t.Errorf("%s: SlidingRMS returned %d values, expected none", tt.name, len(series))
+		}
+	}
+	// a hop of one sample step is the shortest accepted
+	if series := SlidingRMS(data, 0.5, 0.001); len(series) != 501 {
+		t.Errorf("hop of one sample: SlidingRMS returned %d values, expected 501", len(series))
+	}
+}
@@ -0,0 +1,323 @@
+package dynamics
+
+import (
+	"math"
+	"sync"
+)
+
+// Sample32 is a single-channel sample held in single precision, taking half
+// the memory of a SingleChannelSample. Its Time is measured from an epoch the
+// caller keeps, such as the start of a recording, so that small relative times
+// keep their precision; a float32 time resolves the 100 µs steps of 10 kHz
+// data for the first 1024 seconds after the epoch.
+//
+// The functions taking Sample32 data compute in float64, so their results
+// differ from the float64 functions only by the rounding of the stored samples.
+type Sample32 struct {
+	Time  float32 `json:"time"` // seconds since the epoch
+	Value float32 `json:"value"`
+}
+
+// ToSample32 converts samples to single precision, measuring times from epoch.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - epoch: The time that becomes time 0 in the converted samples
+//
+// Returns:
+//   - []Sample32: The converted samples
+func ToSample32(data []SingleChannelSample, epoch float64) []Sample32 {
+	converted := make([]Sample32, len(data))
+	for i, sample := range data {
+		converted[i] = Sample32{Time: float32(sample.Time - epoch), Value: float32(sample.Value)}
+	}
+	return converted
+}
+
+// FromSample32 converts single-precision samples back to double precision,
+// adding epoch to every time.
+//
+// Parameters:
+//   - data: A slice of Sample32 structs
+//   - epoch: The time that time 0 in data stands for
+//
+// Returns:
+//   - []Sample: The converted samples
+func FromSample32(data []Sample32, epoch float64) []SingleChannelSample {
+	converted := make([]SingleChannelSample, len(data))
+	for i, sample := range data {
+		converted[i] = SingleChannelSample{Time: epoch + float64(sample.Time), Value: float64(sample.Value)}
+	}
+	return converted
+}
+
+// Analyze32 is Analyze for single-precision samples. Data or options that
+// Analyze32E rejects yield zeros.
+//
+// Parameters:
+//   - data: A slice of Sample32 structs
+//   - opts: Options such as WithNonFinite
+//
+// Returns:
+//   - rms: The calculated Root Mean Square value
+//   - zcr: The calculated Negative Zero Crossing Rate
+func Analyze32(data []Sample32, opts ...AnalyzeOption) (rms float64, zcr float64) {
+	rms, zcr, _ = Analyze32E(data, opts...)
+	return rms, zcr
+}
+
+// Analyze32E is Analyze32, reporting what it cannot analyse instead of
+// returning zeros.
+//
+// Parameters:
+//   - data: A slice of Sample32 structs
+//   - opts: Options such as WithNonFinite
+//
+// Returns:
+//   - rms: The calculated Root Mean Square value, or 0 on error
+//   - zcr: The calculated Negative Zero Crossing Rate, or 0 on error
+//   - err: ErrEmptyData if data is empty, ErrZeroDuration if it holds a single
+//     sample, ErrNonFinite under NonFiniteStrict, or an error wrapping
+//     ErrUnsupportedOption for WithRemoveDC or WithACFFrequency
+func Analyze32E(data []Sample32, opts ...AnalyzeOption) (rms float64, zcr float64, err error) {
+	config := newAnalyzeConfig(opts)
+	if err := config.checkSinglePass(); err != nil {
+		return 0, 0, err
+	}
+	data, scratch, err := prepare32(data, config)
+	defer sample32Scratch.put(scratch)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch len(data) {
+	case 0:
+		return 0, 0, ErrEmptyData
+	case 1:
+		return 0, 0, ErrZeroDuration
+	}
+
+	zcr = NegativeZeroCrossingRate32(data)
+	if zcr == 0 || math.IsNaN(zcr) || math.IsInf(zcr, 0) {
+		return rms32(data, config.compensated), 0, nil
+	}
+	span := rmsSpan(duration32(data), zcr, config.maxCycles)
+	return rms32(keep32(data, span), config.compensated), zcr, nil
+}
+
+// RMS32 is RMS for single-precision samples.
+//
+// Parameters:
+//   - data: A slice of Sample32 structs
+//   - frequency: The frequency of the signal
+//
+// Returns:
+//   - float64: The calculated Root Mean Square value
+func RMS32(data []Sample32, frequency float64) float64 {
+	if len(data) == 0 || frequency == 0 {
+		return 0
+	}
+	if frequency < 0 || math.IsNaN(frequency) || math.IsInf(frequency, 0) {
+		return rms32(data, false)
+	}
+	return rms32(keep32(data, rmsSpan(duration32(data), frequency, DefaultMaxCycles)), false)
+}
+
+// ZeroCrossingRate32 is ZeroCrossingRate for single-precision samples.
+func ZeroCrossingRate32(data []Sample32) float64 {
+	negative, positive := crossings32(data)
+	return rate32(data, negative+positive)
+}
+
+// NegativeZeroCrossingRate32 is NegativeZeroCrossingRate for single-precision samples.
+func NegativeZeroCrossingRate32(data []Sample32) float64 {
+	negative, _ := crossings32(data)
+	return rate32(data, negative)
+}
+
+// crossings32 counts the zero crossings in the data as crossingDetector.count does.
+func crossings32(data []Sample32) (negative, positive int) {
+	var detector crossingDetector
+	for _, sample := range data {
+		n, p := detector.step(float64(sample.Value))
+		if n {
+			negative++
+		}
+		if p {
+			positive++
+		}
+	}
+	return negative, positive
+}
+
+// rate32 divides a crossing count by the duration of the data, giving 0 when
+// the duration is not positive.
+func rate32(data []Sample32, crossings int) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	duration := duration32(data)
+	if !(duration > 0) {
+		return 0
+	}
+	return float64(crossings) / duration
+}
+
+// duration32 returns the time from the first to the last sample of non-empty data.
+func duration32(data []Sample32) float64 {
+	return float64(data[len(data)-1].Time) - float64(data[0].Time)
+}
+
+// keep32 is KeepXSecondsOfData for single-precision samples.
+func keep32(data []Sample32, seconds float64) []Sample32 {
+	cutoff := float64(data[len(data)-1].Time) - seconds
+	for i, sample := range data {
+		if float64(sample.Time) >= cutoff {
+			return data[i:]
+		}
+	}
+	return nil
+}
+
+// rms32 returns the RMS of the data, summing the squares in float64 and with
+// compensation when asked to or when the data is long enough to need it.
+func rms32(data []Sample32, compensated bool) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	var sum float64
+	if compensated || len(data) >= compensatedSumThreshold {
+		var s compensatedSum
+		for _, sample := range data {
+			v := float64(sample.Value)
+			s.add(v * v)
+		}
+		sum = s.value()
+	} else {
+		for _, sample := range data {
+			v := float64(sample.Value)
+			sum += v * v
+		}
+	}
+	return math.Sqrt(sum / float64(len(data)))
+}
+
+// prepare32 applies the configured non-finite policy to single-precision
+// data, as analyzeConfig.prepareScratch does, returning an error wrapping
+// ErrNonFinite when NonFiniteStrict rejects it. The caller must hand scratch
+// back with sample32Scratch.put.
+func prepare32(data []Sample32, config analyzeConfig) (prepared []Sample32, scratch *[]Sample32, err error) {
+	if config.nonFinite == NonFinitePropagate {
+		return data, nil, nil
+	}
+
+	for i, sample := range data {
+		v := float64(sample.Value)
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			continue
+		}
+		if config.nonFinite == NonFiniteStrict {
+			return nil, nil, nonFiniteError(i, float64(sample.Time), v)
+		}
+
+		scratch = sample32Scratch.get(0)
+		finite := append(*scratch, data[:i]...)
+		for _, sample := range data[i+1:] {
+			v := float64(sample.Value)
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				finite = append(finite, sample)
+			}
+		}
+		*scratch = finite
+		return finite, scratch, nil
+	}
+	return data, nil, nil
+}
+
+// CircularBuffer32 is a CircularBuffer of single-precision samples, holding
+// a long history in half the memory. It is safe for concurrent use.
+type CircularBuffer32 struct {
+	mu    sync.RWMutex
+	data  []Sample32
+	head  int
+	count int
+}
+
+// NewCircularBuffer32 creates a new CircularBuffer32 with the specified size.
+func NewCircularBuffer32(size int) *CircularBuffer32 {
+	return &CircularBuffer32{data: make([]Sample32, size)}
+}
+
+// Update adds a new sample to the circular buffer.
+func (cb *CircularBuffer32) Update(sample Sample32) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.data[cb.head] = sample
+	cb.head = (cb.head + 1) % len(cb.data)
+	if cb.count < len(cb.data) {
+		cb.count++
+	}
+}
+
+// GetData returns a slice of the data in the buffer, from oldest to newest.
+func (cb *CircularBuffer32) GetData() []Sample32 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.appendData(make([]Sample32, 0, cb.count))
+}
+
+// AnalyzeBuffer calculates the RMS and NZCR of the data stored in the circular
+// buffer, over all of it as CircularBuffer.AnalyzeBuffer does.
+func (cb *CircularBuffer32) AnalyzeBuffer() (rms float64, zcr float64) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if cb.count == 0 {
+		return 0, 0
+	}
+
+	older, newer := cb.segments()
+	compensated := cb.count >= compensatedSumThreshold
+	var detector crossingDetector
+	var crossings int
+	var sum compensatedSum
+	for _, segment := range [][]Sample32{older, newer} {
+		for _, sample := range segment {
+			v := float64(sample.Value)
+			if compensated {
+				sum.add(v * v)
+			} else {
+				sum.sum += v * v
+			}
+			if negative, _ := detector.step(v); negative {
+				crossings++
+			}
+		}
+	}
+	rms = math.Sqrt(sum.value() / float64(cb.count))
+
+	duration := float64(cb.data[(cb.head-1+len(cb.data))%len(cb.data)].Time) - float64(older[0].Time)
+	if duration > 0 {
+		zcr = float64(crossings) / duration
+	}
+	return rms, zcr
+}
+
+// segments returns the buffered data, oldest first, as the two contiguous
+// runs of the underlying array it occupies. The caller must hold cb.mu.
+func (cb *CircularBuffer32) segments() (older, newer []Sample32) {
+	first := (cb.head - cb.count + len(cb.data)) % len(cb.data)
+	if first+cb.count <= len(cb.data) {
+		return cb.data[first : first+cb.count], nil
+	}
+	return cb.data[first:], cb.data[:cb.head]
+}
+
+// appendData appends the buffered data, oldest first, to dst. The caller must hold cb.mu.
+func (cb *CircularBuffer32) appendData(dst []Sample32) []Sample32 {
+	older, newer := cb.segments()
+	dst = append(dst, older...)
+	return append(dst, newer...)
+}
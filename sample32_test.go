@@ -0,0 +1,133 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// sample32Tolerance is the relative difference allowed between float32 and
+// float64 results. Rounding the values costs about 1e-7, but a time rounded
+// across the start of the RMS window moves a sample in or out of it, which
+// for these signals of 10000 or more samples costs up to about 1e-4.
+const sample32Tolerance = 1e-4
+
+func closeRelative(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestSample32Conversion(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 2, 0.1, 1000)
+	const epoch = 1.7e9
+	for i := range data {
+		data[i].Time += epoch
+	}
+
+	// Run the test: times are relative to the epoch and survive the round trip
+	converted := ToSample32(data, epoch)
+	if math.Abs(float64(converted[1].Time)-0.001) > 1e-6 {
+		t.Errorf("second sample time = %v, expected 0.001", converted[1].Time)
+	}
+	back := FromSample32(converted, epoch)
+	for i := range data {
+		if math.Abs(back[i].Time-data[i].Time) > 1e-6 || math.Abs(back[i].Value-data[i].Value) > 1e-6 {
+			t.Fatalf("sample %d = %+v after the round trip, expected %+v", i, back[i], data[i])
+		}
+	}
+}
+
+func TestAnalyze32MatchesAnalyze(t *testing.T) {
+	for _, frequency := range []float64{50, 60, 440} {
+		// Generate sample data
+		data := GenerateSineWave(frequency, 1.5, 2, 10000)
+		converted := ToSample32(data, 0)
+
+		// Run the test
+		rms, zcr := Analyze(data)
+		rms32, zcr32 := Analyze32(converted)
+		if !closeRelative(rms, rms32, sample32Tolerance) || !closeRelative(zcr, zcr32, sample32Tolerance) {
+			t.Errorf("%v Hz: Analyze32 = %v, %v, expected %v, %v", frequency, rms32, zcr32, rms, zcr)
+		}
+		if got, expected := RMS32(converted, frequency), RMS(data, frequency); !closeRelative(got, expected, sample32Tolerance) {
+			t.Errorf("%v Hz: RMS32 = %v, expected %v", frequency, got, expected)
+		}
+		if got, expected := ZeroCrossingRate32(converted), ZeroCrossingRate(data); !closeRelative(got, expected, sample32Tolerance) {
+			t.Errorf("%v Hz: ZeroCrossingRate32 = %v, expected %v", frequency, got, expected)
+		}
+	}
+}
+
+func TestAnalyze32NonFinite(t *testing.T) {
+	// Generate sample data with a gap
+	data := GenerateSineWave(60, 1, 1, 10000)
+	data[100].Value = math.NaN()
+	converted := ToSample32(data, 0)
+
+	// Run the test
+	if rms, zcr := Analyze32(converted, WithNonFinite(NonFiniteStrict)); rms != 0 || zcr != 0 {
+		t.Errorf("Analyze32 with NonFiniteStrict = %v, %v, expected zeros", rms, zcr)
+	}
+	rms, zcr := Analyze(data, WithNonFinite(NonFiniteSkip))
+	rms32, zcr32 := Analyze32(converted, WithNonFinite(NonFiniteSkip))
+	if !closeRelative(rms, rms32, sample32Tolerance) || !closeRelative(zcr, zcr32, sample32Tolerance) {
+		t.Errorf("Analyze32 with NonFiniteSkip = %v, %v, expected %v, %v", rms32, zcr32, rms, zcr)
+	}
+	if !math.IsNaN(float64(converted[100].Value)) {
+		t.Error("Analyze32 modified its input")
+	}
+}
+
+func TestAnalyze32Errors(t *testing.T) {
+	// Generate sample data
+	data := ToSample32(GenerateSineWave(60, 1, 1, 1000), 0)
+	broken := append([]Sample32(nil), data...)
+	broken[10].Value = float32(math.Inf(1))
+
+	// Run the test
+	if _, _, err := Analyze32E(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got error %v, expected ErrEmptyData", err)
+	}
+	if _, _, err := Analyze32E(data[:1]); !errors.Is(err, ErrZeroDuration) {
+		t.Errorf("single sample: got error %v, expected ErrZeroDuration", err)
+	}
+	if _, _, err := Analyze32E(broken, WithNonFinite(NonFiniteStrict)); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("NonFiniteStrict: got error %v, expected ErrNonFinite", err)
+	}
+	if _, _, err := Analyze32E(data, WithRemoveDC()); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("WithRemoveDC: got error %v, expected ErrUnsupportedOption", err)
+	}
+	if _, _, err := Analyze32E(data, WithACFFrequency()); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("WithACFFrequency: got error %v, expected ErrUnsupportedOption", err)
+	}
+	if rms, zcr := Analyze32(data, WithRemoveDC()); rms != 0 || zcr != 0 {
+		t.Errorf("Analyze32 with WithRemoveDC = %v, %v, expected zeros", rms, zcr)
+	}
+	rms, zcr := Analyze32(data)
+	rmsE, zcrE, err := Analyze32E(data)
+	if err != nil || rmsE != rms || zcrE != zcr {
+		t.Errorf("Analyze32E = %v, %v, %v; expected %v, %v, nil", rmsE, zcrE, err, rms, zcr)
+	}
+}
+
+func TestCircularBuffer32(t *testing.T) {
+	// Generate sample data, wrapping the buffer
+	data := GenerateSineWave(50, 1, 1.5, 1000)
+	cb := NewCircularBuffer(1000)
+	cb32 := NewCircularBuffer32(1000)
+	for _, sample := range data {
+		cb.Update(sample)
+		cb32.Update(Sample32{Time: float32(sample.Time), Value: float32(sample.Value)})
+	}
+
+	// Run the test
+	rms, zcr := cb.AnalyzeBuffer()
+	rms32, zcr32 := cb32.AnalyzeBuffer()
+	if !closeRelative(rms, rms32, sample32Tolerance) || !closeRelative(zcr, zcr32, sample32Tolerance) {
+		t.Errorf("AnalyzeBuffer = %v, %v, expected %v, %v", rms32, zcr32, rms, zcr)
+	}
+	got := cb32.GetData()
+	if len(got) != 1000 || got[0].Time != float32(data[500].Time) {
+		t.Errorf("GetData returned %d samples starting at %v, expected 1000 from %v", len(got), got[0].Time, data[500].Time)
+	}
+}
@@ -0,0 +1,52 @@
+package dynamics
+
+import (
+	"context"
+	"time"
+)
+
+// StartPeriodicAnalysis analyses the circular buffer every interval until the
+// context is cancelled, handing each result to fn.
+//
+// Intervals where the buffer holds no samples are skipped. fn is always called
+// from the same goroutine, so it is never called concurrently with itself, and
+// it is not called again once ctx is done. As with time.NewTicker, interval must
+// be greater than zero.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the analysis
+//   - buf: The circular buffer to analyse
+//   - interval: The wall-clock time between analyses
+//   - fn: Callback receiving each analysis result
+//
+// Returns:
+//   - <-chan struct{}: A channel that is closed once the analysis goroutine has exited
+func StartPeriodicAnalysis(ctx context.Context, buf *CircularBuffer, interval time.Duration, fn func(AnalysisResult)) <-chan struct{} {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// a tick and the cancellation can be ready at the same time, and
+				// select picks between them at random
+				if ctx.Err() != nil {
+					return
+				}
+				result := buf.Analysis()
+				if result.Samples == 0 {
+					continue
+				}
+				fn(result)
+			}
+		}
+	}()
+
+	return done
+}
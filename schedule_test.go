@@ -0,0 +1,69 @@
+package dynamics
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartPeriodicAnalysis(t *testing.T) {
+	// Fill a buffer with one second of a 50 Hz sine
+	cb := NewCircularBuffer(1000)
+	for _, sample := range GenerateSineWave(50, 1, 1, 1000) {
+		cb.Update(sample)
+	}
+
+	// Run the test
+	var calls atomic.Int32
+	var running atomic.Bool
+	var overlapped atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	done := StartPeriodicAnalysis(ctx, cb, 5*time.Millisecond, func(result AnalysisResult) {
+		if !running.CompareAndSwap(false, true) {
+			overlapped.Store(true)
+		}
+		// a slow callback must delay the next call rather than overlap it
+		time.Sleep(7 * time.Millisecond)
+		if result.Samples != 1000 {
+			t.Errorf("StartPeriodicAnalysis reported %d samples, expected 1000", result.Samples)
+		}
+		calls.Add(1)
+		running.Store(false)
+	})
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	count := calls.Load()
+	if count < 3 {
+		t.Errorf("StartPeriodicAnalysis called fn %d times in 100ms, expected at least 3", count)
+	}
+	if overlapped.Load() {
+		t.Errorf("StartPeriodicAnalysis called fn concurrently with itself")
+	}
+
+	// No calls may happen after cancellation
+	time.Sleep(20 * time.Millisecond)
+	if after := calls.Load(); after != count {
+		t.Errorf("StartPeriodicAnalysis called fn %d times after cancel", after-count)
+	}
+}
+
+func TestStartPeriodicAnalysisEmptyBuffer(t *testing.T) {
+	cb := NewCircularBuffer(100)
+
+	// Run the test
+	var calls atomic.Int32
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	done := StartPeriodicAnalysis(ctx, cb, time.Millisecond, func(AnalysisResult) {
+		calls.Add(1)
+	})
+	<-done
+
+	if count := calls.Load(); count != 0 {
+		t.Errorf("StartPeriodicAnalysis called fn %d times on an empty buffer, expected 0", count)
+	}
+}
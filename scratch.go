@@ -0,0 +1,53 @@
+package dynamics
+
+import "sync"
+
+// Pools of scratch slices for analyses called at a high rate, so that
+// temporary storage is reused rather than allocated on every call. Nothing
+// taken from a pool may be referenced by a value returned to the caller.
+var (
+	sampleScratch   scratchPool[SingleChannelSample]
+	columnScratch   scratchPool[column]
+	sample32Scratch scratchPool[Sample32]
+	floatScratch    scratchPool[float64]
+	complexScratch  scratchPool[complex128]
+	boolScratch     scratchPool[bool]
+)
+
+// scratchPool is a sync.Pool of slices of T. Slices are held by pointer so
+// that putting one back does not allocate. It is safe for concurrent use.
+type scratchPool[T any] struct {
+	pool sync.Pool
+}
+
+// get returns a slice of n zero values, reusing a pooled slice when one is
+// large enough.
+func (p *scratchPool[T]) get(n int) *[]T {
+	if s, ok := p.pool.Get().(*[]T); ok && cap(*s) >= n {
+		*s = (*s)[:n]
+		clear(*s)
+		return s
+	}
+	s := make([]T, n)
+	return &s
+}
+
+// put hands a slice from get back to the pool. The caller must not use it
+// afterwards. A nil s is ignored.
+func (p *scratchPool[T]) put(s *[]T) {
+	if s == nil {
+		return
+	}
+	*s = (*s)[:0]
+	p.pool.Put(s)
+}
+
+// scratchValues returns the sample values, as sampleValues does, in a slice
+// from floatScratch. The caller must hand it back with floatScratch.put.
+func scratchValues(data []SingleChannelSample) *[]float64 {
+	values := floatScratch.get(len(data))
+	for i, sample := range data {
+		(*values)[i] = sample.Value
+	}
+	return values
+}
@@ -0,0 +1,201 @@
+package dynamics
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestScratchPoolGet(t *testing.T) {
+	var pool scratchPool[float64]
+
+	// Run the test: a reused slice comes back zeroed at the requested length
+	s := pool.get(8)
+	for i := range *s {
+		(*s)[i] = float64(i + 1)
+	}
+	pool.put(s)
+
+	s = pool.get(4)
+	if len(*s) != 4 {
+		t.Fatalf("get(4) returned %d values", len(*s))
+	}
+	for i, v := range *s {
+		if v != 0 {
+			t.Errorf("value %d is %v, expected 0", i, v)
+		}
+	}
+	pool.put(s)
+	pool.put(nil)
+}
+
+func TestAnalyzeSkipConcurrent(t *testing.T) {
+	// Generate sample data: signals of different amplitudes with gaps
+	signals := make([][]SingleChannelSample, 8)
+	expected := make([]AnalysisResult, len(signals))
+	for i := range signals {
+		signals[i] = GenerateSineWave(50, float64(i+1), 0.2, 1000)
+		for j := 7; j < len(signals[i]); j += 13 {
+			signals[i][j].Value = math.NaN()
+		}
+		expected[i], _ = AnalyzeDetailed(signals[i], WithNonFinite(NonFiniteSkip))
+	}
+
+	// Run the test: results must not be disturbed by other calls sharing the pool
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range 200 {
+				i := (g + n) % len(signals)
+				result, err := AnalyzeDetailed(signals[i], WithNonFinite(NonFiniteSkip))
+				if err != nil || result != expected[i] {
+					t.Errorf("signal %d: got %+v, %v, expected %+v", i, result, err, expected[i])
+					return
+				}
+				if rms, _ := Analyze(signals[i], WithNonFinite(NonFiniteSkip)); rms != expected[i].RMS {
+					t.Errorf("signal %d: Analyze RMS = %v, expected %v", i, rms, expected[i].RMS)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, signal := range signals {
+		if !math.IsNaN(signal[7].Value) {
+			t.Errorf("signal %d was modified", i)
+		}
+	}
+}
+
+func TestAnalyzeMultiChannelResultsNotPooled(t *testing.T) {
+	// Generate sample data
+	wave := GenerateSineWave(50, 1, 0.2, 1000)
+	data := make([]MultiChannelSample, len(wave))
+	for i, sample := range wave {
+		data[i] = MultiChannelSample{Time: sample.Time, Value: []float64{sample.Value, 2 * sample.Value}}
+	}
+
+	// Run the test: a later call must not overwrite an earlier result
+	rms, zcr := AnalyzeMultiChannel(data)
+	first := append([]float64(nil), rms...)
+	firstZCR := append([]float64(nil), zcr...)
+	for i := range data {
+		data[i].Value = []float64{0, 0}
+	}
+	AnalyzeMultiChannel(data)
+	for c := range rms {
+		if rms[c] != first[c] || zcr[c] != firstZCR[c] {
+			t.Errorf("channel %d result changed to %v, %v after a later call", c, rms[c], zcr[c])
+		}
+	}
+}
+
+func BenchmarkAnalyzeSkip(b *testing.B) {
+	// Generate sample data with a gap every hundred samples
+	data := GenerateSineWave(440, 1, 1, 1000)
+	for i := 50; i < len(data); i += 100 {
+		data[i].Value = math.NaN()
+	}
+
+	// Run the benchmark
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Analyze(data, WithNonFinite(NonFiniteSkip))
+	}
+}
+
+func BenchmarkAnalyzeMultiChannelSmall(b *testing.B) {
+	// Generate sample data: 4 channels of 1000 samples
+	wave := GenerateSineWave(440, 1, 1, 1000)
+	data := make([]MultiChannelSample, len(wave))
+	for i, sample := range wave {
+		data[i] = MultiChannelSample{Time: sample.Time, Value: []float64{sample.Value, sample.Value, sample.Value, sample.Value}}
+	}
+
+	// Run the benchmark
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		AnalyzeMultiChannel(data)
+	}
+}
+
+func TestSpectralScratchConcurrent(t *testing.T) {
+	// Generate sample data: tones of different frequencies, one of a length
+	// that takes the Bluestein path
+	signals := make([][]SingleChannelSample, 4)
+	for i := range signals {
+		signals[i] = GenerateSineWave(float64(50*(i+1)), 1, 0.1, 999+i)
+	}
+	type outcome struct {
+		spectrum []SpectrumBin
+		dominant float64
+		acf      []float64
+		corr     []float64
+		rolling  []AnalysisResult
+		sliding  []SingleChannelSample
+	}
+	run := func(data []SingleChannelSample) outcome {
+		var o outcome
+		o.spectrum, _ = Spectrum(data)
+		o.dominant, _ = DominantFrequency(data)
+		o.acf = Autocorrelation(data, 50)
+		_, o.corr, _ = CrossCorrelate(data, data, 0.01)
+		o.rolling, _ = RollingAnalyze(data, 0.02, 0.01)
+		o.sliding = SlidingRMS(data, 0.02, 0.01)
+		return o
+	}
+	expected := make([]outcome, len(signals))
+	for i, signal := range signals {
+		expected[i] = run(signal)
+	}
+
+	// Run the test: results must not be disturbed by other calls sharing the pools
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range 20 {
+				i := (g + n) % len(signals)
+				if got := run(signals[i]); !reflect.DeepEqual(got, expected[i]) {
+					t.Errorf("signal %d: results differ from a lone call", i)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkSpectralScratch(b *testing.B) {
+	// Generate sample data: 1 s at 10 kHz, a power-of-two-free length
+	data := GenerateSineWave(440, 1, 1, 10000)
+
+	// Run the benchmark
+	benchmarks := []struct {
+		name string
+		fn   func()
+	}{
+		{"Spectrum", func() { Spectrum(data) }},
+		{"DominantFrequency", func() { DominantFrequency(data) }},
+		{"Autocorrelation", func() { Autocorrelation(data, 100) }},
+		{"EstimateFrequencyACF", func() { EstimateFrequencyACF(data) }},
+		{"CrossCorrelate", func() { CrossCorrelate(data, data, 0.01) }},
+		{"RollingAnalyze", func() { RollingAnalyze(data, 0.1, 0.01) }},
+		{"SlidingRMS", func() { SlidingRMS(data, 0.1, 0.01) }},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.fn()
+			}
+		})
+	}
+}
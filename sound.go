@@ -0,0 +1,166 @@
+package dynamics
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// ReferencePressure is the reference sound pressure of 0 dB SPL, in pascals.
+const ReferencePressure = 20e-6
+
+// Weighting is a frequency weighting of IEC 61672-1 applied to sound pressure.
+type Weighting int
+
+const (
+	WeightingZ Weighting = iota // no weighting
+	WeightingA                  // follows the ear's sensitivity at moderate levels
+	WeightingC                  // flat but for the extremes of the audible band
+)
+
+// Pole frequencies of the A and C weightings, in Hz, from IEC 61672-1.
+const (
+	weightingPole1 = 20.598997
+	weightingPole2 = 107.65265
+	weightingPole3 = 737.86223
+	weightingPole4 = 12194.217
+)
+
+// weightingFilter returns the sections of the weighting for samples step
+// seconds apart, normalised to unit gain at 1 kHz as the standard is. Each
+// pole of the analog weighting becomes a first-order section by the bilinear
+// transform. The transform compresses the frequency axis towards the Nyquist
+// frequency, so the weighting falls away from the standard at the top of the
+// band: at a sample rate of 48 kHz it is within 0.1 dB up to 2 kHz and well
+// within the class 1 tolerances of IEC 61672-1 to 10 kHz.
+func weightingFilter(weighting Weighting, step float64) []biquad {
+	var sections []biquad
+	switch weighting {
+	case WeightingA:
+		sections = []biquad{
+			highPassPole(weightingPole1 * step), highPassPole(weightingPole1 * step),
+			highPassPole(weightingPole2 * step), highPassPole(weightingPole3 * step),
+			lowPassPole(weightingPole4 * step), lowPassPole(weightingPole4 * step),
+		}
+	case WeightingC:
+		sections = []biquad{
+			highPassPole(weightingPole1 * step), highPassPole(weightingPole1 * step),
+			lowPassPole(weightingPole4 * step), lowPassPole(weightingPole4 * step),
+		}
+	default:
+		return nil
+	}
+
+	gain := complex(1, 0)
+	for _, section := range sections {
+		gain *= section.response(1000 * step)
+	}
+	scale := 1 / cmplx.Abs(gain)
+	sections[0].b0 *= scale
+	sections[0].b1 *= scale
+	sections[0].b2 *= scale
+	return sections
+}
+
+// highPassPole returns the first-order section s/(s+ω) of a pole at the given
+// frequency, as a fraction of the sample rate.
+func highPassPole(frequency float64) biquad {
+	w := 2 * math.Pi * frequency
+	return newBiquad(2, -2, 0, 2+w, w-2, 0)
+}
+
+// lowPassPole returns the first-order section ω/(s+ω) of a pole at the given
+// frequency, as a fraction of the sample rate.
+func lowPassPole(frequency float64) biquad {
+	w := 2 * math.Pi * frequency
+	return newBiquad(w, w, 0, 2+w, w-2, 0)
+}
+
+// SoundLevel returns the weighted sound pressure level of a record of
+// calibrated microphone samples, in decibels re 20 µPa: the level of the
+// weighted RMS pressure over the whole record, which is also its equivalent
+// continuous level, Leq. The samples are taken to be evenly spaced.
+//
+// Parameters:
+//   - data: A slice of Sample structs holding sound pressure in pascals
+//   - weighting: The frequency weighting, such as WeightingA for dB(A)
+//
+// Returns:
+//   - float64: The level in dB, -Inf for silence, or NaN when the data spans
+//     no time or the weighting is unknown
+func SoundLevel(data []SingleChannelSample, weighting Weighting) float64 {
+	pressure, ok := weightedPressure(data, weighting)
+	if !ok {
+		return math.NaN()
+	}
+	var sumSq float64
+	for _, p := range pressure {
+		sumSq += p * p
+	}
+	return pressureLevel(sumSq / float64(len(pressure)))
+}
+
+// LeqSeries returns the equivalent continuous sound level of each interval
+// of a record, such as each second, in decibels re 20 µPa. The record is
+// weighted as a whole, so the filter carries from one interval to the next.
+//
+// Parameters:
+//   - data: A slice of Sample structs holding sound pressure in pascals
+//   - weighting: The frequency weighting, such as WeightingA for dB(A)
+//   - interval: The length of each interval in seconds
+//
+// Returns:
+//   - []SingleChannelSample: The Leq of each interval holding samples, timed
+//     at the interval's end; none when interval is not positive and finite,
+//     the data spans no time or the weighting is unknown
+func LeqSeries(data []SingleChannelSample, weighting Weighting, interval float64) []SingleChannelSample {
+	if !(interval > 0) || math.IsInf(interval, 1) {
+		return nil
+	}
+	pressure, ok := weightedPressure(data, weighting)
+	if !ok {
+		return nil
+	}
+
+	var series []SingleChannelSample
+	current := -1
+	var sumSq float64
+	var n int
+	flush := func() {
+		if n > 0 {
+			end := data[0].Time + float64(current+1)*interval
+			series = append(series, SingleChannelSample{Time: end, Value: pressureLevel(sumSq / float64(n))})
+		}
+	}
+	for i, p := range pressure {
+		k := int((data[i].Time - data[0].Time) / interval)
+		if k != current {
+			flush()
+			current, sumSq, n = k, 0, 0
+		}
+		sumSq += p * p
+		n++
+	}
+	flush()
+	return series
+}
+
+// weightedPressure returns the sample values passed through the weighting.
+func weightedPressure(data []SingleChannelSample, weighting Weighting) ([]float64, bool) {
+	if len(data) < 2 || weighting < WeightingZ || weighting > WeightingC {
+		return nil, false
+	}
+	step := (data[len(data)-1].Time - data[0].Time) / float64(len(data)-1)
+	if !(step > 0) {
+		return nil, false
+	}
+	pressure := sampleValues(data)
+	for _, section := range weightingFilter(weighting, step) {
+		section.filter(pressure)
+	}
+	return pressure, true
+}
+
+// pressureLevel converts a mean square pressure to decibels re 20 µPa.
+func pressureLevel(meanSquare float64) float64 {
+	return 10 * math.Log10(meanSquare/(ReferencePressure*ReferencePressure))
+}
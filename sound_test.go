@@ -0,0 +1,83 @@
+package dynamics
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestSoundLevel(t *testing.T) {
+	// Generate sample data: 1 s at 48 kHz of a 1 kHz tone of 1 Pa RMS
+	data := GenerateSineWave(1000, math.Sqrt2, 1, 48000)
+
+	// Run the test: 20·log10(1/20 µPa) = 93.98 dB, unweighted at 1 kHz
+	for _, weighting := range []Weighting{WeightingZ, WeightingA, WeightingC} {
+		if level := SoundLevel(data, weighting); math.Abs(level-93.98) > 0.3 {
+			t.Errorf("weighting %d: level %v dB, expected 93.98", weighting, level)
+		}
+	}
+	if level := SoundLevel(data, Weighting(7)); !math.IsNaN(level) {
+		t.Errorf("unknown weighting: got %v, expected NaN", level)
+	}
+}
+
+func TestWeightingResponse(t *testing.T) {
+	// Run the test: the IEC 61672-1 design values at 48 kHz, closely in the
+	// low and middle of the band and within class 1 tolerances at the top
+	tests := []struct {
+		weighting Weighting
+		frequency float64
+		expected  float64 // dB
+		tolerance float64
+	}{
+		{WeightingA, 31.5, -39.5, 0.1},
+		{WeightingA, 100, -19.1, 0.1},
+		{WeightingA, 500, -3.2, 0.1},
+		{WeightingA, 2000, 1.2, 0.1},
+		{WeightingA, 8000, -1.1, 1.5},
+		{WeightingA, 10000, -2.5, 2.0},
+		{WeightingC, 31.5, -3.0, 0.1},
+		{WeightingC, 100, -0.3, 0.1},
+		{WeightingC, 8000, -3.0, 1.5},
+	}
+	for _, tt := range tests {
+		gain := complex(1, 0)
+		for _, section := range weightingFilter(tt.weighting, 1.0/48000) {
+			gain *= section.response(tt.frequency / 48000)
+		}
+		if got := 20 * math.Log10(cmplx.Abs(gain)); math.Abs(got-tt.expected) > tt.tolerance {
+			t.Errorf("weighting %d at %v Hz: %v dB, expected %v", tt.weighting, tt.frequency, got, tt.expected)
+		}
+	}
+}
+
+func TestLeqSeries(t *testing.T) {
+	// Generate sample data: 3 s of a 1 kHz tone at 1 Pa RMS for the first
+	// second and 0.1 Pa RMS after
+	data := GenerateSineWave(1000, math.Sqrt2, 3, 48000)
+	for i := range data {
+		if data[i].Time >= 1 {
+			data[i].Value /= 10
+		}
+	}
+
+	// Run the test
+	series := LeqSeries(data, WeightingA, 1)
+	if len(series) != 3 {
+		t.Fatalf("got %d intervals, expected 3", len(series))
+	}
+	for k, expected := range []float64{93.98, 73.98, 73.98} {
+		if math.Abs(series[k].Time-float64(k+1)) > 1e-9 || math.Abs(series[k].Value-expected) > 0.3 {
+			t.Errorf("interval %d: %v dB at %v s, expected %v dB at %d s", k, series[k].Value, series[k].Time, expected, k+1)
+		}
+	}
+
+	// the Leq of the record is the energy mean of the intervals
+	expected := 10 * math.Log10((math.Pow(10, 9.398)+2*math.Pow(10, 7.398))/3)
+	if level := SoundLevel(data, WeightingA); math.Abs(level-expected) > 0.3 {
+		t.Errorf("record level %v dB, expected %v", level, expected)
+	}
+	if LeqSeries(data, WeightingA, 0) != nil {
+		t.Error("zero interval: expected nil")
+	}
+}
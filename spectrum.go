@@ -0,0 +1,146 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// spacingTolerance is the fraction of the mean sample interval by which an
+// interval may differ from it before Spectrum rejects the samples as unevenly
+// spaced, enough to pass the rounding and jitter of recorded timestamps.
+const spacingTolerance = 0.01
+
+// SpectrumOption configures a call to Spectrum.
+type SpectrumOption func(*spectrumConfig)
+
+// spectrumConfig holds the settings made by SpectrumOptions.
+type spectrumConfig struct {
+	hann bool
+}
+
+// WithHannWindow tapers the samples with a Hann window before the transform,
+// so that a tone between lines leaks into a few neighbouring lines rather than
+// across the whole spectrum. The magnitudes are divided by the window's mean,
+// its coherent gain of one half, so that a tone centred on a line keeps its
+// amplitude.
+func WithHannWindow() SpectrumOption {
+	return func(c *spectrumConfig) {
+		c.hann = true
+	}
+}
+
+// Spectrum returns the one-sided amplitude spectrum of the data, lines 0 to
+// N/2 for N samples, each giving the peak amplitude of a sinusoid at its
+// frequency. The lines are 1/(N·step) apart, the step being the mean interval
+// between the timestamps, so the frequency axis follows the times in the data
+// rather than a nominal rate. The whole record is transformed, without padding,
+// by a radix-2 FFT when N is a power of two and by Bluestein's algorithm
+// otherwise.
+//
+// The samples must be evenly spaced: every interval must lie within 1% of the
+// mean interval.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - opts: Options such as WithHannWindow
+//
+// Returns:
+//   - []SpectrumBin: The spectrum, lowest frequency first
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span
+//     no time, ErrUnsortedData if the data is not in time order, or an error
+//     wrapping ErrUnevenSpacing naming the first interval out of tolerance
+func Spectrum(data []SingleChannelSample, opts ...SpectrumOption) ([]SpectrumBin, error) {
+	var config spectrumConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	step, err := evenStep(data)
+	if err != nil {
+		return nil, err
+	}
+
+	scratch := scratchValues(data)
+	defer floatScratch.put(scratch)
+	values := *scratch
+	gain := 1.0
+	if config.hann {
+		// the periodic form, whose mean is exactly one half
+		n := float64(len(values))
+		for i := range values {
+			values[i] *= 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/n)
+		}
+		gain = 0.5
+	}
+	amplitudesScratch := floatScratch.get(len(values)/2 + 1)
+	defer floatScratch.put(amplitudesScratch)
+	amplitudes := amplitudeSpectrumInto(*amplitudesScratch, values)
+
+	resolution := 1 / (step * float64(len(data)))
+	bins := make([]SpectrumBin, len(amplitudes))
+	for k, amplitude := range amplitudes {
+		bins[k] = SpectrumBin{Frequency: float64(k) * resolution, Magnitude: amplitude / gain}
+	}
+	return bins, nil
+}
+
+// DominantFrequency returns the frequency of the largest peak of the
+// spectrum, which unlike the zero crossing rate is not misled by noise or
+// weaker tones. The mean is removed and a Hann window applied, the spectrum
+// is interpolated by padding the record with zeros to eight times its length,
+// and a parabola through the highest line and its neighbours places the peak
+// between lines, well within the resolution of the record.
+//
+// The samples must be evenly spaced, as for Spectrum.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The dominant frequency in Hz, or 0 on error
+//   - error: As for Spectrum, or an error if the values are constant and so have
+//     no peak but DC
+func DominantFrequency(data []SingleChannelSample) (float64, error) {
+	step, err := evenStep(data)
+	if err != nil {
+		return 0, err
+	}
+	frequency := 0.0
+	if Variance(data) > 0 {
+		values := scratchValues(data)
+		frequency = peakFrequency(*values, step, 0)
+		floatScratch.put(values)
+	}
+	if frequency == 0 {
+		return 0, errors.New("dynamics: signal has no spectral peak above DC")
+	}
+	return frequency, nil
+}
+
+// evenStep returns the mean interval between the samples after checking that
+// every interval lies within spacingTolerance of it.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The mean interval between samples in seconds
+//   - error: ErrEmptyData if data is empty, ErrZeroDuration if the samples span
+//     no time, ErrUnsortedData if the data is not in time order, or an error
+//     wrapping ErrUnevenSpacing naming the first interval out of tolerance
+func evenStep(data []SingleChannelSample) (float64, error) {
+	duration, err := recordDuration(data)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTimeOrder(data); err != nil {
+		return 0, err
+	}
+	step := duration / float64(len(data)-1)
+	for i := 1; i < len(data); i++ {
+		if interval := data[i].Time - data[i-1].Time; math.Abs(interval-step) > spacingTolerance*step {
+			return 0, fmt.Errorf("%w: samples %d and %d are %g s apart, the mean interval is %g s", ErrUnevenSpacing, i-1, i, interval, step)
+		}
+	}
+	return step, nil
+}
@@ -0,0 +1,175 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// spectrumPeak returns the line of the spectrum with the largest magnitude.
+func spectrumPeak(spectrum []SpectrumBin) SpectrumBin {
+	var peak SpectrumBin
+	for _, bin := range spectrum {
+		if bin.Magnitude > peak.Magnitude {
+			peak = bin
+		}
+	}
+	return peak
+}
+
+func TestSpectrum(t *testing.T) {
+	// Generate sample data: 0.1 s of a 440 Hz sine of amplitude 2 at 48 kHz,
+	// whose lines are 10 Hz apart
+	data := GenerateSineWave(440, 2, 0.1, 48000)
+
+	for _, tt := range []struct {
+		name string
+		opts []SpectrumOption
+	}{
+		{"rectangular", nil},
+		{"hann", []SpectrumOption{WithHannWindow()}},
+	} {
+		// Run the test
+		spectrum, err := Spectrum(data, tt.opts...)
+		if err != nil {
+			t.Fatalf("%s: Spectrum returned error: %v", tt.name, err)
+		}
+		if len(spectrum) != 2401 {
+			t.Fatalf("%s: Spectrum returned %d lines, expected 2401", tt.name, len(spectrum))
+		}
+		if last := spectrum[len(spectrum)-1].Frequency; math.Abs(last-24000) > 1e-6 {
+			t.Errorf("%s: last line at %v Hz, expected the Nyquist frequency, 24000 Hz", tt.name, last)
+		}
+		peak := spectrumPeak(spectrum)
+		if math.Abs(peak.Frequency-440) > 10 {
+			t.Errorf("%s: peak at %v Hz, expected within a line of 440 Hz", tt.name, peak.Frequency)
+		}
+		if math.Abs(peak.Magnitude-2) > 1e-3 {
+			t.Errorf("%s: peak magnitude %v, expected the amplitude, 2", tt.name, peak.Magnitude)
+		}
+	}
+}
+
+func TestSpectrumLeakage(t *testing.T) {
+	// Generate sample data: 445 Hz falls midway between lines, 1 kHz away
+	// from which a rectangular window still leaks, but a Hann window not
+	data := GenerateSineWave(445, 1, 0.1, 48000)
+
+	// Run the test
+	rect, err := Spectrum(data)
+	if err != nil {
+		t.Fatalf("Spectrum returned error: %v", err)
+	}
+	hann, err := Spectrum(data, WithHannWindow())
+	if err != nil {
+		t.Fatalf("Spectrum with WithHannWindow returned error: %v", err)
+	}
+	if far := rect[144].Magnitude; far < 1e-3 {
+		t.Errorf("rectangular window leaked %v to 1440 Hz, expected at least 1e-3", far)
+	}
+	if far := hann[144].Magnitude; far > 1e-5 {
+		t.Errorf("Hann window leaked %v to 1440 Hz, expected under 1e-5", far)
+	}
+	// between lines a Hann window loses at most 1.4 dB of the amplitude
+	if peak := spectrumPeak(hann); math.Abs(peak.Frequency-445) > 10 || peak.Magnitude < 0.84 {
+		t.Errorf("Hann peak %v at %v Hz, expected at least 0.84 within a line of 445 Hz", peak.Magnitude, peak.Frequency)
+	}
+}
+
+func TestSpectrumTimebase(t *testing.T) {
+	// Generate sample data: 1000 samples a millisecond apart starting at
+	// 100 s, of a 50 Hz sine with a DC level of 0.5
+	data := make([]SingleChannelSample, 1000)
+	for i := range data {
+		tm := float64(i) / 1000
+		data[i] = SingleChannelSample{Time: 100 + tm, Value: 0.5 + math.Sin(2*math.Pi*50*tm)}
+	}
+
+	// Run the test: the lines follow the timestamps, 1 Hz apart
+	spectrum, err := Spectrum(data)
+	if err != nil {
+		t.Fatalf("Spectrum returned error: %v", err)
+	}
+	if len(spectrum) != 501 || math.Abs(spectrum[1].Frequency-1) > 1e-6 {
+		t.Fatalf("Spectrum returned %d lines with the first at %v Hz, expected 501 lines 1 Hz apart", len(spectrum), spectrum[1].Frequency)
+	}
+	if dc := spectrum[0].Magnitude; math.Abs(dc-0.5) > 1e-9 {
+		t.Errorf("DC line %v, expected 0.5", dc)
+	}
+	if line := spectrum[50]; math.Abs(line.Frequency-50) > 1e-6 || math.Abs(line.Magnitude-1) > 1e-9 {
+		t.Errorf("line 50 is %v at %v Hz, expected 1 at 50 Hz", line.Magnitude, line.Frequency)
+	}
+}
+
+func TestSpectrumInvalid(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 0.1, 1000)
+	jittered := append([]SingleChannelSample(nil), data...)
+	jittered[40].Time += 0.0002
+	unsorted := append([]SingleChannelSample(nil), data...)
+	unsorted[40], unsorted[41] = unsorted[41], unsorted[40]
+	wobble := append([]SingleChannelSample(nil), data...)
+	wobble[40].Time += 0.000005
+
+	// Run the test
+	for _, tt := range []struct {
+		name string
+		data []SingleChannelSample
+		err  error
+	}{
+		{"empty", nil, ErrEmptyData},
+		{"single", data[:1], ErrZeroDuration},
+		{"unsorted", unsorted, ErrUnsortedData},
+		{"uneven", jittered, ErrUnevenSpacing},
+	} {
+		if _, err := Spectrum(tt.data); !errors.Is(err, tt.err) {
+			t.Errorf("%s: Spectrum returned %v, expected %v", tt.name, err, tt.err)
+		}
+	}
+	if _, err := Spectrum(wobble); err != nil {
+		t.Errorf("Spectrum with 0.5%% timestamp jitter returned %v, expected no error", err)
+	}
+}
+
+func TestDominantFrequency(t *testing.T) {
+	// Generate sample data: 441.7 Hz for 0.5 s at 8 kHz, between lines 2 Hz
+	// apart, with a weaker tone at 1200 Hz and a DC level
+	data := GenerateSineWave(441.7, 1, 0.5, 8000, WithDCOffset(3))
+	for i, sample := range GenerateSineWave(1200, 0.4, 0.5, 8000) {
+		data[i].Value += sample.Value
+	}
+
+	// Run the test: picking the highest line alone is up to 1 Hz out
+	frequency, err := DominantFrequency(data)
+	if err != nil {
+		t.Fatalf("DominantFrequency returned error: %v", err)
+	}
+	if math.Abs(frequency-441.7) > 0.5 {
+		t.Errorf("DominantFrequency returned %v Hz, expected 441.7 Hz", frequency)
+	}
+	spectrum, _ := Spectrum(data, WithHannWindow())
+	if line := spectrumPeak(spectrum[1:]).Frequency; math.Abs(line-441.7) < 0.5 {
+		t.Errorf("highest line at %v Hz, expected more than 0.5 Hz from 441.7 Hz", line)
+	}
+}
+
+func TestDominantFrequencyInvalid(t *testing.T) {
+	// Generate sample data
+	dc := make([]SingleChannelSample, 100)
+	for i := range dc {
+		dc[i] = SingleChannelSample{Time: float64(i) / 1000, Value: 2.5}
+	}
+	uneven := GenerateSineWave(50, 1, 0.1, 1000)
+	uneven[40].Time += 0.0002
+
+	// Run the test
+	if _, err := DominantFrequency(nil); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("DominantFrequency of no data returned %v, expected ErrEmptyData", err)
+	}
+	if _, err := DominantFrequency(uneven); !errors.Is(err, ErrUnevenSpacing) {
+		t.Errorf("DominantFrequency of unevenly spaced data returned %v, expected ErrUnevenSpacing", err)
+	}
+	if frequency, err := DominantFrequency(dc); err == nil {
+		t.Errorf("DominantFrequency of a DC signal returned %v Hz, expected an error", frequency)
+	}
+}
@@ -0,0 +1,75 @@
+package dynamics
+
+import "math"
+
+// SRS computes the shock response spectrum of an acceleration record: the
+// largest absolute acceleration response, positive or negative, of single
+// degree of freedom oscillators of quality factor q at natural frequencies
+// spaced evenly on a log scale. Each oscillator is the ramp-invariant digital
+// filter of Smallwood, as ISO 18431-4 specifies, and is run on for one period
+// of the lowest frequency after the record ends, so the residual response is
+// included. The samples are taken to be evenly spaced; the filters are
+// accurate for natural frequencies up to about a tenth of the sample rate.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing the acceleration
+//   - fMin: The lowest natural frequency in Hz
+//   - fMax: The highest natural frequency in Hz, below the Nyquist frequency
+//   - pointsPerOctave: The number of natural frequencies per doubling, such as 12
+//   - q: The quality factor of the oscillators, typically 10
+//
+// Returns:
+//   - freqs: The natural frequencies in Hz, from fMin up to fMax
+//   - peaks: The maximax response at each frequency, in the units of the
+//     data; both are nil when an argument is out of range or the data spans
+//     no time
+func SRS(data []SingleChannelSample, fMin, fMax float64, pointsPerOctave int, q float64) (freqs, peaks []float64) {
+	if len(data) < 2 || !(fMin > 0) || !(fMax >= fMin) || pointsPerOctave <= 0 || !(q > 0.5) || math.IsInf(q, 1) {
+		return nil, nil
+	}
+	step := (data[len(data)-1].Time - data[0].Time) / float64(len(data)-1)
+	if !(step > 0) || fMax*step >= 0.5 {
+		return nil, nil
+	}
+
+	values := sampleValues(data)
+	tail := int(math.Ceil(1 / (fMin * step)))
+	zeta := 1 / (2 * q)
+	for k := 0; ; k++ {
+		fn := fMin * math.Pow(2, float64(k)/float64(pointsPerOctave))
+		if fn > fMax*(1+1e-9) {
+			break
+		}
+		freqs = append(freqs, fn)
+		peaks = append(peaks, smallwoodPeak(values, tail, fn*step, zeta))
+	}
+	return freqs, peaks
+}
+
+// smallwoodPeak runs the ramp-invariant filter for absolute acceleration of
+// an oscillator of natural frequency fn, as a fraction of the sample rate, and
+// damping ratio zeta over the values and tail zeros after them, returning the
+// largest magnitude of the response.
+func smallwoodPeak(values []float64, tail int, fn, zeta float64) float64 {
+	wn := 2 * math.Pi * fn
+	wd := wn * math.Sqrt(1-zeta*zeta)
+	e := math.Exp(-zeta * wn)
+	c := e * math.Cos(wd)
+	s := e * math.Sin(wd) / wd
+
+	b0, b1, b2 := 1-s, 2*(s-c), e*e-s
+	a1, a2 := 2*c, e*e
+
+	var x1, x2, y1, y2, peak float64
+	for i := range len(values) + tail {
+		var x float64
+		if i < len(values) {
+			x = values[i]
+		}
+		y := b0*x + b1*x1 + b2*x2 + a1*y1 - a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		peak = math.Max(peak, math.Abs(y))
+	}
+	return peak
+}
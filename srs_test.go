@@ -0,0 +1,100 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// halfSine returns a half-sine shock of the given peak and duration in
+// seconds, sampled at rate after 10 ms at rest, followed by 10 ms at rest.
+func halfSine(peak, duration float64, rate int) []SingleChannelSample {
+	n := int(math.Round((duration + 0.02) * float64(rate)))
+	data := make([]SingleChannelSample, n+1)
+	for i := range data {
+		tm := float64(i) / float64(rate)
+		var v float64
+		if tm > 0.01 && tm < 0.01+duration {
+			v = peak * math.Sin(math.Pi*(tm-0.01)/duration)
+		}
+		data[i] = SingleChannelSample{Time: tm, Value: v}
+	}
+	return data
+}
+
+// referenceSRS integrates the oscillator for a half-sine base acceleration
+// with fourth-order Runge-Kutta at a fine step, through the shock and for a
+// period after it, returning the largest absolute acceleration.
+func referenceSRS(peak, duration, fn, q float64) float64 {
+	wn := 2 * math.Pi * fn
+	zeta := 1 / (2 * q)
+	base := func(t float64) float64 {
+		if t < duration {
+			return peak * math.Sin(math.Pi*t/duration)
+		}
+		return 0
+	}
+	// z is the displacement relative to the base; the absolute acceleration is −2ζωn·ż − ωn²·z
+	deriv := func(t, z, v float64) (float64, float64) {
+		return v, -base(t) - 2*zeta*wn*v - wn*wn*z
+	}
+	h := math.Min(duration, 1/fn) / 20000
+	var z, v, result float64
+	for t := 0.0; t < duration+1/fn; t += h {
+		k1z, k1v := deriv(t, z, v)
+		k2z, k2v := deriv(t+h/2, z+h/2*k1z, v+h/2*k1v)
+		k3z, k3v := deriv(t+h/2, z+h/2*k2z, v+h/2*k2v)
+		k4z, k4v := deriv(t+h, z+h*k3z, v+h*k3v)
+		z += h / 6 * (k1z + 2*k2z + 2*k3z + k4z)
+		v += h / 6 * (k1v + 2*k2v + 2*k3v + k4v)
+		result = math.Max(result, math.Abs(2*zeta*wn*v+wn*wn*z))
+	}
+	return result
+}
+
+func TestSRS(t *testing.T) {
+	// Generate sample data: a 100 g, 11 ms half-sine sampled at 100 kHz
+	data := halfSine(100, 0.011, 100000)
+
+	// Run the test against a direct integration of each oscillator
+	freqs, peaks := SRS(data, 10, 5000, 3, 10)
+	if len(freqs) != 27 || len(peaks) != 27 {
+		t.Fatalf("got %d frequencies and %d peaks, expected 27", len(freqs), len(peaks))
+	}
+	for i, fn := range freqs {
+		if expected := referenceSRS(100, 0.011, fn, 10); math.Abs(peaks[i]-expected) > 0.03*expected {
+			t.Errorf("%v Hz: peak %v, expected %v", fn, peaks[i], expected)
+		}
+	}
+
+	// the familiar shape: well above 1/duration the response follows the
+	// input, and near it the shock is amplified about 1.7 times
+	if last := peaks[len(peaks)-1]; math.Abs(last-100) > 5 {
+		t.Errorf("high-frequency peak %v, expected about 100", last)
+	}
+	var most float64
+	for _, peak := range peaks {
+		most = math.Max(most, peak)
+	}
+	if most < 160 || most > 180 {
+		t.Errorf("largest peak %v, expected about 170", most)
+	}
+}
+
+func TestSRSInvalid(t *testing.T) {
+	data := halfSine(1, 0.011, 10000)
+	for _, tt := range []struct {
+		fMin, fMax float64
+		ppo        int
+		q          float64
+	}{
+		{0, 100, 3, 10},
+		{100, 50, 3, 10},
+		{10, 100, 0, 10},
+		{10, 100, 3, 0},
+		{10, 6000, 3, 10},
+	} {
+		if freqs, peaks := SRS(data, tt.fMin, tt.fMax, tt.ppo, tt.q); freqs != nil || peaks != nil {
+			t.Errorf("%+v: expected nil", tt)
+		}
+	}
+}
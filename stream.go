@@ -0,0 +1,248 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StreamAnalyzer analyses a live stream of samples over a sliding window of
+// sample time, producing an AnalysisResult every hop seconds once the window
+// has filled. RMS and NZCR are maintained incrementally as samples arrive and
+// leave the window, so the cost of a Push does not grow with the window length.
+//
+// By default each Push analyses its sample before returning. With WithQueue,
+// Push hands the sample to a background goroutine through a bounded queue and
+// the OverflowPolicy decides what happens when the analysis falls behind.
+//
+// Close ends the stream: samples still queued are analysed and the samples
+// that had not yet produced a result are flushed as a final, partial result.
+//
+// A StreamAnalyzer is safe for concurrent use. Callbacks are invoked while the
+// analyzer's lock is held and must not call back into the analyzer.
+type StreamAnalyzer struct {
+	mu       sync.Mutex
+	window   *slidingWindow
+	schedule hopSchedule
+	pending  bool           // samples have been analysed since the last result
+	latest   AnalysisResult // the most recent result emitted
+	onResult func(AnalysisResult)
+	alarms   []*Alarm
+
+	pushMu sync.Mutex // serialises producers; guards last and closed
+	last   float64    // time of the most recent sample pushed
+	pushed bool
+	closed bool
+	queue  *sampleQueue  // nil when samples are analysed synchronously
+	exited chan struct{} // closed when the queue worker has returned
+
+	samplesIn      atomic.Uint64
+	samplesDropped atomic.Uint64
+	resultsEmitted atomic.Uint64
+	processed      atomic.Uint64 // samples taken off the queue and analysed
+}
+
+// StreamStats holds the counters of a StreamAnalyzer.
+type StreamStats struct {
+	SamplesIn      uint64 `json:"samplesIn"`      // samples accepted by Push
+	SamplesDropped uint64 `json:"samplesDropped"` // samples discarded by the overflow policy
+	ResultsEmitted uint64 `json:"resultsEmitted"` // results delivered to the callback and alarms
+}
+
+// AnalyzerSnapshot is a consistent view of a StreamAnalyzer's state.
+type AnalyzerSnapshot struct {
+	Taken  time.Time             `json:"taken"`  // wall-clock time the snapshot was taken
+	Window []SingleChannelSample `json:"window"` // copy of the samples in the current window
+	Last   AnalysisResult        `json:"last"`   // the most recent result, zero if none yet
+	Stats  StreamStats           `json:"stats"`  // counters at the time of the snapshot
+}
+
+// StreamOption configures a StreamAnalyzer.
+type StreamOption func(*StreamAnalyzer)
+
+// WithQueue makes the analyzer process samples on a background goroutine fed
+// by a queue of the given size, applying policy when the queue is full.
+func WithQueue(size int, policy OverflowPolicy) StreamOption {
+	return func(sa *StreamAnalyzer) {
+		sa.queue = newSampleQueue(size, policy)
+	}
+}
+
+// NewStreamAnalyzer creates a StreamAnalyzer.
+//
+// Parameters:
+//   - window: The length of the analysis window in seconds
+//   - hop: The sample time between successive results in seconds
+//   - fn: Callback receiving each result, may be nil when only alarms are used
+//   - opts: Options such as WithQueue
+//
+// Returns:
+//   - *StreamAnalyzer: The new analyzer
+//   - error: An error if window or hop is not a positive, finite number
+func NewStreamAnalyzer(window, hop float64, fn func(AnalysisResult), opts ...StreamOption) (*StreamAnalyzer, error) {
+	if !(window > 0) || math.IsInf(window, 1) {
+		return nil, errors.New("dynamics: stream window must be positive")
+	}
+	if !(hop > 0) || math.IsInf(hop, 1) {
+		return nil, errors.New("dynamics: stream hop must be positive")
+	}
+
+	sa := &StreamAnalyzer{
+		window:   newSlidingWindow(window, 1),
+		schedule: hopSchedule{window: window, hop: hop},
+		onResult: fn,
+	}
+	for _, opt := range opts {
+		opt(sa)
+	}
+	if sa.queue != nil {
+		sa.exited = make(chan struct{})
+		go sa.run()
+	}
+	return sa, nil
+}
+
+// AddAlarm attaches an alarm to the analyzer. The alarm is evaluated against
+// every result the analyzer produces from then on.
+func (sa *StreamAnalyzer) AddAlarm(alarm *Alarm) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	sa.alarms = append(sa.alarms, alarm)
+}
+
+// Push adds a sample to the stream. Samples must arrive in time order; a
+// sample older than its predecessor is rejected with an error, as is any
+// sample pushed after Close.
+func (sa *StreamAnalyzer) Push(sample SingleChannelSample) error {
+	sa.pushMu.Lock()
+	defer sa.pushMu.Unlock()
+
+	if sa.closed {
+		return ErrClosed
+	}
+	if sa.pushed && sample.Time < sa.last {
+		return errors.New("dynamics: sample time is earlier than the previous sample")
+	}
+	sa.pushed = true
+	sa.last = sample.Time
+	sa.samplesIn.Add(1)
+
+	if sa.queue == nil {
+		sa.process(sample)
+		return nil
+	}
+	dropped, _ := sa.queue.put(sample)
+	sa.samplesDropped.Add(uint64(dropped))
+	return nil
+}
+
+// Close ends the stream. Queued samples are analysed first, then if any
+// samples have arrived since the last result, a final result flagged as
+// Partial is emitted for the current window, which may be shorter than the
+// configured length. The background goroutine, if any, has exited when Close
+// returns. Closing an analyzer more than once is harmless.
+func (sa *StreamAnalyzer) Close() error {
+	sa.pushMu.Lock()
+	defer sa.pushMu.Unlock()
+
+	if sa.closed {
+		return nil
+	}
+	sa.closed = true
+
+	if sa.queue != nil {
+		close(sa.queue.stop)
+		<-sa.exited
+	}
+
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if sa.pending {
+		result := sa.window.result(0)
+		result.Partial = true
+		sa.emit(result)
+	}
+	return nil
+}
+
+// Snapshot returns the current window, the most recent result and the
+// counters, captured under the analyzer's lock so that the window and result
+// describe the same moment. It only copies; no analysis is performed, so the
+// producer is held up for no longer than the copy takes. With WithQueue,
+// SamplesIn also counts samples still waiting in the queue.
+func (sa *StreamAnalyzer) Snapshot() AnalyzerSnapshot {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	return AnalyzerSnapshot{
+		Taken:  time.Now(),
+		Window: sa.window.samples(0),
+		Last:   sa.latest,
+		Stats:  sa.Stats(),
+	}
+}
+
+// Stats returns the analyzer's counters.
+func (sa *StreamAnalyzer) Stats() StreamStats {
+	// a sample is counted in before it can be dropped, so loading the drops
+	// first keeps SamplesDropped from exceeding SamplesIn under concurrent pushes
+	dropped := sa.samplesDropped.Load()
+	return StreamStats{
+		SamplesIn:      sa.samplesIn.Load(),
+		SamplesDropped: dropped,
+		ResultsEmitted: sa.resultsEmitted.Load(),
+	}
+}
+
+// run analyses queued samples until the queue is stopped, then analyses
+// whatever is left in the queue.
+func (sa *StreamAnalyzer) run() {
+	defer close(sa.exited)
+
+	for {
+		select {
+		case <-sa.queue.stop:
+			for {
+				select {
+				case sample := <-sa.queue.ch:
+					sa.process(sample)
+					sa.processed.Add(1)
+				default:
+					return
+				}
+			}
+		case sample := <-sa.queue.ch:
+			sa.process(sample)
+			sa.processed.Add(1)
+		}
+	}
+}
+
+// process adds a sample to the window and emits a result if one is due.
+func (sa *StreamAnalyzer) process(sample SingleChannelSample) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	sa.window.push(sample.Time, []float64{sample.Value})
+	sa.pending = true
+	if sa.schedule.advance(sample.Time) {
+		sa.emit(sa.window.result(0))
+	}
+}
+
+// emit delivers a result to the callback and alarms. The caller must hold sa.mu.
+func (sa *StreamAnalyzer) emit(result AnalysisResult) {
+	sa.pending = false
+	sa.latest = result
+	sa.resultsEmitted.Add(1)
+	if sa.onResult != nil {
+		sa.onResult(result)
+	}
+	for _, alarm := range sa.alarms {
+		alarm.update(result)
+	}
+}
@@ -0,0 +1,300 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamAnalyzer(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(50, 1, 2, 1000)
+
+	// Run the test
+	var results []AnalysisResult
+	sa, err := NewStreamAnalyzer(0.2, 0.1, func(result AnalysisResult) {
+		results = append(results, result)
+	})
+	if err != nil {
+		t.Fatalf("NewStreamAnalyzer returned error: %v", err)
+	}
+	for _, sample := range data {
+		if err := sa.Push(sample); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+	}
+
+	// The first result is due once 0.2s have been seen, then one every 0.1s
+	if len(results) != 18 {
+		t.Fatalf("StreamAnalyzer produced %d results, expected 18", len(results))
+	}
+	if diff := math.Abs(results[0].Time - 0.2); diff > 1e-9 {
+		t.Errorf("First result at %f, expected 0.2", results[0].Time)
+	}
+	for _, result := range results {
+		if result.Samples != 200 {
+			t.Errorf("Result at %f covered %d samples, expected 200", result.Time, result.Samples)
+		}
+		if diff := math.Abs(result.RMS - 0.7071); diff > 0.001 {
+			t.Errorf("Result at %f has RMS %f, expected 0.7071 (difference: %f)", result.Time, result.RMS, diff)
+		}
+		if diff := math.Abs(result.Peak - 1); diff > 0.001 {
+			t.Errorf("Result at %f has peak %f, expected 1 (difference: %f)", result.Time, result.Peak, diff)
+		}
+		if diff := math.Abs(result.NZCR - 50); diff > 5.1 {
+			t.Errorf("Result at %f has NZCR %f, expected 50 (difference: %f)", result.Time, result.NZCR, diff)
+		}
+	}
+}
+
+func TestStreamAnalyzerMatchesBatch(t *testing.T) {
+	// Generate sample data
+	data := GenerateSineWave(37, 2, 1, 1000)
+
+	// Run the test
+	var last AnalysisResult
+	sa, _ := NewStreamAnalyzer(0.25, 0.05, func(result AnalysisResult) {
+		last = result
+	})
+	for _, sample := range data {
+		_ = sa.Push(sample)
+	}
+
+	// The incremental sums must agree with a batch computation over the same
+	// window, which ends at 0.95s as the next result would be due at 1s
+	window := data[701:951]
+	if last.Time != window[len(window)-1].Time || last.Samples != len(window) {
+		t.Fatalf("Last result at %f over %d samples, expected %f over %d", last.Time, last.Samples, window[len(window)-1].Time, len(window))
+	}
+	if diff := math.Abs(last.RMS - calculateRMS(window)); diff > 1e-9 {
+		t.Errorf("Streaming RMS %f differs from batch RMS %f", last.RMS, calculateRMS(window))
+	}
+	if diff := math.Abs(last.NZCR - NegativeZeroCrossingRate(window)); diff > 1e-9 {
+		t.Errorf("Streaming NZCR %f differs from batch NZCR %f", last.NZCR, NegativeZeroCrossingRate(window))
+	}
+}
+
+func TestStreamAnalyzerRejectsOutOfOrder(t *testing.T) {
+	sa, _ := NewStreamAnalyzer(1, 1, nil)
+
+	// Run the test
+	if err := sa.Push(SingleChannelSample{Time: 1, Value: 0}); err != nil {
+		t.Fatalf("Push returned error: %v", err)
+	}
+	if err := sa.Push(SingleChannelSample{Time: 0.5, Value: 0}); err == nil {
+		t.Errorf("Push accepted a sample earlier than its predecessor")
+	}
+}
+
+func TestNewStreamAnalyzerValidation(t *testing.T) {
+	if _, err := NewStreamAnalyzer(0, 1, nil); err == nil {
+		t.Errorf("NewStreamAnalyzer accepted a zero window")
+	}
+	if _, err := NewStreamAnalyzer(1, math.NaN(), nil); err == nil {
+		t.Errorf("NewStreamAnalyzer accepted a NaN hop")
+	}
+}
+
+// slowStream pushes 200 samples at 1 kHz into an analyzer whose callback takes
+// a millisecond, with a result due for every sample after the first two.
+func slowStream(t *testing.T, policy OverflowPolicy) (*StreamAnalyzer, []AnalysisResult) {
+	var mu sync.Mutex
+	var results []AnalysisResult
+	sa, err := NewStreamAnalyzer(0.002, 0.001, func(result AnalysisResult) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	}, WithQueue(10, policy))
+	if err != nil {
+		t.Fatalf("NewStreamAnalyzer returned error: %v", err)
+	}
+
+	data := GenerateSineWave(50, 1, 0.2, 1000)
+	for _, sample := range data {
+		if err := sa.Push(sample); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+	}
+
+	// wait for the worker to catch up with everything that was not dropped
+	waitFor(func() bool {
+		stats := sa.Stats()
+		return sa.processed.Load() == stats.SamplesIn-stats.SamplesDropped
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	return sa, results
+}
+
+func TestStreamAnalyzerOverflowBlock(t *testing.T) {
+	sa, results := slowStream(t, OverflowBlock)
+
+	// Every sample is analysed, however long the producer has to wait
+	stats := sa.Stats()
+	if stats.SamplesIn != 200 || stats.SamplesDropped != 0 {
+		t.Errorf("Stats %+v, expected 200 samples in and none dropped", stats)
+	}
+	if len(results) != 198 || stats.ResultsEmitted != 198 {
+		t.Errorf("Analyzer emitted %d results (stats %d), expected 198", len(results), stats.ResultsEmitted)
+	}
+}
+
+func TestStreamAnalyzerOverflowDropNewest(t *testing.T) {
+	sa, results := slowStream(t, OverflowDropNewest)
+
+	// The producer outruns the analysis, so the end of the stream is lost
+	stats := sa.Stats()
+	if stats.SamplesIn != 200 || stats.SamplesDropped == 0 {
+		t.Errorf("Stats %+v, expected 200 samples in and some dropped", stats)
+	}
+	if stats.ResultsEmitted != uint64(len(results)) {
+		t.Errorf("Stats report %d results, callback saw %d", stats.ResultsEmitted, len(results))
+	}
+	if len(results) > 0 && results[len(results)-1].Time >= 0.199 {
+		t.Errorf("Last result at %f, expected the newest samples to have been dropped", results[len(results)-1].Time)
+	}
+}
+
+func TestStreamAnalyzerOverflowDropOldest(t *testing.T) {
+	sa, results := slowStream(t, OverflowDropOldest)
+
+	// Queued samples are discarded in favour of new ones, so the newest survives
+	stats := sa.Stats()
+	if stats.SamplesIn != 200 || stats.SamplesDropped == 0 {
+		t.Errorf("Stats %+v, expected 200 samples in and some dropped", stats)
+	}
+	if len(results) == 0 || math.Abs(results[len(results)-1].Time-0.199) > 1e-9 {
+		t.Errorf("Last result is not for the newest sample: %v", results)
+	}
+}
+
+func TestStreamAnalyzerClose(t *testing.T) {
+	// Generate a stream shorter than the window
+	data := GenerateSineWave(50, 1, 0.4, 1000)
+
+	for _, opts := range [][]StreamOption{nil, {WithQueue(8, OverflowBlock)}} {
+		var results []AnalysisResult
+		sa, _ := NewStreamAnalyzer(1, 0.5, func(result AnalysisResult) {
+			results = append(results, result)
+		}, opts...)
+
+		// Run the test
+		for _, sample := range data {
+			_ = sa.Push(sample)
+		}
+		if err := sa.Close(); err != nil {
+			t.Fatalf("Close returned error: %v", err)
+		}
+		if err := sa.Close(); err != nil {
+			t.Errorf("Second Close returned error: %v", err)
+		}
+
+		if len(results) != 1 {
+			t.Fatalf("Close produced %d results, expected 1", len(results))
+		}
+		if !results[0].Partial || results[0].Samples != 400 {
+			t.Errorf("Final result %+v, expected a partial result over 400 samples", results[0])
+		}
+		if diff := math.Abs(results[0].RMS - 0.7071); diff > 0.001 {
+			t.Errorf("Final result RMS %f, expected 0.7071 (difference: %f)", results[0].RMS, diff)
+		}
+		if err := sa.Push(SingleChannelSample{Time: 1}); !errors.Is(err, ErrClosed) {
+			t.Errorf("Push after Close returned %v, expected ErrClosed", err)
+		}
+	}
+}
+
+func TestStreamAnalyzerCloseAfterResult(t *testing.T) {
+	var results []AnalysisResult
+	sa, _ := NewStreamAnalyzer(0.1, 0.1, func(result AnalysisResult) {
+		results = append(results, result)
+	})
+
+	// Run the test: the last sample is exactly on a hop, so nothing is left to flush
+	for _, sample := range GenerateSineWave(50, 1, 0.301, 1000) {
+		_ = sa.Push(sample)
+	}
+	_ = sa.Close()
+
+	if len(results) != 3 {
+		t.Fatalf("Analyzer produced %d results, expected 3", len(results))
+	}
+	for _, result := range results {
+		if result.Partial {
+			t.Errorf("Result at %f flagged as partial", result.Time)
+		}
+	}
+}
+
+func TestStreamAnalyzerSnapshot(t *testing.T) {
+	sa, _ := NewStreamAnalyzer(0.1, 0.01, nil)
+
+	// Run the test
+	snapshot := sa.Snapshot()
+	if len(snapshot.Window) != 0 || snapshot.Last.Samples != 0 || snapshot.Taken.IsZero() {
+		t.Errorf("Snapshot of a new analyzer %+v, expected an empty window and no result", snapshot)
+	}
+
+	data := GenerateSineWave(50, 1, 0.5, 1000)
+	for _, sample := range data {
+		_ = sa.Push(sample)
+	}
+	snapshot = sa.Snapshot()
+
+	if len(snapshot.Window) != 100 || snapshot.Window[99] != data[len(data)-1] {
+		t.Fatalf("Snapshot window has %d samples, expected the last 100", len(snapshot.Window))
+	}
+	if math.Abs(snapshot.Last.Time-0.49) > 1e-9 || snapshot.Last.Samples != 100 {
+		t.Errorf("Snapshot last result %+v, expected the result due at 0.49", snapshot.Last)
+	}
+	if snapshot.Stats.SamplesIn != 500 || snapshot.Stats.ResultsEmitted != 40 {
+		t.Errorf("Snapshot stats %+v, expected 500 samples in and 40 results", snapshot.Stats)
+	}
+
+	// The window is a copy: changing it does not affect the analyzer
+	snapshot.Window[0].Value = 1e9
+	if again := sa.Snapshot(); again.Window[0].Value == 1e9 {
+		t.Errorf("Snapshot window aliases the analyzer's storage")
+	}
+}
+
+func TestStreamAnalyzerSnapshotConcurrent(t *testing.T) {
+	sa, _ := NewStreamAnalyzer(0.05, 0.01, nil, WithQueue(64, OverflowDropOldest))
+	done := make(chan struct{})
+
+	// A hot producer pushes while snapshots are taken
+	go func() {
+		defer close(done)
+		for i := 0; i < 20000; i++ {
+			_ = sa.Push(SingleChannelSample{Time: float64(i) / 1000, Value: math.Sin(float64(i))})
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		// Run the test: the window and the last result must describe the same moment
+		snapshot := sa.Snapshot()
+		window := snapshot.Window
+		for i := 1; i < len(window); i++ {
+			if window[i].Time < window[i-1].Time {
+				t.Fatalf("Snapshot window out of order at %d", i)
+			}
+		}
+		if snapshot.Last.Samples > 0 && len(window) > 0 && window[len(window)-1].Time < snapshot.Last.Time {
+			t.Fatalf("Snapshot window ends at %f, before the last result at %f", window[len(window)-1].Time, snapshot.Last.Time)
+		}
+		if snapshot.Stats.SamplesDropped > snapshot.Stats.SamplesIn {
+			t.Fatalf("Snapshot stats %+v drop more samples than were pushed", snapshot.Stats)
+		}
+	}
+	_ = sa.Close()
+}
@@ -0,0 +1,137 @@
+package dynamics
+
+import "math"
+
+// compensatedSumThreshold is the number of samples from which sums of squares,
+// and the sums behind Mean and AverageRectified, are accumulated with
+// compensation. Below it the rounding error of plain
+// summation is far smaller than any measurement error, and plain summation is
+// about four times faster.
+const compensatedSumThreshold = 1 << 20
+
+// sumSquares returns the sum of the squares of the sample values. From
+// compensatedSumThreshold samples on it switches to sumSquaresCompensated.
+//
+// The loop is unrolled four times into independent partial sums, with sample i
+// going to sum i%4. Successive additions no longer wait on each other, which
+// roughly halves the time on data already in cache, and each partial sum adds
+// a quarter as many terms, which loses less precision than a single running sum.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The sum of the squared values
+func sumSquares(data []SingleChannelSample) float64 {
+	if len(data) >= compensatedSumThreshold {
+		return sumSquaresCompensated(data)
+	}
+
+	// separate variables rather than an array, so that they stay in registers
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(data); i += 4 {
+		run := data[i : i+4 : i+4]
+		s0 += run[0].Value * run[0].Value
+		s1 += run[1].Value * run[1].Value
+		s2 += run[2].Value * run[2].Value
+		s3 += run[3].Value * run[3].Value
+	}
+	tail := data[i:]
+	if len(tail) > 0 {
+		s0 += tail[0].Value * tail[0].Value
+	}
+	if len(tail) > 1 {
+		s1 += tail[1].Value * tail[1].Value
+	}
+	if len(tail) > 2 {
+		s2 += tail[2].Value * tail[2].Value
+	}
+	return combineLanes([4]float64{s0, s1, s2, s3})
+}
+
+// combineLanes adds the partial sums of sumSquares in a fixed order, so that
+// code accumulating the same lanes by another route gets the same result.
+func combineLanes(lanes [4]float64) float64 {
+	return (lanes[0] + lanes[1]) + (lanes[2] + lanes[3])
+}
+
+// sumSquaresCompensated returns the sum of the squares of the sample values,
+// accumulated with compensatedSum.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//
+// Returns:
+//   - float64: The sum of the squared values
+func sumSquaresCompensated(data []SingleChannelSample) float64 {
+	var sum compensatedSum
+	sum.addSquares(data)
+	return sum.value()
+}
+
+// sumValues returns the sum of the sample values, or of their magnitudes when
+// rectified is set. From compensatedSumThreshold samples on it is accumulated
+// with compensatedSum.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - rectified: Whether to sum the absolute values
+//
+// Returns:
+//   - float64: The sum of the values or of their magnitudes
+func sumValues(data []SingleChannelSample, rectified bool) float64 {
+	if len(data) >= compensatedSumThreshold {
+		var sum compensatedSum
+		for _, sample := range data {
+			if rectified {
+				sum.add(math.Abs(sample.Value))
+			} else {
+				sum.add(sample.Value)
+			}
+		}
+		return sum.value()
+	}
+	var sum float64
+	for _, sample := range data {
+		if rectified {
+			sum += math.Abs(sample.Value)
+		} else {
+			sum += sample.Value
+		}
+	}
+	return sum
+}
+
+// compensatedSum accumulates a sum using Neumaier's variant of Kahan
+// summation: the low-order bits that each addition rounds away are collected
+// separately and added back at the end, so the error stays near one rounding
+// however many terms are added. Terms may be negative, which lets a running
+// sum subtract the terms that leave a sliding window.
+type compensatedSum struct {
+	sum          float64
+	compensation float64
+}
+
+// add adds a term to the sum.
+func (s *compensatedSum) add(x float64) {
+	t := s.sum + x
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.compensation += (s.sum - t) + x
+	} else {
+		s.compensation += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+// addSquares adds the squares of the sample values to the sum.
+func (s *compensatedSum) addSquares(data []SingleChannelSample) {
+	for _, sample := range data {
+		s.add(sample.Value * sample.Value)
+	}
+}
+
+// value returns the compensated sum.
+func (s compensatedSum) value() float64 {
+	return s.sum + s.compensation
+}
@@ -0,0 +1,155 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// largeThenOnes returns four samples of 1e8 followed by n samples of 1. Each
+// square of 1 is below the rounding step of the 4e16 it is added to, so a
+// plain sum loses all of them. The exact sum of squares is 4e16+n.
+func largeThenOnes(n int) []SingleChannelSample {
+	data := make([]SingleChannelSample, n+4)
+	for i := range data {
+		data[i] = SingleChannelSample{Time: float64(i), Value: 1}
+	}
+	for i := range 4 {
+		data[i].Value = 1e8
+	}
+	return data
+}
+
+func TestSumSquaresCompensated(t *testing.T) {
+	// Generate sample data
+	const n = 1000
+	data := largeThenOnes(n)
+	exact := 4e16 + n
+
+	// Run the test
+	if got := sumSquaresCompensated(data); got != exact {
+		t.Errorf("sumSquaresCompensated = %v, expected %v", got, exact)
+	}
+	if got := sumSquares(data); math.Abs(got-exact)/exact < 1e-14 {
+		t.Errorf("sumSquares = %v, expected the plain sum to lose the small terms", got)
+	}
+}
+
+func TestCompensatedSumSubtraction(t *testing.T) {
+	// Generate sample data: add and remove a large term around many small ones
+	var sum compensatedSum
+	sum.add(1e16)
+	for range 1000 {
+		sum.add(1)
+	}
+	sum.add(-1e16)
+
+	// Run the test
+	if got := sum.value(); got != 1000 {
+		t.Errorf("value = %v, expected 1000", got)
+	}
+}
+
+func TestSumSquaresCompensatedAboveThreshold(t *testing.T) {
+	// Generate sample data
+	data := largeThenOnes(compensatedSumThreshold)
+	expected := math.Sqrt((4e16 + compensatedSumThreshold) / float64(len(data)))
+
+	// Run the test
+	if got := calculateRMS(data); got != expected {
+		t.Errorf("calculateRMS = %v, expected %v", got, expected)
+	}
+}
+
+func TestWithCompensatedSum(t *testing.T) {
+	// Generate sample data; there are no crossings, so the RMS covers all of it
+	const n = 1000
+	data := largeThenOnes(n)
+	expected := math.Sqrt((4e16 + n) / float64(len(data)))
+
+	// Run the test
+	result, err := AnalyzeDetailed(data, WithCompensatedSum())
+	if err != nil {
+		t.Fatalf("AnalyzeDetailed returned error: %v", err)
+	}
+	if result.RMS != expected {
+		t.Errorf("RMS = %v, expected %v", result.RMS, expected)
+	}
+	if rms, _ := Analyze(data, WithCompensatedSum()); rms != expected {
+		t.Errorf("Analyze RMS = %v, expected %v", rms, expected)
+	}
+
+	multi := make([]MultiChannelSample, len(data))
+	for i, sample := range data {
+		multi[i] = MultiChannelSample{Time: sample.Time, Value: []float64{sample.Value}}
+	}
+	rms, _ := AnalyzeMultiChannel(multi, WithCompensatedSum())
+	if rms[0] != expected {
+		t.Errorf("AnalyzeMultiChannel RMS = %v, expected %v", rms[0], expected)
+	}
+}
+
+func TestMeanCompensatedAboveThreshold(t *testing.T) {
+	// Generate sample data: 1e16 and -1e16 around ones that a plain sum would
+	// lose against the large running total
+	n := compensatedSumThreshold
+	data := make([]SingleChannelSample, n)
+	for i := range data {
+		data[i] = SingleChannelSample{Time: float64(i), Value: 1}
+	}
+	data[0].Value, data[n-1].Value = 1e16, -1e16
+
+	// Run the test
+	if got, expected := Mean(data), float64(n-2)/float64(n); got != expected {
+		t.Errorf("Mean = %v, expected %v", got, expected)
+	}
+	if got, expected := AverageRectified(data), (2e16+float64(n-2))/float64(n); math.Abs(got-expected) > 1e-15*expected {
+		t.Errorf("AverageRectified = %v, expected %v", got, expected)
+	}
+}
+
+func TestAnalyzeMultiChannelCompensatedThreshold(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping a record of over a million rows in short mode")
+	}
+
+	// Generate sample data: a sine whose whole cycles hold more than
+	// compensatedSumThreshold samples, so the windowed pass switches too; a
+	// level without crossings whose small squares a plain sum loses; and the
+	// same level with enough values skipped to stay below the threshold
+	const channels = 3
+	n := compensatedSumThreshold + 1<<16
+	values := make([]float64, n*channels)
+	data := make([]MultiChannelSample, n)
+	for i := range data {
+		row := values[i*channels : (i+1)*channels : (i+1)*channels]
+		row[0] = math.Sin(2 * math.Pi * 100 * float64(i) / float64(n))
+		row[1], row[2] = 1, 1
+		if i < 4 {
+			row[1], row[2] = 1e8, 1e8
+		}
+		if i%15 == 14 {
+			row[2] = math.NaN()
+		}
+		data[i] = MultiChannelSample{Time: float64(i), Value: row}
+	}
+
+	// Run the test: every channel matches its own analysis bit for bit
+	for _, policy := range []NonFinitePolicy{NonFinitePropagate, NonFiniteSkip} {
+		config := newAnalyzeConfig([]AnalyzeOption{WithNonFinite(policy)})
+		rms, zcr, err := analyzeColumns(data, channels, config)
+		if err != nil {
+			t.Fatalf("policy %d: analyzeColumns returned error %v", policy, err)
+		}
+		for c := range channels {
+			single := make([]SingleChannelSample, n)
+			for i := range data {
+				single[i] = SingleChannelSample{Time: data[i].Time, Value: data[i].Value[c]}
+			}
+			single, _ = config.prepare(single)
+			expectedRMS, expectedZCR, _ := analyze(single, config)
+			if math.Float64bits(rms[c]) != math.Float64bits(expectedRMS) || zcr[c] != expectedZCR {
+				t.Errorf("policy %d, channel %d: got %v, %v; expected %v, %v", policy, c, rms[c], zcr[c], expectedRMS, expectedZCR)
+			}
+		}
+	}
+}
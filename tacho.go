@@ -0,0 +1,109 @@
+package dynamics
+
+import (
+	"math"
+	"slices"
+)
+
+// DefaultGlitchTolerance is the largest fraction by which TachoToRPM lets an
+// interval between pulses differ from the median of its neighbours.
+const DefaultGlitchTolerance = 0.3
+
+// glitchNeighbours is the number of intervals on each side of an interval
+// that join it in the median it is compared with.
+const glitchNeighbours = 2
+
+// TachoOption configures a call to TachoToRPM.
+type TachoOption func(*tachoConfig)
+
+// tachoConfig holds the settings made by TachoOptions.
+type tachoConfig struct {
+	tolerance float64 // 0 to keep every interval
+}
+
+// WithGlitchTolerance sets the largest fraction by which an interval may
+// differ from the median of the five intervals centred on it before it is
+// rejected, in place of DefaultGlitchTolerance. A spurious pulse splits an
+// interval in two short ones, and a missed pulse merges two in one long one,
+// so both are rejected. Zero or less keeps every interval.
+func WithGlitchTolerance(fraction float64) TachoOption {
+	return func(c *tachoConfig) {
+		c.tolerance = max(fraction, 0)
+	}
+}
+
+// TachoToRPM converts a tachometer pulse channel to shaft speed. Each pulse
+// is timed where the signal rises through the threshold, interpolating
+// linearly between samples, and each interval between successive pulses gives
+// the mean speed over it, timed at its centre. Intervals that differ too much
+// from their neighbours are taken for glitches and left out.
+//
+// Parameters:
+//   - tacho: A slice of Sample structs containing time and value data
+//   - threshold: The level that the leading edge of a pulse rises through
+//   - pulsesPerRev: The number of pulses per revolution of the shaft
+//   - opts: Options such as WithGlitchTolerance
+//
+// Returns:
+//   - []SingleChannelSample: The speed in revolutions per minute, none when
+//     pulsesPerRev is not positive
+func TachoToRPM(tacho []SingleChannelSample, threshold float64, pulsesPerRev int, opts ...TachoOption) []SingleChannelSample {
+	if pulsesPerRev <= 0 {
+		return nil
+	}
+	config := tachoConfig{tolerance: DefaultGlitchTolerance}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	pulses := pulseTimes(tacho, threshold)
+	if len(pulses) < 2 {
+		return nil
+	}
+	intervals := make([]float64, len(pulses)-1)
+	for i := range intervals {
+		intervals[i] = pulses[i+1] - pulses[i]
+	}
+
+	rpm := make([]SingleChannelSample, 0, len(intervals))
+	window := make([]float64, 0, 2*glitchNeighbours+1)
+	for i, interval := range intervals {
+		if !(interval > 0) {
+			continue
+		}
+		if config.tolerance > 0 {
+			window = append(window[:0], intervals[max(i-glitchNeighbours, 0):min(i+glitchNeighbours+1, len(intervals))]...)
+			if reference := median(window); math.Abs(interval-reference) > config.tolerance*reference {
+				continue
+			}
+		}
+		rpm = append(rpm, SingleChannelSample{
+			Time:  (pulses[i] + pulses[i+1]) / 2,
+			Value: 60 / (interval * float64(pulsesPerRev)),
+		})
+	}
+	return rpm
+}
+
+// pulseTimes returns the times at which the signal rises through the
+// threshold, interpolating linearly between samples.
+func pulseTimes(tacho []SingleChannelSample, threshold float64) []float64 {
+	var pulses []float64
+	for i := 1; i < len(tacho); i++ {
+		a, b := tacho[i-1], tacho[i]
+		if a.Value < threshold && b.Value >= threshold {
+			pulses = append(pulses, a.Time+(b.Time-a.Time)*(threshold-a.Value)/(b.Value-a.Value))
+		}
+	}
+	return pulses
+}
+
+// median returns the median of the values, which it sorts.
+func median(values []float64) float64 {
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
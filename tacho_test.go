@@ -0,0 +1,79 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+// tachoRamp returns a once-per-rev pulse train sampled at 50 kHz whose speed
+// ramps linearly from 1000 to 3000 RPM over 10 s, and the speed at a time.
+func tachoRamp() ([]SingleChannelSample, func(float64) float64) {
+	speed := func(t float64) float64 { return 1000 + 200*t }
+	data := make([]SingleChannelSample, 500000)
+	for i := range data {
+		t := float64(i) / 50000
+		revs := (1000*t + 100*t*t) / 60
+		// a smooth 5 V pulse 5% of a revolution wide
+		x := (revs - math.Floor(revs) - 0.5) / 0.05
+		data[i] = SingleChannelSample{Time: t, Value: 5 * math.Exp(-x*x)}
+	}
+	return data, speed
+}
+
+func TestTachoToRPM(t *testing.T) {
+	// Generate sample data
+	data, speed := tachoRamp()
+
+	// Run the test: with a linear ramp the mean speed over an interval is the
+	// speed at its centre
+	rpm := TachoToRPM(data, 2.5, 1)
+	if len(rpm) < 300 {
+		t.Fatalf("got %d speeds, expected over 300", len(rpm))
+	}
+	for _, point := range rpm {
+		if expected := speed(point.Time); math.Abs(point.Value-expected) > 1 {
+			t.Errorf("speed %v RPM at %v s, expected %v", point.Value, point.Time, expected)
+		}
+	}
+}
+
+func TestTachoToRPMGlitch(t *testing.T) {
+	// Generate sample data with a spurious pulse at 5 s, between two real ones
+	data, speed := tachoRamp()
+	for i := 250000; i < 250020; i++ {
+		data[i].Value = 5
+	}
+
+	// Run the test: the two short intervals it makes are rejected
+	rpm := TachoToRPM(data, 2.5, 1)
+	for _, point := range rpm {
+		if expected := speed(point.Time); math.Abs(point.Value-expected) > 1 {
+			t.Errorf("speed %v RPM at %v s, expected %v", point.Value, point.Time, expected)
+		}
+	}
+
+	// without rejection they are kept
+	all := TachoToRPM(data, 2.5, 1, WithGlitchTolerance(0))
+	if len(all) != len(rpm)+2 {
+		t.Errorf("got %d speeds without rejection, expected %d", len(all), len(rpm)+2)
+	}
+}
+
+func TestTachoToRPMPulsesPerRev(t *testing.T) {
+	// Generate sample data: two pulses per revolution at 600 RPM
+	data := GenerateSineWave(20, 1, 1, 10000)
+
+	// Run the test
+	rpm := TachoToRPM(data, 0.5, 2)
+	if len(rpm) == 0 {
+		t.Fatal("got no speeds")
+	}
+	for _, point := range rpm {
+		if math.Abs(point.Value-600) > 0.01 {
+			t.Errorf("speed %v RPM, expected 600", point.Value)
+		}
+	}
+	if TachoToRPM(data, 0.5, 0) != nil {
+		t.Error("zero pulses per revolution: expected no speeds")
+	}
+}
@@ -0,0 +1,94 @@
+package dynamics
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// ThreePhaseResult holds the analysis of a three-phase set of voltages or
+// currents. The sequence components are RMS magnitudes, in the same units as
+// the phase RMS values.
+type ThreePhaseResult struct {
+	RMS [3]float64 `json:"rms"` // RMS of each phase
+	// Unbalance is the NEMA unbalance in percent: the largest deviation of a
+	// phase RMS from the mean of the three, over that mean.
+	Unbalance float64 `json:"unbalance"`
+	Positive  float64 `json:"positive"` // positive-sequence magnitude at the fundamental
+	Negative  float64 `json:"negative"` // negative-sequence magnitude at the fundamental
+	Zero      float64 `json:"zero"`     // zero-sequence magnitude at the fundamental
+}
+
+// ThreePhaseAnalysis analyses three channels holding the phases of a
+// three-phase system, in the order A, B, C, so that B lags A. The RMS values
+// and the phasors behind the symmetrical components are taken over the last
+// whole cycles of the fundamental, up to DefaultMaxCycles of them, as RMS does.
+//
+// Parameters:
+//   - data: A slice of MultiChannelSample structs with three values each
+//   - fundamental: The frequency of the system in Hz
+//
+// Returns:
+//   - ThreePhaseResult: The analysis, zero on error
+//   - error: ErrEmptyData if data is empty, ErrInvalidFrequency if fundamental
+//     is not positive and finite, an error wrapping ErrChannelMismatch naming
+//     the first sample without three values, ErrUnsortedData if the data is not
+//     in time order, or ErrTooShort if it spans less than one cycle
+func ThreePhaseAnalysis(data []MultiChannelSample, fundamental float64) (ThreePhaseResult, error) {
+	if len(data) == 0 {
+		return ThreePhaseResult{}, ErrEmptyData
+	}
+	if !(fundamental > 0) || math.IsInf(fundamental, 1) {
+		return ThreePhaseResult{}, fmt.Errorf("%w: %g Hz", ErrInvalidFrequency, fundamental)
+	}
+	for i, sample := range data {
+		if len(sample.Value) != 3 {
+			return ThreePhaseResult{}, fmt.Errorf("%w: sample %d at time %g has %d channels, expected 3", ErrChannelMismatch, i, sample.Time, len(sample.Value))
+		}
+		if i > 0 && sample.Time < data[i-1].Time {
+			return ThreePhaseResult{}, fmt.Errorf("%w: sample %d at time %g is earlier than sample %d at time %g", ErrUnsortedData, i, sample.Time, i-1, data[i-1].Time)
+		}
+	}
+
+	last := data[len(data)-1].Time
+	span := rmsSpan(last-data[0].Time, fundamental, DefaultMaxCycles)
+	if math.IsInf(span, 1) {
+		return ThreePhaseResult{}, ErrTooShort
+	}
+	// as in KeepXSecondsOfData, the whole cycles start at the first sample at or after the cutoff
+	start := 0
+	for data[start].Time < last-span {
+		start++
+	}
+	data = data[start:]
+
+	var result ThreePhaseResult
+	var phasors [3]complex128
+	scratch := sampleScratch.get(len(data))
+	defer sampleScratch.put(scratch)
+	phase := *scratch
+	for c := range 3 {
+		for i, sample := range data {
+			phase[i] = SingleChannelSample{Time: sample.Time, Value: sample.Value[c]}
+		}
+		result.RMS[c] = calculateRMS(phase)
+		phasors[c] = Goertzel(phase, fundamental)
+	}
+
+	mean := (result.RMS[0] + result.RMS[1] + result.RMS[2]) / 3
+	if mean > 0 {
+		deviation := 0.0
+		for _, rms := range result.RMS {
+			deviation = math.Max(deviation, math.Abs(rms-mean))
+		}
+		result.Unbalance = 100 * deviation / mean
+	}
+
+	// symmetrical components, with a the operator rotating a phasor by 120°
+	a := cmplx.Rect(1, 2*math.Pi/3)
+	va, vb, vc := phasors[0], phasors[1], phasors[2]
+	result.Zero = cmplx.Abs(va+vb+vc) / 3 / math.Sqrt2
+	result.Positive = cmplx.Abs(va+a*vb+a*a*vc) / 3 / math.Sqrt2
+	result.Negative = cmplx.Abs(va+a*a*vb+a*vc) / 3 / math.Sqrt2
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// threePhase generates a three-phase set at 50 Hz with the given phase RMS values.
+func threePhase(a, b, c float64) []MultiChannelSample {
+	data := make([]MultiChannelSample, 10000)
+	for i := range data {
+		tm := float64(i) / 10000
+		angle := 2 * math.Pi * 50 * tm
+		data[i] = MultiChannelSample{Time: tm, Value: []float64{
+			a * math.Sqrt2 * math.Cos(angle),
+			b * math.Sqrt2 * math.Cos(angle-2*math.Pi/3),
+			c * math.Sqrt2 * math.Cos(angle+2*math.Pi/3),
+		}}
+	}
+	return data
+}
+
+func TestThreePhaseAnalysisBalanced(t *testing.T) {
+	// Generate sample data
+	data := threePhase(230, 230, 230)
+
+	// Run the test
+	result, err := ThreePhaseAnalysis(data, 50)
+	if err != nil {
+		t.Fatalf("ThreePhaseAnalysis returned error: %v", err)
+	}
+	for c, rms := range result.RMS {
+		if math.Abs(rms-230) > 0.05 {
+			t.Errorf("phase %d RMS = %v, expected 230", c, rms)
+		}
+	}
+	if result.Unbalance > 0.01 {
+		t.Errorf("Unbalance = %v%%, expected 0", result.Unbalance)
+	}
+	if math.Abs(result.Positive-230) > 0.05 || result.Negative > 0.05 || result.Zero > 0.05 {
+		t.Errorf("sequence components = %v, %v, %v, expected 230, 0, 0", result.Positive, result.Negative, result.Zero)
+	}
+}
+
+func TestThreePhaseAnalysisSag(t *testing.T) {
+	// Generate sample data: phase A sagged to 90%
+	data := threePhase(207, 230, 230)
+
+	// Run the test: the mean is 222.33 and A deviates from it by 15.33
+	result, err := ThreePhaseAnalysis(data, 50)
+	if err != nil {
+		t.Fatalf("ThreePhaseAnalysis returned error: %v", err)
+	}
+	mean := (207 + 230 + 230) / 3.0
+	if expected := 100 * (mean - 207) / mean; math.Abs(result.Unbalance-expected) > 0.01 {
+		t.Errorf("Unbalance = %v%%, expected %v%%", result.Unbalance, expected)
+	}
+	// a sag of one phase by d gives positive sequence V−d/3 and negative and zero sequence d/3
+	if math.Abs(result.Positive-222.333) > 0.05 {
+		t.Errorf("Positive = %v, expected 222.33", result.Positive)
+	}
+	if math.Abs(result.Negative-7.667) > 0.05 || math.Abs(result.Zero-7.667) > 0.05 {
+		t.Errorf("Negative, Zero = %v, %v, expected 7.67 each", result.Negative, result.Zero)
+	}
+}
+
+func TestThreePhaseAnalysisErrors(t *testing.T) {
+	// Generate sample data
+	data := threePhase(230, 230, 230)
+	twoChannels := append([]MultiChannelSample(nil), data...)
+	twoChannels[5] = MultiChannelSample{Time: data[5].Time, Value: []float64{1, 2}}
+
+	// Run the test
+	if _, err := ThreePhaseAnalysis(nil, 50); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got error %v, expected ErrEmptyData", err)
+	}
+	if _, err := ThreePhaseAnalysis(data, 0); !errors.Is(err, ErrInvalidFrequency) {
+		t.Errorf("zero fundamental: got error %v, expected ErrInvalidFrequency", err)
+	}
+	if _, err := ThreePhaseAnalysis(twoChannels, 50); !errors.Is(err, ErrChannelMismatch) {
+		t.Errorf("two channels: got error %v, expected ErrChannelMismatch", err)
+	}
+	if _, err := ThreePhaseAnalysis(data[:150], 50); !errors.Is(err, ErrTooShort) {
+		t.Errorf("less than a cycle: got error %v, expected ErrTooShort", err)
+	}
+}
@@ -0,0 +1,98 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// FrequencyTracker estimates the fundamental frequency of a live stream of
+// samples. Each negative-going zero crossing is located between its bracketing
+// samples by linear interpolation, and the period between successive crossings
+// gives a per-cycle estimate that is smoothed with an exponential filter.
+//
+// The first complete cycle initialises the estimate directly, so the tracker
+// converges within a couple of cycles of startup. A FrequencyTracker is safe
+// for concurrent use.
+type FrequencyTracker struct {
+	mu           sync.Mutex
+	timeConstant float64
+	prev         SingleChannelSample
+	started      bool
+	detector     crossingDetector
+	crossing     float64 // interpolated time of the most recent crossing
+	crossed      bool
+	frequency    float64
+}
+
+// NewFrequencyTracker creates a FrequencyTracker.
+//
+// Parameters:
+//   - timeConstant: The time constant in seconds of the smoothing applied to the
+//     per-cycle estimates; zero disables smoothing
+//
+// Returns:
+//   - *FrequencyTracker: The new tracker
+//   - error: An error if timeConstant is negative or not finite
+func NewFrequencyTracker(timeConstant float64) (*FrequencyTracker, error) {
+	if !(timeConstant >= 0) || math.IsInf(timeConstant, 1) {
+		return nil, errors.New("dynamics: tracker time constant must not be negative")
+	}
+	return &FrequencyTracker{timeConstant: timeConstant}, nil
+}
+
+// Push adds a sample to the tracker. Samples must arrive in time order; a
+// sample older than its predecessor is rejected with an error.
+func (ft *FrequencyTracker) Push(sample SingleChannelSample) error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if !ft.started {
+		ft.started = true
+		ft.prev = sample
+		ft.detector.step(sample.Value)
+		return nil
+	}
+	if sample.Time < ft.prev.Time {
+		return errors.New("dynamics: sample time is earlier than the previous sample")
+	}
+
+	prev := ft.prev
+	ft.prev = sample
+	if negative, _ := ft.detector.step(sample.Value); !negative {
+		return nil
+	}
+
+	// interpolate the time at which the signal passed through zero; after a
+	// run of zeros prev is the last of them and the crossing is placed there
+	crossing := prev.Time + (sample.Time-prev.Time)*prev.Value/(prev.Value-sample.Value)
+	if !ft.crossed {
+		ft.crossed = true
+		ft.crossing = crossing
+		return nil
+	}
+
+	period := crossing - ft.crossing
+	ft.crossing = crossing
+	if period <= 0 {
+		return nil
+	}
+
+	estimate := 1 / period
+	if ft.frequency == 0 || ft.timeConstant == 0 {
+		ft.frequency = estimate
+		return nil
+	}
+	alpha := 1 - math.Exp(-period/ft.timeConstant)
+	ft.frequency += alpha * (estimate - ft.frequency)
+	return nil
+}
+
+// Frequency returns the current frequency estimate in Hz, or 0 until a full
+// cycle has been observed.
+func (ft *FrequencyTracker) Frequency() float64 {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	return ft.frequency
+}
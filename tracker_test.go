@@ -0,0 +1,58 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFrequencyTracker(t *testing.T) {
+	// Generate a sine whose frequency ramps from 50 Hz to 49.9 Hz over 20 seconds
+	sampleRate := 10000.0
+	duration := 20.0
+	frequency := func(t float64) float64 {
+		return 50 - 0.1*t/duration
+	}
+
+	tracker, err := NewFrequencyTracker(0.1)
+	if err != nil {
+		t.Fatalf("NewFrequencyTracker returned error: %v", err)
+	}
+
+	// Run the test
+	worst := 0.0
+	for i := 0; i < int(duration*sampleRate); i++ {
+		time := float64(i) / sampleRate
+		phase := 2 * math.Pi * (50*time - 0.1*time*time/(2*duration))
+		if err := tracker.Push(SingleChannelSample{Time: time, Value: math.Sin(phase)}); err != nil {
+			t.Fatalf("Push returned error: %v", err)
+		}
+
+		// allow a few cycles for the tracker to converge
+		if time < 0.1 {
+			continue
+		}
+		worst = math.Max(worst, math.Abs(tracker.Frequency()-frequency(time)))
+	}
+
+	if worst > 0.02 {
+		t.Errorf("FrequencyTracker deviated by up to %f Hz, expected at most 0.02 Hz", worst)
+	}
+}
+
+func TestFrequencyTrackerStartup(t *testing.T) {
+	tracker, _ := NewFrequencyTracker(1)
+
+	// Run the test
+	if f := tracker.Frequency(); f != 0 {
+		t.Errorf("Frequency before any samples returned %f, expected 0", f)
+	}
+	for _, sample := range GenerateSineWave(60, 1, 0.05, 2000) {
+		_ = tracker.Push(sample)
+	}
+	if diff := math.Abs(tracker.Frequency() - 60); diff > 0.01 {
+		t.Errorf("Frequency after three cycles returned %f, expected 60 (difference: %f)", tracker.Frequency(), diff)
+	}
+	if err := tracker.Push(SingleChannelSample{Time: 0}); err == nil {
+		t.Errorf("Push accepted a sample earlier than its predecessor")
+	}
+}
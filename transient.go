@@ -0,0 +1,167 @@
+package dynamics
+
+import "math"
+
+// Defaults of DetectTransients.
+const (
+	DefaultTransientRatio      = 4    // short-window RMS over baseline RMS that starts an event
+	DefaultTransientShort      = 0.01 // seconds of the short window
+	DefaultTransientLong       = 1    // seconds of the baseline window
+	DefaultTransientSeparation = 0.1  // seconds between events below which they merge
+)
+
+// Transient is an impact or other burst found by DetectTransients.
+type Transient struct {
+	StartTime float64 `json:"startTime"`
+	Duration  float64 `json:"duration"` // seconds, including any context
+	Peak      float64 `json:"peak"`     // largest absolute value
+	Energy    float64 `json:"energy"`   // integral of the squared value over the event
+}
+
+// TransientOption configures a call to DetectTransients.
+type TransientOption func(*transientConfig)
+
+// transientConfig holds the settings made by TransientOptions.
+type transientConfig struct {
+	ratio      float64
+	short      float64
+	long       float64
+	separation float64
+	pre, post  float64
+}
+
+// WithTransientRatio sets how many times the baseline RMS the short-window
+// RMS must reach to start an event, in place of DefaultTransientRatio.
+func WithTransientRatio(ratio float64) TransientOption {
+	return func(c *transientConfig) {
+		c.ratio = ratio
+	}
+}
+
+// WithTransientWindows sets the lengths in seconds of the short window,
+// which should span about one impact, and of the baseline window before it,
+// in place of DefaultTransientShort and DefaultTransientLong.
+func WithTransientWindows(short, long float64) TransientOption {
+	return func(c *transientConfig) {
+		c.short = short
+		c.long = long
+	}
+}
+
+// WithTransientSeparation sets the least time in seconds from the end of one
+// event to the start of the next; an event starting sooner is merged into the
+// one before, so the ringing of one impact is not counted twice.
+func WithTransientSeparation(seconds float64) TransientOption {
+	return func(c *transientConfig) {
+		c.separation = seconds
+	}
+}
+
+// WithTransientContext widens each event by pre seconds before its start and
+// post seconds after its end, so the measured peak and energy take in the
+// onset and the tail of the ringing.
+func WithTransientContext(pre, post float64) TransientOption {
+	return func(c *transientConfig) {
+		c.pre = max(pre, 0)
+		c.post = max(post, 0)
+	}
+}
+
+// DetectTransients finds impacts and other bursts in a vibration record by
+// comparing the RMS over a short window with the RMS over a long baseline
+// window just before it, as a seismic STA/LTA trigger does. An event starts
+// when the short-window RMS reaches the ratio times the baseline, which is
+// then held until the event ends, when the short-window RMS falls back below
+// the same level. Detection starts once a full baseline has been seen.
+//
+// Parameters:
+//   - data: A slice of Sample structs containing time and value data
+//   - opts: Options such as WithTransientRatio and WithTransientContext
+//
+// Returns:
+//   - []Transient: The events in time order
+func DetectTransients(data []SingleChannelSample, opts ...TransientOption) []Transient {
+	config := transientConfig{
+		ratio:      DefaultTransientRatio,
+		short:      DefaultTransientShort,
+		long:       DefaultTransientLong,
+		separation: DefaultTransientSeparation,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if len(data) < 2 || !(config.short > 0) || !(config.long > 0) || !(config.ratio > 0) {
+		return nil
+	}
+
+	// spans of samples [start, end] in trigger order, before context and merging
+	type span struct{ start, end int }
+	var spans []span
+	var shortSum, longSum compensatedSum
+	shortFrom, longFrom := 0, 0 // first samples in each window
+	triggered := false
+	var level float64 // squared trigger level, held during an event
+	for i, sample := range data {
+		shortSum.add(sample.Value * sample.Value)
+		for data[shortFrom].Time <= sample.Time-config.short {
+			v := data[shortFrom].Value
+			shortSum.add(-v * v)
+			longSum.add(v * v)
+			shortFrom++
+		}
+		for data[longFrom].Time <= sample.Time-config.short-config.long {
+			v := data[longFrom].Value
+			longSum.add(-v * v)
+			longFrom++
+		}
+		if longFrom == 0 {
+			continue // the baseline is not yet full
+		}
+
+		shortMS := shortSum.value() / float64(i-shortFrom+1)
+		if !triggered {
+			level = config.ratio * config.ratio * longSum.value() / float64(shortFrom-longFrom)
+			if shortMS >= level && shortMS > 0 {
+				triggered = true
+				spans = append(spans, span{start: i, end: i})
+			}
+			continue
+		}
+		spans[len(spans)-1].end = i
+		if shortMS < level {
+			triggered = false
+		}
+	}
+
+	var transients []Transient
+	lastEnd := math.Inf(-1)
+	for _, s := range spans {
+		start := data[s.start].Time - config.pre
+		end := data[s.end].Time + config.post
+		if len(transients) > 0 && start-lastEnd < config.separation {
+			previous := &transients[len(transients)-1]
+			previous.Duration = end - previous.StartTime
+		} else {
+			transients = append(transients, Transient{StartTime: start, Duration: end - start})
+		}
+		lastEnd = end
+	}
+
+	// measure each event over the samples it spans
+	i := 0
+	for k := range transients {
+		event := &transients[k]
+		end := event.StartTime + event.Duration
+		for i < len(data) && data[i].Time < event.StartTime {
+			i++
+		}
+		for j := i; j < len(data) && data[j].Time <= end; j++ {
+			v := data[j].Value
+			event.Peak = math.Max(event.Peak, math.Abs(v))
+			if j+1 < len(data) {
+				event.Energy += v * v * (data[j+1].Time - data[j].Time)
+			}
+		}
+	}
+	return transients
+}
@@ -0,0 +1,78 @@
+package dynamics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// burstTimes are the onsets of the bursts injected by burstRecord.
+var burstTimes = []float64{1.5, 3, 4.5, 6.2, 8}
+
+// burstRecord returns 10 s at 10 kHz of background noise with five decaying
+// 2 kHz bursts of amplitude 5.
+func burstRecord() []SingleChannelSample {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]SingleChannelSample, 100000)
+	for i := range data {
+		tm := float64(i) / 10000
+		v := 0.1 * rng.NormFloat64()
+		for _, onset := range burstTimes {
+			if tm >= onset {
+				v += 5 * math.Exp(-(tm-onset)/0.005) * math.Sin(2*math.Pi*2000*(tm-onset))
+			}
+		}
+		data[i] = SingleChannelSample{Time: tm, Value: v}
+	}
+	return data
+}
+
+func TestDetectTransients(t *testing.T) {
+	// Generate sample data
+	data := burstRecord()
+
+	// Run the test
+	transients := DetectTransients(data)
+	if len(transients) != len(burstTimes) {
+		t.Fatalf("got %d events, expected %d: %+v", len(transients), len(burstTimes), transients)
+	}
+	for k, event := range transients {
+		if math.Abs(event.StartTime-burstTimes[k]) > 0.002 {
+			t.Errorf("event %d starts at %v, expected %v", k, event.StartTime, burstTimes[k])
+		}
+		if event.Duration <= 0 || event.Duration > 0.05 {
+			t.Errorf("event %d lasts %v s", k, event.Duration)
+		}
+		if event.Peak < 3 || event.Peak > 6 {
+			t.Errorf("event %d peak %v, expected about 5", k, event.Peak)
+		}
+		// a burst of 25·e^(−2t/τ)·sin² holds 25·τ/4 of energy
+		if expected := 25 * 0.005 / 4; event.Energy < 0.5*expected || event.Energy > 1.1*expected {
+			t.Errorf("event %d energy %v, expected nearly %v", k, event.Energy, expected)
+		}
+	}
+}
+
+func TestDetectTransientsOptions(t *testing.T) {
+	data := burstRecord()
+
+	// context widens each event and takes in the whole burst
+	for k, event := range DetectTransients(data, WithTransientContext(0.005, 0.05)) {
+		if math.Abs(event.StartTime-(burstTimes[k]-0.005)) > 0.002 {
+			t.Errorf("event %d starts at %v, expected 5 ms before %v", k, event.StartTime, burstTimes[k])
+		}
+		if expected := 25 * 0.005 / 4; math.Abs(event.Energy-expected) > 0.1*expected {
+			t.Errorf("event %d energy %v, expected %v", k, event.Energy, expected)
+		}
+	}
+
+	// the first three bursts, 1.5 s apart, merge when events must be 1.6 s apart
+	if got := DetectTransients(data, WithTransientSeparation(1.6)); len(got) != 3 {
+		t.Errorf("1.6 s separation: got %d events, expected 3", len(got))
+	}
+
+	// an unreachable ratio finds nothing
+	if got := DetectTransients(data, WithTransientRatio(1000)); len(got) != 0 {
+		t.Errorf("ratio 1000: got %d events, expected none", len(got))
+	}
+}
@@ -0,0 +1,110 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// TrendAlarm reports a reading above the adaptive band of a TrendTracker.
+type TrendAlarm struct {
+	Time      float64 `json:"time"`
+	Value     float64 `json:"value"`     // the reading
+	Mean      float64 `json:"mean"`      // the baseline mean before the reading
+	Sigma     float64 `json:"sigma"`     // the baseline standard deviation before the reading
+	Deviation float64 `json:"deviation"` // (Value − Mean)/Sigma
+}
+
+// TrendTracker learns the baseline of a slowly sampled level, such as the RMS
+// of each measurement window, as an exponentially weighted mean and variance,
+// and reports readings above mean + k·sigma. The weight of each reading
+// follows the time elapsed since the one before, 1 − e^(−Δt/τ), so irregular
+// intervals are handled and a change in level is taken into the baseline over
+// about the time constant τ. No alarm is raised until the readings span one
+// time constant, nor while the readings have all been equal, which leaves
+// sigma at 0 and the band without width.
+//
+// A TrendTracker is safe for concurrent use. The callback is invoked while the
+// tracker's lock is held and must not call back into the tracker.
+type TrendTracker struct {
+	mu           sync.Mutex
+	timeConstant float64
+	k            float64
+	fn           func(TrendAlarm)
+	started      bool
+	first        float64 // time of the first reading
+	last         float64 // time of the latest reading
+	mean         float64
+	variance     float64
+}
+
+// NewTrendTracker creates a TrendTracker.
+//
+// Parameters:
+//   - timeConstant: The time constant in seconds over which the baseline adapts
+//   - k: The width of the alarm band in standard deviations above the mean
+//   - fn: Callback receiving each TrendAlarm
+//
+// Returns:
+//   - *TrendTracker: The new tracker
+//   - error: An error if timeConstant or k is not positive and finite, or fn is nil
+func NewTrendTracker(timeConstant, k float64, fn func(TrendAlarm)) (*TrendTracker, error) {
+	if !(timeConstant > 0) || math.IsInf(timeConstant, 1) {
+		return nil, errors.New("dynamics: trend time constant must be positive")
+	}
+	if !(k > 0) || math.IsInf(k, 1) {
+		return nil, errors.New("dynamics: trend band width must be positive")
+	}
+	if fn == nil {
+		return nil, errors.New("dynamics: trend callback is nil")
+	}
+	return &TrendTracker{timeConstant: timeConstant, k: k, fn: fn}, nil
+}
+
+// Push adds a reading to the tracker, raising a TrendAlarm if it lies above
+// the band, and then folds it into the baseline. Readings must arrive in time
+// order; one older than its predecessor is rejected with an error.
+func (tt *TrendTracker) Push(time, rms float64) error {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if !tt.started {
+		tt.started = true
+		tt.first, tt.last = time, time
+		tt.mean = rms
+		return nil
+	}
+	if time < tt.last {
+		return errors.New("dynamics: reading time is earlier than the previous reading")
+	}
+
+	sigma := math.Sqrt(tt.variance)
+	if time-tt.first >= tt.timeConstant && sigma > 0 && rms > tt.mean+tt.k*sigma {
+		tt.fn(TrendAlarm{Time: time, Value: rms, Mean: tt.mean, Sigma: sigma, Deviation: (rms - tt.mean) / sigma})
+	}
+
+	// exponentially weighted mean and variance, after Finch (2009)
+	alpha := 1 - math.Exp(-(time-tt.last)/tt.timeConstant)
+	diff := rms - tt.mean
+	increment := alpha * diff
+	tt.mean += increment
+	tt.variance = (1 - alpha) * (tt.variance + diff*increment)
+	tt.last = time
+	return nil
+}
+
+// Mean returns the baseline mean, or 0 before the first reading.
+func (tt *TrendTracker) Mean() float64 {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return tt.mean
+}
+
+// Sigma returns the baseline standard deviation, or 0 before the second reading.
+func (tt *TrendTracker) Sigma() float64 {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return math.Sqrt(tt.variance)
+}
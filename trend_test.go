@@ -0,0 +1,89 @@
+package dynamics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTrendTracker(t *testing.T) {
+	// Generate sample data: readings at irregular intervals of 30 to 90 s,
+	// steady at 1 ± 0.03 for 10 hours and then stepping up to 1.5
+	rng := rand.New(rand.NewSource(1))
+	var alarms []TrendAlarm
+	tracker, err := NewTrendTracker(3600, 4, func(alarm TrendAlarm) { alarms = append(alarms, alarm) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run the test
+	const step = 36000.0
+	for tm := 0.0; tm < step+5*3600; tm += 30 + 60*rng.Float64() {
+		level := 1.0
+		if tm >= step {
+			level = 1.5
+		}
+		if err := tracker.Push(tm, level+0.06*(rng.Float64()-0.5)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(alarms) == 0 {
+		t.Fatal("no alarm on the step")
+	}
+	if first := alarms[0]; first.Time < step || first.Time > step+90 {
+		t.Errorf("first alarm at %v s, expected at the step at %v s", first.Time, step)
+	} else if first.Deviation < 10 {
+		t.Errorf("deviation %v sigma at the step, expected far beyond the band", first.Deviation)
+	}
+	// the baseline takes in the new level over about the time constant
+	if last := alarms[len(alarms)-1]; last.Time > step+3600 {
+		t.Errorf("still alarming at %v s, expected to re-baseline within an hour", last.Time)
+	}
+	if mean := tracker.Mean(); math.Abs(mean-1.5) > 0.01 {
+		t.Errorf("mean %v after re-baselining, expected 1.5", mean)
+	}
+	if sigma := tracker.Sigma(); sigma > 0.05 {
+		t.Errorf("sigma %v after re-baselining, expected the noise of about 0.017", sigma)
+	}
+}
+
+func TestTrendTrackerInvalid(t *testing.T) {
+	fn := func(TrendAlarm) {}
+	if _, err := NewTrendTracker(0, 3, fn); err == nil {
+		t.Error("zero time constant: expected an error")
+	}
+	if _, err := NewTrendTracker(60, 0, fn); err == nil {
+		t.Error("zero k: expected an error")
+	}
+	if _, err := NewTrendTracker(60, 3, nil); err == nil {
+		t.Error("nil callback: expected an error")
+	}
+
+	tracker, _ := NewTrendTracker(60, 3, fn)
+	tracker.Push(10, 1)
+	if err := tracker.Push(5, 1); err == nil {
+		t.Error("out-of-order reading: expected an error")
+	}
+}
+
+func TestTrendTrackerConstantBaseline(t *testing.T) {
+	// Generate sample data: identical readings for two time constants, then a step
+	var alarms []TrendAlarm
+	tracker, _ := NewTrendTracker(60, 3, func(alarm TrendAlarm) { alarms = append(alarms, alarm) })
+
+	// Run the test: a band of zero width raises no alarm with an infinite deviation
+	for tm := 0.0; tm <= 120; tm += 10 {
+		tracker.Push(tm, 1)
+	}
+	tracker.Push(130, 2)
+	if len(alarms) != 0 {
+		t.Errorf("got %d alarms on a baseline without variation, the first %+v", len(alarms), alarms[0])
+	}
+
+	// once the step has given the baseline a spread, a further rise alarms
+	tracker.Push(140, 10)
+	if len(alarms) != 1 || math.IsInf(alarms[0].Deviation, 0) || !(alarms[0].Sigma > 0) {
+		t.Errorf("got alarms %+v, expected one with a finite deviation", alarms)
+	}
+}
@@ -0,0 +1,120 @@
+package dynamics
+
+import "math"
+
+// utilizationGap is the multiple of the usual spacing of the levels beyond
+// which UtilizationFromLevel takes an interval to be a gap in the data.
+const utilizationGap = 1.5
+
+// UtilizationReport summarises when a machine ran, from UtilizationFromLevel.
+type UtilizationReport struct {
+	RunTime     float64 `json:"runTime"`     // seconds running
+	OffTime     float64 `json:"offTime"`     // seconds stopped, including suppressed runs
+	UnknownTime float64 `json:"unknownTime"` // seconds in gaps in the data
+	Starts      int     `json:"starts"`      // runs seen to start from stopped
+	Runs        int     `json:"runs"`        // runs, including those starting or ending in a gap
+	LongestRun  float64 `json:"longestRun"`  // seconds
+	Utilization float64 `json:"utilization"` // RunTime over the known time, 0 when none is known
+}
+
+// UtilizationFromLevel tracks when a machine is running from a level such as
+// its vibration RMS. The machine starts when the level reaches onThreshold
+// and stops when it falls to offThreshold, so a level wavering between the
+// two does not flicker the state. Each level holds from its time until the
+// next, but an interval longer than one and a half times the usual spacing of
+// the levels is a gap in the data, whose time is unknown rather than off; a
+// run broken by a gap counts as two runs, and the one after the gap is not
+// counted as a start. Runs shorter than minRunDuration are taken to be noise
+// and counted as off time.
+//
+// Parameters:
+//   - levels: A slice of Sample structs holding levels in time order
+//   - onThreshold: The level at or above which the machine is running
+//   - offThreshold: The level at or below which it is stopped, no higher than onThreshold
+//   - minRunDuration: The shortest run in seconds that is counted
+//
+// Returns:
+//   - UtilizationReport: The run times and counts, zero when there are fewer
+//     than two levels or offThreshold is above onThreshold
+func UtilizationFromLevel(levels []SingleChannelSample, onThreshold, offThreshold float64, minRunDuration float64) UtilizationReport {
+	if len(levels) < 2 || offThreshold > onThreshold {
+		return UtilizationReport{}
+	}
+	spacings := make([]float64, len(levels)-1)
+	for i := range spacings {
+		spacings[i] = levels[i+1].Time - levels[i].Time
+	}
+	gap := utilizationGap * median(spacings)
+
+	// the record as alternating periods of each state
+	type period struct {
+		state      int // one of the states below
+		start, end float64
+	}
+	const (
+		stopped = iota
+		running
+		unknown
+	)
+	var periods []period
+	extend := func(state int, start, end float64) {
+		if n := len(periods); n > 0 && periods[n-1].state == state {
+			periods[n-1].end = end
+			return
+		}
+		periods = append(periods, period{state: state, start: start, end: end})
+	}
+	on := false
+	for i, level := range levels[:len(levels)-1] {
+		switch {
+		case level.Value >= onThreshold:
+			on = true
+		case level.Value <= offThreshold:
+			on = false
+		}
+		next := levels[i+1].Time
+		switch {
+		case next-level.Time > gap:
+			extend(unknown, level.Time, next)
+		case on:
+			extend(running, level.Time, next)
+		default:
+			extend(stopped, level.Time, next)
+		}
+	}
+
+	// suppress short runs, merging them into the periods around them
+	suppressed := periods[:0]
+	for _, p := range periods {
+		if p.state == running && p.end-p.start < minRunDuration {
+			p.state = stopped
+		}
+		if n := len(suppressed); n > 0 && suppressed[n-1].state == p.state {
+			suppressed[n-1].end = p.end
+			continue
+		}
+		suppressed = append(suppressed, p)
+	}
+
+	var report UtilizationReport
+	for i, p := range suppressed {
+		length := p.end - p.start
+		switch p.state {
+		case running:
+			report.RunTime += length
+			report.Runs++
+			report.LongestRun = math.Max(report.LongestRun, length)
+			if i > 0 && suppressed[i-1].state == stopped {
+				report.Starts++
+			}
+		case stopped:
+			report.OffTime += length
+		default:
+			report.UnknownTime += length
+		}
+	}
+	if known := report.RunTime + report.OffTime; known > 0 {
+		report.Utilization = report.RunTime / known
+	}
+	return report
+}
@@ -0,0 +1,50 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUtilizationFromLevel(t *testing.T) {
+	// Generate sample data: a level each second through 100 s off, a 300 s
+	// run, 50 s off, a 5 s blip, 45 s off, a 200 s run that is lost in a
+	// 100 s gap and carries on for 100 s after it, then 100 s off. The level
+	// hovers between the thresholds for the middle of the first run.
+	pattern := []struct {
+		level    float64
+		duration int
+	}{{0.1, 100}, {5, 100}, {1.5, 100}, {5, 100}, {0.1, 50}, {5, 5}, {0.1, 45}, {5, 200}, {math.NaN(), 100}, {5, 100}, {0.1, 100}}
+	var levels []SingleChannelSample
+	var tm float64
+	for _, p := range pattern {
+		for range p.duration {
+			if !math.IsNaN(p.level) {
+				levels = append(levels, SingleChannelSample{Time: tm, Value: p.level})
+			}
+			tm++
+		}
+	}
+
+	// Run the test: the gap runs from the last level before it to the first after
+	report := UtilizationFromLevel(levels, 2, 1, 30)
+	expected := UtilizationReport{
+		RunTime:     300 + 199 + 100,
+		OffTime:     100 + 100 + 99,
+		UnknownTime: 101,
+		Starts:      2,
+		Runs:        3,
+		LongestRun:  300,
+	}
+	expected.Utilization = expected.RunTime / (expected.RunTime + expected.OffTime)
+	if report != expected {
+		t.Errorf("got %+v, expected %+v", report, expected)
+	}
+
+	// without suppression the blip is a run of its own
+	if report := UtilizationFromLevel(levels, 2, 1, 0); report.Starts != 3 || report.Runs != 4 {
+		t.Errorf("no suppression: got %d starts and %d runs, expected 3 and 4", report.Starts, report.Runs)
+	}
+	if report := UtilizationFromLevel(levels, 1, 2, 30); report != (UtilizationReport{}) {
+		t.Errorf("off threshold above on: got %+v, expected zero", report)
+	}
+}
@@ -0,0 +1,133 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MachineClass is a machine group of ISO 10816-1, which sets the velocity
+// boundaries between the vibration severity zones.
+type MachineClass int
+
+const (
+	MachineClassI   MachineClass = iota + 1 // small machines, up to 15 kW
+	MachineClassII                          // medium machines, 15 to 75 kW, or up to 300 kW on special foundations
+	MachineClassIII                         // large machines on rigid foundations
+	MachineClassIV                          // large machines on soft foundations
+)
+
+// VibrationZone is an evaluation zone of ISO 10816/20816.
+type VibrationZone int
+
+const (
+	VibrationZoneA VibrationZone = iota + 1 // newly commissioned machines
+	VibrationZoneB                          // acceptable for unrestricted long-term operation
+	VibrationZoneC                          // unsatisfactory for long-term continuous operation
+	VibrationZoneD                          // severe enough to cause damage
+)
+
+// vibrationZoneLimits are the A/B, B/C and C/D zone boundaries of each
+// machine class, as velocity RMS in mm/s.
+var vibrationZoneLimits = map[MachineClass][3]float64{
+	MachineClassI:   {0.71, 1.8, 4.5},
+	MachineClassII:  {1.12, 2.8, 7.1},
+	MachineClassIII: {1.8, 4.5, 11.2},
+	MachineClassIV:  {2.8, 7.1, 18},
+}
+
+// The band over which ISO 10816 measures velocity, in Hz.
+const (
+	vibrationLowCutoff  = 10
+	vibrationHighCutoff = 1000
+)
+
+// vibrationSettle is the time in seconds that VibrationSeverity leaves for its
+// filters to settle before measuring. The slowest pole, that of the 10 Hz
+// high-pass, has decayed by over 10⁻⁴ in this time.
+const vibrationSettle = 0.25
+
+// VibrationResult is the vibration severity of a machine.
+type VibrationResult struct {
+	VelocityRMS float64       `json:"velocityRms"` // band-limited velocity RMS in mm/s
+	Zone        VibrationZone `json:"zone"`
+}
+
+// VibrationSeverity classifies the vibration of a machine from an
+// accelerometer record, as ISO 10816/20816 does: the acceleration is converted
+// to m/s², limited to the 10 Hz to 1000 Hz band by second-order Butterworth
+// filters and integrated to velocity, a second 10 Hz high-pass removes the
+// drift of the integration, and the RMS of the velocity is compared with the
+// zone boundaries of the machine class. The low-pass is left out when the
+// sample rate is too low to place it below the Nyquist frequency.
+//
+// The samples are taken to be evenly spaced. The first quarter second, while
+// the filters settle, is not measured.
+//
+// Parameters:
+//   - acceleration: A slice of Sample structs holding acceleration
+//   - machineClass: The ISO 10816-1 class of the machine
+//   - scale: The factor converting sample values to m/s², such as 9.80665 for
+//     values in g, or 1/sensitivity for an accelerometer read in volts
+//
+// Returns:
+//   - VibrationResult: The velocity RMS and its zone, zero on error
+//   - error: ErrEmptyData if there is no data, ErrUnsortedData if it is not
+//     in time order, ErrTooShort if less than a cycle at 10 Hz follows the
+//     settling time, or an error if machineClass is unknown or scale is not
+//     positive and finite
+func VibrationSeverity(acceleration []SingleChannelSample, machineClass MachineClass, scale float64) (VibrationResult, error) {
+	if len(acceleration) == 0 {
+		return VibrationResult{}, ErrEmptyData
+	}
+	limits, ok := vibrationZoneLimits[machineClass]
+	if !ok {
+		return VibrationResult{}, fmt.Errorf("dynamics: unknown machine class %d", machineClass)
+	}
+	if !(scale > 0) || math.IsInf(scale, 1) {
+		return VibrationResult{}, errors.New("dynamics: acceleration scale must be positive")
+	}
+	if err := checkTimeOrder(acceleration); err != nil {
+		return VibrationResult{}, err
+	}
+	start := acceleration[0].Time + vibrationSettle
+	if acceleration[len(acceleration)-1].Time-start < 1.0/vibrationLowCutoff {
+		return VibrationResult{}, fmt.Errorf("%w: %g s of vibration data leaves less than one 10 Hz cycle after settling", ErrTooShort, acceleration[len(acceleration)-1].Time-acceleration[0].Time)
+	}
+	step := (acceleration[len(acceleration)-1].Time - acceleration[0].Time) / float64(len(acceleration)-1)
+
+	values := sampleValues(acceleration)
+	for i := range values {
+		values[i] *= scale
+	}
+	highPass := highPassBiquad(vibrationLowCutoff*step, 1/math.Sqrt2)
+	highPass.filter(values)
+	if vibrationHighCutoff*step < 0.45 {
+		lowPass := lowPassBiquad(vibrationHighCutoff*step, 1/math.Sqrt2)
+		lowPass.filter(values)
+	}
+
+	integrate(values, step) // to velocity in m/s
+	highPass.filter(values)
+
+	var sumSq float64
+	var n int
+	for i, v := range values {
+		if acceleration[i].Time >= start {
+			sumSq += v * v
+			n++
+		}
+	}
+	result := VibrationResult{VelocityRMS: 1000 * math.Sqrt(sumSq/float64(n))}
+	switch {
+	case result.VelocityRMS <= limits[0]:
+		result.Zone = VibrationZoneA
+	case result.VelocityRMS <= limits[1]:
+		result.Zone = VibrationZoneB
+	case result.VelocityRMS <= limits[2]:
+		result.Zone = VibrationZoneC
+	default:
+		result.Zone = VibrationZoneD
+	}
+	return result, nil
+}
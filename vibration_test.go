@@ -0,0 +1,83 @@
+package dynamics
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestVibrationSeverity(t *testing.T) {
+	// Generate sample data: 2 s of a 100 Hz acceleration at 10 kHz, in g,
+	// whose velocity amplitude is A/ω
+	const amplitude = 0.5 // g
+	data := make([]SingleChannelSample, 20000)
+	for i := range data {
+		tm := float64(i) / 10000
+		data[i] = SingleChannelSample{Time: tm, Value: amplitude * math.Sin(2*math.Pi*100*tm)}
+	}
+	expected := 1000 * amplitude * 9.80665 / (2 * math.Pi * 100) / math.Sqrt2
+
+	// Run the test
+	result, err := VibrationSeverity(data, MachineClassII, 9.80665)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.VelocityRMS-expected) > 0.005*expected {
+		t.Errorf("velocity RMS %v mm/s, expected %v", result.VelocityRMS, expected)
+	}
+	// about 5.5 mm/s is zone C for class II and zone B for class IV
+	if result.Zone != VibrationZoneC {
+		t.Errorf("zone %v, expected C", result.Zone)
+	}
+	if result, _ := VibrationSeverity(data, MachineClassIV, 9.80665); result.Zone != VibrationZoneB {
+		t.Errorf("class IV zone %v, expected B", result.Zone)
+	}
+}
+
+func TestVibrationSeverityBand(t *testing.T) {
+	// Generate sample data: 100 Hz acceleration with a large offset and a
+	// 3 kHz component, both outside the band
+	data := make([]SingleChannelSample, 20000)
+	for i := range data {
+		tm := float64(i) / 10000
+		data[i] = SingleChannelSample{Time: tm, Value: 5 + math.Sin(2*math.Pi*100*tm) + math.Sin(2*math.Pi*3000*tm)}
+	}
+	expected := 1000 / (2 * math.Pi * 100) / math.Sqrt2
+
+	// Run the test
+	result, err := VibrationSeverity(data, MachineClassI, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.VelocityRMS-expected) > 0.01*expected {
+		t.Errorf("velocity RMS %v mm/s, expected %v", result.VelocityRMS, expected)
+	}
+}
+
+func TestVibrationSeverityErrors(t *testing.T) {
+	data := GenerateSineWave(100, 1, 1, 10000)
+	if _, err := VibrationSeverity(nil, MachineClassI, 1); !errors.Is(err, ErrEmptyData) {
+		t.Errorf("empty data: got %v", err)
+	}
+	if _, err := VibrationSeverity(data, MachineClass(0), 1); err == nil {
+		t.Error("unknown machine class: expected an error")
+	}
+	if _, err := VibrationSeverity(data, MachineClassI, 0); err == nil {
+		t.Error("zero scale: expected an error")
+	}
+	if _, err := VibrationSeverity(data[:3000], MachineClassI, 1); !errors.Is(err, ErrTooShort) {
+		t.Errorf("short data: got %v", err)
+	}
+}
+
+func TestVibrationResultJSON(t *testing.T) {
+	data, err := json.Marshal(VibrationResult{VelocityRMS: 2.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"velocityRms":2.5`) {
+		t.Errorf("got %s, expected the camelCase key velocityRms", data)
+	}
+}
@@ -0,0 +1,234 @@
+package dynamics
+
+import "math"
+
+// slidingWindow holds the samples of the last length seconds of a stream of
+// one or more channels, together with running per-channel sums, so that RMS
+// and crossing counts are cheap to read and the cost of a push scales with
+// the number of channels but not the window length.
+type slidingWindow struct {
+	length    float64
+	channels  int
+	times     []float64
+	values    []float64        // row-major: sample i of channel c is values[i*channels+c]
+	crossed   []bool           // row-major: a negative-going crossing into sample i of channel c
+	start     int              // index of the oldest sample still in the window
+	sumSq     []compensatedSum // compensated so that adding and removing squares does not drift
+	crossings []int
+	nonzero   []int // per channel, index of the most recent nonzero sample, below start if none is in the window
+
+	// storage taken by borrowScratch, nil otherwise
+	timesScratch, valuesScratch *[]float64
+	crossedScratch              *[]bool
+}
+
+// newSlidingWindow creates a window of the given length in seconds.
+func newSlidingWindow(length float64, channels int) *slidingWindow {
+	w := &slidingWindow{
+		length:    length,
+		channels:  channels,
+		sumSq:     make([]compensatedSum, channels),
+		crossings: make([]int, channels),
+		nonzero:   make([]int, channels),
+	}
+	for c := range w.nonzero {
+		w.nonzero[c] = -1
+	}
+	return w
+}
+
+// borrowScratch takes the window's storage from the scratch pools. The
+// caller must hand it back with releaseScratch once it has finished with the
+// window.
+func (w *slidingWindow) borrowScratch() {
+	w.timesScratch, w.valuesScratch, w.crossedScratch = floatScratch.get(0), floatScratch.get(0), boolScratch.get(0)
+	w.times, w.values, w.crossed = *w.timesScratch, *w.valuesScratch, *w.crossedScratch
+}
+
+// releaseScratch hands the window's storage, which may have outgrown what
+// borrowScratch took, back to the pools. The window must not be used
+// afterwards.
+func (w *slidingWindow) releaseScratch() {
+	*w.timesScratch, *w.valuesScratch, *w.crossedScratch = w.times, w.values, w.crossed
+	floatScratch.put(w.timesScratch)
+	floatScratch.put(w.valuesScratch)
+	boolScratch.put(w.crossedScratch)
+	w.times, w.values, w.crossed = nil, nil, nil
+	w.timesScratch, w.valuesScratch, w.crossedScratch = nil, nil, nil
+}
+
+// len returns the number of samples in the window.
+func (w *slidingWindow) len() int {
+	return len(w.times) - w.start
+}
+
+// push appends a sample with one value per channel and evicts the samples
+// that have left the window.
+//
+// Crossings follow the convention of crossingDetector, restricted to the
+// window: a crossing is only counted while the nonzero sample that gave the
+// sign before it is still inside the window.
+func (w *slidingWindow) push(t float64, values []float64) {
+	row := len(w.times)
+	for c, value := range values {
+		crossed := false
+		if value > 0 || value < 0 {
+			if last := w.nonzero[c]; last >= w.start {
+				crossed = w.values[last*w.channels+c] > 0 && value < 0
+			}
+			w.nonzero[c] = row
+		}
+		if crossed {
+			w.crossings[c]++
+		}
+		w.crossed = append(w.crossed, crossed)
+		w.sumSq[c].add(value * value)
+	}
+	w.times = append(w.times, t)
+	w.values = append(w.values, values...)
+
+	cutoff := t - w.length
+	cutoff += timeTolerance(cutoff, w.length)
+	for w.times[w.start] <= cutoff {
+		row := w.start * w.channels
+		w.start++
+		for c := 0; c < w.channels; c++ {
+			value := w.values[row+c]
+			w.sumSq[c].add(-value * value)
+			if !(value > 0 || value < 0) {
+				// the evicted zero gave no sign, so no crossing depended on it
+				continue
+			}
+
+			// the first nonzero sample left in the window took its sign from
+			// the evicted one, so its crossing is no longer inside the window
+			for i := w.start; i < len(w.times); i++ {
+				next := i*w.channels + c
+				if v := w.values[next]; v > 0 || v < 0 {
+					if w.crossed[next] {
+						w.crossed[next] = false
+						w.crossings[c]--
+					}
+					break
+				}
+			}
+		}
+	}
+
+	// reclaim the evicted prefix once it dominates the backing arrays
+	if w.start > 64 && w.start > len(w.times)/2 {
+		n := copy(w.times, w.times[w.start:])
+		copy(w.values, w.values[w.start*w.channels:])
+		copy(w.crossed, w.crossed[w.start*w.channels:])
+		w.times = w.times[:n]
+		w.values = w.values[:n*w.channels]
+		w.crossed = w.crossed[:n*w.channels]
+		for c := range w.nonzero {
+			w.nonzero[c] -= w.start
+		}
+		w.start = 0
+	}
+}
+
+// samples returns a copy of one channel of the samples currently in the window.
+func (w *slidingWindow) samples(channel int) []SingleChannelSample {
+	result := make([]SingleChannelSample, w.len())
+	for i := range result {
+		row := w.start + i
+		result[i] = SingleChannelSample{Time: w.times[row], Value: w.values[row*w.channels+channel]}
+	}
+	return result
+}
+
+// result returns the analysis of one channel of the samples currently in the window.
+func (w *slidingWindow) result(channel int) AnalysisResult {
+	n := w.len()
+	if n == 0 {
+		return AnalysisResult{}
+	}
+
+	peak := 0.0
+	for i := w.start; i < len(w.times); i++ {
+		peak = math.Max(peak, math.Abs(w.values[i*w.channels+channel]))
+	}
+
+	first, last := w.times[w.start], w.times[len(w.times)-1]
+	nzcr := 0.0
+	if duration := last - first; duration > 0 {
+		nzcr = float64(w.crossings[channel]) / duration
+	}
+
+	// the running sum can drift fractionally below zero on silent input
+	rms := math.Sqrt(math.Max(w.sumSq[channel].value(), 0) / float64(n))
+	return AnalysisResult{
+		Time:        last,
+		RMS:         rms,
+		Peak:        peak,
+		NZCR:        nzcr,
+		Samples:     n,
+		RMSSpan:     last - first,
+		CrestFactor: crestFactorOf(peak, rms),
+	}
+}
+
+// hopSchedule decides when a windowed stream is due to produce a result: first
+// once a full window has been seen, then every hop seconds of sample time.
+type hopSchedule struct {
+	window  float64
+	hop     float64
+	origin  float64 // time of the first sample
+	hops    int     // number of hops scheduled after the first result
+	started bool
+}
+
+// advance records a sample time and reports whether a result is due. At most
+// one result is due per sample, even if the stream jumped over several hops.
+func (s *hopSchedule) advance(t float64) bool {
+	if !s.started {
+		s.started = true
+		s.origin = t
+	}
+	if !s.due(t) {
+		return false
+	}
+	for s.due(t) {
+		s.hops++
+	}
+	return true
+}
+
+// due reports whether the next result is due at time t. Due times are derived
+// from the hop count rather than accumulated, so they do not drift.
+func (s *hopSchedule) due(t float64) bool {
+	return s.dueAfter(s.hops, t)
+}
+
+// dueAfter reports whether the result following the given number of hops is
+// due at time t.
+func (s *hopSchedule) dueAfter(hops int, t float64) bool {
+	next := s.origin + s.window + float64(hops)*s.hop
+	return t >= next-timeTolerance(next, s.hop)
+}
+
+// hopsBy returns the hop count of a started schedule once it has seen a
+// sample at time t: the fewest hops after which no result is due at t.
+// Advancing sample by sample arrives at the same count.
+func (s *hopSchedule) hopsBy(t float64) int {
+	// estimate, then correct for the tolerance and rounding
+	hops := max(int(math.Floor((t-s.origin-s.window)/s.hop)), 0)
+	for hops > 0 && !s.dueAfter(hops-1, t) {
+		hops--
+	}
+	for s.dueAfter(hops, t) {
+		hops++
+	}
+	return hops
+}
+
+// timeTolerance returns the margin used when comparing timestamps derived by
+// arithmetic, such as window edges, against sample times. It absorbs rounding
+// in both the timestamps and the arithmetic for a time t and an interval of
+// the given scale.
+func timeTolerance(t, scale float64) float64 {
+	return 1e-9*scale + 1e-15*math.Abs(t)
+}
@@ -0,0 +1,178 @@
+package dynamics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// CrossCorrelate returns the cross-correlation of two channels, such as two
+// accelerometers on the same machine, against the delay of b relative to a.
+// At a positive lag b follows a, b(t) ≈ a(t − lag), so the lag of the peak is
+// the propagation delay from the first sensor to the second.
+//
+// Only the overlapping part of the records is correlated: samples more than
+// half a step outside the span both cover are dropped and the longer channel
+// is cut to the length of the shorter. The means are removed and the
+// correlation Σ aₙ·bₙ₊ₖ is normalised by the root of the product of the sums
+// of squares, so it lies between −1 and 1. Lags are converted to seconds
+// through the Time fields, including any offset between the first overlapping
+// samples of the two channels. Both channels must be evenly spaced, as for
+// Spectrum, at the same sample rate.
+//
+// Parameters:
+//   - a: The reference channel
+//   - b: The delayed channel
+//   - maxLagSeconds: The largest lag either way, cut to the length of the overlap
+//
+// Returns:
+//   - lags: The lags in seconds, in increasing order
+//   - corr: The correlation at each lag
+//   - err: As for Spectrum for either channel, an error wrapping ErrMisaligned
+//     if the sample rates differ or the records do not overlap, or an error if
+//     maxLagSeconds is negative or NaN or either channel is constant
+func CrossCorrelate(a, b []SingleChannelSample, maxLagSeconds float64) (lags []float64, corr []float64, err error) {
+	if !(maxLagSeconds >= 0) {
+		return nil, nil, fmt.Errorf("dynamics: maximum lag %g s is negative or NaN", maxLagSeconds)
+	}
+	stepA, err := evenStep(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	stepB, err := evenStep(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if math.Abs(stepA-stepB) > spacingTolerance*stepA {
+		return nil, nil, fmt.Errorf("%w: sample intervals of %g s and %g s", ErrMisaligned, stepA, stepB)
+	}
+
+	// keep the overlap
+	start := math.Max(a[0].Time, b[0].Time) - stepA/2
+	end := math.Min(a[len(a)-1].Time, b[len(b)-1].Time) + stepA/2
+	a, b = overlapping(a, start, end), overlapping(b, start, end)
+	n := min(len(a), len(b))
+	if n < 2 {
+		return nil, nil, fmt.Errorf("%w: the records do not overlap", ErrMisaligned)
+	}
+	xScratch, yScratch := floatScratch.get(n), floatScratch.get(n)
+	defer floatScratch.put(xScratch)
+	defer floatScratch.put(yScratch)
+	x, y := *xScratch, *yScratch
+	meanA, meanB := Mean(a[:n]), Mean(b[:n])
+	var xx, yy float64
+	for i := range x {
+		x[i], y[i] = a[i].Value-meanA, b[i].Value-meanB
+		xx += x[i] * x[i]
+		yy += y[i] * y[i]
+	}
+	if !(xx > 0 && yy > 0) {
+		return nil, nil, errors.New("dynamics: cannot correlate a constant signal")
+	}
+
+	maxLag := n - 1
+	if lag := maxLagSeconds / stepA; lag < float64(maxLag) {
+		maxLag = int(lag + 0.5)
+	}
+	corr = crossCorrelation(x, y, maxLag)
+	scale := math.Sqrt(xx * yy)
+	offset := b[0].Time - a[0].Time
+	lags = make([]float64, len(corr))
+	for i := range corr {
+		corr[i] /= scale
+		lags[i] = float64(i-maxLag)*stepA + offset
+	}
+	return lags, corr, nil
+}
+
+// EstimateLag estimates the delay of b relative to a from the peak of their
+// cross-correlation over every lag of the overlap, placed between samples by a
+// parabola through it and its neighbours. It is positive when b follows a.
+//
+// Parameters:
+//   - a: The reference channel
+//   - b: The delayed channel
+//
+// Returns:
+//   - float64: The delay in seconds, or 0 on error
+//   - error: As for CrossCorrelate
+func EstimateLag(a, b []SingleChannelSample) (float64, error) {
+	lags, corr, err := CrossCorrelate(a, b, math.Inf(1))
+	if err != nil {
+		return 0, err
+	}
+	peak := 0
+	for i := range corr {
+		if corr[i] > corr[peak] {
+			peak = i
+		}
+	}
+	offset := 0.0
+	if peak > 0 && peak < len(corr)-1 {
+		l, c, r := corr[peak-1], corr[peak], corr[peak+1]
+		if curvature := l - 2*c + r; curvature < 0 {
+			offset = (l - r) / (2 * curvature)
+		}
+		return lags[peak] + offset*(lags[peak+1]-lags[peak]), nil
+	}
+	return lags[peak], nil
+}
+
+// overlapping returns the samples of the time-ordered data from start to end.
+func overlapping(data []SingleChannelSample, start, end float64) []SingleChannelSample {
+	first := 0
+	for first < len(data) && data[first].Time < start {
+		first++
+	}
+	last := len(data)
+	for last > first && data[last-1].Time > end {
+		last--
+	}
+	return data[first:last]
+}
+
+// crossCorrelation returns Σ xₙ·yₙ₊ₖ for k from −maxLag to maxLag, summed
+// directly up to acfDirectLimit products and computed through the FFT beyond,
+// with the values padded to at least twice their length so that the
+// correlation does not wrap around.
+//
+// Parameters:
+//   - x: Evenly spaced values
+//   - y: Values at the same times as x
+//   - maxLag: The largest lag either way, less than the number of values
+//
+// Returns:
+//   - []float64: The correlation at each lag, starting from −maxLag
+func crossCorrelation(x, y []float64, maxLag int) []float64 {
+	n := len(x)
+	corr := make([]float64, 2*maxLag+1)
+	if n*len(corr) <= acfDirectLimit {
+		for k := -maxLag; k <= maxLag; k++ {
+			for i := max(0, -k); i < min(n, n-k); i++ {
+				corr[k+maxLag] += x[i] * y[i+k]
+			}
+		}
+		return corr
+	}
+
+	size := 1 << bits.Len(uint(2*n-1))
+	fxScratch, fyScratch := complexScratch.get(size), complexScratch.get(size)
+	defer complexScratch.put(fxScratch)
+	defer complexScratch.put(fyScratch)
+	fx, fy := *fxScratch, *fyScratch
+	for i := range x {
+		fx[i] = complex(x[i], 0)
+		fy[i] = complex(y[i], 0)
+	}
+	fft(fx)
+	fft(fy)
+	for i := range fx {
+		fx[i] = complex(real(fx[i]), -imag(fx[i])) * fy[i]
+	}
+	ifft(fx)
+	for k := -maxLag; k <= maxLag; k++ {
+		corr[k+maxLag] = real(fx[(k+size)%size])
+	}
+	return corr
+}
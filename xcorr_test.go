@@ -0,0 +1,132 @@
+package dynamics
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// delayed returns a copy of the data with the values delayed by the given
+// number of samples, silence filling the start.
+func delayed(data []SingleChannelSample, samples int) []SingleChannelSample {
+	out := make([]SingleChannelSample, len(data))
+	for i := range data {
+		out[i].Time = data[i].Time
+		if i >= samples {
+			out[i].Value = data[i-samples].Value
+		}
+	}
+	return out
+}
+
+func TestEstimateLag(t *testing.T) {
+	// Generate sample data: a 5-cycle 200 Hz burst sampled at 10 kHz, and the
+	// same burst 37 samples, 3.7 ms, later
+	const step = 1e-4
+	a := GenerateToneBurst(200, 1, 0.02, 0.025, 0.1, 10000)
+	b := delayed(a, 37)
+
+	cases := []struct {
+		name string
+		a, b []SingleChannelSample
+		lag  float64
+	}{
+		{"same length", a, b, 37 * step},
+		{"reversed", b, a, -37 * step},
+		{"b shorter", a, b[:900], 37 * step},
+		{"b starts later", a, b[150:], 37 * step},
+		{"a starts later", a[100:], b[:950], 37 * step},
+	}
+
+	for _, c := range cases {
+		// Run the test
+		lag, err := EstimateLag(c.a, c.b)
+		if err != nil {
+			t.Fatalf("%s: EstimateLag returned error: %v", c.name, err)
+		}
+		if math.Abs(lag-c.lag) > step {
+			t.Errorf("%s: EstimateLag returned %v s, expected %v s", c.name, lag, c.lag)
+		}
+	}
+}
+
+func TestCrossCorrelate(t *testing.T) {
+	// Generate sample data: 600 samples, short enough for 101 lags to be
+	// summed directly
+	a := GenerateToneBurst(200, 1, 0.01, 0.025, 0.06, 10000)
+	b := delayed(a, 37)
+
+	// Run the test
+	lags, corr, err := CrossCorrelate(a, b, 0.005)
+	if err != nil {
+		t.Fatalf("CrossCorrelate returned error: %v", err)
+	}
+	if len(lags) != 101 || len(corr) != 101 {
+		t.Fatalf("CrossCorrelate returned %d lags and %d coefficients, expected 101", len(lags), len(corr))
+	}
+	if math.Abs(lags[0]+0.005) > 1e-9 || math.Abs(lags[100]-0.005) > 1e-9 {
+		t.Errorf("lags run from %v to %v, expected -0.005 to 0.005", lags[0], lags[100])
+	}
+	peak := 0
+	for i := range corr {
+		if math.Abs(corr[i]) > 1+1e-12 {
+			t.Errorf("coefficient %v at lag %v outside [-1, 1]", corr[i], lags[i])
+		}
+		if corr[i] > corr[peak] {
+			peak = i
+		}
+	}
+	if peak != 50+37 {
+		t.Errorf("peak at lag %v, expected 0.0037", lags[peak])
+	}
+
+	// the FFT agrees with the direct sum
+	_, all, err := CrossCorrelate(a, b, math.Inf(1))
+	if err != nil {
+		t.Fatalf("CrossCorrelate returned error: %v", err)
+	}
+	if len(all) != 2*len(a)-1 {
+		t.Fatalf("CrossCorrelate returned %d coefficients, expected %d", len(all), 2*len(a)-1)
+	}
+	for i := range corr {
+		if d := all[len(a)-1-50+i]; math.Abs(d-corr[i]) > 1e-12 {
+			t.Errorf("lag %v: FFT %v, direct %v", lags[i], d, corr[i])
+		}
+	}
+}
+
+func TestCrossCorrelateInvalid(t *testing.T) {
+	a := GenerateSineWave(50, 1, 0.1, 10000)
+	later := GenerateSineWave(50, 1, 0.1, 10000)
+	for i := range later {
+		later[i].Time += 1
+	}
+
+	cases := []struct {
+		name   string
+		a, b   []SingleChannelSample
+		maxLag float64
+		err    error
+	}{
+		{"empty", nil, a, 1, ErrEmptyData},
+		{"different rates", a, GenerateSineWave(50, 1, 0.1, 5000), 1, ErrMisaligned},
+		{"no overlap", a, later, 1, ErrMisaligned},
+		{"negative lag", a, a, -1, nil},
+		{"NaN lag", a, a, math.NaN(), nil},
+		{"constant", a, make([]SingleChannelSample, len(a)), 1, nil},
+	}
+	for i := range cases[5].b {
+		cases[5].b[i].Time = a[i].Time
+	}
+
+	for _, c := range cases {
+		// Run the test
+		lags, corr, err := CrossCorrelate(c.a, c.b, c.maxLag)
+		if err == nil || lags != nil || corr != nil {
+			t.Errorf("%s: CrossCorrelate returned %d lags, %v; expected an error", c.name, len(lags), err)
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: CrossCorrelate returned %v, expected %v", c.name, err, c.err)
+		}
+	}
+}